@@ -1,19 +1,74 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
 
 	"github.com/appxpy/hive-test/config"
 	"github.com/appxpy/hive-test/internal/app"
 )
 
 func main() {
+	path := flag.String("config", "", "config file, overrides CONFIG_PATH (default ./config/config.yml)")
+	profile := flag.String("profile", "", "profile: dev, test or prod, overrides APP_PROFILE (default dev)")
+	storage := flag.String("storage", "", "storage DSN, overrides the config: postgres://..., sqlite://PATH or memory")
+	flag.Parse()
+
+	opts := []config.Option{
+		config.Path(*path),
+		config.Profile(*profile),
+		config.Override(func(cfg *config.Config) {
+			if *storage != "" {
+				cfg.Storage.DSN = *storage
+			}
+		}),
+	}
+
+	// app config print
+	if flag.Arg(0) == "config" {
+		if err := printConfig(flag.Args()[1:], opts); err != nil {
+			log.Fatalf("Config error: %s", err)
+		}
+
+		return
+	}
+
 	// Configuration
-	cfg, err := config.NewConfig()
+	cfg, err := config.NewConfig(opts...)
 	if err != nil {
 		log.Fatalf("Config error: %s", err)
 	}
 
+	// app migrate up | down [N] | goto VERSION | force VERSION | version
+	if flag.Arg(0) == "migrate" {
+		if err = app.Migrate(cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migrate error: %s", err)
+		}
+
+		return
+	}
+
 	// Run
-	app.Run(cfg)
+	app.Run(config.NewStore(cfg, opts...))
+}
+
+// printConfig prints the effective config with secrets redacted, then
+// reports whether it is valid, so that an invalid config can be inspected.
+func printConfig(args []string, opts []config.Option) error {
+	if len(args) != 1 || args[0] != "print" {
+		log.Fatal("Usage: app [-config PATH] [-profile PROFILE] config print")
+	}
+
+	cfg, err := config.NewConfig(append(opts, config.SkipValidation())...)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.Print(os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	return cfg.Validate()
 }
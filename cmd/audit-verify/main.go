@@ -0,0 +1,58 @@
+// Command audit-verify walks the audit log hash chain and exits non-zero
+// when an entry was modified, removed or inserted out of band.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/repo"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+func main() {
+	path := flag.String("config", "", "config file, overrides CONFIG_PATH (default ./config/config.yml)")
+	flag.Parse()
+
+	// Configuration
+	cfg, err := config.NewConfig(config.Path(*path))
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+
+	res, err := verify(cfg)
+	if err != nil {
+		log.Fatalf("Audit verify error: %s", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(res)
+
+	if !res.Valid {
+		os.Exit(1)
+	}
+}
+
+func verify(cfg *config.Config) (*entity.AuditVerification, error) {
+	pg, err := postgres.New(cfg.PG.URL,
+		postgres.MaxPoolSize(cfg.PG.PoolMax),
+		postgres.ConnAttempts(cfg.PG.ConnAttempts),
+		postgres.ConnTimeout(cfg.PG.ConnTimeout),
+		postgres.DialTimeout(cfg.PG.DialTimeout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer pg.Close()
+
+	auditUseCase := usecase.NewAuditUseCase(repo.NewAuditRepo(pg))
+
+	return auditUseCase.VerifyChain(context.Background())
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/appxpy/hive-test/internal/entity"
+)
+
+func (c *cli) asset(ctx context.Context, args []string) error {
+	name, args, err := subcommand("asset", args)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "list":
+		return c.assetList(ctx, args)
+	case "transfer":
+		return c.assetTransfer(ctx, args)
+	case "delete":
+		return c.assetDelete(ctx, args)
+	default:
+		return fmt.Errorf("%w: unknown asset subcommand %q", errUsage, name)
+	}
+}
+
+func (c *cli) assetList(ctx context.Context, args []string) error {
+	fs := c.flags("asset list")
+	owner := fs.Int64("owner", 0, "list only the assets of this user ID")
+	after := fs.Int64("after", 0, "list assets with a greater ID")
+	limit := fs.Uint64("limit", 100, "maximum number of assets")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	assets, err := c.admin.ListAssets(ctx, entity.AssetFilter{UserID: *owner, AfterID: *after, Limit: *limit})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(assets))
+
+	for _, a := range assets {
+		rows = append(rows, []string{
+			strconv.FormatInt(a.ID, 10),
+			strconv.FormatInt(a.UserID, 10),
+			a.Name,
+			strconv.FormatFloat(a.Price, 'f', 2, 64),
+		})
+	}
+
+	return c.out.table(assets, []string{"ID", "OWNER", "NAME", "PRICE"}, rows)
+}
+
+func (c *cli) assetTransfer(ctx context.Context, args []string) error {
+	fs := c.flags("asset transfer")
+	id := fs.Int64("id", 0, "asset ID")
+	to := fs.String("to", "", "username of the new owner")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	before, err := c.admin.TransferAsset(ctx, *id, *to)
+	if err != nil {
+		return err
+	}
+
+	return c.result("transferred asset %d %q from user %d to %s", before.ID, before.Name, before.UserID, *to)
+}
+
+func (c *cli) assetDelete(ctx context.Context, args []string) error {
+	fs := c.flags("asset delete")
+	id := fs.Int64("id", 0, "asset ID")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	deleted, err := c.admin.DeleteAsset(ctx, *id)
+	if err != nil {
+		return err
+	}
+
+	return c.result("deleted asset %d %q of user %d", deleted.ID, deleted.Name, deleted.UserID)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+func (c *cli) ledger(ctx context.Context, args []string) error {
+	name, args, err := subcommand("ledger", args)
+	if err != nil {
+		return err
+	}
+
+	if name != "check" {
+		return fmt.Errorf("%w: unknown ledger subcommand %q", errUsage, name)
+	}
+
+	if err = c.parse(c.flags("ledger check"), args); err != nil {
+		return err
+	}
+
+	if err = c.connect(); err != nil {
+		return err
+	}
+
+	report, err := c.admin.CheckLedger(ctx)
+	if err != nil {
+		return err
+	}
+
+	if c.out.json {
+		err = c.out.encode(report)
+	} else {
+		rows := make([][]string, 0, len(report.Mismatches))
+		for _, m := range report.Mismatches {
+			rows = append(rows, []string{
+				strconv.FormatInt(m.AssetID, 10),
+				strconv.FormatInt(m.ExpectedOwner, 10),
+				strconv.FormatInt(m.ActualOwner, 10),
+				m.Reason,
+			})
+		}
+
+		chain := "valid"
+		if !report.Chain.Valid {
+			chain = fmt.Sprintf("broken at entry %d: %s", report.Chain.BrokenAt, report.Chain.Reason)
+		}
+
+		fmt.Fprintf(c.out.w, "audit chain: %s (%d entries)\n", chain, report.Chain.Checked)
+		fmt.Fprintf(c.out.w, "assets: %d checked against %d audit entries, %d untracked\n",
+			report.Assets, report.Entries, len(report.Untracked))
+
+		if len(rows) > 0 {
+			err = c.out.table(nil, []string{"ASSET", "EXPECTED OWNER", "ACTUAL OWNER", "REASON"}, rows)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !report.Consistent {
+		return exitError(1)
+	}
+
+	return nil
+}
+
+func (c *cli) export(ctx context.Context, args []string) error {
+	fs := c.flags("export")
+	file := fs.String("file", "", "write to this file instead of stdout")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	data, err := c.admin.ExportData(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The export is JSON whatever the output format.
+	w := os.Stdout
+
+	if *file != "" {
+		f, err := os.OpenFile(*file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err = enc.Encode(data); err != nil {
+		return err
+	}
+
+	if *file != "" {
+		fmt.Fprintf(os.Stderr, "exported %d users and %d assets to %s\n", len(data.Users), len(data.Assets), *file)
+	}
+
+	return nil
+}
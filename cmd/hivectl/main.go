@@ -0,0 +1,199 @@
+// Command hivectl runs operator tasks against the service database: user
+// and asset administration, migrations, ledger checks and data export.
+//
+//	hivectl [-config PATH] [-o table|json] [-dry-run] <command> [<subcommand>] [flags]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/app"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const _usage = `Usage: hivectl [-config PATH] [-o table|json] [-dry-run] <command> [flags]
+
+Commands:
+  user create -username NAME [-password PASS] [-admin]
+  user reset-password -username NAME [-password PASS]
+  user revoke-sessions -username NAME
+  user list [-after ID] [-limit N]
+  asset list [-owner ID] [-after ID] [-limit N]
+  asset transfer -id ID -to USERNAME
+  asset delete -id ID
+  migrate up | down [-steps N] | goto -version V | force -version V | version
+  ledger check
+  export [-file PATH]
+
+Passwords not given as flags are read from the first line of stdin.
+With -dry-run, changes are made in a transaction that is rolled back.
+`
+
+// errUsage marks errors that are fixed by reading the usage.
+var errUsage = errors.New("usage")
+
+// exitError carries an exit code without printing an error.
+type exitError int
+
+func (e exitError) Error() string {
+	return "exit status " + strconv.Itoa(int(e))
+}
+
+// globals are the flags accepted before the command and by every command.
+type globals struct {
+	config string
+	output string
+	dryRun bool
+}
+
+func (g *globals) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.config, "config", g.config, "config file, overrides CONFIG_PATH")
+	fs.StringVar(&g.output, "o", g.output, "output format: table or json")
+	fs.BoolVar(&g.dryRun, "dry-run", g.dryRun, "check destructive changes without applying them")
+}
+
+// cli holds the state shared by the commands.
+type cli struct {
+	globals
+	cfg *config.Config
+	out *printer
+
+	admin        usecase.AdminUseCase
+	closeStorage func()
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := run(ctx, os.Args[1:])
+
+	stop()
+
+	var code exitError
+
+	switch {
+	case err == nil:
+	case errors.As(err, &code):
+		os.Exit(int(code))
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "hivectl: %s\n\n%s", err, _usage)
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "hivectl: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	c := &cli{globals: globals{output: "table"}}
+
+	fs := flag.NewFlagSet("hivectl", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, _usage) }
+	c.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return exitError(2)
+	}
+
+	args = fs.Args()
+	if len(args) == 0 {
+		return fmt.Errorf("%w: command is required", errUsage)
+	}
+
+	// The configuration is read from -config, CONFIG_PATH or ./config/config.yml
+	// and the environment.
+	cfg, err := config.NewConfig(config.Path(c.config))
+	if err != nil {
+		return err
+	}
+
+	c.cfg = cfg
+	defer c.close()
+
+	ctx = reqctx.WithMeta(ctx, reqctx.Meta{
+		RequestID: fmt.Sprintf("hivectl-%d", time.Now().UnixNano()),
+		UserAgent: "hivectl/" + cfg.App.Version,
+	})
+
+	switch args[0] {
+	case "user":
+		return c.user(ctx, args[1:])
+	case "asset":
+		return c.asset(ctx, args[1:])
+	case "migrate":
+		return c.migrate(ctx, args[1:])
+	case "ledger":
+		return c.ledger(ctx, args[1:])
+	case "export":
+		return c.export(ctx, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+}
+
+// flags returns a flag set for a command that also accepts the global flags.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("hivectl "+name, flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, _usage) }
+	c.register(fs)
+
+	return fs
+}
+
+// parse parses the command flags and sets up the output.
+func (c *cli) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return exitError(2)
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, fs.Arg(0))
+	}
+
+	out, err := newPrinter(os.Stdout, c.output)
+	if err != nil {
+		return err
+	}
+
+	c.out = out
+
+	return nil
+}
+
+// connect opens the storage of cfg.Storage and builds the use cases.
+func (c *cli) connect() error {
+	admin, closeStorage, err := app.OpenAdmin(c.cfg, c.dryRun)
+	if err != nil {
+		return fmt.Errorf("storage connect: %w", err)
+	}
+
+	c.admin = admin
+	c.closeStorage = closeStorage
+
+	return nil
+}
+
+func (c *cli) close() {
+	if c.closeStorage != nil {
+		c.closeStorage()
+	}
+}
+
+// subcommand splits off the subcommand of a command.
+func subcommand(command string, args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%w: %s needs a subcommand", errUsage, command)
+	}
+
+	return args[0], args[1:], nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/appxpy/hive-test/internal/app"
+	"github.com/appxpy/hive-test/pkg/migrator"
+)
+
+// migrationStatus is the result of a migrate command.
+type migrationStatus struct {
+	Version uint   `json:"version"`
+	Dirty   bool   `json:"dirty"`
+	Applied []uint `json:"applied,omitempty"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+}
+
+func (c *cli) migrate(ctx context.Context, args []string) error {
+	name, args, err := subcommand("migrate", args)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "up", "down", "goto", "force", "version":
+	default:
+		return fmt.Errorf("%w: unknown migrate subcommand %q", errUsage, name)
+	}
+
+	flags := c.flags("migrate " + name)
+	steps := flags.Uint("steps", 1, "number of migrations to revert with down")
+	version := flags.Int("version", -1, "target version of goto and force; -1 clears the version with force")
+
+	if err = c.parse(flags, args); err != nil {
+		return err
+	}
+
+	versionSet := false
+	flags.Visit(func(f *flag.Flag) { versionSet = versionSet || f.Name == "version" })
+
+	if ((name == "goto" || name == "force") && !versionSet) || (name == "goto" && *version < 0) {
+		return fmt.Errorf("%w: migrate %s needs -version", errUsage, name)
+	}
+
+	mg, err := app.NewMigrator(c.cfg, migrator.ConnAttempts(1))
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return err
+	}
+
+	if name == "version" {
+		return c.migrationResult(&migrationStatus{Version: current, Dirty: dirty})
+	}
+
+	if dirty && name != "force" {
+		return fmt.Errorf("migrate: version %d is dirty, fix it by hand and run migrate force first", current)
+	}
+
+	var plan []uint
+
+	if name != "force" {
+		versions, err := mg.Versions()
+		if err != nil {
+			return err
+		}
+
+		plan, err = migrationPlan(name, versions, current, *steps, *version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.dryRun {
+		return c.migrationResult(&migrationStatus{Version: current, Dirty: dirty, Applied: plan, DryRun: true})
+	}
+
+	switch name {
+	case "up":
+		err = mg.Up(ctx)
+	case "down":
+		err = mg.Down(ctx, int(*steps))
+	case "goto":
+		err = mg.Goto(ctx, uint(*version))
+	case "force":
+		err = mg.Force(ctx, *version)
+	}
+
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", name, err)
+	}
+
+	current, dirty, err = mg.Version()
+	if err != nil {
+		return err
+	}
+
+	return c.migrationResult(&migrationStatus{Version: current, Dirty: dirty, Applied: plan})
+}
+
+func (c *cli) migrationResult(s *migrationStatus) error {
+	if c.out.json {
+		return c.out.encode(s)
+	}
+
+	msg := fmt.Sprintf("version %d", s.Version)
+	if s.Dirty {
+		msg += " (dirty)"
+	}
+
+	switch {
+	case s.DryRun:
+		msg = fmt.Sprintf("dry run: at %s, would apply %v", msg, s.Applied)
+	case s.Applied != nil:
+		msg = fmt.Sprintf("applied %v, now at %s", s.Applied, msg)
+	}
+
+	return c.out.message("%s", msg)
+}
+
+// migrationPlan lists the versions that the command applies, in order. For
+// down and goto a lower version these are the versions that are reverted.
+func migrationPlan(command string, versions []uint, current, steps uint, target int) ([]uint, error) {
+	var plan []uint
+
+	switch command {
+	case "up":
+		for _, v := range versions {
+			if v > current {
+				plan = append(plan, v)
+			}
+		}
+	case "down":
+		for i := len(versions) - 1; i >= 0 && uint(len(plan)) < steps; i-- {
+			if versions[i] <= current {
+				plan = append(plan, versions[i])
+			}
+		}
+	case "goto":
+		if !containsVersion(versions, uint(target)) {
+			return nil, fmt.Errorf("%w: unknown migration version %d", errUsage, target)
+		}
+
+		for _, v := range versions {
+			if v > current && v <= uint(target) {
+				plan = append(plan, v)
+			}
+		}
+
+		for i := len(versions) - 1; i >= 0; i-- {
+			if versions[i] <= current && versions[i] > uint(target) {
+				plan = append(plan, versions[i])
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+func containsVersion(versions []uint, v uint) bool {
+	for _, version := range versions {
+		if version == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/appxpy/hive-test/internal/entity"
+)
+
+func (c *cli) user(ctx context.Context, args []string) error {
+	name, args, err := subcommand("user", args)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "create":
+		return c.userCreate(ctx, args)
+	case "reset-password":
+		return c.userResetPassword(ctx, args)
+	case "revoke-sessions":
+		return c.userRevokeSessions(ctx, args)
+	case "list":
+		return c.userList(ctx, args)
+	default:
+		return fmt.Errorf("%w: unknown user subcommand %q", errUsage, name)
+	}
+}
+
+func (c *cli) userCreate(ctx context.Context, args []string) error {
+	fs := c.flags("user create")
+	username := fs.String("username", "", "username")
+	password := fs.String("password", "", "password, read from stdin when empty")
+	admin := fs.Bool("admin", false, "create an administrator")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := readPassword(password); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	role := entity.RoleUser
+	if *admin {
+		role = entity.RoleAdmin
+	}
+
+	user, err := c.admin.CreateUser(ctx, *username, *password, role)
+	if err != nil {
+		return err
+	}
+
+	return c.result("created %s %s with ID %d", role, user.Username, user.ID)
+}
+
+func (c *cli) userResetPassword(ctx context.Context, args []string) error {
+	fs := c.flags("user reset-password")
+	username := fs.String("username", "", "username")
+	password := fs.String("password", "", "new password, read from stdin when empty")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := readPassword(password); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	if err := c.admin.ResetPassword(ctx, *username, *password); err != nil {
+		return err
+	}
+
+	return c.result("reset the password of %s and revoked their sessions", *username)
+}
+
+func (c *cli) userRevokeSessions(ctx context.Context, args []string) error {
+	fs := c.flags("user revoke-sessions")
+	username := fs.String("username", "", "username")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	if err := c.admin.RevokeSessions(ctx, *username); err != nil {
+		return err
+	}
+
+	return c.result("revoked the sessions of %s", *username)
+}
+
+func (c *cli) userList(ctx context.Context, args []string) error {
+	fs := c.flags("user list")
+	after := fs.Int64("after", 0, "list users with a greater ID")
+	limit := fs.Uint64("limit", 100, "maximum number of users")
+
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	users, err := c.admin.ListUsers(ctx, *after, *limit)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(users))
+
+	for _, u := range users {
+		revoked := ""
+		if u.SessionsRevokedAt != nil {
+			revoked = u.SessionsRevokedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+
+		rows = append(rows, []string{strconv.FormatInt(u.ID, 10), u.Username, u.Role, revoked})
+	}
+
+	return c.out.table(users, []string{"ID", "USERNAME", "ROLE", "SESSIONS REVOKED"}, rows)
+}
+
+// result reports a change, noting when it was rolled back.
+func (c *cli) result(format string, args ...interface{}) error {
+	if c.dryRun {
+		format = "dry run: " + format + " (rolled back)"
+	}
+
+	return c.out.message(format, args...)
+}
+
+// readPassword reads the password from the first line of stdin unless it
+// was given as a flag, which leaks it to the process list.
+func readPassword(password *string) error {
+	if *password != "" {
+		return nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return errors.New("read password from stdin: no input")
+	}
+
+	*password = strings.TrimRight(line, "\r\n")
+
+	return nil
+}
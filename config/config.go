@@ -2,56 +2,314 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+const _defaultPath = "./config/config.yml"
+
+// Profiles of the environment the service runs in.
+const (
+	ProfileDev  = "dev"
+	ProfileTest = "test"
+	ProfileProd = "prod"
+)
+
 type (
 	// Config -.
 	Config struct {
-		App  `yaml:"app"`
-		HTTP `yaml:"http"`
-		Log  `yaml:"logger"`
-		PG   `yaml:"postgres"`
+		App      `yaml:"app"`
+		HTTP     `yaml:"http"`
+		GRPC     `yaml:"grpc"`
+		Log      `yaml:"logger"`
+		Storage  `yaml:"storage"`
+		PG       `yaml:"postgres"`
+		Migrate  `yaml:"migrate"`
+		Outbox   `yaml:"outbox"`
+		Webhook  `yaml:"webhook"`
+		Notify   `yaml:"notify"`
+		GraphQL  `yaml:"graphql"`
+		Cache    `yaml:"cache"`
+		Tracing  `yaml:"tracing"`
+		Metrics  `yaml:"metrics"`
+		Health   `yaml:"health"`
+		Shutdown `yaml:"shutdown"`
+		Reload   `yaml:"reload"`
+		Flags    `yaml:"flags"`
 	}
 
 	// App -.
 	App struct {
-		Name      string `env-required:"true" yaml:"name"    env:"APP_NAME"`
-		Version   string `env-required:"true" yaml:"version" env:"APP_VERSION"`
-		JWTSecret string `env-required:"true" yaml:"jwt_secret" env:"JWT_SECRET"`
+		Name    string `env-required:"true" yaml:"name"    env:"APP_NAME"`
+		Version string `env-required:"true" yaml:"version" env:"APP_VERSION"`
+		// Profile is the environment the config was loaded for: "dev", "test" or "prod".
+		Profile string `yaml:"profile" env:"APP_PROFILE"`
+		// JWTSecret signs the access tokens; in prod it must be at least 32 characters.
+		JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
 	}
 
 	// HTTP -.
 	HTTP struct {
 		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
+		// UnixSocket also serves on the socket file, for sidecars and admin tools on the host.
+		UnixSocket string `yaml:"unix_socket" env:"HTTP_UNIX_SOCKET"`
+		// Systemd also serves on the sockets passed by systemd socket activation.
+		Systemd bool `env-default:"false" yaml:"systemd" env:"HTTP_SYSTEMD"`
+		// TLSCertFile and TLSKeyFile serve HTTPS on every listener; changed files are reloaded.
+		TLSCertFile string `yaml:"tls_cert_file" env:"HTTP_TLS_CERT_FILE"`
+		TLSKeyFile  string `yaml:"tls_key_file" env:"HTTP_TLS_KEY_FILE"`
+		// TLSClientCAFile requires client certificates signed by one of its CAs (mutual TLS).
+		TLSClientCAFile string `yaml:"tls_client_ca_file" env:"HTTP_TLS_CLIENT_CA_FILE"`
+		// H2C serves HTTP/2 without TLS, for proxies that terminate TLS.
+		H2C bool `env-default:"false" yaml:"h2c" env:"HTTP_H2C"`
+	}
+
+	// GRPC -.
+	GRPC struct {
+		Port string `env-default:"8081" yaml:"port" env:"GRPC_PORT"`
 	}
 
 	// Log -.
 	Log struct {
-		Level string `env-required:"true" yaml:"log_level"   env:"LOG_LEVEL"`
+		Level string `env-required:"true" yaml:"log_level"   env:"LOG_LEVEL" reload:"true"`
+		// Format is "json" or "console" for human-readable local output.
+		Format string `env-default:"json" yaml:"format" env:"LOG_FORMAT"`
+		// SampleBurst is how many debug and info entries are kept per SamplePeriod; 0 keeps all.
+		SampleBurst  uint32        `env-default:"0" yaml:"sample_burst" env:"LOG_SAMPLE_BURST"`
+		SamplePeriod time.Duration `env-default:"1s" yaml:"sample_period" env:"LOG_SAMPLE_PERIOD"`
+		// RedactKeys are extra field keys whose values are masked, besides passwords, tokens and secrets.
+		RedactKeys []string `yaml:"redact_keys" env:"LOG_REDACT_KEYS" env-separator:","`
+	}
+
+	// Storage -.
+	Storage struct {
+		// DSN selects the backend by its scheme: postgres:// (or postgresql://), sqlite://PATH,
+		// or memory:// for a local server that keeps nothing across restarts. Empty means PG.URL.
+		DSN string `yaml:"dsn" env:"STORAGE_DSN" secret:"true"`
 	}
 
 	// PG -.
 	PG struct {
-		PoolMax int    `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
-		URL     string `env-required:"true"                 env:"PG_URL"`
+		PoolMax      int `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
+		ConnAttempts int `env-default:"10" yaml:"conn_attempts" env:"PG_CONN_ATTEMPTS"`
+		// ConnTimeout is the pause between connection attempts.
+		ConnTimeout time.Duration `env-default:"1s" yaml:"conn_timeout" env:"PG_CONN_TIMEOUT"`
+		// DialTimeout bounds establishing a single connection.
+		DialTimeout time.Duration `env-default:"5s" yaml:"dial_timeout" env:"PG_DIAL_TIMEOUT"`
+		// StatementTimeout aborts longer statements; 0 disables it.
+		StatementTimeout time.Duration `env-default:"30s" yaml:"statement_timeout" env:"PG_STATEMENT_TIMEOUT"`
+		// TxIsolation is the default isolation level of units of work.
+		TxIsolation string `env-default:"read committed" yaml:"tx_isolation" env:"PG_TX_ISOLATION"`
+		// TxMaxRetries is how often a unit of work is retried after a serialization failure or deadlock.
+		TxMaxRetries int    `env-default:"3" yaml:"tx_max_retries" env:"PG_TX_MAX_RETRIES"`
+		URL          string `yaml:"url" env:"PG_URL" secret:"true"`
+		// ReplicaURLs are read replicas for catalog reads; writes and transactions stay on URL.
+		ReplicaURLs []string `yaml:"replica_urls" env:"PG_REPLICA_URLS" env-separator:"," secret:"true"`
+		// ReplicaMaxLag is the replay lag above which a replica receives no reads.
+		ReplicaMaxLag time.Duration `env-default:"10s" yaml:"replica_max_lag" env:"PG_REPLICA_MAX_LAG"`
+		// ReplicaCheckInterval is how often replica health and lag are checked.
+		ReplicaCheckInterval time.Duration `env-default:"5s" yaml:"replica_check_interval" env:"PG_REPLICA_CHECK_INTERVAL"`
+		// StickyWindow is how long a user reads from the primary after a write.
+		StickyWindow time.Duration `env-default:"5s" yaml:"sticky_window" env:"PG_STICKY_WINDOW"`
+	}
+
+	// Migrate -.
+	Migrate struct {
+		// OnStart applies pending migrations before the server starts.
+		OnStart bool `env-default:"false" yaml:"on_start" env:"MIGRATE_ON_START"`
+		// LockTimeout is how long to wait for another replica to finish migrating.
+		LockTimeout time.Duration `env-default:"5m" yaml:"lock_timeout" env:"MIGRATE_LOCK_TIMEOUT"`
+	}
+
+	// Outbox -.
+	Outbox struct {
+		// Publisher is the external event sink besides the in-process one: "", "webhook" or "nats".
+		Publisher    string        `yaml:"publisher" env:"OUTBOX_PUBLISHER"`
+		PollInterval time.Duration `env-default:"1s" yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+		BatchSize    uint64        `env-default:"100" yaml:"batch_size" env:"OUTBOX_BATCH_SIZE"`
+		MaxAttempts  int           `env-default:"10" yaml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS"`
+		Lease        time.Duration `env-default:"2m" yaml:"lease" env:"OUTBOX_LEASE"`
+		WebhookURL   string        `yaml:"webhook_url" env:"OUTBOX_WEBHOOK_URL" secret:"true"`
+		NATSURL      string        `yaml:"nats_url" env:"OUTBOX_NATS_URL" secret:"true"`
+		NATSSubject  string        `env-default:"hive.events" yaml:"nats_subject" env:"OUTBOX_NATS_SUBJECT"`
+	}
+
+	// Webhook -.
+	Webhook struct {
+		Timeout      time.Duration `env-default:"10s" yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+		MaxAttempts  int           `env-default:"8" yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+		DisableAfter int           `env-default:"20" yaml:"disable_after" env:"WEBHOOK_DISABLE_AFTER"`
+		PollInterval time.Duration `env-default:"5s" yaml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL"`
+		// AllowPrivate lets webhooks target loopback, private and link-local
+		// addresses. Outside the dev profile endpoints must also use https.
+		AllowPrivate bool `env-default:"false" yaml:"allow_private" env:"WEBHOOK_ALLOW_PRIVATE"`
+	}
+
+	// Notify -.
+	Notify struct {
+		// Backend is "memory" for a single replica or "postgres" for LISTEN/NOTIFY across replicas.
+		Backend     string        `env-default:"memory" yaml:"backend" env:"NOTIFY_BACKEND"`
+		HistorySize int           `env-default:"100" yaml:"history_size" env:"NOTIFY_HISTORY_SIZE"`
+		BufferSize  int           `env-default:"64" yaml:"buffer_size" env:"NOTIFY_BUFFER_SIZE"`
+		Heartbeat   time.Duration `env-default:"15s" yaml:"heartbeat" env:"NOTIFY_HEARTBEAT"`
+		// Retention is how long read notifications stay in the inbox.
+		Retention     time.Duration `env-default:"720h" yaml:"retention" env:"NOTIFY_RETENTION"`
+		PurgeInterval time.Duration `env-default:"1h" yaml:"purge_interval" env:"NOTIFY_PURGE_INTERVAL"`
+	}
+
+	// GraphQL -.
+	GraphQL struct {
+		MaxDepth      int `env-default:"10" yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH" reload:"true"`
+		MaxComplexity int `env-default:"1000" yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" reload:"true"`
+	}
+
+	// Cache -.
+	Cache struct {
+		// Backend of the asset cache: "" disables it, "lru" keeps it in process, "redis" shares it.
+		Backend    string        `yaml:"backend" env:"CACHE_BACKEND"`
+		TTL        time.Duration `env-default:"30s" yaml:"ttl" env:"CACHE_TTL"`
+		MaxEntries int           `env-default:"10000" yaml:"max_entries" env:"CACHE_MAX_ENTRIES"`
+		// RedisURL is the server of the redis backend. With lru it is optional and
+		// broadcasts invalidations to the in-process caches of other instances.
+		RedisURL string `yaml:"redis_url" env:"CACHE_REDIS_URL" secret:"true"`
+	}
+
+	// Tracing -.
+	Tracing struct {
+		// Exporter of spans: "" records none, "stdout" prints them, "otlp" sends them to Endpoint.
+		Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+		// Endpoint is the host:port of the OTLP gRPC collector; empty reads OTEL_EXPORTER_OTLP_ENDPOINT.
+		Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+		Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+		SampleRatio float64 `env-default:"1" yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	}
+
+	// Metrics -.
+	Metrics struct {
+		// Port serves /metrics on a separate admin port; empty serves it on the HTTP port.
+		Port string `yaml:"port" env:"METRICS_PORT"`
+	}
+
+	// Health -.
+	Health struct {
+		// Timeout bounds every check; CacheTTL is how long a result is reused by later probes.
+		Timeout  time.Duration `env-default:"2s" yaml:"timeout" env:"HEALTH_TIMEOUT"`
+		CacheTTL time.Duration `env-default:"1s" yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+		// OutboxMaxLag is the age of the oldest unpublished event reported as lagging.
+		OutboxMaxLag time.Duration `env-default:"5m" yaml:"outbox_max_lag" env:"HEALTH_OUTBOX_MAX_LAG"`
+		// DrainDelay is how long readiness fails before the servers shut down, for load balancers to notice.
+		DrainDelay time.Duration `env-default:"5s" yaml:"drain_delay" env:"HEALTH_DRAIN_DELAY"`
+	}
+
+	// Shutdown -.
+	Shutdown struct {
+		// Timeout bounds stopping the components without a timeout below, such as the cache.
+		Timeout time.Duration `env-default:"10s" yaml:"timeout" env:"SHUTDOWN_TIMEOUT"`
+		// HTTPTimeout and GRPCTimeout are how long in-flight requests, such as purchases, may
+		// take to finish before their connections are closed.
+		HTTPTimeout time.Duration `env-default:"15s" yaml:"http_timeout" env:"SHUTDOWN_HTTP_TIMEOUT"`
+		GRPCTimeout time.Duration `env-default:"15s" yaml:"grpc_timeout" env:"SHUTDOWN_GRPC_TIMEOUT"`
+		// WorkersTimeout is how long the webhook dispatcher, notification retention and hub may
+		// take to finish their current batch.
+		WorkersTimeout time.Duration `env-default:"10s" yaml:"workers_timeout" env:"SHUTDOWN_WORKERS_TIMEOUT"`
+		// RelayTimeout is how long the outbox relay may take to publish its current batch.
+		RelayTimeout time.Duration `env-default:"10s" yaml:"relay_timeout" env:"SHUTDOWN_RELAY_TIMEOUT"`
+		// StorageTimeout is how long closing the database waits for connections in use.
+		StorageTimeout time.Duration `env-default:"10s" yaml:"storage_timeout" env:"SHUTDOWN_STORAGE_TIMEOUT"`
+	}
+
+	// Reload -.
+	Reload struct {
+		// WatchInterval is how often the config files are checked for changes; 0 only reloads on SIGHUP.
+		WatchInterval time.Duration `env-default:"10s" yaml:"watch_interval" env:"RELOAD_WATCH_INTERVAL"`
+	}
+
+	// Flags -.
+	Flags struct {
+		// RefreshInterval is how often the feature flags are reloaded from the database, in case
+		// a change notification was missed; postgres notifies changes at once.
+		RefreshInterval time.Duration `env-default:"1m" yaml:"refresh_interval" env:"FLAGS_REFRESH_INTERVAL" reload:"true"`
 	}
 )
 
-// NewConfig returns app config.
-func NewConfig() (*Config, error) {
+// NewConfig returns app config. The file at the path, ./config/config.yml
+// by default, is read first, then the file of the profile next to it, such
+// as config.prod.yml, when present. Environment variables override both, and
+// secrets may be read from the files named by their _FILE variants, such as
+// JWT_SECRET_FILE. The config is validated unless SkipValidation is given.
+func NewConfig(opts ...Option) (*Config, error) {
+	l := newLoader(opts)
 	cfg := &Config{}
 
-	err := cleanenv.ReadConfig("./config/config.yml", cfg)
+	err := cleanenv.ReadConfig(l.path, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("config error: %w", err)
 	}
 
-	err = cleanenv.ReadEnv(cfg)
+	// The profile file only overrides the keys it sets; the environment is
+	// read again so that it still takes precedence.
+	profilePath := l.profileFile()
+
+	if _, err = os.Stat(profilePath); err == nil {
+		err = cleanenv.ReadConfig(profilePath, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("config error: %w", err)
+		}
+	}
+
+	err = readSecretFiles(cfg)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("config error: %w", err)
+	}
+
+	cfg.App.Profile = l.profile
+
+	for _, override := range l.overrides {
+		override(cfg)
+	}
+
+	if l.validate {
+		err = cfg.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("config error: %w", err)
+		}
 	}
 
 	return cfg, nil
 }
+
+// newLoader applies opts over the environment and the defaults.
+func newLoader(opts []Option) *loader {
+	l := &loader{
+		path:     os.Getenv("CONFIG_PATH"),
+		profile:  os.Getenv("APP_PROFILE"),
+		validate: true,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.path == "" {
+		l.path = _defaultPath
+	}
+
+	if l.profile == "" {
+		l.profile = ProfileDev
+	}
+
+	return l
+}
+
+// profileFile returns the file of the profile next to the config file:
+// config.yml has config.prod.yml.
+func (l *loader) profileFile() string {
+	ext := filepath.Ext(l.path)
+
+	return strings.TrimSuffix(l.path, ext) + "." + l.profile + ext
+}
@@ -0,0 +1,46 @@
+package config
+
+// Option -.
+type Option func(*loader)
+
+// loader holds the options of NewConfig.
+type loader struct {
+	path      string
+	profile   string
+	validate  bool
+	overrides []func(*Config)
+}
+
+// Path is the config file; empty means CONFIG_PATH or ./config/config.yml.
+func Path(path string) Option {
+	return func(l *loader) {
+		if path != "" {
+			l.path = path
+		}
+	}
+}
+
+// Profile selects the profile file; empty means APP_PROFILE or dev.
+func Profile(profile string) Option {
+	return func(l *loader) {
+		if profile != "" {
+			l.profile = profile
+		}
+	}
+}
+
+// Override changes the config after it is read and before it is validated,
+// as command line flags do.
+func Override(fn func(cfg *Config)) Option {
+	return func(l *loader) {
+		l.overrides = append(l.overrides, fn)
+	}
+}
+
+// SkipValidation returns the config even when it is invalid, for commands
+// that only show it.
+func SkipValidation() Option {
+	return func(l *loader) {
+		l.validate = false
+	}
+}
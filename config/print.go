@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print writes the effective config as YAML in the layout of config.yml,
+// with secrets redacted, so that it can be shared when debugging.
+func (c *Config) Print(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+
+	err := fields(c, func(f field) error {
+		value := &yaml.Node{}
+
+		if err := value.Encode(printable(f)); err != nil {
+			return fmt.Errorf("%s: %w", f.Key, err)
+		}
+
+		parent := root
+		path := strings.Split(f.Key, ".")
+
+		for _, key := range path[:len(path)-1] {
+			parent = child(parent, key)
+		}
+
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[len(path)-1]}, value)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("config - Print: %w", err)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	err = enc.Encode(root)
+	if err != nil {
+		return fmt.Errorf("config - Print - Encode: %w", err)
+	}
+
+	return enc.Close()
+}
+
+// printable returns the value of f as written in config.yml.
+func printable(f field) interface{} {
+	switch v := f.Value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case string:
+		if f.Secret {
+			return redact(v)
+		}
+
+		return v
+	case []string:
+		if !f.Secret {
+			return v
+		}
+
+		redacted := make([]string, len(v))
+		for i := range v {
+			redacted[i] = redact(v[i])
+		}
+
+		return redacted
+	default:
+		return v
+	}
+}
+
+// child returns the mapping under key in parent, adding it when missing.
+func child(parent *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			return parent.Content[i+1]
+		}
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, node)
+
+	return node
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNotReloadable is returned by Reload when a setting that is only read at
+// startup changed.
+var ErrNotReloadable = errors.New("only read at startup, restart to apply")
+
+// Change is a setting changed by a reload. Secrets are redacted.
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Store holds the current config and reloads it from the files and the
+// environment it was read from. Only the settings tagged reload:"true" may
+// change; the subsystems using them subscribe to new configs.
+type Store struct {
+	opts []Option
+
+	current atomic.Pointer[Config]
+
+	// mu serializes reloads, so subscribers see configs in order.
+	mu          sync.Mutex
+	subscribers []func(cfg *Config)
+}
+
+// NewStore returns a store of cfg, which was read with opts.
+func NewStore(cfg *Config, opts ...Option) *Store {
+	s := &Store{opts: opts}
+	s.current.Store(cfg)
+
+	return s
+}
+
+// Current returns the config in effect. It must not be modified.
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// Subscribe calls fn with every config published by a reload.
+func (s *Store) Subscribe(fn func(cfg *Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Reload reads the config again and publishes it when it is valid and only
+// reloadable settings changed; otherwise the current config stays in effect
+// and the error names the offending settings. It returns the changes.
+func (s *Store) Reload() ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next, err := NewConfig(s.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, fixed, err := diff(s.current.Load(), next)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fixed) > 0 {
+		return nil, fmt.Errorf("config error: %s: %w", strings.Join(fixed, ", "), ErrNotReloadable)
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	s.current.Store(next)
+
+	for _, fn := range s.subscribers {
+		fn(next)
+	}
+
+	return changes, nil
+}
+
+// Watch calls changed whenever a config file is modified, checking every
+// interval until ctx is done. It does not reload by itself, so that the
+// caller handles changed files and SIGHUP alike.
+func (s *Store) Watch(ctx context.Context, interval time.Duration, changed func()) {
+	l := newLoader(s.opts)
+	files := []string{l.path, l.profileFile()}
+
+	modTimes := fileModTimes(files)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := fileModTimes(files)
+			if !reflect.DeepEqual(current, modTimes) {
+				modTimes = current
+
+				changed()
+			}
+		}
+	}
+}
+
+// fileModTimes returns the modification times of files; a missing file has
+// the zero time, so that creating or removing it counts as a change.
+func fileModTimes(files []string) []time.Time {
+	modTimes := make([]time.Time, len(files))
+
+	for i, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			modTimes[i] = fi.ModTime()
+		}
+	}
+
+	return modTimes
+}
+
+// diff returns the settings that differ between old and next, and the keys
+// of those that are not reloadable.
+func diff(old, next *Config) (changes []Change, fixed []string, err error) {
+	values := make(map[string]field)
+
+	err = fields(old, func(f field) error {
+		values[f.Key] = f
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = fields(next, func(f field) error {
+		prev := values[f.Key]
+		if reflect.DeepEqual(prev.Value.Interface(), f.Value.Interface()) {
+			return nil
+		}
+
+		if f.Tag.Get("reload") != "true" {
+			fixed = append(fixed, f.Key)
+
+			return nil
+		}
+
+		changes = append(changes, Change{
+			Key: f.Key,
+			Old: fmt.Sprint(printable(prev)),
+			New: fmt.Sprint(printable(f)),
+		})
+
+		return nil
+	})
+
+	return changes, fixed, err
+}
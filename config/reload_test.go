@@ -0,0 +1,149 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/config"
+)
+
+// newStore returns a store of a copy of config.yml and the path of the copy.
+func newStore(t *testing.T) (*config.Store, string) {
+	t.Helper()
+
+	setEnv(t, map[string]string{"PG_URL": "postgres://localhost/db", "JWT_SECRET": _strongSecret})
+
+	data, err := os.ReadFile("config.yml")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	cfg, err := config.NewConfig(config.Path(path))
+	require.NoError(t, err)
+
+	return config.NewStore(cfg, config.Path(path)), path
+}
+
+// edit replaces old with new in the file at path.
+func edit(t *testing.T, path, old, new string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), old)
+
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), 0o600))
+}
+
+func TestStore_Reload(t *testing.T) {
+	store, path := newStore(t)
+
+	var published []*config.Config
+
+	store.Subscribe(func(cfg *config.Config) {
+		published = append(published, cfg)
+	})
+
+	edit(t, path, "log_level: 'debug'", "log_level: 'warn'")
+	edit(t, path, "max_depth: 10", "max_depth: 5")
+	edit(t, path, "refresh_interval: 1m", "refresh_interval: 30s")
+
+	changes, err := store.Reload()
+	require.NoError(t, err)
+
+	assert.Equal(t, []config.Change{
+		{Key: "logger.log_level", Old: "debug", New: "warn"},
+		{Key: "graphql.max_depth", Old: "10", New: "5"},
+		{Key: "flags.refresh_interval", Old: "1m0s", New: "30s"},
+	}, changes)
+	require.Len(t, published, 1)
+	assert.Same(t, store.Current(), published[0])
+	assert.Equal(t, "warn", store.Current().Log.Level)
+}
+
+func TestStore_ReloadUnchanged(t *testing.T) {
+	store, _ := newStore(t)
+	current := store.Current()
+
+	changes, err := store.Reload()
+	require.NoError(t, err)
+
+	assert.Empty(t, changes)
+	assert.Same(t, current, store.Current())
+}
+
+func TestStore_ReloadRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		wantErr  error
+	}{
+		{
+			name:    "setting read at startup",
+			old:     "port: '8080'",
+			new:     "port: '9090'",
+			wantErr: config.ErrNotReloadable,
+		},
+		{
+			name:    "invalid value",
+			old:     "log_level: 'debug'",
+			new:     "log_level: 'loud'",
+			wantErr: config.ErrInvalid,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			store, path := newStore(t)
+			current := store.Current()
+
+			store.Subscribe(func(*config.Config) {
+				t.Error("a rejected config was published")
+			})
+
+			edit(t, path, tc.old, tc.new)
+			// A reloadable change does not make the others acceptable.
+			edit(t, path, "max_depth: 10", "max_depth: 5")
+
+			_, err := store.Reload()
+			require.ErrorIs(t, err, tc.wantErr)
+
+			assert.Same(t, current, store.Current())
+		})
+	}
+}
+
+func TestStore_Watch(t *testing.T) {
+	store, path := newStore(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+
+	go store.Watch(ctx, 10*time.Millisecond, func() {
+		changed <- struct{}{}
+	})
+
+	// Let the watcher record the modification times before changing them.
+	time.Sleep(30 * time.Millisecond)
+
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("the change of the config file was not noticed")
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	// _fileSuffix names the variable holding the file of a secret: JWT_SECRET_FILE.
+	_fileSuffix = "_FILE"
+	_redacted   = "[REDACTED]"
+)
+
+// ErrSecretConflict is returned when a secret is set both directly and by file.
+var ErrSecretConflict = errors.New("set either the variable or its _FILE variant")
+
+// field is a leaf of the config.
+type field struct {
+	// Key is the dotted YAML path, such as postgres.pool_max.
+	Key    string
+	Env    string
+	Secret bool
+	Value  reflect.Value
+	Tag    reflect.StructTag
+}
+
+// fields calls fn for every leaf of the config, in declaration order.
+func fields(cfg *Config, fn func(f field) error) error {
+	return walk(reflect.ValueOf(cfg).Elem(), "", fn)
+}
+
+func walk(v reflect.Value, prefix string, fn func(f field) error) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		key, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if key == "" {
+			key = strings.ToLower(sf.Name)
+		}
+
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if sf.Type.Kind() == reflect.Struct {
+			if err := walk(v.Field(i), key, fn); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err := fn(field{
+			Key:    key,
+			Env:    sf.Tag.Get("env"),
+			Secret: sf.Tag.Get("secret") == "true",
+			Value:  v.Field(i),
+			Tag:    sf.Tag,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readSecretFiles sets every secret whose _FILE variable names a file, as
+// mounted by Docker and Kubernetes secrets, to the contents of the file.
+func readSecretFiles(cfg *Config) error {
+	return fields(cfg, func(f field) error {
+		if !f.Secret || f.Env == "" {
+			return nil
+		}
+
+		path, ok := os.LookupEnv(f.Env + _fileSuffix)
+		if !ok || path == "" {
+			return nil
+		}
+
+		if _, ok = os.LookupEnv(f.Env); ok {
+			return fmt.Errorf("%s: %w", f.Env, ErrSecretConflict)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", f.Env, _fileSuffix, err)
+		}
+
+		value := strings.TrimRight(string(data), "\r\n")
+
+		if f.Value.Kind() == reflect.Slice {
+			sep := f.Tag.Get("env-separator")
+			if sep == "" {
+				sep = ","
+			}
+
+			f.Value.Set(reflect.ValueOf(strings.Split(value, sep)))
+		} else {
+			f.Value.SetString(value)
+		}
+
+		return nil
+	})
+}
+
+// redact masks a secret. Only the scheme, user and host of a URL are kept,
+// so that the printed config still shows which server is used while tokens
+// in the password, path or query stay hidden.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	u, err := url.Parse(secret)
+	if err != nil || u.Scheme == "" {
+		return _redacted
+	}
+
+	// A URL of a file, such as sqlite:///var/lib/hive.db, holds no credentials.
+	if u.Host == "" && u.User == nil && u.RawQuery == "" && strings.HasPrefix(secret, u.Scheme+"://") {
+		return secret
+	}
+
+	if u.Host == "" {
+		return _redacted
+	}
+
+	shown := &url.URL{Scheme: u.Scheme, Host: u.Host, User: u.User}
+	if u.Path != "" || u.RawQuery != "" {
+		shown.Path = "/xxxxx"
+	}
+
+	return shown.Redacted()
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// _minSecretLength is the length of a JWT secret accepted in prod: 256 bits
+// of HS256 key material when the secret is random ASCII.
+const _minSecretLength = 32
+
+var (
+	// ErrInvalid is returned for a value out of its range or set.
+	ErrInvalid = errors.New("invalid value")
+	// ErrRequired is returned for a value that must be set.
+	ErrRequired = errors.New("required")
+	// ErrWeakSecret is returned in prod for a short or well-known secret.
+	ErrWeakSecret = errors.New("weak secret")
+)
+
+// _weakSecrets are secrets of examples and defaults, such as the one of the
+// checked-in config.yml.
+var _weakSecrets = map[string]bool{
+	"kapusta":    true,
+	"secret":     true,
+	"changeme":   true,
+	"jwt_secret": true,
+	"password":   true,
+}
+
+// validator collects every problem, so that they are fixed in one go.
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, key string, err error, format string, args ...interface{}) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %w: %s", key, err, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+
+	v.check(false, key, ErrInvalid, "%q is not one of %q", value, allowed)
+}
+
+func (v *validator) positive(key string, d time.Duration) {
+	v.check(d > 0, key, ErrInvalid, "%s must be positive", d)
+}
+
+// Validate returns the problems of the config joined, or nil. Secrets are
+// only checked for strength in prod, so local setups keep working with the
+// checked-in defaults.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.oneOf("app.profile", c.App.Profile, ProfileDev, ProfileTest, ProfileProd)
+	v.check(c.App.JWTSecret != "", "app.jwt_secret", ErrRequired, "set JWT_SECRET or JWT_SECRET_FILE")
+
+	if c.App.Profile == ProfileProd && c.App.JWTSecret != "" {
+		v.check(len(c.App.JWTSecret) >= _minSecretLength, "app.jwt_secret", ErrWeakSecret,
+			"at least %d characters are required in prod", _minSecretLength)
+		v.check(!_weakSecrets[strings.ToLower(c.App.JWTSecret)], "app.jwt_secret", ErrWeakSecret,
+			"a well-known secret is used in prod")
+	}
+
+	c.validateHTTP(v)
+
+	v.oneOf("logger.log_level", c.Log.Level, "debug", "info", "warn", "error")
+	v.oneOf("logger.format", c.Log.Format, "json", "console")
+
+	if c.Log.SampleBurst > 0 {
+		v.positive("logger.sample_period", c.Log.SamplePeriod)
+	}
+
+	c.validateStorage(v)
+
+	v.oneOf("outbox.publisher", c.Outbox.Publisher, "", "webhook", "nats")
+	v.check(c.Outbox.Publisher != "webhook" || c.Outbox.WebhookURL != "", "outbox.webhook_url", ErrRequired,
+		"the webhook publisher needs a URL")
+	v.check(c.Outbox.Publisher != "nats" || c.Outbox.NATSURL != "", "outbox.nats_url", ErrRequired,
+		"the nats publisher needs a URL")
+	v.positive("outbox.poll_interval", c.Outbox.PollInterval)
+	v.positive("outbox.lease", c.Outbox.Lease)
+	v.check(c.Outbox.BatchSize > 0, "outbox.batch_size", ErrInvalid, "must be positive")
+
+	v.positive("webhook.timeout", c.Webhook.Timeout)
+	v.positive("webhook.poll_interval", c.Webhook.PollInterval)
+
+	v.oneOf("notify.backend", c.Notify.Backend, "memory", "postgres")
+	v.positive("notify.heartbeat", c.Notify.Heartbeat)
+	v.positive("notify.purge_interval", c.Notify.PurgeInterval)
+
+	v.oneOf("cache.backend", c.Cache.Backend, "", "lru", "redis")
+	v.check(c.Cache.Backend != "redis" || c.Cache.RedisURL != "", "cache.redis_url", ErrRequired,
+		"the redis backend needs a URL")
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "", "stdout", "otlp")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", ErrInvalid,
+		"%g is not between 0 and 1", c.Tracing.SampleRatio)
+
+	v.positive("health.timeout", c.Health.Timeout)
+	v.check(c.Health.DrainDelay >= 0, "health.drain_delay", ErrInvalid, "must not be negative")
+
+	v.positive("shutdown.timeout", c.Shutdown.Timeout)
+	v.check(c.Reload.WatchInterval >= 0, "reload.watch_interval", ErrInvalid, "must not be negative")
+	v.positive("flags.refresh_interval", c.Flags.RefreshInterval)
+
+	return errors.Join(v.errs...)
+}
+
+func (c *Config) validateHTTP(v *validator) {
+	v.check((c.HTTP.TLSCertFile == "") == (c.HTTP.TLSKeyFile == ""), "http.tls_key_file", ErrRequired,
+		"tls_cert_file and tls_key_file are set together")
+	v.check(c.HTTP.TLSClientCAFile == "" || c.HTTP.TLSCertFile != "", "http.tls_client_ca_file", ErrInvalid,
+		"client certificates need tls_cert_file")
+}
+
+func (c *Config) validateStorage(v *validator) {
+	// An empty DSN opens PG.URL; a DSN of another scheme needs no pool.
+	if c.Storage.DSN != "" && !strings.HasPrefix(c.Storage.DSN, "postgres") {
+		return
+	}
+
+	v.check(c.Storage.DSN != "" || c.PG.URL != "", "postgres.url", ErrRequired,
+		"set PG_URL, PG_URL_FILE or STORAGE_DSN")
+	v.check(c.PG.PoolMax > 0, "postgres.pool_max", ErrInvalid, "%d must be positive", c.PG.PoolMax)
+	v.check(c.PG.ConnAttempts > 0, "postgres.conn_attempts", ErrInvalid, "%d must be positive", c.PG.ConnAttempts)
+
+	if len(c.PG.ReplicaURLs) > 0 {
+		v.positive("postgres.replica_check_interval", c.PG.ReplicaCheckInterval)
+	}
+}
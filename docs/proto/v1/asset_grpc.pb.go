@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.24.4
+// source: docs/proto/v1/asset.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AssetService_CreateAsset_FullMethodName   = "/hive.v1.AssetService/CreateAsset"
+	AssetService_DeleteAsset_FullMethodName   = "/hive.v1.AssetService/DeleteAsset"
+	AssetService_PurchaseAsset_FullMethodName = "/hive.v1.AssetService/PurchaseAsset"
+	AssetService_ListAssets_FullMethodName    = "/hive.v1.AssetService/ListAssets"
+)
+
+// AssetServiceClient is the client API for AssetService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AssetServiceClient interface {
+	// CreateAsset adds an asset owned by the caller.
+	CreateAsset(ctx context.Context, in *CreateAssetRequest, opts ...grpc.CallOption) (*CreateAssetResponse, error)
+	// DeleteAsset removes an asset owned by the caller.
+	DeleteAsset(ctx context.Context, in *DeleteAssetRequest, opts ...grpc.CallOption) (*DeleteAssetResponse, error)
+	// PurchaseAsset transfers an asset of another user to the caller.
+	PurchaseAsset(ctx context.Context, in *PurchaseAssetRequest, opts ...grpc.CallOption) (*PurchaseAssetResponse, error)
+	// ListAssets streams the assets owned by the caller.
+	ListAssets(ctx context.Context, in *ListAssetsRequest, opts ...grpc.CallOption) (AssetService_ListAssetsClient, error)
+}
+
+type assetServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAssetServiceClient(cc grpc.ClientConnInterface) AssetServiceClient {
+	return &assetServiceClient{cc}
+}
+
+func (c *assetServiceClient) CreateAsset(ctx context.Context, in *CreateAssetRequest, opts ...grpc.CallOption) (*CreateAssetResponse, error) {
+	out := new(CreateAssetResponse)
+	err := c.cc.Invoke(ctx, AssetService_CreateAsset_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *assetServiceClient) DeleteAsset(ctx context.Context, in *DeleteAssetRequest, opts ...grpc.CallOption) (*DeleteAssetResponse, error) {
+	out := new(DeleteAssetResponse)
+	err := c.cc.Invoke(ctx, AssetService_DeleteAsset_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *assetServiceClient) PurchaseAsset(ctx context.Context, in *PurchaseAssetRequest, opts ...grpc.CallOption) (*PurchaseAssetResponse, error) {
+	out := new(PurchaseAssetResponse)
+	err := c.cc.Invoke(ctx, AssetService_PurchaseAsset_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *assetServiceClient) ListAssets(ctx context.Context, in *ListAssetsRequest, opts ...grpc.CallOption) (AssetService_ListAssetsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AssetService_ServiceDesc.Streams[0], AssetService_ListAssets_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &assetServiceListAssetsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AssetService_ListAssetsClient interface {
+	Recv() (*Asset, error)
+	grpc.ClientStream
+}
+
+type assetServiceListAssetsClient struct {
+	grpc.ClientStream
+}
+
+func (x *assetServiceListAssetsClient) Recv() (*Asset, error) {
+	m := new(Asset)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AssetServiceServer is the server API for AssetService service.
+// All implementations must embed UnimplementedAssetServiceServer
+// for forward compatibility
+type AssetServiceServer interface {
+	// CreateAsset adds an asset owned by the caller.
+	CreateAsset(context.Context, *CreateAssetRequest) (*CreateAssetResponse, error)
+	// DeleteAsset removes an asset owned by the caller.
+	DeleteAsset(context.Context, *DeleteAssetRequest) (*DeleteAssetResponse, error)
+	// PurchaseAsset transfers an asset of another user to the caller.
+	PurchaseAsset(context.Context, *PurchaseAssetRequest) (*PurchaseAssetResponse, error)
+	// ListAssets streams the assets owned by the caller.
+	ListAssets(*ListAssetsRequest, AssetService_ListAssetsServer) error
+	mustEmbedUnimplementedAssetServiceServer()
+}
+
+// UnimplementedAssetServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAssetServiceServer struct {
+}
+
+func (UnimplementedAssetServiceServer) CreateAsset(context.Context, *CreateAssetRequest) (*CreateAssetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAsset not implemented")
+}
+func (UnimplementedAssetServiceServer) DeleteAsset(context.Context, *DeleteAssetRequest) (*DeleteAssetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAsset not implemented")
+}
+func (UnimplementedAssetServiceServer) PurchaseAsset(context.Context, *PurchaseAssetRequest) (*PurchaseAssetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurchaseAsset not implemented")
+}
+func (UnimplementedAssetServiceServer) ListAssets(*ListAssetsRequest, AssetService_ListAssetsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListAssets not implemented")
+}
+func (UnimplementedAssetServiceServer) mustEmbedUnimplementedAssetServiceServer() {}
+
+// UnsafeAssetServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AssetServiceServer will
+// result in compilation errors.
+type UnsafeAssetServiceServer interface {
+	mustEmbedUnimplementedAssetServiceServer()
+}
+
+func RegisterAssetServiceServer(s grpc.ServiceRegistrar, srv AssetServiceServer) {
+	s.RegisterService(&AssetService_ServiceDesc, srv)
+}
+
+func _AssetService_CreateAsset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAssetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AssetServiceServer).CreateAsset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AssetService_CreateAsset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AssetServiceServer).CreateAsset(ctx, req.(*CreateAssetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AssetService_DeleteAsset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAssetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AssetServiceServer).DeleteAsset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AssetService_DeleteAsset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AssetServiceServer).DeleteAsset(ctx, req.(*DeleteAssetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AssetService_PurchaseAsset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseAssetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AssetServiceServer).PurchaseAsset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AssetService_PurchaseAsset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AssetServiceServer).PurchaseAsset(ctx, req.(*PurchaseAssetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AssetService_ListAssets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListAssetsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AssetServiceServer).ListAssets(m, &assetServiceListAssetsServer{stream})
+}
+
+type AssetService_ListAssetsServer interface {
+	Send(*Asset) error
+	grpc.ServerStream
+}
+
+type assetServiceListAssetsServer struct {
+	grpc.ServerStream
+}
+
+func (x *assetServiceListAssetsServer) Send(m *Asset) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// AssetService_ServiceDesc is the grpc.ServiceDesc for AssetService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AssetService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hive.v1.AssetService",
+	HandlerType: (*AssetServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateAsset",
+			Handler:    _AssetService_CreateAsset_Handler,
+		},
+		{
+			MethodName: "DeleteAsset",
+			Handler:    _AssetService_DeleteAsset_Handler,
+		},
+		{
+			MethodName: "PurchaseAsset",
+			Handler:    _AssetService_PurchaseAsset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListAssets",
+			Handler:       _AssetService_ListAssets_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "docs/proto/v1/asset.proto",
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+var errNoDatabase = errors.New("the memory storage has no database to administer")
+
+// OpenAdmin opens the storage backend of cfg for an operator tool and
+// returns the admin use case on it; close releases the storage.
+//
+// The tool needs a single connection to the primary: migrations are not
+// applied and read replicas are not used. Its changes go through the asset
+// cache of cfg, so that they reach the caches of the running servers:
+// directly in Redis, or as invalidations published on it.
+func OpenAdmin(cfg *config.Config, dryRun bool) (admin usecase.AdminUseCase, close func(), err error) {
+	backend, _, err := parseStorageDSN(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if backend == _storageMemory {
+		return nil, nil, errNoDatabase
+	}
+
+	c := *cfg
+	c.Migrate.OnStart = false
+	c.PG.PoolMax = 2
+	c.PG.ConnAttempts = 1
+	c.PG.ReplicaURLs = nil
+
+	store, err := newStorage(&c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("app - OpenAdmin - newStorage: %w", err)
+	}
+
+	assetCache, redis, err := openAssetCache(cfg.Cache)
+	if err != nil {
+		store.close()
+		return nil, nil, fmt.Errorf("app - OpenAdmin - openAssetCache: %w", err)
+	}
+
+	close = store.close
+
+	if assetCache != nil {
+		decorate(store, assetCache)
+
+		if redis != nil {
+			close = func() {
+				redis.Close()
+				store.close()
+			}
+		}
+	}
+
+	admin = usecase.NewAdminUseCase(store.users, store.assets, store.unitOfWork, usecase.NewAuditUseCase(store.audit), dryRun)
+
+	return admin, close, nil
+}
@@ -2,45 +2,214 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/appxpy/hive-test/config"
+	grpcv1 "github.com/appxpy/hive-test/internal/controller/grpc/v1"
 	"github.com/appxpy/hive-test/internal/controller/http/v1"
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/outbox"
 	"github.com/appxpy/hive-test/internal/usecase"
-	"github.com/appxpy/hive-test/internal/usecase/repo"
+	"github.com/appxpy/hive-test/pkg/grpcserver"
 	"github.com/appxpy/hive-test/pkg/httpserver"
+	"github.com/appxpy/hive-test/pkg/lifecycle"
 	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/tracing"
+)
+
+const (
+	// _tracingShutdownTimeout bounds flushing the spans on shutdown.
+	_tracingShutdownTimeout = 5 * time.Second
+	// _serverStopGrace lets a server that closed the connections of requests
+	// running past its shutdown timeout report it.
+	_serverStopGrace = time.Second
 )
 
 // Run creates objects via constructors.
-func Run(cfg *config.Config) {
-	l := logger.New(cfg.Log.Level)
+// Components are appended to the lifecycle in dependency order: they are
+// started in that order and stopped in reverse, so the servers stop taking
+// requests first and the database is closed last.
+func Run(configs *config.Store) {
+	cfg := configs.Current()
+
+	l := logger.New(cfg.Log.Level,
+		logger.Format(cfg.Log.Format),
+		logger.Sampling(cfg.Log.SampleBurst, cfg.Log.SamplePeriod),
+		logger.RedactKeys(cfg.Log.RedactKeys...),
+	)
+
+	configs.Subscribe(func(next *config.Config) {
+		l.SetLevel(next.Log.Level)
+	})
+
+	lc := lifecycle.New(lifecycle.Timeout(cfg.Shutdown.Timeout))
 
-	// Repository
-	db, err := sqlx.Connect("postgres", cfg.PG.URL)
+	// Tracing
+	tp, err := tracing.New(cfg.Tracing.Exporter,
+		tracing.ServiceName(cfg.App.Name),
+		tracing.ServiceVersion(cfg.App.Version),
+		tracing.Endpoint(cfg.Tracing.Endpoint),
+		tracing.Insecure(cfg.Tracing.Insecure),
+		tracing.SampleRatio(cfg.Tracing.SampleRatio),
+	)
 	if err != nil {
-		l.Fatal(fmt.Errorf("app - Run - sqlx.Connect: %w", err))
+		l.Fatal(fmt.Errorf("app - Run - tracing.New: %w", err))
 	}
-	defer db.Close()
+
+	lc.Append(lifecycle.Hook{Name: "tracing", OnStop: tp.Shutdown, Timeout: _tracingShutdownTimeout})
+
+	// Health checks
+	checks := newHealth(cfg.Health)
 
 	// Repositories
-	userRepo := repo.NewUserRepo(db)
-	assetRepo := repo.NewAssetRepo(db)
+	store, err := newStorage(cfg)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newStorage: %w", err))
+	}
+
+	lc.Append(lifecycle.Hook{
+		Name:    "storage",
+		OnStop:  func(context.Context) error { store.close(); return nil },
+		Timeout: cfg.Shutdown.StorageTimeout,
+	})
+
+	err = store.registerChecks(cfg, checks)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - registerChecks: %w", err))
+	}
+
+	closeCache, err := newAssetCache(cfg.Cache, store, checks)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newAssetCache: %w", err))
+	}
+
+	lc.Append(lifecycle.Hook{Name: "asset cache", OnStop: func(context.Context) error { closeCache(); return nil }})
+
+	// Business metrics
+	err = usecase.RegisterMetrics(prometheus.DefaultRegisterer, store.assets)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - usecase.RegisterMetrics: %w", err))
+	}
 
 	// Use cases
-	userUseCase := usecase.NewUserUseCase(userRepo, cfg.App.JWTSecret)
-	assetUseCase := usecase.NewAssetUseCase(assetRepo)
+	userUseCase := usecase.NewTracedUserUseCase(usecase.NewUserUseCase(store.users, cfg.App.JWTSecret))
+	flagUseCase := usecase.NewFlagUseCase(store.flags)
+	assetUseCase := usecase.NewTracedAssetUseCase(usecase.NewAssetUseCase(store.assets, store.unitOfWork, flagUseCase))
+	auditUseCase := usecase.NewAuditUseCase(store.audit)
+	webhookUseCase := usecase.NewWebhookUseCase(store.webhooks, newWebhookSender(cfg),
+		cfg.Webhook.MaxAttempts, cfg.Webhook.DisableAfter)
+
+	// Feature flags, loaded before the servers start
+	lc.Append(lifecycle.Hook{Name: "feature flags", OnStart: flagUseCase.Refresh})
+	lc.Append(lifecycle.Background("feature flag refresh", cfg.Shutdown.WorkersTimeout,
+		refreshFlags(store, flagUseCase, configs, l)))
+
+	// Notification hub
+	notificationHub, runHub, err := newNotificationHub(cfg, store, l)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newNotificationHub: %w", err))
+	}
+
+	lc.Append(lifecycle.Background("notification hub", cfg.Shutdown.WorkersTimeout, runHub))
+
+	notificationUseCase := usecase.NewNotificationUseCase(notificationHub, store.notifications, cfg.Notify.Retention)
+
+	// Outbox relay
+	inProcessPublisher := outbox.NewInProcess()
+	inProcessPublisher.Subscribe(entity.EventAssetPurchased, webhookUseCase.HandleEvent)
+	inProcessPublisher.Subscribe(entity.EventAssetPurchased, notificationUseCase.HandleEvent)
+
+	publisher, closePublisher, err := newEventPublisher(cfg.Outbox, inProcessPublisher)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - newEventPublisher: %w", err))
+	}
+
+	lc.Append(lifecycle.Hook{
+		Name:   "event publisher",
+		OnStop: func(context.Context) error { closePublisher(); return nil },
+	})
+
+	relay := outbox.NewRelay(store.outbox, publisher, l,
+		outbox.PollInterval(cfg.Outbox.PollInterval),
+		outbox.BatchSize(cfg.Outbox.BatchSize),
+		outbox.MaxAttempts(cfg.Outbox.MaxAttempts),
+		outbox.Lease(cfg.Outbox.Lease),
+	)
+
+	lc.Append(lifecycle.Background("outbox relay", cfg.Shutdown.RelayTimeout, relay.Run))
+
+	// Webhook dispatcher
+	lc.Append(lifecycle.Background("webhook dispatcher", cfg.Shutdown.WorkersTimeout,
+		periodically(l, "webhook dispatcher", cfg.Webhook.PollInterval, webhookUseCase.DispatchPending)))
+
+	// Notification retention
+	lc.Append(lifecycle.Background("notification retention", cfg.Shutdown.WorkersTimeout,
+		periodically(l, "notification retention", cfg.Notify.PurgeInterval, notificationUseCase.PurgeRead)))
+
+	// Config reload
+	lc.Append(lifecycle.Background("config reload", cfg.Shutdown.WorkersTimeout,
+		reloadConfig(configs, l, auditUseCase)))
 
 	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(handler, cfg, l, userUseCase, assetUseCase)
-	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
+	v1.NewRouter(handler, configs, l, checks, userUseCase, assetUseCase, auditUseCase, webhookUseCase,
+		notificationUseCase, flagUseCase)
+	httpServer := httpserver.New(handler, httpServerOptions(cfg)...)
+
+	lc.Append(serverHook("http server", httpServer.Start, httpServer.Shutdown, cfg.Shutdown.HTTPTimeout))
+
+	// Metrics Server
+	var metricsNotify <-chan error
+
+	metricsServer := newMetricsServer(cfg.Metrics)
+	if metricsServer != nil {
+		metricsNotify = metricsServer.Notify()
+
+		lc.Append(serverHook("metrics server", metricsServer.Start, metricsServer.Shutdown, 0))
+	}
+
+	// gRPC Server
+	grpcServer := grpcserver.New(
+		grpcserver.Port(cfg.GRPC.Port),
+		grpcserver.ServerOptions(grpcv1.Interceptors(l, cfg.JWTSecret, userUseCase)...),
+		grpcserver.ShutdownTimeout(cfg.Shutdown.GRPCTimeout),
+	)
+	grpcHealth := grpcv1.NewRouter(grpcServer.App, l, userUseCase, assetUseCase)
+
+	lc.Append(serverHook("grpc server", grpcServer.Start, grpcServer.Shutdown, cfg.Shutdown.GRPCTimeout))
+
+	// Drain: load balancers see the instance is not ready and stop sending
+	// requests before the servers stop.
+	lc.Append(lifecycle.Hook{
+		Name: "readiness",
+		OnStop: func(ctx context.Context) error {
+			checks.Drain()
+			grpcHealth.Shutdown()
+
+			l.Info("app - Run - draining", "delay", cfg.Health.DrainDelay.String())
+
+			select {
+			case <-time.After(cfg.Health.DrainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		Timeout: cfg.Health.DrainDelay + time.Second,
+	})
+
+	err = lc.Start(context.Background())
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - lifecycle.Start: %w", err))
+	}
 
 	// Waiting signal
 	interrupt := make(chan os.Signal, 1)
@@ -51,11 +220,74 @@ func Run(cfg *config.Config) {
 		l.Info("app - Run - signal: " + s.String())
 	case err = <-httpServer.Notify():
 		l.Error(fmt.Errorf("app - Run - httpServer.Notify: %w", err))
+	case err = <-grpcServer.Notify():
+		l.Error(fmt.Errorf("app - Run - grpcServer.Notify: %w", err))
+	case err = <-metricsNotify:
+		l.Error(fmt.Errorf("app - Run - metricsServer.Notify: %w", err))
 	}
 
+	// A signal during the shutdown skips what is left of it.
+	go func() {
+		s := <-interrupt
+		l.Error("app - Run - forced exit, signal: " + s.String())
+		os.Exit(1)
+	}()
+
 	// Shutdown
-	err = httpServer.Shutdown()
+	err = lc.Stop()
 	if err != nil {
-		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err))
+		l.Error(fmt.Errorf("app - Run - lifecycle.Stop: %w", err))
+	}
+}
+
+// serverHook starts a server and shuts it down, which waits for running
+// requests up to timeout, the shutdown timeout of the server, or the
+// default of the lifecycle when zero.
+func serverHook(name string, start func(), shutdown func() error, timeout time.Duration) lifecycle.Hook {
+	if timeout > 0 {
+		timeout += _serverStopGrace
+	}
+
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			return shutdown()
+		},
+		Timeout: timeout,
+	}
+}
+
+// httpServerOptions returns the listeners, TLS and shutdown timeout of the
+// HTTP server.
+func httpServerOptions(cfg *config.Config) []httpserver.Option {
+	opts := []httpserver.Option{
+		httpserver.Port(cfg.HTTP.Port),
+		httpserver.ShutdownTimeout(cfg.Shutdown.HTTPTimeout),
+	}
+
+	if cfg.HTTP.UnixSocket != "" {
+		opts = append(opts, httpserver.UnixSocket(cfg.HTTP.UnixSocket))
 	}
+
+	if cfg.HTTP.Systemd {
+		opts = append(opts, httpserver.Systemd())
+	}
+
+	if cfg.HTTP.TLSCertFile != "" {
+		opts = append(opts, httpserver.TLS(cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile))
+
+		if cfg.HTTP.TLSClientCAFile != "" {
+			opts = append(opts, httpserver.ClientCA(cfg.HTTP.TLSClientCAFile))
+		}
+	}
+
+	if cfg.HTTP.H2C {
+		opts = append(opts, httpserver.H2C())
+	}
+
+	return opts
 }
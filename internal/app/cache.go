@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/usecase/repo/cached"
+	"github.com/appxpy/hive-test/pkg/cache"
+	"github.com/appxpy/hive-test/pkg/health"
+)
+
+// Asset cache backends.
+const (
+	_cacheLRU   = "lru"
+	_cacheRedis = "redis"
+)
+
+var (
+	errUnknownCacheBackend = errors.New("unknown cache backend")
+	errCacheNeedsRedisURL  = errors.New("cache backend redis requires cache.redis_url")
+)
+
+// newAssetCache puts the cache of cfg.Cache in front of the assets and the
+// unit of work of store and registers the check of its server, if any. The
+// returned function releases the cache.
+func newAssetCache(cfg config.Cache, store *storage, checks *health.Registry) (func(), error) {
+	assetCache, redis, err := openAssetCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if assetCache == nil {
+		return func() {}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release := func() {
+		cancel()
+		if redis != nil {
+			redis.Close()
+		}
+	}
+
+	if err := assetCache.Listen(ctx); err != nil {
+		release()
+		return nil, err
+	}
+
+	prometheus.MustRegister(cached.NewCollector(assetCache))
+
+	// Cache errors are misses, so the server being down only slows reads.
+	if redis != nil {
+		checks.Register("redis", func(ctx context.Context) error {
+			return redis.Client.Ping(ctx).Err()
+		}, health.Optional())
+	}
+
+	decorate(store, assetCache)
+
+	return release, nil
+}
+
+// openAssetCache returns the cache of cfg, nil when caching is off, and its
+// Redis server, if any.
+func openAssetCache(cfg config.Cache) (*cached.AssetCache, *cache.Redis, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil, nil
+	case _cacheLRU:
+	case _cacheRedis:
+		if cfg.RedisURL == "" {
+			return nil, nil, errCacheNeedsRedisURL
+		}
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", errUnknownCacheBackend, cfg.Backend)
+	}
+
+	var (
+		c     cache.Cache = cache.NewLRU(cache.MaxEntries(cfg.MaxEntries))
+		bus   cache.Invalidator
+		redis *cache.Redis
+	)
+
+	if cfg.RedisURL != "" {
+		var err error
+		if redis, err = cache.NewRedis(cfg.RedisURL); err != nil {
+			return nil, nil, err
+		}
+
+		// The shared cache needs no broadcast; in-process caches of other
+		// instances do. Without a server they serve their copies until the
+		// TTL expires.
+		if cfg.Backend == _cacheRedis {
+			c = redis
+		} else {
+			bus = redis
+		}
+	}
+
+	return cached.NewAssetCache(c, bus, cfg.TTL), redis, nil
+}
+
+// decorate serves the assets of store through assetCache and invalidates
+// it on every change, including those of units of work.
+func decorate(store *storage, assetCache *cached.AssetCache) {
+	store.assets = cached.NewAssetRepo(store.assets, assetCache)
+	store.unitOfWork = cached.NewUnitOfWork(store.unitOfWork, assetCache)
+}
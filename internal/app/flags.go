@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/repo"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+// refreshFlags returns a loop reloading the cache of feature flags every
+// flags.refresh_interval, which a config reload may change, and, with the
+// postgres storage, whenever another instance changes a flag, until its
+// context is cancelled.
+func refreshFlags(
+	store *storage,
+	flags usecase.FlagUseCase,
+	configs *config.Store,
+	l logger.Interface,
+) func(context.Context) {
+	return func(ctx context.Context) {
+		changed := make(chan struct{}, 1)
+
+		if store.pg != nil {
+			go listenFlags(ctx, store.pgURL, changed, l)
+		}
+
+		// Reloads are serialized, so only the latest interval is kept.
+		intervals := make(chan time.Duration, 1)
+		configs.Subscribe(func(next *config.Config) {
+			select {
+			case <-intervals:
+			default:
+			}
+			intervals <- next.Flags.RefreshInterval
+		})
+
+		interval := configs.Current().Flags.RefreshInterval
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case next := <-intervals:
+				if next != interval {
+					interval = next
+					ticker.Reset(interval)
+				}
+
+				continue
+			case <-ticker.C:
+			case <-changed:
+			}
+
+			err := flags.Refresh(ctx)
+			if err != nil && ctx.Err() == nil {
+				l.Error(fmt.Errorf("app - refreshFlags - flags.Refresh: %w", err))
+			}
+		}
+	}
+}
+
+// listenFlags signals changed on every notification of repo.FlagsChannel
+// until ctx is done, reconnecting on errors. It also signals on every
+// connection, since changes made while disconnected were not notified.
+func listenFlags(ctx context.Context, url string, changed chan<- struct{}, l logger.Interface) {
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	listener := postgres.NewListener(url, repo.FlagsChannel,
+		postgres.OnConnect(notify),
+		postgres.OnError(func(err error) {
+			l.Error(fmt.Errorf("app - listenFlags - listen: %w", err))
+		}),
+	)
+
+	listener.Run(ctx, func(string) { notify() })
+}
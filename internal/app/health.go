@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/outbox"
+	"github.com/appxpy/hive-test/migrations"
+	"github.com/appxpy/hive-test/pkg/health"
+	"github.com/appxpy/hive-test/pkg/migrator"
+)
+
+// _schemaVersionQuery reads the version recorded by golang-migrate.
+const _schemaVersionQuery = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+var (
+	errSchemaDirty    = errors.New("a migration failed half-way; fix it and run migrate force")
+	errSchemaOutdated = errors.New("migrations are pending")
+)
+
+// newHealth returns the registry of the checks configured by cfg.
+func newHealth(cfg config.Health) *health.Registry {
+	return health.New(
+		health.Timeout(cfg.Timeout),
+		health.CacheTTL(cfg.CacheTTL),
+	)
+}
+
+// registerChecks adds the checks of the database and of the outbox to
+// checks. A lagging outbox is only reported: every instance shares it, so
+// failing readiness would take them all out of rotation.
+func (s *storage) registerChecks(cfg *config.Config, checks *health.Registry) error {
+	switch {
+	case s.pg != nil:
+		latest, err := migrator.Latest(migrations.FS)
+		if err != nil {
+			return err
+		}
+
+		checks.Register("postgres", func(ctx context.Context) error {
+			return s.pg.Pool.Ping(ctx)
+		})
+		checks.Register("migrations", schemaCheck(latest, func(ctx context.Context) (version int64, dirty bool, err error) {
+			err = s.pg.Pool.QueryRow(ctx, _schemaVersionQuery).Scan(&version, &dirty)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, false, nil
+			}
+
+			return version, dirty, err
+		}))
+	case s.lite != nil:
+		latest, err := migrator.Latest(migrations.SQLiteFS)
+		if err != nil {
+			return err
+		}
+
+		checks.Register("sqlite", func(ctx context.Context) error {
+			return s.lite.DB.PingContext(ctx)
+		})
+		checks.Register("migrations", schemaCheck(latest, func(ctx context.Context) (version int64, dirty bool, err error) {
+			err = s.lite.DB.QueryRowContext(ctx, _schemaVersionQuery).Scan(&version, &dirty)
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, false, nil
+			}
+
+			return version, dirty, err
+		}))
+	}
+
+	checks.Register("outbox", outbox.LagCheck(s.outbox, cfg.Health.OutboxMaxLag), health.Optional())
+
+	return nil
+}
+
+// schemaCheck fails while the schema is older than the latest embedded
+// migration or a migration failed. A newer schema is fine: it is what an
+// instance of the next release leaves behind during a rolling update.
+func schemaCheck(latest uint, current func(ctx context.Context) (int64, bool, error)) health.Check {
+	return func(ctx context.Context) error {
+		version, dirty, err := current(ctx)
+		if err != nil {
+			return fmt.Errorf("schema version: %w", err)
+		}
+
+		switch {
+		case dirty:
+			return fmt.Errorf("%w: version %d", errSchemaDirty, version)
+		case version < int64(latest):
+			return fmt.Errorf("%w: version %d, latest %d", errSchemaOutdated, version, latest)
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/pkg/httpserver"
+)
+
+// newMetricsServer serves /metrics on the admin port of cfg, or returns nil
+// when the metrics stay on the HTTP port.
+func newMetricsServer(cfg config.Metrics) *httpserver.Server {
+	if cfg.Port == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return httpserver.New(mux, httpserver.Port(cfg.Port))
+}
@@ -1,61 +1,134 @@
-//go:build migrate
-
 package app
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
-	"os"
-	"time"
+	"strconv"
 
-	"github.com/golang-migrate/migrate/v4"
-	// migrate tools
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/migrations"
+	"github.com/appxpy/hive-test/pkg/migrator"
 )
 
-const (
-	_defaultAttempts = 20
-	_defaultTimeout  = time.Second
-)
+var errNothingToMigrate = errors.New("the memory storage has no migrations")
+
+// Migrate runs the migrate subcommand with the embedded migrations:
+// up, down [N], goto VERSION, force VERSION or version.
+func Migrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate: expected up, down [N], goto VERSION, force VERSION or version")
+	}
+
+	command, args := args[0], args[1:]
 
-func init() {
-	databaseURL, ok := os.LookupEnv("PG_URL")
-	if !ok || len(databaseURL) == 0 {
-		log.Fatalf("migrate: environment variable not declared: PG_URL")
+	arg, err := migrateArg(command, args)
+	if err != nil {
+		return err
+	}
+
+	mg, err := NewMigrator(cfg)
+	if err != nil {
+		return err
 	}
+	defer mg.Close()
 
-	var (
-		attempts = _defaultAttempts
-		err      error
-		m        *migrate.Migrate
-	)
+	ctx := context.Background()
 
-	for attempts > 0 {
-		m, err = migrate.New("file://migrations", databaseURL)
-		if err == nil {
-			break
-		}
+	switch command {
+	case "up":
+		err = mg.Up(ctx)
+	case "down":
+		err = mg.Down(ctx, arg)
+	case "goto":
+		err = mg.Goto(ctx, uint(arg))
+	case "force":
+		err = mg.Force(ctx, arg)
+	}
 
-		log.Printf("Migrate: postgres is trying to connect, attempts left: %d", attempts)
-		time.Sleep(_defaultTimeout)
-		attempts--
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", command, err)
 	}
 
+	version, dirty, err := mg.Version()
 	if err != nil {
-		log.Fatalf("Migrate: postgres connect error: %s", err)
+		return fmt.Errorf("migrate version: %w", err)
+	}
+
+	log.Printf("Migrate: %s done, version %d, dirty %t", command, version, dirty)
+
+	return nil
+}
+
+// migrateUp applies the pending migrations when the app starts.
+func migrateUp(cfg *config.Config) error {
+	mg, err := NewMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+
+	err = mg.Up(context.Background())
+	if err != nil {
+		return err
+	}
+
+	version, _, err := mg.Version()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Migrate: up to date, version %d", version)
+
+	return nil
+}
+
+// NewMigrator opens the migrations of the storage backend of cfg; opts
+// follow the options taken from cfg.Migrate.
+func NewMigrator(cfg *config.Config, opts ...migrator.Option) (*migrator.Migrator, error) {
+	backend, dsn, err := parseStorageDSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case _storagePostgres:
+		opts = append([]migrator.Option{migrator.LockTimeout(cfg.Migrate.LockTimeout)}, opts...)
+
+		return migrator.New(dsn, migrations.FS, opts...)
+	case _storageSQLite:
+		return migrator.NewSQLite(dsn, migrations.SQLiteFS, opts...)
+	default:
+		return nil, errNothingToMigrate
+	}
+}
+
+// migrateArg validates the command and returns its numeric argument.
+func migrateArg(command string, args []string) (int, error) {
+	switch command {
+	case "up", "down", "goto", "force", "version":
+	default:
+		return 0, fmt.Errorf("migrate: unknown command %q", command)
 	}
 
-	err = m.Up()
-	defer m.Close()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalf("Migrate: up error: %s", err)
+	switch {
+	case len(args) > 1:
+		return 0, fmt.Errorf("migrate %s: too many arguments", command)
+	case len(args) == 0 && (command == "goto" || command == "force"):
+		return 0, fmt.Errorf("migrate %s: version is required", command)
+	case len(args) == 0 && command == "down":
+		return 1, nil
+	case len(args) == 0:
+		return 0, nil
+	case command == "up" || command == "version":
+		return 0, fmt.Errorf("migrate %s: unexpected argument %q", command, args[0])
 	}
 
-	if errors.Is(err, migrate.ErrNoChange) {
-		log.Printf("Migrate: no change")
-		return
+	n, err := strconv.Atoi(args[0])
+	if err != nil || (n < 0 && !(command == "force" && n == -1)) || (command == "down" && n == 0) {
+		return 0, fmt.Errorf("migrate %s: invalid number %q", command, args[0])
 	}
 
-	log.Printf("Migrate: up success")
+	return n, nil
 }
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/notify"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+)
+
+var (
+	errUnknownNotifyBackend = errors.New("unknown notify backend")
+	errNotifyNeedsPostgres  = errors.New("notify backend postgres needs the postgres storage")
+)
+
+// newNotificationHub returns the configured hub and its loop, which runs
+// until its context is cancelled.
+func newNotificationHub(
+	cfg *config.Config,
+	store *storage,
+	l logger.Interface,
+) (usecase.NotificationHub, func(context.Context), error) {
+	hub := notify.NewHub(notify.HistorySize(cfg.Notify.HistorySize), notify.BufferSize(cfg.Notify.BufferSize))
+
+	switch cfg.Notify.Backend {
+	case "memory":
+		return hub, hub.Run, nil
+	case "postgres":
+		if store.pg == nil {
+			return nil, nil, errNotifyNeedsPostgres
+		}
+
+		pgHub := notify.NewPGHub(hub, store.pg.Pool, store.pgURL, l)
+
+		return pgHub, pgHub.Run, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", errUnknownNotifyBackend, cfg.Notify.Backend)
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/outbox"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+var errUnknownPublisher = errors.New("unknown outbox publisher")
+
+// newEventPublisher returns the in-process publisher, which in-process
+// consumers subscribe to, fanned out with the configured external one.
+func newEventPublisher(cfg config.Outbox, inProcess *outbox.InProcess) (usecase.EventPublisher, func(), error) {
+	switch cfg.Publisher {
+	case "":
+		return inProcess, func() {}, nil
+	case "webhook":
+		return outbox.Multi{inProcess, outbox.NewWebhook(cfg.WebhookURL)}, func() {}, nil
+	case "nats":
+		p, closeFn, err := outbox.ConnectNATS(cfg.NATSURL, cfg.NATSSubject)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return outbox.Multi{inProcess, p}, closeFn, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", errUnknownPublisher, cfg.Publisher)
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+// Triggers of a config reload, recorded as the user agent of its audit entry.
+const (
+	_reloadSignal = "sighup"
+	_reloadFile   = "file"
+)
+
+// reloadConfig returns a loop reloading the config on SIGHUP and, unless the
+// watch interval is zero, when its files change, until its context is
+// cancelled.
+func reloadConfig(configs *config.Store, l logger.Interface, audit usecase.AuditUseCase) func(context.Context) {
+	return func(ctx context.Context) {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+
+		defer signal.Stop(hup)
+
+		changed := make(chan struct{}, 1)
+
+		if interval := configs.Current().Reload.WatchInterval; interval > 0 {
+			go configs.Watch(ctx, interval, func() {
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			})
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				applyConfig(ctx, configs, l, audit, _reloadSignal)
+			case <-changed:
+				applyConfig(ctx, configs, l, audit, _reloadFile)
+			}
+		}
+	}
+}
+
+// applyConfig reloads the config and records what changed in the audit log.
+// A rejected config is only logged: the current one stays in effect.
+func applyConfig(
+	ctx context.Context,
+	configs *config.Store,
+	l logger.Interface,
+	audit usecase.AuditUseCase,
+	trigger string,
+) {
+	changes, err := configs.Reload()
+	if err != nil {
+		l.Error(fmt.Errorf("app - applyConfig - configs.Reload: %w", err), "trigger", trigger)
+
+		return
+	}
+
+	if len(changes) == 0 {
+		l.Info("app - applyConfig - config unchanged", "trigger", trigger)
+
+		return
+	}
+
+	before := make(map[string]string, len(changes))
+	after := make(map[string]string, len(changes))
+
+	for _, c := range changes {
+		before[c.Key], after[c.Key] = c.Old, c.New
+
+		l.Info("app - applyConfig - config changed", "trigger", trigger, "key", c.Key, "old", c.Old, "new", c.New)
+	}
+
+	ctx = reqctx.WithMeta(ctx, reqctx.Meta{
+		RequestID: fmt.Sprintf("config-reload-%d", time.Now().UnixNano()),
+		UserAgent: "config-reload/" + trigger,
+	})
+
+	err = audit.RecordConfigReload(ctx, before, after)
+	if err != nil {
+		l.Error(fmt.Errorf("app - applyConfig - audit.RecordConfigReload: %w", err))
+	}
+}
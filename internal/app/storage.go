@@ -0,0 +1,234 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/repo"
+	"github.com/appxpy/hive-test/internal/usecase/repo/memory"
+	sqliterepo "github.com/appxpy/hive-test/internal/usecase/repo/sqlite"
+	"github.com/appxpy/hive-test/pkg/postgres"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+// Storage backends, named by the scheme of the storage DSN.
+const (
+	_storagePostgres = "postgres"
+	_storageSQLite   = "sqlite"
+	_storageMemory   = "memory"
+)
+
+var errUnknownStorageBackend = errors.New("unknown storage backend")
+
+// storage holds the repositories of the configured backend.
+type storage struct {
+	users         usecase.UserRepo
+	assets        usecase.AssetRepo
+	audit         usecase.AuditRepo
+	outbox        usecase.OutboxRepo
+	webhooks      usecase.WebhookRepo
+	notifications usecase.NotificationRepo
+	flags         usecase.FlagRepo
+	unitOfWork    usecase.UnitOfWork
+
+	// pg and pgURL are only set for the postgres backend, lite for sqlite.
+	pg    *postgres.Postgres
+	pgURL string
+	lite  *sqlite.SQLite
+
+	// replicas are checked until stopReplicas is called.
+	replicas     *postgres.Replicas
+	stopReplicas context.CancelFunc
+}
+
+// parseStorageDSN returns the backend named by cfg.Storage.DSN and the DSN
+// to open it with; an empty DSN is PG.URL.
+func parseStorageDSN(cfg *config.Config) (backend, dsn string, err error) {
+	if cfg.Storage.DSN == "" {
+		return _storagePostgres, cfg.PG.URL, nil
+	}
+
+	scheme, rest, ok := strings.Cut(cfg.Storage.DSN, "://")
+
+	switch {
+	case cfg.Storage.DSN == _storageMemory || (ok && scheme == _storageMemory):
+		return _storageMemory, "", nil
+	case ok && (scheme == "postgres" || scheme == "postgresql"):
+		return _storagePostgres, cfg.Storage.DSN, nil
+	case ok && scheme == _storageSQLite:
+		return _storageSQLite, rest, nil
+	default:
+		return "", "", fmt.Errorf("%w: %q", errUnknownStorageBackend, cfg.Storage.DSN)
+	}
+}
+
+// newStorage opens the backend of cfg.Storage; close releases it.
+func newStorage(cfg *config.Config) (*storage, error) {
+	backend, dsn, err := parseStorageDSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case _storagePostgres:
+		return newPostgresStorage(cfg, dsn)
+	case _storageSQLite:
+		return newSQLiteStorage(cfg, dsn)
+	default:
+		s := memory.New()
+
+		return &storage{
+			users:         memory.NewUserRepo(s),
+			assets:        memory.NewAssetRepo(s),
+			audit:         memory.NewAuditRepo(s),
+			outbox:        memory.NewOutboxRepo(s),
+			webhooks:      memory.NewWebhookRepo(s),
+			notifications: memory.NewNotificationRepo(s),
+			flags:         memory.NewFlagRepo(s),
+			unitOfWork:    memory.NewUnitOfWork(s, cfg.PG.TxMaxRetries),
+		}, nil
+	}
+}
+
+func newPostgresStorage(cfg *config.Config, url string) (*storage, error) {
+	isolation, err := usecase.ParseIsolationLevel(cfg.PG.TxIsolation)
+	if err != nil {
+		return nil, fmt.Errorf("usecase.ParseIsolationLevel: %w", err)
+	}
+
+	if cfg.Migrate.OnStart {
+		if err = migrateUp(cfg); err != nil {
+			return nil, fmt.Errorf("migrateUp: %w", err)
+		}
+	}
+
+	pg, err := newPostgres(cfg, url)
+	if err != nil {
+		return nil, fmt.Errorf("postgres.New: %w", err)
+	}
+
+	prometheus.MustRegister(postgres.NewCollector(pg))
+
+	s := &storage{
+		pg:    pg,
+		pgURL: url,
+	}
+
+	var router *repo.ReadRouter
+
+	if len(cfg.PG.ReplicaURLs) > 0 {
+		if err = s.connectReplicas(cfg); err != nil {
+			pg.Close()
+			return nil, err
+		}
+
+		router = repo.NewReadRouter(s.replicas, cfg.PG.StickyWindow)
+	}
+
+	s.users = repo.NewUserRepo(pg, router)
+	s.assets = repo.NewAssetRepo(pg, router)
+	s.audit = repo.NewAuditRepo(pg)
+	s.outbox = repo.NewOutboxRepo(pg)
+	s.webhooks = repo.NewWebhookRepo(pg)
+	s.notifications = repo.NewNotificationRepo(pg)
+	s.flags = repo.NewFlagRepo(pg)
+	s.unitOfWork = repo.NewUnitOfWork(pg, router, isolation, cfg.PG.TxMaxRetries)
+
+	return s, nil
+}
+
+// connectReplicas opens the pools of cfg.PG.ReplicaURLs and starts checking
+// them. The pools connect lazily, so a replica that is down does not keep
+// the service from starting; it receives no reads until a check succeeds.
+func (s *storage) connectReplicas(cfg *config.Config) error {
+	pools := make([]*postgres.Postgres, 0, len(cfg.PG.ReplicaURLs))
+
+	for _, url := range cfg.PG.ReplicaURLs {
+		pg, err := newPostgres(cfg, url, postgres.LazyConnect())
+		if err != nil {
+			for _, p := range pools {
+				p.Close()
+			}
+			return fmt.Errorf("postgres.New replica: %w", err)
+		}
+
+		pools = append(pools, pg)
+	}
+
+	s.replicas = postgres.NewReplicas(pools,
+		postgres.MaxReplicaLag(cfg.PG.ReplicaMaxLag),
+		postgres.ReplicaCheckInterval(cfg.PG.ReplicaCheckInterval),
+	)
+	prometheus.MustRegister(postgres.NewReplicasCollector(s.replicas))
+
+	var ctx context.Context
+	ctx, s.stopReplicas = context.WithCancel(context.Background())
+
+	go s.replicas.Run(ctx)
+
+	return nil
+}
+
+func newSQLiteStorage(cfg *config.Config, path string) (*storage, error) {
+	if cfg.Migrate.OnStart {
+		if err := migrateUp(cfg); err != nil {
+			return nil, fmt.Errorf("migrateUp: %w", err)
+		}
+	}
+
+	lite, err := sqlite.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite.New: %w", err)
+	}
+
+	prometheus.MustRegister(collectors.NewDBStatsCollector(lite.DB, _storageSQLite))
+
+	return &storage{
+		users:         sqliterepo.NewUserRepo(lite),
+		assets:        sqliterepo.NewAssetRepo(lite),
+		audit:         sqliterepo.NewAuditRepo(lite),
+		outbox:        sqliterepo.NewOutboxRepo(lite),
+		webhooks:      sqliterepo.NewWebhookRepo(lite),
+		notifications: sqliterepo.NewNotificationRepo(lite),
+		flags:         sqliterepo.NewFlagRepo(lite),
+		unitOfWork:    sqliterepo.NewUnitOfWork(lite, cfg.PG.TxMaxRetries),
+		lite:          lite,
+	}, nil
+}
+
+func (s *storage) close() {
+	if s.replicas != nil {
+		s.stopReplicas()
+		s.replicas.Close()
+	}
+	if s.pg != nil {
+		s.pg.Close()
+	}
+	if s.lite != nil {
+		s.lite.Close()
+	}
+}
+
+// newPostgres connects the pool configured by cfg.PG to url.
+func newPostgres(cfg *config.Config, url string, opts ...postgres.Option) (*postgres.Postgres, error) {
+	opts = append([]postgres.Option{
+		postgres.MaxPoolSize(cfg.PG.PoolMax),
+		postgres.ConnAttempts(cfg.PG.ConnAttempts),
+		postgres.ConnTimeout(cfg.PG.ConnTimeout),
+		postgres.DialTimeout(cfg.PG.DialTimeout),
+		postgres.StatementTimeout(cfg.PG.StatementTimeout),
+	}, opts...)
+
+	if cfg.Tracing.Exporter != "" {
+		opts = append(opts, postgres.Tracing())
+	}
+
+	return postgres.New(url, opts...)
+}
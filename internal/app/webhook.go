@@ -0,0 +1,21 @@
+package app
+
+import (
+	"github.com/appxpy/hive-test/config"
+	"github.com/appxpy/hive-test/internal/usecase/webapi"
+)
+
+// newWebhookSender returns the sender of user webhooks. Only the dev profile
+// accepts plain http endpoints, so that a local receiver can be used.
+func newWebhookSender(cfg *config.Config) *webapi.WebhookSender {
+	var opts []webapi.Option
+	if cfg.App.Profile == config.ProfileDev {
+		opts = append(opts, webapi.AllowHTTP())
+	}
+
+	if cfg.Webhook.AllowPrivate {
+		opts = append(opts, webapi.AllowPrivateNetworks())
+	}
+
+	return webapi.NewWebhookSender(cfg.Webhook.Timeout, opts...)
+}
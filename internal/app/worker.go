@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/appxpy/hive-test/pkg/logger"
+)
+
+// periodically returns a loop calling fn every interval until its context
+// is cancelled. A batch that handled something is followed by the next one
+// right away.
+func periodically(
+	l logger.Interface,
+	name string,
+	interval time.Duration,
+	fn func(context.Context) (int, error),
+) func(context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n, err := fn(ctx)
+			if err != nil && ctx.Err() == nil {
+				l.Error(fmt.Errorf("app - %s: %w", name, err))
+			}
+
+			if err == nil && n > 0 && ctx.Err() == nil {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// Error codes in the extensions of GraphQL errors.
+const (
+	_codeBadInput      = "BAD_USER_INPUT"
+	_codeNotFound      = "NOT_FOUND"
+	_codeUnauthorized  = "UNAUTHENTICATED"
+	_codeInternalError = "INTERNAL_SERVER_ERROR"
+)
+
+// codedError is a GraphQL error with a code in its extensions.
+type codedError struct {
+	msg  string
+	code string
+}
+
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+func (e *codedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func badInput(msg string) error {
+	return &codedError{msg: msg, code: _codeBadInput}
+}
+
+// resolverError converts a use case error into a GraphQL error. Unknown
+// errors are logged and hidden from the client.
+func (s *Service) resolverError(ctx context.Context, err error, field string) error {
+	switch {
+	case errors.Is(err, usecase.ErrAssetNotFound):
+		return &codedError{msg: err.Error(), code: _codeNotFound}
+	case errors.Is(err, usecase.ErrOwnAsset):
+		return badInput(err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	default:
+		s.l.WithContext(ctx).Error(err, "graphql - "+field)
+		return &codedError{msg: "internal error", code: _codeInternalError}
+	}
+}
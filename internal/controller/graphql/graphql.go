@@ -0,0 +1,190 @@
+// Package graphql implements the GraphQL API over the use cases.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+)
+
+const (
+	_defaultMaxDepth      = 10
+	_defaultMaxComplexity = 1000
+)
+
+var errSubscriptionOverHTTP = errors.New("subscriptions are only served over WebSocket")
+
+// Request -.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Service executes GraphQL operations against the schema.
+type Service struct {
+	schema graphql.Schema
+
+	u  usecase.UserUseCase
+	a  usecase.AssetUseCase
+	au usecase.AuditUseCase
+	n  usecase.NotificationUseCase
+	l  logger.Interface
+
+	// maxDepth and maxComplexity change when the config is reloaded.
+	maxDepth      atomic.Int64
+	maxComplexity atomic.Int64
+}
+
+// New -.
+func New(
+	u usecase.UserUseCase,
+	a usecase.AssetUseCase,
+	au usecase.AuditUseCase,
+	n usecase.NotificationUseCase,
+	l logger.Interface,
+	opts ...Option,
+) (*Service, error) {
+	s := &Service{
+		u:  u,
+		a:  a,
+		au: au,
+		n:  n,
+		l:  l,
+	}
+
+	s.SetLimits(_defaultMaxDepth, _defaultMaxComplexity)
+
+	// Custom options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	schema, err := s.newSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	s.schema = schema
+
+	return s, nil
+}
+
+// SetLimits changes the maximum depth and complexity of the operations
+// accepted from now on.
+func (s *Service) SetLimits(maxDepth, maxComplexity int) {
+	s.maxDepth.Store(int64(maxDepth))
+	s.maxComplexity.Store(int64(maxComplexity))
+}
+
+// Execute runs a query or mutation. The user is taken from ctx.
+func (s *Service) Execute(ctx context.Context, req Request) *graphql.Result {
+	doc, op, res := s.prepare(req)
+	if res != nil {
+		return res
+	}
+
+	if op.Operation == ast.OperationTypeSubscription {
+		return errorResult(errSubscriptionOverHTTP)
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, loadersKey{}, s.newLoaders()),
+	})
+}
+
+// Subscribe runs a subscription until ctx is done. The channel must be
+// drained until it is closed.
+func (s *Service) Subscribe(ctx context.Context, req Request) <-chan *graphql.Result {
+	doc, op, res := s.prepare(req)
+	if res == nil && op.Operation != ast.OperationTypeSubscription {
+		res = s.Execute(ctx, req)
+	}
+
+	if res != nil {
+		ch := make(chan *graphql.Result, 1)
+		ch <- res
+		close(ch)
+
+		return ch
+	}
+
+	return graphql.ExecuteSubscription(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// prepare parses and validates the request and checks its limits. It
+// returns a result only when the request must not run.
+func (s *Service) prepare(req Request) (*ast.Document, *ast.OperationDefinition, *graphql.Result) {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return nil, nil, errorResult(err)
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return nil, nil, &graphql.Result{Errors: validation.Errors}
+	}
+
+	op := operation(doc, req.OperationName)
+	if op == nil {
+		return nil, nil, errorResult(errors.New("unknown operation"))
+	}
+
+	if err = s.checkLimits(doc, op, req.Variables); err != nil {
+		return nil, nil, errorResult(err)
+	}
+
+	return doc, op, nil
+}
+
+func operation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+
+			found = op
+
+			continue
+		}
+
+		if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+	}
+
+	return found
+}
+
+func errorResult(err error) *graphql.Result {
+	return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+}
@@ -0,0 +1,474 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/internal/controller/graphql"
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+type fakeUserUseCase struct {
+	usecase.UserUseCase
+
+	mu    sync.Mutex
+	users map[int64]*entity.User
+	calls int
+}
+
+func (f *fakeUserUseCase) GetUsersByIDs(_ context.Context, ids []int64) ([]*entity.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	var res []*entity.User
+
+	for _, id := range ids {
+		if u, ok := f.users[id]; ok {
+			res = append(res, u)
+		}
+	}
+
+	return res, nil
+}
+
+type fakeAssetUseCase struct {
+	usecase.AssetUseCase
+
+	mu          sync.Mutex
+	assets      []*entity.Asset
+	byOwner     int
+	purchaseErr error
+}
+
+func (f *fakeAssetUseCase) ListAssets(_ context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	var res []*entity.Asset
+
+	for _, a := range f.assets {
+		if a.UserID != filter.ExcludeUserID && a.ID > filter.AfterID {
+			res = append(res, a)
+		}
+	}
+
+	return res, nil
+}
+
+func (f *fakeAssetUseCase) GetAssetsByUsers(_ context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.byOwner++
+
+	var res []*entity.Asset
+
+	for _, a := range f.assets {
+		for _, id := range userIDs {
+			if a.UserID == id {
+				res = append(res, a)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (f *fakeAssetUseCase) GetAssetsByIDs(_ context.Context, ids []int64) ([]*entity.Asset, error) {
+	var res []*entity.Asset
+
+	for _, a := range f.assets {
+		for _, id := range ids {
+			if a.ID == id {
+				res = append(res, a)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (f *fakeAssetUseCase) AddAsset(_ context.Context, asset *entity.Asset) error {
+	asset.ID = int64(len(f.assets) + 1)
+	f.assets = append(f.assets, asset)
+
+	return nil
+}
+
+func (f *fakeAssetUseCase) PurchaseAsset(_ context.Context, assetID, buyerID int64) error {
+	if f.purchaseErr != nil {
+		return f.purchaseErr
+	}
+
+	for _, a := range f.assets {
+		if a.ID == assetID {
+			a.UserID = buyerID
+		}
+	}
+
+	return nil
+}
+
+type fakeAuditUseCase struct {
+	usecase.AuditUseCase
+
+	mu      sync.Mutex
+	entries []*entity.AuditEntry
+	calls   int
+}
+
+func (f *fakeAuditUseCase) ListEntries(_ context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	var res []*entity.AuditEntry
+
+	perEntity := make(map[int64]uint64)
+
+	for _, e := range f.entries {
+		for _, id := range filter.EntityIDs {
+			if e.EntityType == filter.EntityType && e.EntityID == id && perEntity[id] < filter.LimitPerEntity {
+				perEntity[id]++
+				res = append(res, e)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+type fakeNotificationUseCase struct {
+	usecase.NotificationUseCase
+
+	ch chan *entity.Notification
+}
+
+func (f *fakeNotificationUseCase) Subscribe(context.Context, int64, int64) (<-chan *entity.Notification, error) {
+	return f.ch, nil
+}
+
+type fixture struct {
+	users  *fakeUserUseCase
+	assets *fakeAssetUseCase
+	audit  *fakeAuditUseCase
+	notify *fakeNotificationUseCase
+	svc    *graphql.Service
+}
+
+func newFixture(t *testing.T, opts ...graphql.Option) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		users: &fakeUserUseCase{users: map[int64]*entity.User{
+			1: {ID: 1, Username: "alice"},
+			2: {ID: 2, Username: "bob"},
+			3: {ID: 3, Username: "carol"},
+		}},
+		assets: &fakeAssetUseCase{assets: []*entity.Asset{
+			{ID: 1, UserID: 2, Name: "lamp", Price: 10},
+			{ID: 2, UserID: 2, Name: "chair", Price: 20},
+			{ID: 3, UserID: 3, Name: "table", Price: 30},
+		}},
+		audit: &fakeAuditUseCase{entries: []*entity.AuditEntry{
+			{ID: 1, Action: entity.AuditActionAssetCreate, EntityType: entity.AuditEntityAsset, EntityID: 1, ActorID: 2},
+			{ID: 2, Action: entity.AuditActionAssetCreate, EntityType: entity.AuditEntityAsset, EntityID: 3, ActorID: 3},
+			{ID: 3, Action: entity.AuditActionAssetPurchase, EntityType: entity.AuditEntityAsset, EntityID: 1, ActorID: 3},
+		}},
+		notify: &fakeNotificationUseCase{ch: make(chan *entity.Notification, 1)},
+	}
+
+	svc, err := graphql.New(f.users, f.assets, f.audit, f.notify, logger.New("error"), opts...)
+	require.NoError(t, err)
+
+	f.svc = svc
+
+	return f
+}
+
+func asUser(userID int64) context.Context {
+	return reqctx.WithUserID(context.Background(), userID)
+}
+
+func decode(t *testing.T, data interface{}, v interface{}) {
+	t.Helper()
+
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, v))
+}
+
+func errorCode(t *testing.T, errs interface{}) string {
+	t.Helper()
+
+	var decoded []struct {
+		Extensions map[string]interface{} `json:"extensions"`
+	}
+
+	decode(t, errs, &decoded)
+	require.NotEmpty(t, decoded)
+
+	code, _ := decoded[0].Extensions["code"].(string)
+
+	return code
+}
+
+func TestListings_BatchesLoads(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	res := f.svc.Execute(asUser(1), graphql.Request{
+		Query: `{ listings { id owner { username assets { name owner { username } } } } }`,
+	})
+	require.Empty(t, res.Errors)
+
+	var data struct {
+		Listings []struct {
+			ID    string
+			Owner struct {
+				Username string
+				Assets   []struct{ Name string }
+			}
+		}
+	}
+
+	decode(t, res.Data, &data)
+	require.Len(t, data.Listings, 3)
+	assert.Equal(t, "bob", data.Listings[0].Owner.Username)
+	assert.Len(t, data.Listings[0].Owner.Assets, 2)
+	assert.Equal(t, "carol", data.Listings[2].Owner.Username)
+
+	// One fetch per level, not per asset; the nested owners come from the cache.
+	assert.Equal(t, 1, f.users.calls)
+	assert.Equal(t, 1, f.assets.byOwner)
+}
+
+func TestListings_BatchesHistory(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	res := f.svc.Execute(asUser(1), graphql.Request{
+		Query: `{ listings { id history { action actorId } } }`,
+	})
+	require.Empty(t, res.Errors)
+
+	var data struct {
+		Listings []struct {
+			ID      string
+			History []struct {
+				Action  string
+				ActorID string
+			}
+		}
+	}
+
+	decode(t, res.Data, &data)
+	require.Len(t, data.Listings, 3)
+	require.Len(t, data.Listings[0].History, 2)
+	assert.Equal(t, string(entity.AuditActionAssetPurchase), data.Listings[0].History[1].Action)
+	assert.Equal(t, "3", data.Listings[0].History[1].ActorID)
+	assert.Empty(t, data.Listings[1].History)
+	assert.Len(t, data.Listings[2].History, 1)
+
+	// One audit query for all the listings, not one per asset.
+	assert.Equal(t, 1, f.audit.calls)
+}
+
+func TestListings_BatchesHistoryPerLimit(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	res := f.svc.Execute(asUser(1), graphql.Request{
+		Query: `{ listings { first: history(limit: 1) { action } all: history { action } } }`,
+	})
+	require.Empty(t, res.Errors)
+
+	var data struct {
+		Listings []struct {
+			First []struct{ Action string }
+			All   []struct{ Action string }
+		}
+	}
+
+	decode(t, res.Data, &data)
+	require.Len(t, data.Listings, 3)
+	assert.Len(t, data.Listings[0].First, 1)
+	assert.Len(t, data.Listings[0].All, 2)
+	assert.Equal(t, 2, f.audit.calls)
+}
+
+func TestListings_RequiresAuthentication(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	res := f.svc.Execute(context.Background(), graphql.Request{Query: `{ listings { id } }`})
+	assert.Equal(t, "UNAUTHENTICATED", errorCode(t, res.Errors))
+}
+
+func TestExecute_Limits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		opts  []graphql.Option
+		query string
+	}{
+		{
+			name:  "too deep",
+			opts:  []graphql.Option{graphql.MaxDepth(3)},
+			query: `{ me { assets { owner { assets { id } } } } }`,
+		},
+		{
+			name:  "too complex",
+			opts:  []graphql.Option{graphql.MaxComplexity(50)},
+			query: `{ listings(first: 100) { id name } }`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newFixture(t, tc.opts...)
+
+			res := f.svc.Execute(asUser(1), graphql.Request{Query: tc.query})
+			require.NotEmpty(t, res.Errors)
+			assert.Nil(t, res.Data)
+			assert.Zero(t, f.users.calls)
+		})
+	}
+}
+
+func TestMutations(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	res := f.svc.Execute(asUser(1), graphql.Request{
+		Query:     `mutation($name: String!) { createAsset(name: $name, price: 5) { id name owner { username } } }`,
+		Variables: map[string]interface{}{"name": "vase"},
+	})
+	require.Empty(t, res.Errors)
+
+	var created struct {
+		CreateAsset struct {
+			ID    string
+			Name  string
+			Owner struct{ Username string }
+		}
+	}
+
+	decode(t, res.Data, &created)
+	assert.Equal(t, "4", created.CreateAsset.ID)
+	assert.Equal(t, "alice", created.CreateAsset.Owner.Username)
+
+	res = f.svc.Execute(asUser(1), graphql.Request{Query: `mutation { purchaseAsset(id: "3") { owner { username } } }`})
+	require.Empty(t, res.Errors)
+
+	var purchased struct {
+		PurchaseAsset struct{ Owner struct{ Username string } }
+	}
+
+	decode(t, res.Data, &purchased)
+	assert.Equal(t, "alice", purchased.PurchaseAsset.Owner.Username)
+}
+
+func TestMutations_ErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		err   error
+		query string
+		code  string
+	}{
+		{name: "not found", err: usecase.ErrAssetNotFound, query: `mutation { purchaseAsset(id: "9") { id } }`, code: "NOT_FOUND"},
+		{name: "own asset", err: usecase.ErrOwnAsset, query: `mutation { purchaseAsset(id: "1") { id } }`, code: "BAD_USER_INPUT"},
+		{name: "bad id", query: `mutation { purchaseAsset(id: "x") { id } }`, code: "BAD_USER_INPUT"},
+		{name: "empty name", query: `mutation { createAsset(name: "", price: 1) { id } }`, code: "BAD_USER_INPUT"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newFixture(t)
+			f.assets.purchaseErr = tc.err
+
+			res := f.svc.Execute(asUser(2), graphql.Request{Query: tc.query})
+			assert.Equal(t, tc.code, errorCode(t, res.Errors))
+		})
+	}
+}
+
+func TestExecute_RejectsSubscription(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	res := f.svc.Execute(asUser(1), graphql.Request{Query: `subscription { purchases { id } }`})
+	assert.NotEmpty(t, res.Errors)
+}
+
+func TestSubscribe_Purchases(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	ctx, cancel := context.WithCancel(asUser(2))
+	defer cancel()
+
+	results := f.svc.Subscribe(ctx, graphql.Request{
+		Query: `subscription { purchases { id type price asset { name } buyer { username } } }`,
+	})
+
+	data, err := json.Marshal(entity.AssetSoldData{AssetID: 1, SellerID: 2, BuyerID: 1, Price: 10})
+	require.NoError(t, err)
+
+	f.notify.ch <- &entity.Notification{ID: 7, Type: entity.NotificationAssetSold, Data: data, CreatedAt: time.Now()}
+
+	var res struct {
+		Purchases struct {
+			ID    string
+			Type  string
+			Price float64
+			Asset struct{ Name string }
+			Buyer struct{ Username string }
+		}
+	}
+
+	select {
+	case r := <-results:
+		require.Empty(t, r.Errors)
+		decode(t, r.Data, &res)
+	case <-time.After(time.Second):
+		t.Fatal("no purchase received")
+	}
+
+	assert.Equal(t, "7", res.Purchases.ID)
+	assert.Equal(t, entity.NotificationAssetSold, res.Purchases.Type)
+	assert.Equal(t, "lamp", res.Purchases.Asset.Name)
+	assert.Equal(t, "alice", res.Purchases.Buyer.Username)
+
+	cancel()
+
+	for range results { //nolint:revive // drain until closed
+	}
+}
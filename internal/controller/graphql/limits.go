@@ -0,0 +1,168 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// _defaultListSize is the assumed length of a list field without a page size.
+const _defaultListSize = 10
+
+// limitWalker measures the depth and cost of an operation. Each field costs
+// one; the selections of a list field are counted once per expected item,
+// taken from its first or limit argument. Introspection fields are free.
+type limitWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+func (s *Service) checkLimits(doc *ast.Document, op *ast.OperationDefinition, variables map[string]interface{}) error {
+	w := &limitWalker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			w.fragments[f.Name.Value] = f
+		}
+	}
+
+	root := s.rootType(op)
+	if root == nil {
+		return fmt.Errorf("%s operations are not supported", op.Operation)
+	}
+
+	cost, depth := w.selectionSet(op.SelectionSet, root, 1)
+
+	if maxDepth := int(s.maxDepth.Load()); depth > maxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, maxDepth)
+	}
+
+	if maxComplexity := int(s.maxComplexity.Load()); cost > maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", cost, maxComplexity)
+	}
+
+	return nil
+}
+
+func (s *Service) rootType(op *ast.OperationDefinition) *graphql.Object {
+	switch op.Operation {
+	case ast.OperationTypeQuery:
+		return s.schema.QueryType()
+	case ast.OperationTypeMutation:
+		return s.schema.MutationType()
+	case ast.OperationTypeSubscription:
+		return s.schema.SubscriptionType()
+	}
+
+	return nil
+}
+
+func (w *limitWalker) selectionSet(set *ast.SelectionSet, parent *graphql.Object, depth int) (cost, maxDepth int) {
+	if set == nil {
+		return 0, depth - 1
+	}
+
+	maxDepth = depth
+
+	for _, sel := range set.Selections {
+		var c, d int
+
+		switch sel := sel.(type) {
+		case *ast.Field:
+			c, d = w.field(sel, parent, depth)
+		case *ast.InlineFragment:
+			c, d = w.selectionSet(sel.SelectionSet, parent, depth)
+		case *ast.FragmentSpread:
+			if f := w.fragments[sel.Name.Value]; f != nil {
+				c, d = w.selectionSet(f.SelectionSet, parent, depth)
+			}
+		}
+
+		cost += c
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+
+	return cost, maxDepth
+}
+
+func (w *limitWalker) field(f *ast.Field, parent *graphql.Object, depth int) (cost, maxDepth int) {
+	if strings.HasPrefix(f.Name.Value, "__") {
+		return 0, depth
+	}
+
+	def, ok := parent.Fields()[f.Name.Value]
+	if !ok {
+		return 1, depth
+	}
+
+	child, isList := unwrapType(def.Type)
+
+	obj, ok := child.(*graphql.Object)
+	if !ok || f.SelectionSet == nil {
+		return 1, depth
+	}
+
+	cost, maxDepth = w.selectionSet(f.SelectionSet, obj, depth+1)
+
+	if isList {
+		cost *= w.listSize(f, def)
+	}
+
+	return 1 + cost, maxDepth
+}
+
+func (w *limitWalker) listSize(f *ast.Field, def *graphql.FieldDefinition) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "first" && arg.Name.Value != "limit" {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := w.variables[v.Name.Value].(float64); ok && n > 0 {
+				return int(n)
+			}
+		}
+	}
+
+	for _, arg := range def.Args {
+		if arg.Name() != "first" && arg.Name() != "limit" {
+			continue
+		}
+
+		if n, ok := arg.DefaultValue.(int); ok && n > 0 {
+			return n
+		}
+	}
+
+	return _defaultListSize
+}
+
+// unwrapType strips non-null and list wrappers and reports whether there was a list.
+func unwrapType(t graphql.Type) (graphql.Type, bool) {
+	isList := false
+
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			isList = true
+			t = wrapped.OfType
+		default:
+			return t, isList
+		}
+	}
+}
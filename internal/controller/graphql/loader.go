@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/appxpy/hive-test/internal/entity"
+)
+
+// loader batches the keys requested within one level of a query into a
+// single fetch. graphql-go resolves thunks breadth-first, so every key of a
+// level is registered before the first of its thunks runs.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// load registers key and returns a thunk that yields its value. Keys the
+// fetch does not return yield the zero value.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if !l.done(key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if !l.done(key) {
+			l.flush(ctx)
+		}
+
+		return l.results[key], l.errs[key]
+	}
+}
+
+func (l *loader[K, V]) done(key K) bool {
+	_, ok := l.results[key]
+	if !ok {
+		_, ok = l.errs[key]
+	}
+
+	return ok
+}
+
+func (l *loader[K, V]) flush(ctx context.Context) {
+	seen := make(map[K]bool, len(l.pending))
+	keys := make([]K, 0, len(l.pending))
+
+	for _, key := range l.pending {
+		if !seen[key] && !l.done(key) {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	l.pending = nil
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+
+		l.results[key] = values[key]
+	}
+}
+
+// loaders are the per-request loaders of a query.
+type loaders struct {
+	users         *loader[int64, *entity.User]
+	assets        *loader[int64, *entity.Asset]
+	assetsByOwner *loader[int64, []*entity.Asset]
+	history       *loader[historyKey, []*entity.AuditEntry]
+}
+
+// historyKey is an asset and how many of its audit entries are requested.
+type historyKey struct {
+	assetID int64
+	limit   int
+}
+
+type loadersKey struct{}
+
+func (s *Service) newLoaders() *loaders {
+	return &loaders{
+		users: newLoader(func(ctx context.Context, ids []int64) (map[int64]*entity.User, error) {
+			users, err := s.u.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[int64]*entity.User, len(users))
+			for _, u := range users {
+				byID[u.ID] = u
+			}
+
+			return byID, nil
+		}),
+		assets: newLoader(func(ctx context.Context, ids []int64) (map[int64]*entity.Asset, error) {
+			assets, err := s.a.GetAssetsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			byID := make(map[int64]*entity.Asset, len(assets))
+			for _, a := range assets {
+				byID[a.ID] = a
+			}
+
+			return byID, nil
+		}),
+		assetsByOwner: newLoader(func(ctx context.Context, userIDs []int64) (map[int64][]*entity.Asset, error) {
+			assets, err := s.a.GetAssetsByUsers(ctx, userIDs)
+			if err != nil {
+				return nil, err
+			}
+
+			byOwner := make(map[int64][]*entity.Asset, len(userIDs))
+			for _, id := range userIDs {
+				byOwner[id] = []*entity.Asset{}
+			}
+
+			for _, a := range assets {
+				byOwner[a.UserID] = append(byOwner[a.UserID], a)
+			}
+
+			return byOwner, nil
+		}),
+		history: newLoader(s.fetchHistory),
+	}
+}
+
+// fetchHistory lists the audit entries of the assets with one query per
+// distinct limit, which is a single query unless aliases ask for different
+// limits.
+func (s *Service) fetchHistory(ctx context.Context, keys []historyKey) (map[historyKey][]*entity.AuditEntry, error) {
+	byLimit := make(map[int][]int64)
+	for _, key := range keys {
+		byLimit[key.limit] = append(byLimit[key.limit], key.assetID)
+	}
+
+	history := make(map[historyKey][]*entity.AuditEntry, len(keys))
+	for _, key := range keys {
+		history[key] = []*entity.AuditEntry{}
+	}
+
+	for limit, ids := range byLimit {
+		entries, err := s.au.ListEntries(ctx, entity.AuditFilter{
+			EntityType:     entity.AuditEntityAsset,
+			EntityIDs:      ids,
+			LimitPerEntity: uint64(limit),
+			Limit:          uint64(limit * len(ids)),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			key := historyKey{assetID: e.EntityID, limit: limit}
+			history[key] = append(history[key], e)
+		}
+	}
+
+	return history, nil
+}
+
+// loadersFrom returns the loaders of the request. Subscription events run
+// without them and get fresh ones, so they never see cached data.
+func (s *Service) loadersFrom(ctx context.Context) *loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l
+	}
+
+	return s.newLoaders()
+}
@@ -0,0 +1,18 @@
+package graphql
+
+// Option -.
+type Option func(*Service)
+
+// MaxDepth -.
+func MaxDepth(depth int) Option {
+	return func(s *Service) {
+		s.maxDepth.Store(int64(depth))
+	}
+}
+
+// MaxComplexity -.
+func MaxComplexity(complexity int) Option {
+	return func(s *Service) {
+		s.maxComplexity.Store(int64(complexity))
+	}
+}
@@ -0,0 +1,464 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const (
+	_listingsDefaultSize = 20
+	_listingsMaxSize     = 100
+	_historyDefaultSize  = 20
+	_historyMaxSize      = 100
+)
+
+// purchaseEvent is the payload of the purchases subscription.
+type purchaseEvent struct {
+	ID        int64
+	Type      string
+	AssetID   int64
+	SellerID  int64
+	BuyerID   int64
+	Price     float64
+	CreatedAt time.Time
+}
+
+func (s *Service) newSchema() (graphql.Schema, error) {
+	userType, assetType := s.catalogTypes()
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        s.queryType(userType, assetType),
+		Mutation:     s.mutationType(assetType),
+		Subscription: s.subscriptionType(s.purchaseType(userType, assetType)),
+	})
+}
+
+// catalogTypes returns the User and Asset types, which refer to each other.
+func (s *Service) catalogTypes() (userType, assetType *graphql.Object) {
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: resolveUser(func(u *entity.User) interface{} { return u.ID })},
+			"username": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: resolveUser(func(u *entity.User) interface{} { return u.Username })},
+		},
+	})
+
+	historyType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AssetHistoryEntry",
+		Description: "A change of an asset, taken from the audit log.",
+		Fields: graphql.Fields{
+			"action":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: resolveHistory(func(e *entity.AuditEntry) interface{} { return string(e.Action) })},
+			"actorId":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: resolveHistory(func(e *entity.AuditEntry) interface{} { return e.ActorID })},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: resolveHistory(func(e *entity.AuditEntry) interface{} { return e.CreatedAt })},
+		},
+	})
+
+	assetType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Asset",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: resolveAsset(func(a *entity.Asset) interface{} { return a.ID })},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: resolveAsset(func(a *entity.Asset) interface{} { return a.Name })},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: resolveAsset(func(a *entity.Asset) interface{} { return a.Description })},
+			"price":       &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: resolveAsset(func(a *entity.Asset) interface{} { return a.Price })},
+			"owner": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.loadUser(p.Context, p.Source.(*entity.Asset).UserID), nil
+				},
+			},
+			"history": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(historyType))),
+				Args: graphql.FieldConfigArgument{
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: _historyDefaultSize},
+				},
+				Resolve: s.resolveAssetHistory,
+			},
+		},
+	})
+
+	userType.AddFieldConfig("assets", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(assetType))),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			thunk := s.loadersFrom(p.Context).assetsByOwner.load(p.Context, p.Source.(*entity.User).ID)
+
+			return func() (interface{}, error) {
+				assets, err := thunk()
+				if err != nil {
+					return nil, s.resolverError(p.Context, err, "User.assets")
+				}
+
+				return assets, nil
+			}, nil
+		},
+	})
+
+	return userType, assetType
+}
+
+func (s *Service) purchaseType(userType, assetType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        "PurchaseEvent",
+		Description: "A purchase in which the user sold or bought an asset.",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: resolvePurchase(func(e *purchaseEvent) interface{} { return e.ID })},
+			"type": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "asset.sold or asset.bought",
+				Resolve:     resolvePurchase(func(e *purchaseEvent) interface{} { return e.Type }),
+			},
+			"price":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: resolvePurchase(func(e *purchaseEvent) interface{} { return e.Price })},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: resolvePurchase(func(e *purchaseEvent) interface{} { return e.CreatedAt })},
+			"asset": &graphql.Field{
+				Type: assetType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.loadAsset(p.Context, p.Source.(*purchaseEvent).AssetID), nil
+				},
+			},
+			"seller": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.loadUser(p.Context, p.Source.(*purchaseEvent).SellerID), nil
+				},
+			},
+			"buyer": &graphql.Field{
+				Type: userType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.loadUser(p.Context, p.Source.(*purchaseEvent).BuyerID), nil
+				},
+			},
+		},
+	})
+}
+
+func (s *Service) queryType(userType, assetType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID, err := currentUser(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					return s.loadUser(p.Context, userID), nil
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					return s.loadUser(p.Context, id), nil
+				},
+			},
+			"asset": &graphql.Field{
+				Type: assetType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p.Args, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					return s.loadAsset(p.Context, id), nil
+				},
+			},
+			"listings": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(assetType))),
+				Description: "Assets of other users, ordered by ID. Pass the last ID as after to get the next page.",
+				Args: graphql.FieldConfigArgument{
+					"first":    &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: _listingsDefaultSize},
+					"after":    &graphql.ArgumentConfig{Type: graphql.ID},
+					"minPrice": &graphql.ArgumentConfig{Type: graphql.Float},
+					"maxPrice": &graphql.ArgumentConfig{Type: graphql.Float},
+				},
+				Resolve: s.resolveListings,
+			},
+		},
+	})
+}
+
+func (s *Service) mutationType(assetType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createAsset": &graphql.Field{
+				Type: graphql.NewNonNull(assetType),
+				Args: graphql.FieldConfigArgument{
+					"name":        &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"price":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Float)},
+				},
+				Resolve: s.resolveCreateAsset,
+			},
+			"purchaseAsset": &graphql.Field{
+				Type:    graphql.NewNonNull(assetType),
+				Args:    graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: s.resolvePurchaseAsset,
+			},
+		},
+	})
+}
+
+func (s *Service) subscriptionType(purchaseType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"purchases": &graphql.Field{
+				Type:        graphql.NewNonNull(purchaseType),
+				Description: "Purchases in which the user sold or bought an asset. Pass the last seen ID as after to resume.",
+				Args:        graphql.FieldConfigArgument{"after": &graphql.ArgumentConfig{Type: graphql.ID}},
+				Subscribe:   s.subscribePurchases,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+}
+
+func (s *Service) resolveAssetHistory(p graphql.ResolveParams) (interface{}, error) {
+	limit, _ := p.Args["limit"].(int)
+	if limit <= 0 || limit > _historyMaxSize {
+		return nil, badInput(fmt.Sprintf("limit must be between 1 and %d", _historyMaxSize))
+	}
+
+	key := historyKey{assetID: p.Source.(*entity.Asset).ID, limit: limit}
+	thunk := s.loadersFrom(p.Context).history.load(p.Context, key)
+
+	return func() (interface{}, error) {
+		entries, err := thunk()
+		if err != nil {
+			return nil, s.resolverError(p.Context, err, "Asset.history")
+		}
+
+		return entries, nil
+	}, nil
+}
+
+func (s *Service) resolveListings(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := currentUser(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	first, _ := p.Args["first"].(int)
+	if first <= 0 || first > _listingsMaxSize {
+		return nil, badInput(fmt.Sprintf("first must be between 1 and %d", _listingsMaxSize))
+	}
+
+	filter := entity.AssetFilter{ExcludeUserID: userID, Limit: uint64(first)}
+	filter.MinPrice, _ = p.Args["minPrice"].(float64)
+	filter.MaxPrice, _ = p.Args["maxPrice"].(float64)
+
+	if _, ok := p.Args["after"]; ok {
+		if filter.AfterID, err = idArg(p.Args, "after"); err != nil {
+			return nil, err
+		}
+	}
+
+	assets, err := s.a.ListAssets(p.Context, filter)
+	if err != nil {
+		return nil, s.resolverError(p.Context, err, "Query.listings")
+	}
+
+	return assets, nil
+}
+
+func (s *Service) resolveCreateAsset(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := currentUser(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &entity.Asset{UserID: userID}
+	asset.Name, _ = p.Args["name"].(string)
+	asset.Description, _ = p.Args["description"].(string)
+	asset.Price, _ = p.Args["price"].(float64)
+
+	if asset.Name == "" {
+		return nil, badInput("name is required")
+	}
+
+	if err = s.a.AddAsset(p.Context, asset); err != nil {
+		return nil, s.resolverError(p.Context, err, "Mutation.createAsset")
+	}
+
+	return asset, nil
+}
+
+func (s *Service) resolvePurchaseAsset(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := currentUser(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	assetID, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.a.PurchaseAsset(p.Context, assetID, userID); err != nil {
+		return nil, s.resolverError(p.Context, err, "Mutation.purchaseAsset")
+	}
+
+	assets, err := s.a.GetAssetsByIDs(p.Context, []int64{assetID})
+	if err != nil {
+		return nil, s.resolverError(p.Context, err, "Mutation.purchaseAsset")
+	}
+
+	if len(assets) == 0 {
+		// Deleted right after the purchase by its new owner.
+		return nil, s.resolverError(p.Context, usecase.ErrAssetNotFound, "Mutation.purchaseAsset")
+	}
+
+	return assets[0], nil
+}
+
+func (s *Service) subscribePurchases(p graphql.ResolveParams) (interface{}, error) {
+	userID, err := currentUser(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	var afterID int64
+	if _, ok := p.Args["after"]; ok {
+		if afterID, err = idArg(p.Args, "after"); err != nil {
+			return nil, err
+		}
+	}
+
+	notifications, err := s.n.Subscribe(p.Context, userID, afterID)
+	if err != nil {
+		return nil, s.resolverError(p.Context, err, "Subscription.purchases")
+	}
+
+	events := make(chan interface{})
+
+	go func() {
+		defer close(events)
+
+		for n := range notifications {
+			if n.Type != entity.NotificationAssetSold && n.Type != entity.NotificationAssetBought {
+				continue
+			}
+
+			var payload entity.AssetSoldData
+			if err := json.Unmarshal(n.Data, &payload); err != nil {
+				s.l.WithContext(p.Context).Error(err, "graphql - Subscription.purchases")
+				continue
+			}
+
+			select {
+			case events <- &purchaseEvent{
+				ID:        n.ID,
+				Type:      n.Type,
+				AssetID:   payload.AssetID,
+				SellerID:  payload.SellerID,
+				BuyerID:   payload.BuyerID,
+				Price:     payload.Price,
+				CreatedAt: n.CreatedAt,
+			}:
+			case <-p.Context.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// loadUser returns a thunk resolving to the user, or to null if it does not exist.
+func (s *Service) loadUser(ctx context.Context, id int64) func() (interface{}, error) {
+	thunk := s.loadersFrom(ctx).users.load(ctx, id)
+
+	return func() (interface{}, error) {
+		u, err := thunk()
+		if err != nil {
+			return nil, s.resolverError(ctx, err, "User")
+		}
+
+		if u == nil {
+			return nil, nil
+		}
+
+		return u, nil
+	}
+}
+
+// loadAsset returns a thunk resolving to the asset, or to null if it does not exist.
+func (s *Service) loadAsset(ctx context.Context, id int64) func() (interface{}, error) {
+	thunk := s.loadersFrom(ctx).assets.load(ctx, id)
+
+	return func() (interface{}, error) {
+		a, err := thunk()
+		if err != nil {
+			return nil, s.resolverError(ctx, err, "Asset")
+		}
+
+		if a == nil {
+			return nil, nil
+		}
+
+		return a, nil
+	}
+}
+
+func currentUser(ctx context.Context) (int64, error) {
+	userID := reqctx.FromContext(ctx).UserID
+	if userID == 0 {
+		return 0, &codedError{msg: "authentication required", code: _codeUnauthorized}
+	}
+
+	return userID, nil
+}
+
+func idArg(args map[string]interface{}, name string) (int64, error) {
+	raw, _ := args[name].(string)
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, badInput(fmt.Sprintf("%s must be a positive integer ID", name))
+	}
+
+	return id, nil
+}
+
+func resolveUser(get func(*entity.User) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*entity.User)), nil
+	}
+}
+
+func resolveAsset(get func(*entity.Asset) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*entity.Asset)), nil
+	}
+}
+
+func resolveHistory(get func(*entity.AuditEntry) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*entity.AuditEntry)), nil
+	}
+}
+
+func resolvePurchase(get func(*purchaseEvent) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*purchaseEvent)), nil
+	}
+}
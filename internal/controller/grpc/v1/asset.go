@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/appxpy/hive-test/docs/proto/v1"
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+type assetService struct {
+	pb.UnimplementedAssetServiceServer
+
+	a usecase.AssetUseCase
+	l logger.Interface
+}
+
+func (s *assetService) CreateAsset(ctx context.Context, req *pb.CreateAssetRequest) (*pb.CreateAssetResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	asset := &entity.Asset{
+		UserID:      reqctx.FromContext(ctx).UserID,
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       req.GetPrice(),
+	}
+
+	err := s.a.AddAsset(ctx, asset)
+	if err != nil {
+		s.l.WithContext(ctx).Error(err, "grpc - v1 - CreateAsset")
+		return nil, statusError(err, "could not add asset")
+	}
+
+	return &pb.CreateAssetResponse{Asset: toProtoAsset(asset)}, nil
+}
+
+func (s *assetService) DeleteAsset(ctx context.Context, req *pb.DeleteAssetRequest) (*pb.DeleteAssetResponse, error) {
+	err := s.a.RemoveAsset(ctx, req.GetId(), reqctx.FromContext(ctx).UserID)
+	if err != nil {
+		s.l.WithContext(ctx).Error(err, "grpc - v1 - DeleteAsset")
+		return nil, statusError(err, "could not remove asset")
+	}
+
+	return &pb.DeleteAssetResponse{}, nil
+}
+
+func (s *assetService) PurchaseAsset(
+	ctx context.Context,
+	req *pb.PurchaseAssetRequest,
+) (*pb.PurchaseAssetResponse, error) {
+	err := s.a.PurchaseAsset(ctx, req.GetId(), reqctx.FromContext(ctx).UserID)
+	if err != nil {
+		s.l.WithContext(ctx).Error(err, "grpc - v1 - PurchaseAsset")
+		return nil, statusError(err, "could not purchase asset")
+	}
+
+	return &pb.PurchaseAssetResponse{}, nil
+}
+
+func (s *assetService) ListAssets(_ *pb.ListAssetsRequest, stream pb.AssetService_ListAssetsServer) error {
+	ctx := stream.Context()
+
+	assets, err := s.a.GetAssetsByUser(ctx, reqctx.FromContext(ctx).UserID)
+	if err != nil {
+		s.l.WithContext(ctx).Error(err, "grpc - v1 - ListAssets")
+		return statusError(err, "could not retrieve assets")
+	}
+
+	for _, asset := range assets {
+		if err = stream.Send(toProtoAsset(asset)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toProtoAsset(asset *entity.Asset) *pb.Asset {
+	return &pb.Asset{
+		Id:          asset.ID,
+		UserId:      asset.UserID,
+		Name:        asset.Name,
+		Description: asset.Description,
+		Price:       asset.Price,
+	}
+}
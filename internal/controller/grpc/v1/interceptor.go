@@ -0,0 +1,180 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/appxpy/hive-test/docs/proto/v1"
+	"github.com/appxpy/hive-test/internal/middleware"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+// _publicMethods can be called without a token.
+var _publicMethods = map[string]bool{
+	pb.UserService_Register_FullMethodName: true,
+	pb.UserService_Login_FullMethodName:    true,
+}
+
+// _requestIDMetadata carries the request ID of a call in both directions.
+const _requestIDMetadata = "x-request-id"
+
+// _publicPrefixes are services that can be called without a token.
+var _publicPrefixes = []string{
+	"/grpc.health.v1.",
+	"/grpc.reflection.",
+}
+
+// Interceptors returns the server options that recover from panics, record
+// request metadata and check the JWT of every non-public call. The token is
+// passed as "authorization: Bearer <token>" metadata.
+func Interceptors(l logger.Interface, jwtSecret string, sessions middleware.SessionChecker) []grpc.ServerOption {
+	i := &interceptors{l: l, jwtSecret: jwtSecret, sessions: sessions}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+	}
+}
+
+type interceptors struct {
+	l         logger.Interface
+	jwtSecret string
+	sessions  middleware.SessionChecker
+}
+
+func (i *interceptors) unary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	defer i.recover(info.FullMethod, &err)
+
+	ctx, err = i.authenticate(requestMeta(ctx), info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (i *interceptors) stream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer i.recover(info.FullMethod, &err)
+
+	ctx, err := i.authenticate(requestMeta(ss.Context()), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+func (i *interceptors) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if isPublic(method) {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata missing")
+	}
+
+	claims, err := middleware.ParseToken(i.jwtSecret, strings.TrimPrefix(values[0], "Bearer "))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	err = i.sessions.CheckSession(ctx, claims.UserID, claims.IssuedAt)
+	if errors.Is(err, usecase.ErrSessionRevoked) {
+		return nil, status.Error(codes.Unauthenticated, "session revoked")
+	}
+
+	if err != nil {
+		i.l.WithContext(ctx).Error(err, "grpc - v1 - authenticate")
+		return nil, status.Error(codes.Internal, "could not verify session")
+	}
+
+	return reqctx.WithUser(ctx, claims.UserID, claims.Role), nil
+}
+
+func (i *interceptors) recover(method string, err *error) {
+	if r := recover(); r != nil {
+		i.l.Error(fmt.Errorf("panic: %v", r), "grpc - v1 - "+method)
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
+
+func isPublic(method string) bool {
+	if _publicMethods[method] {
+		return true
+	}
+
+	for _, prefix := range _publicPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestMeta stores the peer IP, user agent and x-request-id of the call
+// in its context, so use cases can record them. An invalid or missing
+// request ID is replaced by a new one, which is sent back as a header.
+func requestMeta(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	meta := reqctx.Meta{
+		RequestID: first(md.Get(_requestIDMetadata)),
+		UserAgent: first(md.Get("user-agent")),
+	}
+
+	if !reqctx.ValidRequestID(meta.RequestID) {
+		meta.RequestID = reqctx.NewRequestID()
+	}
+
+	// Fails only outside of a call, where there is nobody to tell.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(_requestIDMetadata, meta.RequestID))
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			meta.IP = host
+		}
+	}
+
+	return reqctx.WithMeta(ctx, meta)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// serverStream overrides the context of a stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,37 @@
+// Package v1 implements gRPC services. Each service in own file.
+package v1
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	pb "github.com/appxpy/hive-test/docs/proto/v1"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+)
+
+// NewRouter registers the services, the health service and server
+// reflection on app. It returns the health service, which reports
+// NOT_SERVING once shut down.
+func NewRouter(app *grpc.Server, l logger.Interface, u usecase.UserUseCase, a usecase.AssetUseCase) *health.Server {
+	pb.RegisterUserServiceServer(app, &userService{u: u, l: l})
+	pb.RegisterAssetServiceServer(app, &assetService{a: a, l: l})
+
+	// Health
+	healthServer := health.NewServer()
+	for _, service := range []string{
+		"",
+		pb.UserService_ServiceDesc.ServiceName,
+		pb.AssetService_ServiceDesc.ServiceName,
+	} {
+		healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(app, healthServer)
+
+	// Reflection
+	reflection.Register(app)
+
+	return healthServer
+}
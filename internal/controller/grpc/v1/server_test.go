@@ -0,0 +1,309 @@
+package v1_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/appxpy/hive-test/docs/proto/v1"
+	v1 "github.com/appxpy/hive-test/internal/controller/grpc/v1"
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const _jwtSecret = "secretkey"
+
+type fakeUserUseCase struct {
+	registered map[string]string
+	revoked    map[int64]bool
+}
+
+func (f *fakeUserUseCase) Register(_ context.Context, username, password string) error {
+	if _, ok := f.registered[username]; ok {
+		return usecase.ErrUserExists
+	}
+
+	f.registered[username] = password
+
+	return nil
+}
+
+func (f *fakeUserUseCase) Login(_ context.Context, username, password string) (string, error) {
+	if f.registered[username] != password {
+		return "", usecase.ErrInvalidCredentials
+	}
+
+	return "token", nil
+}
+
+func (f *fakeUserUseCase) GetUsersByIDs(context.Context, []int64) ([]*entity.User, error) {
+	return nil, nil
+}
+
+func (f *fakeUserUseCase) CheckSession(_ context.Context, userID int64, _ time.Time) error {
+	if f.revoked[userID] {
+		return usecase.ErrSessionRevoked
+	}
+
+	return nil
+}
+
+type fakeAssetUseCase struct {
+	assets []*entity.Asset
+	err    error
+}
+
+func (f *fakeAssetUseCase) AddAsset(ctx context.Context, asset *entity.Asset) error {
+	asset.ID = int64(len(f.assets) + 1)
+	f.assets = append(f.assets, asset)
+
+	return f.err
+}
+
+func (f *fakeAssetUseCase) RemoveAsset(context.Context, int64, int64) error {
+	return f.err
+}
+
+func (f *fakeAssetUseCase) PurchaseAsset(context.Context, int64, int64) error {
+	return f.err
+}
+
+func (f *fakeAssetUseCase) GetAssetsByUser(ctx context.Context, userID int64) ([]*entity.Asset, error) {
+	var owned []*entity.Asset
+	for _, a := range f.assets {
+		if a.UserID == userID {
+			owned = append(owned, a)
+		}
+	}
+
+	return owned, f.err
+}
+
+func (f *fakeAssetUseCase) GetAssetsByIDs(context.Context, []int64) ([]*entity.Asset, error) {
+	return f.assets, f.err
+}
+
+func (f *fakeAssetUseCase) GetAssetsByUsers(context.Context, []int64) ([]*entity.Asset, error) {
+	return f.assets, f.err
+}
+
+func (f *fakeAssetUseCase) ListAssets(context.Context, entity.AssetFilter) ([]*entity.Asset, error) {
+	return f.assets, f.err
+}
+
+// dial starts a server over bufconn and returns a connection to it.
+func dial(t *testing.T, u usecase.UserUseCase, a usecase.AssetUseCase) *grpc.ClientConn {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 20)
+	l := logger.New("error")
+
+	srv := grpc.NewServer(v1.Interceptors(l, _jwtSecret, u)...)
+	v1.NewRouter(srv, l, u, a)
+
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func withToken(t *testing.T, userID int64) context.Context {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(_jwtSecret))
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestUserService_RegisterAndLogin(t *testing.T) {
+	t.Parallel()
+
+	client := pb.NewUserServiceClient(dial(t, &fakeUserUseCase{registered: map[string]string{}}, &fakeAssetUseCase{}))
+	ctx := context.Background()
+
+	_, err := client.Register(ctx, &pb.RegisterRequest{Username: "aboba", Password: "kapusta"})
+	require.NoError(t, err)
+
+	_, err = client.Register(ctx, &pb.RegisterRequest{Username: "aboba", Password: "kapusta"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	resp, err := client.Login(ctx, &pb.LoginRequest{Username: "aboba", Password: "kapusta"})
+	require.NoError(t, err)
+	assert.Equal(t, "token", resp.GetToken())
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Username: "aboba", Password: "wrong"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Username: "aboba"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAssetService_RequiresToken(t *testing.T) {
+	t.Parallel()
+
+	client := pb.NewAssetServiceClient(dial(t, &fakeUserUseCase{}, &fakeAssetUseCase{}))
+
+	_, err := client.CreateAsset(context.Background(), &pb.CreateAssetRequest{Name: "a"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer garbage")
+	_, err = client.CreateAsset(ctx, &pb.CreateAssetRequest{Name: "a"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.ListAssets(context.Background(), &pb.ListAssetsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAssetService_RejectsRevokedSession(t *testing.T) {
+	t.Parallel()
+
+	client := pb.NewAssetServiceClient(dial(t, &fakeUserUseCase{revoked: map[int64]bool{5: true}}, &fakeAssetUseCase{}))
+
+	_, err := client.CreateAsset(withToken(t, 5), &pb.CreateAssetRequest{Name: "a"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.CreateAsset(withToken(t, 6), &pb.CreateAssetRequest{Name: "a"})
+	assert.NoError(t, err)
+}
+
+func TestAssetService_CreateAndListAssets(t *testing.T) {
+	t.Parallel()
+
+	assets := &fakeAssetUseCase{}
+	client := pb.NewAssetServiceClient(dial(t, &fakeUserUseCase{}, assets))
+	ctx := withToken(t, 7)
+
+	for _, name := range []string{"first", "second"} {
+		resp, err := client.CreateAsset(ctx, &pb.CreateAssetRequest{Name: name, Price: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), resp.GetAsset().GetUserId())
+	}
+
+	_, err := client.CreateAsset(withToken(t, 8), &pb.CreateAssetRequest{Name: "other"})
+	require.NoError(t, err)
+
+	stream, err := client.ListAssets(ctx, &pb.ListAssetsRequest{})
+	require.NoError(t, err)
+
+	var names []string
+	for {
+		asset, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, asset.GetName())
+	}
+
+	assert.Equal(t, []string{"first", "second"}, names)
+}
+
+func TestAssetService_MapsErrorsToCodes(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{usecase.ErrAssetNotFound, codes.NotFound},
+		{usecase.ErrOwnAsset, codes.FailedPrecondition},
+		{assert.AnError, codes.Internal},
+	} {
+		client := pb.NewAssetServiceClient(dial(t, &fakeUserUseCase{}, &fakeAssetUseCase{err: tc.err}))
+
+		_, err := client.PurchaseAsset(withToken(t, 1), &pb.PurchaseAssetRequest{Id: 1})
+
+		assert.Equal(t, tc.code, status.Code(err), tc.err.Error())
+		assert.NotContains(t, status.Convert(err).Message(), assert.AnError.Error())
+	}
+}
+
+func TestInterceptors_SetRequestMeta(t *testing.T) {
+	t.Parallel()
+
+	var got reqctx.Meta
+
+	ln := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(v1.Interceptors(logger.New("error"), _jwtSecret, &fakeUserUseCase{})...)
+	pb.RegisterAssetServiceServer(srv, &metaRecorder{got: &got})
+
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var header metadata.MD
+
+	ctx := metadata.AppendToOutgoingContext(withToken(t, 3), "x-request-id", "req-1")
+	_, err = pb.NewAssetServiceClient(conn).DeleteAsset(ctx, &pb.DeleteAssetRequest{Id: 1}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), got.UserID)
+	assert.Equal(t, "req-1", got.RequestID)
+	assert.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+	assert.Contains(t, got.UserAgent, "grpc-go")
+
+	// A missing or malformed request ID is replaced and sent back.
+	ctx = metadata.AppendToOutgoingContext(withToken(t, 3), "x-request-id", "bad id")
+	_, err = pb.NewAssetServiceClient(conn).DeleteAsset(ctx, &pb.DeleteAssetRequest{Id: 1}, grpc.Header(&header))
+	require.NoError(t, err)
+
+	assert.Len(t, got.RequestID, 32)
+	assert.Equal(t, []string{got.RequestID}, header.Get("x-request-id"))
+}
+
+type metaRecorder struct {
+	pb.UnimplementedAssetServiceServer
+
+	got *reqctx.Meta
+}
+
+func (m *metaRecorder) DeleteAsset(ctx context.Context, _ *pb.DeleteAssetRequest) (*pb.DeleteAssetResponse, error) {
+	*m.got = reqctx.FromContext(ctx)
+
+	return &pb.DeleteAssetResponse{}, nil
+}
+
+func TestHealth_IsServingWithoutToken(t *testing.T) {
+	t.Parallel()
+
+	client := healthpb.NewHealthClient(dial(t, &fakeUserUseCase{}, &fakeAssetUseCase{}))
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "hive.v1.AssetService"})
+
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/appxpy/hive-test/docs/proto/v1"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+)
+
+type userService struct {
+	pb.UnimplementedUserServiceServer
+
+	u usecase.UserUseCase
+	l logger.Interface
+}
+
+func (s *userService) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "username and password are required")
+	}
+
+	err := s.u.Register(ctx, req.GetUsername(), req.GetPassword())
+	if err != nil {
+		s.l.WithContext(ctx).Error(err, "grpc - v1 - Register")
+		return nil, statusError(err, "could not create user")
+	}
+
+	return &pb.RegisterResponse{}, nil
+}
+
+func (s *userService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "username and password are required")
+	}
+
+	token, err := s.u.Login(ctx, req.GetUsername(), req.GetPassword())
+	if err != nil {
+		s.l.WithContext(ctx).Error(err, "grpc - v1 - Login")
+		return nil, statusError(err, "could not log in")
+	}
+
+	return &pb.LoginResponse{Token: token}, nil
+}
@@ -43,7 +43,7 @@ func newAssetRoutes(handler *gin.RouterGroup, a usecase.AssetUseCase, l logger.I
 func (r *assetRoutes) addAsset(c *gin.Context) {
 	var asset entity.Asset
 	if err := c.ShouldBindJSON(&asset); err != nil {
-		r.l.Error(err, "http - v1 - addAsset")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - addAsset")
 		errorResponse(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
@@ -53,7 +53,7 @@ func (r *assetRoutes) addAsset(c *gin.Context) {
 
 	err := r.a.AddAsset(c.Request.Context(), &asset)
 	if err != nil {
-		r.l.Error(err, "http - v1 - addAsset")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - addAsset")
 		errorResponse(c, http.StatusInternalServerError, "Could not add asset")
 		return
 	}
@@ -76,7 +76,7 @@ func (r *assetRoutes) removeAsset(c *gin.Context) {
 	assetIDStr := c.Param("id")
 	assetID, err := strconv.ParseInt(assetIDStr, 10, 64)
 	if err != nil {
-		r.l.Error(err, "http - v1 - removeAsset")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - removeAsset")
 		errorResponse(c, http.StatusBadRequest, "Invalid asset ID")
 		return
 	}
@@ -85,7 +85,7 @@ func (r *assetRoutes) removeAsset(c *gin.Context) {
 
 	err = r.a.RemoveAsset(c.Request.Context(), assetID, userID)
 	if err != nil {
-		r.l.Error(err, "http - v1 - removeAsset")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - removeAsset")
 		errorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -108,7 +108,7 @@ func (r *assetRoutes) purchaseAsset(c *gin.Context) {
 	assetIDStr := c.Param("id")
 	assetID, err := strconv.ParseInt(assetIDStr, 10, 64)
 	if err != nil {
-		r.l.Error(err, "http - v1 - purchaseAsset")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - purchaseAsset")
 		errorResponse(c, http.StatusBadRequest, "Invalid asset ID")
 		return
 	}
@@ -117,7 +117,7 @@ func (r *assetRoutes) purchaseAsset(c *gin.Context) {
 
 	err = r.a.PurchaseAsset(c.Request.Context(), assetID, userID)
 	if err != nil {
-		r.l.Error(err, "http - v1 - purchaseAsset")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - purchaseAsset")
 		errorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -138,7 +138,7 @@ func (r *assetRoutes) getUserAssets(c *gin.Context) {
 
 	assets, err := r.a.GetAssetsByUser(c.Request.Context(), userID)
 	if err != nil {
-		r.l.Error(err, "http - v1 - getUserAssets")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - getUserAssets")
 		errorResponse(c, http.StatusInternalServerError, "Could not retrieve assets")
 		return
 	}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/middleware"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type auditRoutes struct {
+	au usecase.AuditUseCase
+	l  logger.Interface
+}
+
+func newAuditRoutes(handler *gin.RouterGroup, au usecase.AuditUseCase, l logger.Interface, jwtSecret string) {
+	r := &auditRoutes{au, l}
+
+	h := handler.Group("/admin/audit")
+	h.Use(middleware.JWTAuth(jwtSecret), middleware.RequireRole(entity.RoleAdmin))
+	{
+		h.GET("/", r.listEntries)
+		h.GET("/verify", r.verifyChain)
+	}
+}
+
+type auditQuery struct {
+	ActorID    int64     `form:"actor_id"`
+	Action     string    `form:"action"`
+	EntityType string    `form:"entity_type"`
+	EntityID   int64     `form:"entity_id"`
+	RequestID  string    `form:"request_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      uint64    `form:"limit" binding:"max=1000"`
+	Offset     uint64    `form:"offset"`
+}
+
+// @Security    BearerAuth
+// @Summary     List Audit Entries
+// @Description Returns audit log entries matching the filters, oldest first. Admin only.
+// @Tags        admin
+// @Produce     json
+// @Param       actor_id    query    int    false "Actor user ID"
+// @Param       action      query    string false "Action, e.g. asset.purchase"
+// @Param       entity_type query    string false "Entity type, e.g. asset"
+// @Param       entity_id   query    int    false "Entity ID"
+// @Param       request_id  query    string false "Request ID"
+// @Param       from        query    string false "Start time, RFC 3339"
+// @Param       to          query    string false "End time (exclusive), RFC 3339"
+// @Param       limit       query    int    false "Page size, at most 1000"
+// @Param       offset      query    int    false "Page offset"
+// @Success     200 {array}  entity.AuditEntry
+// @Failure     400 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/audit [get]
+func (r *auditRoutes) listEntries(c *gin.Context) {
+	var q auditQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listEntries")
+		errorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	entries, err := r.au.ListEntries(c.Request.Context(), entity.AuditFilter{
+		ActorID:    q.ActorID,
+		Action:     entity.AuditAction(q.Action),
+		EntityType: q.EntityType,
+		EntityID:   q.EntityID,
+		RequestID:  q.RequestID,
+		From:       q.From,
+		To:         q.To,
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+	})
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listEntries")
+		errorResponse(c, http.StatusInternalServerError, "Could not retrieve audit entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// @Security    BearerAuth
+// @Summary     Verify Audit Chain
+// @Description Walks the audit hash chain and reports the first broken entry. Admin only.
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} entity.AuditVerification
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/audit/verify [get]
+func (r *auditRoutes) verifyChain(c *gin.Context) {
+	res, err := r.au.VerifyChain(c.Request.Context())
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - verifyChain")
+		errorResponse(c, http.StatusInternalServerError, "Could not verify audit chain")
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
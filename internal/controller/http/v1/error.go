@@ -2,12 +2,18 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"github.com/appxpy/hive-test/pkg/reqctx"
 )
 
 type response struct {
-	Error string `json:"error" example:"message"`
+	Error     string `json:"error" example:"message"`
+	RequestID string `json:"request_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
 }
 
 func errorResponse(c *gin.Context, code int, msg string) {
-	c.AbortWithStatusJSON(code, response{msg})
+	c.AbortWithStatusJSON(code, response{
+		Error:     msg,
+		RequestID: reqctx.FromContext(c.Request.Context()).RequestID,
+	})
 }
@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/middleware"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type flagRoutes struct {
+	f usecase.FlagUseCase
+	l logger.Interface
+}
+
+func newFlagRoutes(handler *gin.RouterGroup, f usecase.FlagUseCase, l logger.Interface, jwtSecret string) {
+	r := &flagRoutes{f, l}
+
+	h := handler.Group("/flags")
+	h.Use(middleware.JWTAuth(jwtSecret))
+	{
+		h.GET("/", r.enabledFlags)
+	}
+
+	a := handler.Group("/admin/flags")
+	a.Use(middleware.JWTAuth(jwtSecret), middleware.RequireRole(entity.RoleAdmin))
+	{
+		a.GET("/", r.listFlags)
+		a.GET("/:key", r.getFlag)
+		a.PUT("/:key", r.setFlag)
+		a.DELETE("/:key", r.deleteFlag)
+	}
+}
+
+type enabledFlagsResponse struct {
+	Flags []string `json:"flags" example:"auctions,royalties"`
+}
+
+type setFlagRequest struct {
+	Description string   `json:"description" example:"Auction listings"`
+	Enabled     bool     `json:"enabled"`
+	Rollout     int      `json:"rollout" example:"25"`
+	Users       []int64  `json:"users"`
+	Roles       []string `json:"roles" example:"admin"`
+}
+
+// @Security    BearerAuth
+// @Summary     Enabled Feature Flags
+// @Description Returns the keys of the feature flags on for the user, for clients to show the features.
+// @Tags        flags
+// @Produce     json
+// @Success     200 {object} enabledFlagsResponse
+// @Failure     401 {object} response
+// @Router      /flags [get]
+func (r *flagRoutes) enabledFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, enabledFlagsResponse{Flags: r.f.EnabledFlags(c.Request.Context())})
+}
+
+// @Security    BearerAuth
+// @Summary     List Feature Flags
+// @Description Returns all feature flags, sorted by key. Admin only.
+// @Tags        admin
+// @Produce     json
+// @Success     200 {array}  entity.FeatureFlag
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/flags [get]
+func (r *flagRoutes) listFlags(c *gin.Context) {
+	flags, err := r.f.ListFlags(c.Request.Context())
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listFlags")
+		errorResponse(c, http.StatusInternalServerError, "Could not retrieve feature flags")
+		return
+	}
+
+	c.JSON(http.StatusOK, flags)
+}
+
+// @Security    BearerAuth
+// @Summary     Get Feature Flag
+// @Description Returns a feature flag. Admin only.
+// @Tags        admin
+// @Produce     json
+// @Param       key path     string true "Flag key"
+// @Success     200 {object} entity.FeatureFlag
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/flags/{key} [get]
+func (r *flagRoutes) getFlag(c *gin.Context) {
+	flag, err := r.f.GetFlag(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - getFlag")
+		r.flagError(c, err, "Could not retrieve feature flag")
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// @Security    BearerAuth
+// @Summary     Set Feature Flag
+// @Description Creates or replaces a feature flag. A disabled flag is off for everyone; an enabled one is on
+// @Description for the listed users and roles and for rollout percent of the other users. Admin only.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       key  path     string         true "Flag key"
+// @Param       flag body     setFlagRequest true "Flag Data"
+// @Success     200 {object} entity.FeatureFlag
+// @Failure     400 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/flags/{key} [put]
+func (r *flagRoutes) setFlag(c *gin.Context) {
+	var req setFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - setFlag")
+		errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	flag := &entity.FeatureFlag{
+		Key:         c.Param("key"),
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Rollout:     req.Rollout,
+		Users:       req.Users,
+		Roles:       req.Roles,
+	}
+
+	err := r.f.SetFlag(c.Request.Context(), flag)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - setFlag")
+		r.flagError(c, err, "Could not save feature flag")
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// @Security    BearerAuth
+// @Summary     Delete Feature Flag
+// @Description Deletes a feature flag, which turns it off for everyone. Admin only.
+// @Tags        admin
+// @Produce     json
+// @Param       key path string true "Flag key"
+// @Success     200
+// @Failure     403 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/flags/{key} [delete]
+func (r *flagRoutes) deleteFlag(c *gin.Context) {
+	err := r.f.DeleteFlag(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - deleteFlag")
+		r.flagError(c, err, "Could not delete feature flag")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (r *flagRoutes) flagError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, usecase.ErrFlagNotFound):
+		errorResponse(c, http.StatusNotFound, "Feature flag not found")
+	case errors.Is(err, usecase.ErrInvalidFlag):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		errorResponse(c, http.StatusInternalServerError, msg)
+	}
+}
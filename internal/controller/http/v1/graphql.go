@@ -0,0 +1,356 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/appxpy/hive-test/internal/controller/graphql"
+	"github.com/appxpy/hive-test/internal/middleware"
+	"github.com/appxpy/hive-test/pkg/httpserver"
+	"github.com/appxpy/hive-test/pkg/logger"
+)
+
+// graphql-transport-ws protocol, see
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md.
+const (
+	_gqlMaxBodySize        = 1 << 20
+	_gqlWSProtocol         = "graphql-transport-ws"
+	_gqlWSInitTimeout      = 10 * time.Second
+	_gqlWSPingInterval     = 30 * time.Second
+	_gqlWSWriteTimeout     = 10 * time.Second
+	_gqlWSMaxMessageSize   = 64 << 10
+	_gqlWSMaxSubscriptions = 20
+
+	_gqlWSCloseBadRequest     = 4400
+	_gqlWSCloseUnauthorized   = 4401
+	_gqlWSCloseBadSubprotocol = 4406
+	_gqlWSCloseInitTimeout    = 4408
+	_gqlWSCloseDuplicateID    = 4409
+	_gqlWSCloseTooManyInits   = 4429
+	_gqlWSCloseTooManySubs    = 4430
+
+	_gqlWSMessageInit      = "connection_init"
+	_gqlWSMessageAck       = "connection_ack"
+	_gqlWSMessagePing      = "ping"
+	_gqlWSMessagePong      = "pong"
+	_gqlWSMessageSubscribe = "subscribe"
+	_gqlWSMessageNext      = "next"
+	_gqlWSMessageError     = "error"
+	_gqlWSMessageComplete  = "complete"
+)
+
+type graphqlRoutes struct {
+	g        *graphql.Service
+	l        logger.Interface
+	upgrader websocket.Upgrader
+}
+
+func newGraphQLRoutes(handler *gin.RouterGroup, g *graphql.Service, l logger.Interface, jwtSecret string) {
+	r := &graphqlRoutes{
+		g: g,
+		l: l,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{_gqlWSProtocol},
+			// Authentication is by bearer token, not cookies, so any origin may connect.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+
+	h := handler.Group("/graphql")
+	h.Use(middleware.TokenFromQuery(), middleware.JWTAuth(jwtSecret))
+	{
+		h.POST("", r.execute)
+		h.GET("", r.subscribe)
+	}
+}
+
+type gqlWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// @Security    BearerAuth
+// @Summary     GraphQL
+// @Description Runs a GraphQL query or mutation. Subscriptions use the graphql-transport-ws protocol over a WebSocket on GET.
+// @Tags        graphql
+// @Accept      json
+// @Produce     json
+// @Param       request body     graphql.Request true "GraphQL Request"
+// @Success     200 {object} object
+// @Failure     400 {object} response
+// @Failure     401 {object} response
+// @Router      /graphql [post]
+func (r *graphqlRoutes) execute(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, _gqlMaxBodySize)
+
+	var req graphql.Request
+	if err := c.ShouldBindJSON(&req); err != nil || req.Query == "" {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - graphql")
+		errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	c.JSON(http.StatusOK, r.g.Execute(c.Request.Context(), req))
+}
+
+func (r *graphqlRoutes) subscribe(c *gin.Context) {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		errorResponse(c, http.StatusBadRequest, "Use POST for queries or a WebSocket for subscriptions")
+		return
+	}
+
+	conn, err := r.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - graphql subscribe")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	s := &gqlWSSession{
+		conn: conn,
+		r:    r,
+		ctx:  ctx,
+		out:  make(chan gqlWSMessage, 16),
+		subs: make(map[string]context.CancelFunc),
+	}
+
+	go func() {
+		s.writePump()
+		cancel()
+		_ = conn.Close()
+	}()
+
+	// Hijacked connections are not closed by the server shutdown.
+	go func() {
+		select {
+		case <-httpserver.Stopping(ctx):
+			s.close(websocket.CloseGoingAway, "Server shutting down")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if conn.Subprotocol() != _gqlWSProtocol {
+		s.close(_gqlWSCloseBadSubprotocol, "Subprotocol not acceptable")
+		return
+	}
+
+	s.readPump()
+	s.wait()
+}
+
+// gqlWSSession serves the subscriptions of one WebSocket connection.
+type gqlWSSession struct {
+	conn *websocket.Conn
+	r    *graphqlRoutes
+	ctx  context.Context
+	out  chan gqlWSMessage
+
+	mu      sync.Mutex
+	subs    map[string]context.CancelFunc
+	running sync.WaitGroup
+	closing bool
+}
+
+func (s *gqlWSSession) readPump() {
+	s.conn.SetReadLimit(_gqlWSMaxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(_gqlWSInitTimeout))
+
+	acknowledged := false
+
+	for {
+		var msg gqlWSMessage
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			var netErr net.Error
+			if !acknowledged && errors.As(err, &netErr) && netErr.Timeout() {
+				s.close(_gqlWSCloseInitTimeout, "Connection initialisation timeout")
+			}
+
+			return
+		}
+
+		switch msg.Type {
+		case _gqlWSMessageInit:
+			if acknowledged {
+				s.close(_gqlWSCloseTooManyInits, "Too many initialisation requests")
+				return
+			}
+
+			// The token was checked on upgrade.
+			acknowledged = true
+			_ = s.conn.SetReadDeadline(time.Time{})
+			s.send(gqlWSMessage{Type: _gqlWSMessageAck})
+		case _gqlWSMessagePing:
+			s.send(gqlWSMessage{Type: _gqlWSMessagePong})
+		case _gqlWSMessagePong:
+		case _gqlWSMessageSubscribe:
+			if !acknowledged {
+				s.close(_gqlWSCloseUnauthorized, "Unauthorized")
+				return
+			}
+
+			if !s.start(msg) {
+				return
+			}
+		case _gqlWSMessageComplete:
+			s.stop(msg.ID)
+		default:
+			s.close(_gqlWSCloseBadRequest, "Unknown message type")
+			return
+		}
+	}
+}
+
+// start runs a subscription and reports whether the connection stays open.
+func (s *gqlWSSession) start(msg gqlWSMessage) bool {
+	var req graphql.Request
+	if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil || req.Query == "" {
+		s.close(_gqlWSCloseBadRequest, "Invalid subscribe message")
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subs[msg.ID]; ok {
+		s.closeLocked(_gqlWSCloseDuplicateID, "Subscriber for "+msg.ID+" already exists")
+		return false
+	}
+
+	if len(s.subs) >= _gqlWSMaxSubscriptions {
+		s.closeLocked(_gqlWSCloseTooManySubs, "Too many subscriptions")
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.subs[msg.ID] = cancel
+	s.running.Add(1)
+
+	go s.run(ctx, msg.ID, req)
+
+	return true
+}
+
+func (s *gqlWSSession) run(ctx context.Context, id string, req graphql.Request) {
+	defer s.running.Done()
+
+	first := true
+
+	// The results must be drained until closed, also after ctx is done.
+	for res := range s.r.g.Subscribe(ctx, req) {
+		if ctx.Err() != nil {
+			continue
+		}
+
+		if first && res.Data == nil && len(res.Errors) > 0 {
+			payload, _ := json.Marshal(res.Errors)
+			s.send(gqlWSMessage{ID: id, Type: _gqlWSMessageError, Payload: payload})
+			s.forget(id)
+
+			return
+		}
+
+		first = false
+
+		payload, err := json.Marshal(res)
+		if err != nil {
+			s.r.l.WithContext(ctx).Error(err, "http - v1 - graphql subscribe")
+			continue
+		}
+
+		s.send(gqlWSMessage{ID: id, Type: _gqlWSMessageNext, Payload: payload})
+	}
+
+	if ctx.Err() == nil {
+		s.send(gqlWSMessage{ID: id, Type: _gqlWSMessageComplete})
+	}
+
+	s.forget(id)
+}
+
+func (s *gqlWSSession) stop(id string) {
+	s.mu.Lock()
+	cancel, ok := s.subs[id]
+	delete(s.subs, id)
+	s.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+func (s *gqlWSSession) forget(id string) {
+	s.mu.Lock()
+	delete(s.subs, id)
+	s.mu.Unlock()
+}
+
+// wait stops the running subscriptions and waits for them to finish.
+func (s *gqlWSSession) wait() {
+	s.mu.Lock()
+	for id, cancel := range s.subs {
+		cancel()
+		delete(s.subs, id)
+	}
+	s.mu.Unlock()
+
+	s.running.Wait()
+}
+
+func (s *gqlWSSession) send(msg gqlWSMessage) {
+	select {
+	case s.out <- msg:
+	case <-s.ctx.Done():
+	}
+}
+
+func (s *gqlWSSession) writePump() {
+	ticker := time.NewTicker(_gqlWSPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-s.out:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(_gqlWSWriteTimeout))
+			if err := s.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(_gqlWSWriteTimeout))
+			if err != nil {
+				return
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *gqlWSSession) close(code int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeLocked(code, reason)
+}
+
+func (s *gqlWSSession) closeLocked(code int, reason string) {
+	if s.closing {
+		return
+	}
+
+	s.closing = true
+
+	_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(_gqlWSWriteTimeout))
+}
@@ -0,0 +1,249 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/middleware"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type notificationRoutes struct {
+	n usecase.NotificationUseCase
+	l logger.Interface
+}
+
+func newNotificationRoutes(handler *gin.RouterGroup, n usecase.NotificationUseCase, l logger.Interface, jwtSecret string) {
+	r := &notificationRoutes{n, l}
+
+	h := handler.Group("/notifications")
+	h.Use(middleware.JWTAuth(jwtSecret))
+	{
+		h.GET("/", r.listNotifications)
+		h.GET("/unread-count", r.unreadCount)
+		h.POST("/read", r.markRead)
+		h.POST("/read-all", r.markAllRead)
+		h.GET("/preferences", r.getPreferences)
+		h.PUT("/preferences", r.updatePreferences)
+	}
+
+	a := handler.Group("/admin/notifications")
+	a.Use(middleware.JWTAuth(jwtSecret), middleware.RequireRole(entity.RoleAdmin))
+	{
+		a.POST("/", r.sendAdminMessage)
+	}
+}
+
+type notificationQuery struct {
+	Unread   bool   `form:"unread"`
+	BeforeID int64  `form:"before_id"`
+	Limit    uint64 `form:"limit" binding:"max=200"`
+}
+
+type markReadRequest struct {
+	IDs []int64 `json:"ids" binding:"required" example:"1,2,3"`
+}
+
+type markReadResponse struct {
+	Updated int64 `json:"updated" example:"3"`
+}
+
+type unreadCountResponse struct {
+	Count int64 `json:"count" example:"5"`
+}
+
+type adminMessageRequest struct {
+	UserID  int64  `json:"user_id" binding:"required" example:"1"`
+	Message string `json:"message" binding:"required" example:"Scheduled maintenance tonight"`
+}
+
+// @Security    BearerAuth
+// @Summary     List Notifications
+// @Description Returns the user's inbox, newest first. Use before_id with the last ID of a page to get the next one.
+// @Tags        notifications
+// @Produce     json
+// @Param       unread    query    bool false "Only unread notifications"
+// @Param       before_id query    int  false "Return notifications older than this ID"
+// @Param       limit     query    int  false "Page size, at most 200"
+// @Success     200 {array}  entity.Notification
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /notifications [get]
+func (r *notificationRoutes) listNotifications(c *gin.Context) {
+	var q notificationQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listNotifications")
+		errorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	notifications, err := r.n.ListNotifications(c.Request.Context(), c.GetInt64("userID"), entity.NotificationFilter{
+		UnreadOnly: q.Unread,
+		BeforeID:   q.BeforeID,
+		Limit:      q.Limit,
+	})
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listNotifications")
+		errorResponse(c, http.StatusInternalServerError, "Could not retrieve notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// @Security    BearerAuth
+// @Summary     Unread Notification Count
+// @Description Returns the number of unread notifications
+// @Tags        notifications
+// @Produce     json
+// @Success     200 {object} unreadCountResponse
+// @Failure     500 {object} response
+// @Router      /notifications/unread-count [get]
+func (r *notificationRoutes) unreadCount(c *gin.Context) {
+	count, err := r.n.UnreadCount(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - unreadCount")
+		errorResponse(c, http.StatusInternalServerError, "Could not count notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, unreadCountResponse{Count: count})
+}
+
+// @Security    BearerAuth
+// @Summary     Mark Notifications Read
+// @Description Marks the given notifications as read
+// @Tags        notifications
+// @Accept      json
+// @Produce     json
+// @Param       request body     markReadRequest true "Notification IDs"
+// @Success     200 {object} markReadResponse
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /notifications/read [post]
+func (r *notificationRoutes) markRead(c *gin.Context) {
+	var req markReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - markRead")
+		errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updated, err := r.n.MarkRead(c.Request.Context(), c.GetInt64("userID"), req.IDs)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - markRead")
+		r.notificationError(c, err, "Could not mark notifications read")
+		return
+	}
+
+	c.JSON(http.StatusOK, markReadResponse{Updated: updated})
+}
+
+// @Security    BearerAuth
+// @Summary     Mark All Notifications Read
+// @Description Marks every notification of the user as read
+// @Tags        notifications
+// @Produce     json
+// @Success     200 {object} markReadResponse
+// @Failure     500 {object} response
+// @Router      /notifications/read-all [post]
+func (r *notificationRoutes) markAllRead(c *gin.Context) {
+	updated, err := r.n.MarkAllRead(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - markAllRead")
+		errorResponse(c, http.StatusInternalServerError, "Could not mark notifications read")
+		return
+	}
+
+	c.JSON(http.StatusOK, markReadResponse{Updated: updated})
+}
+
+// @Security    BearerAuth
+// @Summary     Get Notification Preferences
+// @Description Returns whether each notification type is enabled
+// @Tags        notifications
+// @Produce     json
+// @Success     200 {array}  entity.NotificationPreference
+// @Failure     500 {object} response
+// @Router      /notifications/preferences [get]
+func (r *notificationRoutes) getPreferences(c *gin.Context) {
+	prefs, err := r.n.GetPreferences(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - getPreferences")
+		errorResponse(c, http.StatusInternalServerError, "Could not retrieve preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// @Security    BearerAuth
+// @Summary     Update Notification Preferences
+// @Description Turns notification types on or off, e.g. {"asset.bought": false}. Admin messages cannot be turned off.
+// @Tags        notifications
+// @Accept      json
+// @Produce     json
+// @Param       preferences body     map[string]bool true "Type to enabled"
+// @Success     200 {array}  entity.NotificationPreference
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /notifications/preferences [put]
+func (r *notificationRoutes) updatePreferences(c *gin.Context) {
+	var req map[string]bool
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - updatePreferences")
+		errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	prefs, err := r.n.UpdatePreferences(c.Request.Context(), c.GetInt64("userID"), req)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - updatePreferences")
+		r.notificationError(c, err, "Could not update preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// @Security    BearerAuth
+// @Summary     Send Admin Message
+// @Description Puts a message into a user's notification inbox. Admin only.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       message body     adminMessageRequest true "Message"
+// @Success     201 {object} entity.Notification
+// @Failure     400 {object} response
+// @Failure     403 {object} response
+// @Failure     500 {object} response
+// @Router      /admin/notifications [post]
+func (r *notificationRoutes) sendAdminMessage(c *gin.Context) {
+	var req adminMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - sendAdminMessage")
+		errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	n, err := r.n.SendAdminMessage(c.Request.Context(), c.GetInt64("userID"), req.UserID, req.Message)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - sendAdminMessage")
+		r.notificationError(c, err, "Could not send message")
+		return
+	}
+
+	c.JSON(http.StatusCreated, n)
+}
+
+func (r *notificationRoutes) notificationError(c *gin.Context, err error, msg string) {
+	if errors.Is(err, usecase.ErrInvalidNotification) {
+		errorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	errorResponse(c, http.StatusInternalServerError, msg)
+}
@@ -2,17 +2,21 @@
 package v1
 
 import (
-	"net/http"
+	"fmt"
 
 	"github.com/appxpy/hive-test/config"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	// Swagger docs.
 	_ "github.com/appxpy/hive-test/docs"
+	"github.com/appxpy/hive-test/internal/controller/graphql"
+	"github.com/appxpy/hive-test/internal/middleware"
 	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/health"
 	"github.com/appxpy/hive-test/pkg/logger"
 )
 
@@ -28,29 +32,62 @@ import (
 // @name Authorization
 func NewRouter(
 	handler *gin.Engine,
-	config *config.Config,
+	configs *config.Store,
 	l logger.Interface,
+	checks *health.Registry,
 	u usecase.UserUseCase,
 	a usecase.AssetUseCase,
+	au usecase.AuditUseCase,
+	w usecase.WebhookUseCase,
+	n usecase.NotificationUseCase,
+	f usecase.FlagUseCase,
 ) {
+	cfg := configs.Current()
+
 	// Options
-	handler.Use(gin.Logger())
+	handler.Use(middleware.Tracing(cfg.App.Name))
+	handler.Use(middleware.Metrics(prometheus.DefaultRegisterer))
+	handler.Use(middleware.AccessLog(l))
 	handler.Use(gin.Recovery())
+	handler.Use(middleware.RequestMeta())
+	handler.Use(middleware.RejectRevokedSessions(cfg.JWTSecret, u))
 
 	// Swagger
 	swaggerHandler := ginSwagger.DisablingWrapHandler(swaggerFiles.Handler, "DISABLE_SWAGGER_HTTP_HANDLER")
 	handler.GET("/swagger/*any", swaggerHandler)
 
-	// K8s probe
-	handler.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	// K8s probes
+	handler.GET("/livez", gin.WrapH(checks.LiveHandler()))
+	handler.GET("/readyz", gin.WrapH(checks.ReadyHandler()))
+	handler.GET("/healthz", gin.WrapH(checks.ReadyHandler()))
+
+	// Prometheus metrics, unless served on the admin port
+	if cfg.Metrics.Port == "" {
+		handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
+
+	gql, err := graphql.New(u, a, au, n, l,
+		graphql.MaxDepth(cfg.GraphQL.MaxDepth),
+		graphql.MaxComplexity(cfg.GraphQL.MaxComplexity),
+	)
+	if err != nil {
+		l.Fatal(fmt.Errorf("http - v1 - NewRouter - graphql.New: %w", err))
+	}
 
-	// Prometheus metrics
-	handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	configs.Subscribe(func(next *config.Config) {
+		gql.SetLimits(next.GraphQL.MaxDepth, next.GraphQL.MaxComplexity)
+	})
 
 	// Routers
 	h := handler.Group("/v1")
 	{
 		newUserRoutes(h, u, l)
-		newAssetRoutes(h, a, l, config.JWTSecret)
+		newAssetRoutes(h, a, l, cfg.JWTSecret)
+		newAuditRoutes(h, au, l, cfg.JWTSecret)
+		newWebhookRoutes(h, w, l, cfg.JWTSecret)
+		newNotificationRoutes(h, n, l, cfg.JWTSecret)
+		newStreamRoutes(h, n, l, cfg.JWTSecret, cfg.Notify.Heartbeat)
+		newGraphQLRoutes(h, gql, l, cfg.JWTSecret)
+		newFlagRoutes(h, f, l, cfg.JWTSecret)
 	}
 }
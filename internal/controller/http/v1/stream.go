@@ -0,0 +1,208 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/middleware"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/httpserver"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const _streamWriteTimeout = 10 * time.Second
+
+type streamRoutes struct {
+	n         usecase.NotificationUseCase
+	l         logger.Interface
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+func newStreamRoutes(
+	handler *gin.RouterGroup,
+	n usecase.NotificationUseCase,
+	l logger.Interface,
+	jwtSecret string,
+	heartbeat time.Duration,
+) {
+	r := &streamRoutes{
+		n:         n,
+		l:         l,
+		heartbeat: heartbeat,
+		upgrader: websocket.Upgrader{
+			// Authentication is by bearer token, not cookies, so any origin may connect.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+
+	handler.GET("/stream", middleware.TokenFromQuery(), middleware.JWTAuth(jwtSecret), r.stream)
+}
+
+// @Security    BearerAuth
+// @Summary     Notification Stream
+// @Description Streams the user's notifications as Server-Sent Events, or over a WebSocket when the request is an upgrade.
+// @Description Resume with the Last-Event-ID header or the last_event_id query parameter. Browsers may pass the token as access_token.
+// @Tags        notifications
+// @Produce     text/event-stream
+// @Param       last_event_id query    int    false "Resume after this notification ID"
+// @Param       access_token  query    string false "JWT, for clients that cannot set headers"
+// @Success     200 {object} entity.Notification
+// @Failure     401 {object} response
+// @Router      /stream [get]
+func (r *streamRoutes) stream(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	afterID, _ := strconv.ParseInt(lastEventID, 10, 64)
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	notifications, err := r.n.Subscribe(ctx, c.GetInt64("userID"), afterID)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - stream")
+		errorResponse(c, http.StatusInternalServerError, "Could not subscribe to notifications")
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		r.streamWebSocket(c, cancel, notifications)
+		return
+	}
+
+	r.streamSSE(c, notifications)
+}
+
+func (r *streamRoutes) streamSSE(c *gin.Context, notifications <-chan *entity.Notification) {
+	w := c.Writer
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(frame string) error {
+		// The server-wide write timeout would cut long-lived streams.
+		_ = rc.SetWriteDeadline(time.Now().Add(_streamWriteTimeout))
+
+		if _, err := fmt.Fprint(w, frame); err != nil {
+			return err
+		}
+
+		return rc.Flush()
+	}
+
+	if write(": connected\n\n") != nil {
+		return
+	}
+
+	ticker := time.NewTicker(r.heartbeat)
+	defer ticker.Stop()
+
+	stopping := httpserver.Stopping(c.Request.Context())
+
+	for {
+		var frame string
+
+		select {
+		case <-stopping:
+			// The client reconnects to another instance with Last-Event-ID.
+			return
+		case n, ok := <-notifications:
+			if !ok {
+				// Unsubscribed or too slow; the client reconnects with Last-Event-ID.
+				return
+			}
+
+			data, err := json.Marshal(n)
+			if err != nil {
+				r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - streamSSE")
+				return
+			}
+
+			frame = fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", n.ID, n.Type, data)
+		case <-ticker.C:
+			frame = ": heartbeat\n\n"
+		}
+
+		if write(frame) != nil {
+			return
+		}
+	}
+}
+
+func (r *streamRoutes) streamWebSocket(
+	c *gin.Context,
+	cancel context.CancelFunc,
+	notifications <-chan *entity.Notification,
+) {
+	conn, err := r.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - streamWebSocket")
+		return
+	}
+	defer conn.Close()
+
+	// The read pump handles control frames and notices a closed connection.
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * r.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * r.heartbeat))
+	})
+
+	go func() {
+		defer cancel()
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(r.heartbeat)
+	defer ticker.Stop()
+
+	stopping := httpserver.Stopping(c.Request.Context())
+
+	for {
+		select {
+		case <-stopping:
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(_streamWriteTimeout))
+
+			return
+		case n, ok := <-notifications:
+			if !ok {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "resubscribe"),
+					time.Now().Add(_streamWriteTimeout))
+
+				return
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(_streamWriteTimeout))
+			if err = conn.WriteJSON(n); err != nil {
+				return
+			}
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(_streamWriteTimeout))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
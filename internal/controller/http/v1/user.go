@@ -41,14 +41,14 @@ type userCredentials struct {
 func (r *userRoutes) register(c *gin.Context) {
 	var creds userCredentials
 	if err := c.ShouldBindJSON(&creds); err != nil {
-		r.l.Error(err, "http - v1 - register")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - register")
 		errorResponse(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	err := r.u.Register(c.Request.Context(), creds.Username, creds.Password)
 	if err != nil {
-		r.l.Error(err, "http - v1 - register")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - register")
 		errorResponse(c, http.StatusInternalServerError, "Could not create user")
 		return
 	}
@@ -73,14 +73,14 @@ type loginResponse struct {
 func (r *userRoutes) login(c *gin.Context) {
 	var creds userCredentials
 	if err := c.ShouldBindJSON(&creds); err != nil {
-		r.l.Error(err, "http - v1 - login")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - login")
 		errorResponse(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	token, err := r.u.Login(c.Request.Context(), creds.Username, creds.Password)
 	if err != nil {
-		r.l.Error(err, "http - v1 - login")
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - login")
 		errorResponse(c, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
@@ -0,0 +1,233 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/appxpy/hive-test/internal/middleware"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type webhookRoutes struct {
+	w usecase.WebhookUseCase
+	l logger.Interface
+}
+
+func newWebhookRoutes(handler *gin.RouterGroup, w usecase.WebhookUseCase, l logger.Interface, jwtSecret string) {
+	r := &webhookRoutes{w, l}
+
+	h := handler.Group("/webhooks")
+	h.Use(middleware.JWTAuth(jwtSecret))
+	{
+		h.POST("/", r.createWebhook)
+		h.GET("/", r.listWebhooks)
+		h.DELETE("/:id", r.deleteWebhook)
+		h.PATCH("/:id", r.updateWebhook)
+		h.GET("/:id/deliveries", r.listDeliveries)
+		h.POST("/:id/test", r.sendTestEvent)
+	}
+}
+
+type createWebhookRequest struct {
+	URL    string   `json:"url" binding:"required" example:"https://example.com/hooks/hive"`
+	Events []string `json:"events" binding:"required" example:"asset.sold,asset.bought"`
+}
+
+type updateWebhookRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// @Security    BearerAuth
+// @Summary     Create Webhook
+// @Description Registers a webhook endpoint. The signing secret is only returned here.
+// @Description Deliveries carry X-Hive-Timestamp and X-Hive-Signature: sha256=HMAC(secret, timestamp + "." + body).
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       webhook body createWebhookRequest true "Webhook Data"
+// @Success     201 {object} entity.Webhook
+// @Failure     400 {object} response
+// @Failure     500 {object} response
+// @Router      /webhooks [post]
+func (r *webhookRoutes) createWebhook(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - createWebhook")
+		errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := r.w.CreateWebhook(c.Request.Context(), c.GetInt64("userID"), req.URL, req.Events)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - createWebhook")
+		r.webhookError(c, err, "Could not create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// @Security    BearerAuth
+// @Summary     List Webhooks
+// @Description Returns the user's webhooks
+// @Tags        webhooks
+// @Produce     json
+// @Success     200 {array}  entity.Webhook
+// @Failure     500 {object} response
+// @Router      /webhooks [get]
+func (r *webhookRoutes) listWebhooks(c *gin.Context) {
+	webhooks, err := r.w.ListWebhooks(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listWebhooks")
+		errorResponse(c, http.StatusInternalServerError, "Could not retrieve webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Security    BearerAuth
+// @Summary     Delete Webhook
+// @Description Removes a webhook and its delivery log
+// @Tags        webhooks
+// @Produce     json
+// @Param       id  path     int true "Webhook ID"
+// @Success     200
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /webhooks/{id} [delete]
+func (r *webhookRoutes) deleteWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - deleteWebhook")
+		errorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	err = r.w.DeleteWebhook(c.Request.Context(), webhookID, c.GetInt64("userID"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - deleteWebhook")
+		r.webhookError(c, err, "Could not delete webhook")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Security    BearerAuth
+// @Summary     Update Webhook
+// @Description Enables or disables a webhook. Enabling resets its failure counter.
+// @Tags        webhooks
+// @Accept      json
+// @Produce     json
+// @Param       id      path     int                  true "Webhook ID"
+// @Param       webhook body     updateWebhookRequest true "Webhook State"
+// @Success     200
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /webhooks/{id} [patch]
+func (r *webhookRoutes) updateWebhook(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - updateWebhook")
+		errorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	var req updateWebhookRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - updateWebhook")
+		errorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err = r.w.SetWebhookEnabled(c.Request.Context(), webhookID, c.GetInt64("userID"), *req.Enabled)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - updateWebhook")
+		r.webhookError(c, err, "Could not update webhook")
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// @Security    BearerAuth
+// @Summary     List Webhook Deliveries
+// @Description Returns the latest deliveries of a webhook with their response codes
+// @Tags        webhooks
+// @Produce     json
+// @Param       id    path     int true  "Webhook ID"
+// @Param       limit query    int false "Page size"
+// @Success     200 {array}  entity.WebhookDelivery
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /webhooks/{id}/deliveries [get]
+func (r *webhookRoutes) listDeliveries(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listDeliveries")
+		errorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	limit, err := strconv.ParseUint(c.DefaultQuery("limit", "50"), 10, 64)
+	if err != nil || limit > 500 {
+		errorResponse(c, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	deliveries, err := r.w.ListDeliveries(c.Request.Context(), webhookID, c.GetInt64("userID"), limit)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - listDeliveries")
+		r.webhookError(c, err, "Could not retrieve deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// @Security    BearerAuth
+// @Summary     Send Test Event
+// @Description Sends a signed "ping" event to the webhook right away and returns the delivery result
+// @Tags        webhooks
+// @Produce     json
+// @Param       id  path     int true "Webhook ID"
+// @Success     200 {object} entity.WebhookDelivery
+// @Failure     400 {object} response
+// @Failure     404 {object} response
+// @Failure     500 {object} response
+// @Router      /webhooks/{id}/test [post]
+func (r *webhookRoutes) sendTestEvent(c *gin.Context) {
+	webhookID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - sendTestEvent")
+		errorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	delivery, err := r.w.SendTestEvent(c.Request.Context(), webhookID, c.GetInt64("userID"))
+	if err != nil {
+		r.l.WithContext(c.Request.Context()).Error(err, "http - v1 - sendTestEvent")
+		r.webhookError(c, err, "Could not send test event")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (r *webhookRoutes) webhookError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		errorResponse(c, http.StatusNotFound, "Webhook not found")
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		errorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		errorResponse(c, http.StatusInternalServerError, msg)
+	}
+}
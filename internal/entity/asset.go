@@ -8,3 +8,14 @@ type Asset struct {
 	Description string  `json:"description" db:"description"`
 	Price       float64 `json:"price" db:"price"`
 }
+
+// AssetFilter selects assets of the catalog, ordered by ID. Zero fields are
+// not applied.
+type AssetFilter struct {
+	UserID        int64
+	ExcludeUserID int64
+	MinPrice      float64
+	MaxPrice      float64
+	AfterID       int64
+	Limit         uint64
+}
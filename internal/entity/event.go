@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType names a domain event published through the outbox.
+type EventType string
+
+// Domain events.
+const (
+	EventAssetCreated     EventType = "AssetCreated"
+	EventAssetDeleted     EventType = "AssetDeleted"
+	EventAssetPurchased   EventType = "AssetPurchased"
+	EventAssetTransferred EventType = "AssetTransferred"
+	EventUserRegistered   EventType = "UserRegistered"
+)
+
+// Event aggregate types.
+const (
+	AggregateAsset = "asset"
+	AggregateUser  = "user"
+)
+
+// Event is a domain event stored in the transactional outbox. Events of the
+// same aggregate are delivered in ID order; ID doubles as the idempotency key.
+type Event struct {
+	ID            int64           `json:"id" db:"id"`
+	Type          EventType       `json:"type" db:"event_type"`
+	AggregateType string          `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id" db:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	// RequestID is the request that caused the event, if any.
+	RequestID string `json:"request_id,omitempty" db:"request_id"`
+	Attempts  int    `json:"-" db:"attempts"`
+}
+
+// AssetCreatedPayload is the payload of EventAssetCreated.
+type AssetCreatedPayload struct {
+	Asset *Asset `json:"asset"`
+}
+
+// AssetDeletedPayload is the payload of EventAssetDeleted.
+type AssetDeletedPayload struct {
+	AssetID int64 `json:"asset_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+// AssetPurchasedPayload is the payload of EventAssetPurchased.
+type AssetPurchasedPayload struct {
+	AssetID  int64   `json:"asset_id"`
+	SellerID int64   `json:"seller_id"`
+	BuyerID  int64   `json:"buyer_id"`
+	Price    float64 `json:"price"`
+}
+
+// AssetTransferredPayload is the payload of EventAssetTransferred, an
+// ownership change made by an operator.
+type AssetTransferredPayload struct {
+	AssetID    int64 `json:"asset_id"`
+	FromUserID int64 `json:"from_user_id"`
+	ToUserID   int64 `json:"to_user_id"`
+}
+
+// UserRegisteredPayload is the payload of EventUserRegistered.
+type UserRegisteredPayload struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
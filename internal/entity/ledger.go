@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// LedgerReport is the result of checking asset ownership against the audit log.
+type LedgerReport struct {
+	Consistent bool               `json:"consistent"`
+	Chain      *AuditVerification `json:"chain"`
+	Assets     int                `json:"assets"`
+	Entries    int64              `json:"entries"`
+	Mismatches []*LedgerMismatch  `json:"mismatches"`
+	// Untracked assets have no audit history, such as those created before
+	// the audit log existed. They do not make the ledger inconsistent.
+	Untracked []int64 `json:"untracked"`
+}
+
+// LedgerMismatch is an asset whose stored owner differs from the owner
+// derived from its audit history. Zero owner means the asset should not exist
+// or is missing.
+type LedgerMismatch struct {
+	AssetID       int64  `json:"asset_id"`
+	ExpectedOwner int64  `json:"expected_owner"`
+	ActualOwner   int64  `json:"actual_owner"`
+	Reason        string `json:"reason"`
+}
+
+// DataExport is a full dump of the users and assets.
+type DataExport struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Users      []*User   `json:"users"`
+	Assets     []*Asset  `json:"assets"`
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification types.
+const (
+	NotificationAssetSold    = "asset.sold"
+	NotificationAssetBought  = "asset.bought"
+	NotificationAdminMessage = "admin.message"
+)
+
+// NotificationTypes lists the types a user can turn off. Admin messages
+// are always delivered.
+var NotificationTypes = []string{
+	NotificationAssetSold,
+	NotificationAssetBought,
+}
+
+// IsNotificationType reports whether t is a type a user can turn off.
+func IsNotificationType(t string) bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is a message for a single user. IDs grow per user, so a
+// client can resume a stream after the last ID it has seen. EventID links
+// the notification to the outbox event that publishes it to live streams.
+type Notification struct {
+	ID        int64           `json:"id" db:"id"`
+	UserID    int64           `json:"user_id" db:"user_id"`
+	Type      string          `json:"type" db:"type"`
+	Data      json.RawMessage `json:"data" db:"data" swaggertype:"object"`
+	ReadAt    *time.Time      `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	EventID   int64           `json:"-" db:"event_id"`
+}
+
+// NotificationFilter selects inbox notifications of a user, newest first,
+// or oldest first when AfterID is set.
+type NotificationFilter struct {
+	UnreadOnly bool
+	BeforeID   int64
+	AfterID    int64
+	EventID    int64
+	Limit      uint64
+}
+
+// NotificationPreference tells whether a user receives notifications of a type.
+type NotificationPreference struct {
+	Type    string `json:"type" db:"type"`
+	Enabled bool   `json:"enabled" db:"enabled"`
+}
+
+// AssetSoldData is the data of asset.sold and asset.bought notifications.
+type AssetSoldData struct {
+	AssetID   int64   `json:"asset_id"`
+	AssetName string  `json:"asset_name"`
+	SellerID  int64   `json:"seller_id"`
+	BuyerID   int64   `json:"buyer_id"`
+	Price     float64 `json:"price"`
+}
+
+// AdminMessageData is the data of admin.message notifications.
+type AdminMessageData struct {
+	SenderID int64  `json:"sender_id"`
+	Message  string `json:"message"`
+}
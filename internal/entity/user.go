@@ -1,8 +1,24 @@
 package entity
 
+import "time"
+
+// User roles.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents a user in the system.
 type User struct {
 	ID           int64  `json:"id" db:"id"`
 	Username     string `json:"username" db:"username"`
 	PasswordHash string `json:"-" db:"password_hash"`
+	Role         string `json:"role" db:"role"`
+	// SessionsRevokedAt invalidates the tokens issued before it.
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at,omitempty" db:"sessions_revoked_at"`
+}
+
+// IsRole reports whether role is a known user role.
+func IsRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
 }
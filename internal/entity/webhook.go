@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook events users can subscribe to.
+const (
+	WebhookEventAssetSold   = "asset.sold"
+	WebhookEventAssetBought = "asset.bought"
+	WebhookEventPing        = "ping"
+)
+
+// IsWebhookEvent reports whether users can subscribe to the event.
+func IsWebhookEvent(event string) bool {
+	switch event {
+	case WebhookEventAssetSold, WebhookEventAssetBought:
+		return true
+	default:
+		return false
+	}
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is an HTTP endpoint registered by a user. Secret signs every
+// delivery and is only returned when the webhook is created.
+type Webhook struct {
+	ID             int64     `json:"id" db:"id"`
+	UserID         int64     `json:"user_id" db:"user_id"`
+	URL            string    `json:"url" db:"url"`
+	Secret         string    `json:"secret,omitempty" db:"secret"`
+	Events         []string  `json:"events" db:"-"`
+	Enabled        bool      `json:"enabled" db:"enabled"`
+	FailureCount   int       `json:"failure_count" db:"failure_count"`
+	DisabledReason string    `json:"disabled_reason,omitempty" db:"disabled_reason"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// Subscribed reports whether the webhook wants the given event.
+func (w *Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery is one event queued for a webhook together with the
+// outcome of its latest attempt. Payload holds the event data only.
+type WebhookDelivery struct {
+	ID             int64           `json:"id" db:"id"`
+	WebhookID      int64           `json:"webhook_id" db:"webhook_id"`
+	SourceEventID  int64           `json:"-" db:"source_event_id"`
+	Event          string          `json:"event" db:"event"`
+	Payload        json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseStatus int             `json:"response_status" db:"response_status"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+	// RequestID is the request that caused the delivery; it is sent as X-Request-ID.
+	RequestID string `json:"request_id,omitempty" db:"request_id"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhook endpoints. ID is the
+// delivery ID and stays the same across retries.
+type WebhookPayload struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
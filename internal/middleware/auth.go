@@ -1,41 +1,145 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/reqctx"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
+// ErrInvalidToken is returned by ParseToken for a malformed, expired or
+// wrongly signed token.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the fields of a token issued by UserUseCase.Login.
+type Claims struct {
+	UserID int64
+	Role   string
+	// IssuedAt is zero for tokens issued before it was recorded.
+	IssuedAt time.Time
+}
+
+// SessionChecker rejects tokens of revoked sessions.
+type SessionChecker interface {
+	CheckSession(ctx context.Context, userID int64, issuedAt time.Time) error
+}
+
+// ParseToken validates a JWT issued by UserUseCase.Login and returns its claims.
+func ParseToken(secretKey, tokenString string) (*Claims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	id, ok := mapClaims["user_id"].(float64)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &Claims{UserID: int64(id), Role: entity.RoleUser}
+
+	if role, _ := mapClaims["role"].(string); role != "" {
+		claims.Role = role
+	}
+
+	if iat, ok := mapClaims["iat"].(float64); ok {
+		claims.IssuedAt = time.Unix(int64(iat), 0)
+	}
+
+	return claims, nil
+}
+
 func JWTAuth(secretKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
+			c.JSON(http.StatusUnauthorized, errorBody(c, "Authorization header missing"))
+			c.Abort()
+			return
+		}
+
+		claims, err := ParseToken(secretKey, strings.Replace(authHeader, "Bearer ", "", 1))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, errorBody(c, "Invalid or expired token"))
 			c.Abort()
 			return
 		}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secretKey), nil
-		})
+		c.Set("userID", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Request = c.Request.WithContext(reqctx.WithUser(c.Request.Context(), claims.UserID, claims.Role))
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+// RejectRevokedSessions rejects requests carrying a valid token whose
+// session was revoked. Requests without a valid token are left to JWTAuth.
+func RejectRevokedSessions(secretKey string, sessions SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.Replace(c.GetHeader("Authorization"), "Bearer ", "", 1)
+		if token == "" {
+			token = c.Query("access_token")
+		}
+
+		claims, err := ParseToken(secretKey, token)
+		if token == "" || err != nil {
+			c.Next()
+			return
+		}
+
+		err = sessions.CheckSession(c.Request.Context(), claims.UserID, claims.IssuedAt)
+		if err != nil {
+			if errors.Is(err, usecase.ErrSessionRevoked) {
+				c.JSON(http.StatusUnauthorized, errorBody(c, "Session revoked"))
+			} else {
+				_ = c.Error(err)
+				c.JSON(http.StatusInternalServerError, errorBody(c, "Could not verify session"))
+			}
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		c.Next()
+	}
+}
+
+// TokenFromQuery lets clients that cannot set headers, such as browser
+// EventSource and WebSocket, pass the JWT as the access_token query parameter.
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("access_token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		c.Next()
+	}
+}
+
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			c.JSON(http.StatusForbidden, errorBody(c, "Insufficient permissions"))
 			c.Abort()
 			return
 		}
 
-		c.Set("userID", int64(claims["user_id"].(float64)))
 		c.Next()
 	}
 }
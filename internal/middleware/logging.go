@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/appxpy/hive-test/pkg/logger"
+)
+
+// AccessLog writes an entry for every request to l: server errors at error
+// level, client errors at warn level and the rest at info level. Secrets
+// in the query string, such as access_token, are masked by the logger.
+func AccessLog(l logger.Interface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+
+		size := c.Writer.Size()
+		if size < 0 { // nothing written
+			size = 0
+		}
+
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"bytes", size,
+			"ip", c.ClientIP(),
+			"user_agent", c.Request.UserAgent(),
+		}
+
+		if query := c.Request.URL.Query(); len(query) > 0 {
+			fields = append(fields, "query", query)
+		}
+
+		if len(c.Errors) > 0 {
+			fields = append(fields, "errors", c.Errors.String())
+		}
+
+		// The handlers down the chain add the user to the request context.
+		log := l.WithContext(c.Request.Context())
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Error("http request", fields...)
+		case status >= http.StatusBadRequest:
+			log.Warn("http request", fields...)
+		default:
+			log.Info("http request", fields...)
+		}
+	}
+}
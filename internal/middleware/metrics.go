@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// _unmatchedRoute labels requests that matched no route, so scans of
+// random paths do not blow up the label cardinality.
+const _unmatchedRoute = "unmatched"
+
+// Metrics records the rate, errors and duration of the requests on reg,
+// labelled by method, route template and status code.
+func Metrics(reg prometheus.Registerer) gin.HandlerFunc {
+	labels := []string{"method", "route", "status"}
+
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "http",
+		Name:      "requests_total",
+		Help:      "Handled HTTP requests.",
+	}, labels)
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, labels)
+	inFlight := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests being handled.",
+	})
+
+	reg.MustRegister(requests, duration, inFlight)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = _unmatchedRoute
+		}
+
+		lvs := []string{c.Request.Method, route, strconv.Itoa(c.Writer.Status())}
+
+		requests.WithLabelValues(lvs...).Inc()
+		duration.WithLabelValues(lvs...).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/appxpy/hive-test/pkg/reqctx"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMeta stores the client IP, user agent and request ID of the
+// request in its context, so use cases can record them. The X-Request-ID
+// of the client is kept when valid, otherwise a new one is generated; it
+// is echoed in the response either way.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(reqctx.RequestIDHeader)
+		if !reqctx.ValidRequestID(requestID) {
+			requestID = reqctx.NewRequestID()
+		}
+
+		c.Header(reqctx.RequestIDHeader, requestID)
+
+		ctx := reqctx.WithMeta(c.Request.Context(), reqctx.Meta{
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// errorBody is the JSON body of an error response. It quotes the request
+// ID, so a user can hand it to support.
+func errorBody(c *gin.Context, msg string) gin.H {
+	return gin.H{"error": msg, "request_id": reqctx.FromContext(c.Request.Context()).RequestID}
+}
@@ -0,0 +1,168 @@
+// Package notify fans user notifications out to live connections, within
+// one process or across replicas through Postgres LISTEN/NOTIFY.
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+const (
+	_defaultHistorySize   = 100
+	_defaultBufferSize    = 64
+	_defaultHistoryMaxAge = 15 * time.Minute
+)
+
+// Hub is an in-process NotificationHub. It keeps a short per-user history to
+// drop redelivered notifications, and disconnects subscribers whose buffer is
+// full instead of blocking the publisher. Reconnecting clients resume from
+// the inbox, not from the hub.
+type Hub struct {
+	mu      sync.Mutex
+	subs    map[int64]map[*subscriber]struct{}
+	history map[int64][]*entity.Notification
+
+	historySize   int
+	bufferSize    int
+	historyMaxAge time.Duration
+}
+
+type subscriber struct {
+	ch     chan *entity.Notification
+	closed bool
+}
+
+var _ usecase.NotificationHub = (*Hub)(nil)
+
+// NewHub -.
+func NewHub(opts ...Option) *Hub {
+	h := &Hub{
+		subs:          make(map[int64]map[*subscriber]struct{}),
+		history:       make(map[int64][]*entity.Notification),
+		historySize:   _defaultHistorySize,
+		bufferSize:    _defaultBufferSize,
+		historyMaxAge: _defaultHistoryMaxAge,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Publish delivers the notification to the local subscribers of its user.
+func (h *Hub) Publish(_ context.Context, n *entity.Notification) error {
+	h.Deliver(n)
+
+	return nil
+}
+
+// Deliver records n in the user's history and sends it to every subscriber.
+func (h *Hub) Deliver(n *entity.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist := h.history[n.UserID]
+	for _, old := range hist {
+		// Redelivered notification, already seen by every subscriber.
+		if old.ID == n.ID && old.Type == n.Type {
+			return
+		}
+	}
+
+	hist = append(hist, n)
+	if len(hist) > h.historySize {
+		hist = hist[len(hist)-h.historySize:]
+	}
+
+	h.history[n.UserID] = hist
+
+	for s := range h.subs[n.UserID] {
+		h.send(n.UserID, s, n)
+	}
+}
+
+// Subscribe -.
+func (h *Hub) Subscribe(ctx context.Context, userID int64) (<-chan *entity.Notification, error) {
+	s := &subscriber{ch: make(chan *entity.Notification, h.bufferSize)}
+
+	h.mu.Lock()
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*subscriber]struct{})
+	}
+
+	h.subs[userID][s] = struct{}{}
+
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		h.mu.Lock()
+		h.drop(userID, s)
+		h.mu.Unlock()
+	}()
+
+	return s.ch, nil
+}
+
+// Sweep forgets the history of users who got nothing for historyMaxAge.
+func (h *Hub) Sweep() {
+	cutoff := time.Now().Add(-h.historyMaxAge)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for userID, hist := range h.history {
+		if hist[len(hist)-1].CreatedAt.Before(cutoff) {
+			delete(h.history, userID)
+		}
+	}
+}
+
+// Run sweeps the history periodically until ctx is done.
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.historyMaxAge / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Sweep()
+		}
+	}
+}
+
+// send must be called with mu held. It drops a subscriber whose buffer is full.
+func (h *Hub) send(userID int64, s *subscriber, n *entity.Notification) {
+	select {
+	case s.ch <- n:
+	default:
+		h.drop(userID, s)
+	}
+}
+
+// drop must be called with mu held.
+func (h *Hub) drop(userID int64, s *subscriber) {
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.ch)
+
+	delete(h.subs[userID], s)
+
+	if len(h.subs[userID]) == 0 {
+		delete(h.subs, userID)
+	}
+}
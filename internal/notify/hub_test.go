@@ -0,0 +1,129 @@
+package notify_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/notify"
+)
+
+func notification(id, userID int64) *entity.Notification {
+	return &entity.Notification{ID: id, UserID: userID, Type: entity.NotificationAssetSold, CreatedAt: time.Now()}
+}
+
+func receive(t *testing.T, ch <-chan *entity.Notification) *entity.Notification {
+	t.Helper()
+
+	select {
+	case n := <-ch:
+		return n
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+
+		return nil
+	}
+}
+
+func TestHub_DeliversToSubscribersOfUser(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := notify.NewHub()
+
+	mine, err := hub.Subscribe(ctx, 1)
+	require.NoError(t, err)
+	other, err := hub.Subscribe(ctx, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, hub.Publish(ctx, notification(10, 1)))
+
+	assert.Equal(t, int64(10), receive(t, mine).ID)
+	assert.Empty(t, other)
+}
+
+func TestHub_IgnoresRedeliveredNotification(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := notify.NewHub()
+
+	ch, err := hub.Subscribe(ctx, 1)
+	require.NoError(t, err)
+
+	hub.Deliver(notification(1, 1))
+	hub.Deliver(notification(1, 1))
+
+	receive(t, ch)
+	assert.Empty(t, ch)
+}
+
+func TestHub_DisconnectsSlowConsumer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := notify.NewHub(notify.BufferSize(2))
+
+	slow, err := hub.Subscribe(ctx, 1)
+	require.NoError(t, err)
+
+	for i := int64(1); i <= 3; i++ {
+		hub.Deliver(notification(i, 1))
+	}
+
+	assert.Equal(t, int64(1), receive(t, slow).ID)
+	assert.Equal(t, int64(2), receive(t, slow).ID)
+
+	_, ok := <-slow
+	assert.False(t, ok, "slow subscriber must be closed")
+}
+
+func TestHub_ClosesSubscriptionOnContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hub := notify.NewHub()
+
+	ch, err := hub.Subscribe(ctx, 1)
+	require.NoError(t, err)
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
+func TestHub_SweepForgetsIdleHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := notify.NewHub(notify.HistoryMaxAge(time.Minute))
+
+	old := notification(1, 1)
+	old.CreatedAt = time.Now().Add(-time.Hour)
+	hub.Deliver(old)
+	hub.Sweep()
+
+	ch, err := hub.Subscribe(ctx, 1)
+	require.NoError(t, err)
+
+	// The forgotten notification is no longer taken for a redelivery.
+	hub.Deliver(old)
+	assert.Equal(t, int64(1), receive(t, ch).ID)
+}
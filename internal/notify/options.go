@@ -0,0 +1,28 @@
+package notify
+
+import "time"
+
+// Option -.
+type Option func(*Hub)
+
+// HistorySize sets how many recent notifications per user are kept for resume.
+func HistorySize(size int) Option {
+	return func(h *Hub) {
+		h.historySize = size
+	}
+}
+
+// BufferSize sets how many undelivered notifications a subscriber may lag
+// behind before it is disconnected.
+func BufferSize(size int) Option {
+	return func(h *Hub) {
+		h.bufferSize = size
+	}
+}
+
+// HistoryMaxAge -.
+func HistoryMaxAge(age time.Duration) Option {
+	return func(h *Hub) {
+		h.historyMaxAge = age
+	}
+}
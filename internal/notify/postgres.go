@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+const _pgChannel = "hive_notifications"
+
+// Execer runs a statement on the primary database.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// PGHub is a NotificationHub shared by all replicas. Publish sends the
+// notification with pg_notify; every replica, this one included, receives
+// it in Listen and delivers it to its local Hub.
+type PGHub struct {
+	*Hub
+
+	db  Execer
+	url string
+	l   logger.Interface
+}
+
+var _ usecase.NotificationHub = (*PGHub)(nil)
+
+// NewPGHub -.
+func NewPGHub(hub *Hub, db Execer, url string, l logger.Interface) *PGHub {
+	return &PGHub{
+		Hub: hub,
+		db:  db,
+		url: url,
+		l:   l,
+	}
+}
+
+// Publish -.
+func (h *PGHub) Publish(ctx context.Context, n *entity.Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("notify - PGHub - json.Marshal: %w", err)
+	}
+
+	_, err = h.db.Exec(ctx, `SELECT pg_notify($1, $2)`, _pgChannel, string(payload))
+	if err != nil {
+		return fmt.Errorf("notify - PGHub - pg_notify: %w", err)
+	}
+
+	return nil
+}
+
+// Run listens for notifications until ctx is done, reconnecting on errors.
+// Notifications sent while disconnected are lost to live streams.
+func (h *PGHub) Run(ctx context.Context) {
+	go h.Hub.Run(ctx)
+
+	listener := postgres.NewListener(h.url, _pgChannel,
+		postgres.OnError(func(err error) {
+			h.l.Error(fmt.Errorf("notify - PGHub - listen: %w", err))
+		}),
+	)
+
+	listener.Run(ctx, h.deliver)
+}
+
+func (h *PGHub) deliver(payload string) {
+	n := &entity.Notification{}
+	if err := json.Unmarshal([]byte(payload), n); err != nil {
+		h.l.Error(fmt.Errorf("notify - PGHub - json.Unmarshal: %w", err))
+
+		return
+	}
+
+	h.Deliver(n)
+}
@@ -0,0 +1,32 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/health"
+)
+
+// ErrLagging is returned by the lag check while events wait too long.
+var ErrLagging = errors.New("outbox is lagging")
+
+// LagCheck returns a health check that fails while the oldest unpublished
+// event is older than maxLag, as when the relay is stuck or the publisher
+// keeps failing.
+func LagCheck(repo usecase.OutboxRepo, maxLag time.Duration) health.Check {
+	return func(ctx context.Context) error {
+		oldest, err := repo.OldestPending(ctx)
+		if err != nil {
+			return fmt.Errorf("outbox - LagCheck - repo.OldestPending: %w", err)
+		}
+
+		if lag := time.Since(oldest); !oldest.IsZero() && lag > maxLag {
+			return fmt.Errorf("%w: oldest pending event is %s old", ErrLagging, lag.Round(time.Second))
+		}
+
+		return nil
+	}
+}
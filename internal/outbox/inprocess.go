@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// Handler consumes a published event.
+type Handler func(ctx context.Context, event *entity.Event) error
+
+// InProcess delivers events to handlers registered in the same process.
+type InProcess struct {
+	mu       sync.RWMutex
+	handlers map[entity.EventType][]Handler
+	all      []Handler
+}
+
+var _ usecase.EventPublisher = (*InProcess)(nil)
+
+// NewInProcess -.
+func NewInProcess() *InProcess {
+	return &InProcess{
+		handlers: make(map[entity.EventType][]Handler),
+	}
+}
+
+// Subscribe registers h for events of the given type.
+func (p *InProcess) Subscribe(eventType entity.EventType, h Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handlers[eventType] = append(p.handlers[eventType], h)
+}
+
+// SubscribeAll registers h for every event.
+func (p *InProcess) SubscribeAll(h Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.all = append(p.all, h)
+}
+
+// Publish calls the handlers synchronously and returns the first error.
+// Handlers that already succeeded will see the event again on retry.
+func (p *InProcess) Publish(ctx context.Context, event *entity.Event) error {
+	p.mu.RLock()
+	handlers := append(append([]Handler(nil), p.all...), p.handlers[event.Type]...)
+	p.mu.RUnlock()
+
+	for _, h := range handlers {
+		if err := h(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// Multi publishes every event to all publishers in order and fails on the
+// first error; publishers that already succeeded see the event again on retry.
+type Multi []usecase.EventPublisher
+
+var _ usecase.EventPublisher = Multi(nil)
+
+// Publish -.
+func (m Multi) Publish(ctx context.Context, event *entity.Event) error {
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+// JetStream is the subset of nats.JetStreamContext used by NATS.
+type JetStream interface {
+	PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error)
+}
+
+// NATS publishes events to JetStream subjects "<prefix>.<EventType>".
+// The event ID is sent as Nats-Msg-Id, so the stream drops redelivered
+// duplicates within its deduplication window.
+type NATS struct {
+	js     JetStream
+	prefix string
+}
+
+var _ usecase.EventPublisher = (*NATS)(nil)
+
+// NewNATS -.
+func NewNATS(js JetStream, subjectPrefix string) *NATS {
+	return &NATS{
+		js:     js,
+		prefix: subjectPrefix,
+	}
+}
+
+// ConnectNATS connects to the NATS server at url and returns a JetStream
+// publisher together with a function that closes the connection.
+func ConnectNATS(url, subjectPrefix string) (*NATS, func(), error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("outbox - ConnectNATS - nats.Connect: %w", err)
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+
+		return nil, nil, fmt.Errorf("outbox - ConnectNATS - nc.JetStream: %w", err)
+	}
+
+	return NewNATS(js, subjectPrefix), func() { _ = nc.Drain() }, nil
+}
+
+// Publish waits for the JetStream acknowledgement.
+func (p *NATS) Publish(ctx context.Context, event *entity.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("outbox - NATS - json.Marshal: %w", err)
+	}
+
+	msg := nats.NewMsg(p.prefix + "." + string(event.Type))
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, strconv.FormatInt(event.ID, 10))
+
+	if event.RequestID != "" {
+		msg.Header.Set(reqctx.RequestIDHeader, event.RequestID)
+	}
+
+	_, err = p.js.PublishMsg(msg, nats.Context(ctx))
+	if err != nil {
+		return fmt.Errorf("outbox - NATS - PublishMsg: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import "time"
+
+// Option -.
+type Option func(*Relay)
+
+// PollInterval -.
+func PollInterval(interval time.Duration) Option {
+	return func(r *Relay) {
+		r.pollInterval = interval
+	}
+}
+
+// BatchSize -.
+func BatchSize(size uint64) Option {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// MaxAttempts sets how many deliveries are tried before an event is moved
+// to the dead-letter table.
+func MaxAttempts(attempts int) Option {
+	return func(r *Relay) {
+		r.maxAttempts = attempts
+	}
+}
+
+// Lease sets how long claimed events are hidden from other relays. It must
+// cover publishing a whole batch, or events are published twice.
+func Lease(lease time.Duration) Option {
+	return func(r *Relay) {
+		r.lease = lease
+	}
+}
+
+// Backoff sets the first retry delay and the cap of the exponential backoff.
+func Backoff(base, maxDelay time.Duration) Option {
+	return func(r *Relay) {
+		r.baseBackoff = base
+		r.maxBackoff = maxDelay
+	}
+}
@@ -0,0 +1,157 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/outbox"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+func someEvent() *entity.Event {
+	return &entity.Event{
+		ID:            42,
+		Type:          entity.EventAssetPurchased,
+		AggregateType: entity.AggregateAsset,
+		AggregateID:   7,
+		Payload:       json.RawMessage(`{"asset_id":7,"seller_id":1,"buyer_id":2,"price":10}`),
+		RequestID:     "req-1",
+	}
+}
+
+func TestInProcess_DeliversToTypeAndAllSubscribers(t *testing.T) {
+	t.Parallel()
+
+	p := outbox.NewInProcess()
+
+	var got []string
+
+	p.SubscribeAll(func(_ context.Context, e *entity.Event) error {
+		got = append(got, "all")
+
+		return nil
+	})
+	p.Subscribe(entity.EventAssetPurchased, func(_ context.Context, e *entity.Event) error {
+		got = append(got, "purchased")
+
+		return nil
+	})
+	p.Subscribe(entity.EventAssetCreated, func(_ context.Context, e *entity.Event) error {
+		got = append(got, "created")
+
+		return nil
+	})
+
+	require.NoError(t, p.Publish(context.Background(), someEvent()))
+	assert.Equal(t, []string{"all", "purchased"}, got)
+}
+
+func TestInProcess_ReturnsHandlerError(t *testing.T) {
+	t.Parallel()
+
+	p := outbox.NewInProcess()
+	p.SubscribeAll(func(context.Context, *entity.Event) error { return assert.AnError })
+
+	assert.ErrorIs(t, p.Publish(context.Background(), someEvent()), assert.AnError)
+}
+
+func TestWebhook_PostsEvent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		header http.Header
+		body   []byte
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	err := outbox.NewWebhook(srv.URL).Publish(context.Background(), someEvent())
+
+	require.NoError(t, err)
+	assert.Equal(t, "42", header.Get("X-Event-ID"))
+	assert.Equal(t, "AssetPurchased", header.Get("X-Event-Type"))
+	assert.Equal(t, "req-1", header.Get(reqctx.RequestIDHeader))
+
+	var e entity.Event
+	require.NoError(t, json.Unmarshal(body, &e))
+	assert.Equal(t, int64(7), e.AggregateID)
+}
+
+func TestWebhook_FailsOnNon2xx(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := outbox.NewWebhook(srv.URL).Publish(context.Background(), someEvent())
+
+	assert.ErrorIs(t, err, outbox.ErrUnexpectedStatus)
+}
+
+type fakeJetStream struct {
+	msgs []*nats.Msg
+	err  error
+}
+
+func (js *fakeJetStream) PublishMsg(m *nats.Msg, _ ...nats.PubOpt) (*nats.PubAck, error) {
+	if js.err != nil {
+		return nil, js.err
+	}
+
+	js.msgs = append(js.msgs, m)
+
+	return &nats.PubAck{}, nil
+}
+
+func TestNATS_PublishesWithMsgID(t *testing.T) {
+	t.Parallel()
+
+	js := &fakeJetStream{}
+
+	err := outbox.NewNATS(js, "hive.events").Publish(context.Background(), someEvent())
+
+	require.NoError(t, err)
+	require.Len(t, js.msgs, 1)
+	assert.Equal(t, "hive.events.AssetPurchased", js.msgs[0].Subject)
+	assert.Equal(t, "42", js.msgs[0].Header.Get(nats.MsgIdHdr))
+	assert.Equal(t, "req-1", js.msgs[0].Header.Get(reqctx.RequestIDHeader))
+}
+
+func TestNATS_ReturnsPublishError(t *testing.T) {
+	t.Parallel()
+
+	js := &fakeJetStream{err: assert.AnError}
+
+	err := outbox.NewNATS(js, "hive.events").Publish(context.Background(), someEvent())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestMulti_StopsOnFirstError(t *testing.T) {
+	t.Parallel()
+
+	js := &fakeJetStream{}
+	failing := outbox.NewInProcess()
+	failing.SubscribeAll(func(context.Context, *entity.Event) error { return assert.AnError })
+
+	err := outbox.Multi{failing, outbox.NewNATS(js, "x")}.Publish(context.Background(), someEvent())
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Empty(t, js.msgs)
+}
@@ -0,0 +1,146 @@
+// Package outbox relays domain events from the transactional outbox to an EventPublisher.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const (
+	_defaultPollInterval = time.Second
+	_defaultBatchSize    = 100
+	_defaultMaxAttempts  = 10
+	_defaultBaseBackoff  = time.Second
+	_defaultMaxBackoff   = 10 * time.Minute
+	_defaultLease        = 2 * time.Minute
+)
+
+// Relay polls the outbox and publishes pending events. Delivery is
+// at-least-once: an event is removed only after the publisher accepted it,
+// and an event whose lease expires before that is published again, so
+// consumers should deduplicate by event ID.
+type Relay struct {
+	repo      usecase.OutboxRepo
+	publisher usecase.EventPublisher
+	l         logger.Interface
+
+	pollInterval time.Duration
+	batchSize    uint64
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+}
+
+// NewRelay -.
+func NewRelay(repo usecase.OutboxRepo, publisher usecase.EventPublisher, l logger.Interface, opts ...Option) *Relay {
+	r := &Relay{
+		repo:         repo,
+		publisher:    publisher,
+		l:            l,
+		pollInterval: _defaultPollInterval,
+		batchSize:    _defaultBatchSize,
+		maxAttempts:  _defaultMaxAttempts,
+		baseBackoff:  _defaultBaseBackoff,
+		maxBackoff:   _defaultMaxBackoff,
+		lease:        _defaultLease,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.ProcessBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.l.Error(fmt.Errorf("outbox - Relay - ProcessBatch: %w", err))
+		}
+
+		// A full batch means more events are likely waiting.
+		if err == nil && uint64(n) == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch publishes one batch of pending events and returns how many
+// events were handled, whether delivered, rescheduled or dead-lettered.
+// The events are claimed with a lease and published outside of a
+// transaction; each is then marked on its own, so that a failure to mark
+// one does not undo the others.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	events, err := r.repo.ClaimPending(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		handled int
+		errs    []error
+	)
+
+	for _, e := range events {
+		// Subscribers act on behalf of the request that caused the event.
+		pubErr := r.publisher.Publish(reqctx.WithRequestID(ctx, e.RequestID), e)
+
+		switch {
+		case pubErr == nil:
+			err = r.repo.MarkPublished(ctx, e.ID)
+		case ctx.Err() != nil:
+			// Stopping: the events left are published once their lease expires.
+			return handled, errors.Join(append(errs, ctx.Err())...)
+		case e.Attempts+1 >= r.maxAttempts:
+			r.l.Error(fmt.Errorf("outbox - Relay - event %d dead-lettered: %w", e.ID, pubErr),
+				"request_id", e.RequestID)
+			err = r.repo.MoveToDeadLetter(ctx, e, pubErr.Error())
+		default:
+			err = r.repo.MarkFailed(ctx, e.ID, pubErr.Error(), time.Now().Add(r.backoff(e.Attempts)))
+		}
+
+		if err != nil {
+			// The event is handled again once its lease expires.
+			errs = append(errs, fmt.Errorf("event %d: %w", e.ID, err))
+			continue
+		}
+
+		handled++
+	}
+
+	return handled, errors.Join(errs...)
+}
+
+// backoff returns the delay before the next attempt after the given number
+// of failed attempts, doubling from baseBackoff up to maxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.baseBackoff
+	for i := 0; i < attempts && d < r.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > r.maxBackoff {
+		return r.maxBackoff
+	}
+
+	return d
+}
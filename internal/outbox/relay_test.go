@@ -0,0 +1,253 @@
+package outbox_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/outbox"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutboxRepo keeps events in memory and mimics the head-of-aggregate
+// selection and the leases of the Postgres implementation.
+type fakeOutboxRepo struct {
+	mu         sync.Mutex
+	events     []*entity.Event
+	nextRetry  map[int64]time.Time
+	deadLetter []*entity.Event
+	leases     []time.Duration
+	// markErr fails MarkPublished of the event with that ID.
+	markErr int64
+}
+
+func newFakeOutboxRepo(events ...*entity.Event) *fakeOutboxRepo {
+	return &fakeOutboxRepo{events: events, nextRetry: make(map[int64]time.Time)}
+}
+
+func (r *fakeOutboxRepo) AddEvent(_ context.Context, event *entity.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+
+	return nil
+}
+
+func (r *fakeOutboxRepo) ClaimPending(_ context.Context, limit uint64, lease time.Duration) ([]*entity.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.leases = append(r.leases, lease)
+
+	seen := make(map[int64]bool)
+
+	var res []*entity.Event
+
+	for _, e := range r.events {
+		if seen[e.AggregateID] {
+			continue
+		}
+
+		seen[e.AggregateID] = true
+
+		if r.nextRetry[e.ID].After(time.Now()) || uint64(len(res)) == limit {
+			continue
+		}
+
+		r.nextRetry[e.ID] = time.Now().Add(lease)
+		res = append(res, e)
+	}
+
+	return res, nil
+}
+
+func (r *fakeOutboxRepo) MarkPublished(_ context.Context, eventID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if eventID == r.markErr {
+		return assert.AnError
+	}
+
+	for i, e := range r.events {
+		if e.ID == eventID {
+			r.events = append(r.events[:i], r.events[i+1:]...)
+
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(_ context.Context, eventID int64, _ string, nextAttemptAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.events {
+		if e.ID == eventID {
+			e.Attempts++
+			r.nextRetry[eventID] = nextAttemptAt
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeOutboxRepo) MoveToDeadLetter(ctx context.Context, event *entity.Event, _ string) error {
+	r.mu.Lock()
+	r.deadLetter = append(r.deadLetter, event)
+	r.mu.Unlock()
+
+	return r.MarkPublished(ctx, event.ID)
+}
+
+func (r *fakeOutboxRepo) OldestPending(context.Context) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.events) == 0 {
+		return time.Time{}, nil
+	}
+
+	return r.events[0].CreatedAt, nil
+}
+
+func (r *fakeOutboxRepo) ExecuteTx(_ context.Context, fn func(repo usecase.OutboxRepo) error) error {
+	return fn(r)
+}
+
+// recordingPublisher records published event IDs and fails while failures > 0.
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published []int64
+	failures  int
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event *entity.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+
+		return assert.AnError
+	}
+
+	p.published = append(p.published, event.ID)
+
+	return nil
+}
+
+func TestRelay_PublishesInOrderPerAggregate(t *testing.T) {
+	t.Parallel()
+
+	repo := newFakeOutboxRepo(
+		&entity.Event{ID: 1, AggregateID: 10},
+		&entity.Event{ID: 2, AggregateID: 20},
+		&entity.Event{ID: 3, AggregateID: 10},
+	)
+	pub := &recordingPublisher{}
+	relay := outbox.NewRelay(repo, pub, logger.New("error"))
+
+	n, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{1, 2}, pub.published)
+
+	n, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{1, 2, 3}, pub.published)
+	assert.Empty(t, repo.events)
+}
+
+func TestRelay_RetriesWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	repo := newFakeOutboxRepo(
+		&entity.Event{ID: 1, AggregateID: 10},
+		&entity.Event{ID: 2, AggregateID: 10},
+	)
+	pub := &recordingPublisher{failures: 1}
+	relay := outbox.NewRelay(repo, pub, logger.New("error"), outbox.Backoff(time.Hour, time.Hour))
+
+	_, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	// The failed head event is delayed and keeps the next one of its aggregate waiting.
+	n, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Empty(t, pub.published)
+	assert.Equal(t, 1, repo.events[0].Attempts)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), repo.nextRetry[1], time.Minute)
+}
+
+func TestRelay_MovesToDeadLetterAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	repo := newFakeOutboxRepo(
+		&entity.Event{ID: 1, AggregateID: 10, Attempts: 2},
+		&entity.Event{ID: 2, AggregateID: 10},
+	)
+	pub := &recordingPublisher{failures: 1}
+	relay := outbox.NewRelay(repo, pub, logger.New("error"), outbox.MaxAttempts(3))
+
+	_, err := relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, repo.deadLetter, 1)
+	assert.Equal(t, int64(1), repo.deadLetter[0].ID)
+
+	_, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, pub.published)
+}
+
+func TestRelay_MarkFailureOnlyAffectsItsEvent(t *testing.T) {
+	t.Parallel()
+
+	repo := newFakeOutboxRepo(
+		&entity.Event{ID: 1, AggregateID: 10},
+		&entity.Event{ID: 2, AggregateID: 20},
+		&entity.Event{ID: 3, AggregateID: 30},
+		&entity.Event{ID: 4, AggregateID: 20},
+	)
+	repo.markErr = 2
+	pub := &recordingPublisher{}
+	relay := outbox.NewRelay(repo, pub, logger.New("error"), outbox.Lease(time.Hour))
+
+	n, err := relay.ProcessBatch(context.Background())
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int64{1, 2, 3}, pub.published)
+	assert.Equal(t, []time.Duration{time.Hour}, repo.leases)
+
+	// The events marked published stay so; the leased one holds back its aggregate.
+	require.Len(t, repo.events, 2)
+	assert.Equal(t, int64(2), repo.events[0].ID)
+
+	n, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestLagCheck_FailsWhenOldestEventIsTooOld(t *testing.T) {
+	t.Parallel()
+
+	check := outbox.LagCheck(newFakeOutboxRepo(), time.Minute)
+	assert.NoError(t, check(context.Background()))
+
+	check = outbox.LagCheck(newFakeOutboxRepo(&entity.Event{ID: 1, CreatedAt: time.Now()}), time.Minute)
+	assert.NoError(t, check(context.Background()))
+
+	check = outbox.LagCheck(newFakeOutboxRepo(&entity.Event{ID: 1, CreatedAt: time.Now().Add(-time.Hour)}), time.Minute)
+	assert.ErrorIs(t, check(context.Background()), outbox.ErrLagging)
+}
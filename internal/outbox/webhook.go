@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const _defaultWebhookTimeout = 10 * time.Second
+
+// ErrUnexpectedStatus is returned when the receiver answers with a non-2xx status.
+var ErrUnexpectedStatus = errors.New("unexpected status")
+
+// Webhook POSTs every event as JSON to a single URL. Any non-2xx response
+// is treated as a failed delivery.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+var _ usecase.EventPublisher = (*Webhook)(nil)
+
+// NewWebhook -.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: _defaultWebhookTimeout},
+	}
+}
+
+// Publish -.
+func (w *Webhook) Publish(ctx context.Context, event *entity.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("outbox - Webhook - json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("outbox - Webhook - http.NewRequest: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	if event.RequestID != "" {
+		req.Header.Set(reqctx.RequestIDHeader, event.RequestID)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("outbox - Webhook - client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("outbox - Webhook: %w %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return nil
+}
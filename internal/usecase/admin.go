@@ -0,0 +1,410 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/appxpy/hive-test/internal/entity"
+)
+
+const _adminPageSize = 500
+
+var (
+	// ErrInvalidAdminInput is returned when an operator action is not acceptable.
+	ErrInvalidAdminInput = errors.New("invalid input")
+
+	// errDryRun rolls back the transaction of an action run in dry-run mode.
+	errDryRun = errors.New("dry run")
+)
+
+// AdminUseCaseImpl implements the AdminUseCase interface. Operator actions
+// are audited with actor ID 0.
+type AdminUseCaseImpl struct {
+	users  UserRepo
+	assets AssetRepo
+	uow    UnitOfWork
+	audit  AuditUseCase
+	dryRun bool
+}
+
+// NewAdminUseCase creates a new AdminUseCase. Changes run in units of work
+// of uow, like those of the service; reads go straight to the repos. In
+// dry-run mode every change runs in a transaction that is rolled back, so it
+// is checked against the database but not applied.
+func NewAdminUseCase(users UserRepo, assets AssetRepo, uow UnitOfWork, audit AuditUseCase, dryRun bool) AdminUseCase {
+	return &AdminUseCaseImpl{
+		users:  users,
+		assets: assets,
+		uow:    uow,
+		audit:  audit,
+		dryRun: dryRun,
+	}
+}
+
+// CreateUser creates a user with the given role.
+func (uc *AdminUseCaseImpl) CreateUser(ctx context.Context, username, password, role string) (*entity.User, error) {
+	switch {
+	case username == "":
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidAdminInput)
+	case password == "":
+		return nil, fmt.Errorf("%w: password is required", ErrInvalidAdminInput)
+	case !entity.IsRole(role):
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidAdminInput, role)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &entity.User{
+		Username:     username,
+		PasswordHash: string(hashedPassword),
+		Role:         role,
+	}
+
+	err = uc.uow.Do(ctx, func(ctx context.Context, repos Repos) error {
+		err := repos.Users().CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		err = appendAudit(ctx, repos.Audit(), 0, entity.AuditActionUserRegister,
+			entity.AuditEntityUser, user.ID, nil, user)
+		if err != nil {
+			return err
+		}
+
+		err = appendEvent(ctx, repos.Outbox(), entity.EventUserRegistered, entity.AggregateUser, user.ID,
+			entity.UserRegisteredPayload{UserID: user.ID, Username: user.Username})
+		if err != nil {
+			return err
+		}
+
+		return uc.commit()
+	})
+
+	return user, uc.result(err)
+}
+
+// ResetPassword sets a new password and revokes the user's sessions.
+func (uc *AdminUseCaseImpl) ResetPassword(ctx context.Context, username, password string) error {
+	if password == "" {
+		return fmt.Errorf("%w: password is required", ErrInvalidAdminInput)
+	}
+
+	user, err := uc.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	err = uc.uow.Do(ctx, func(ctx context.Context, repos Repos) error {
+		err := repos.Users().UpdatePasswordHash(ctx, user.ID, string(hashedPassword))
+		if err != nil {
+			return err
+		}
+
+		err = repos.Users().RevokeSessions(ctx, user.ID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		err = appendAudit(ctx, repos.Audit(), 0, entity.AuditActionAdmin,
+			entity.AuditEntityUser, user.ID, nil, map[string]string{"operation": "password_reset"})
+		if err != nil {
+			return err
+		}
+
+		return uc.commit()
+	})
+
+	return uc.result(err)
+}
+
+// RevokeSessions invalidates every token issued to the user so far.
+func (uc *AdminUseCaseImpl) RevokeSessions(ctx context.Context, username string) error {
+	user, err := uc.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	err = uc.uow.Do(ctx, func(ctx context.Context, repos Repos) error {
+		err := repos.Users().RevokeSessions(ctx, user.ID, time.Now())
+		if err != nil {
+			return err
+		}
+
+		err = appendAudit(ctx, repos.Audit(), 0, entity.AuditActionAdmin,
+			entity.AuditEntityUser, user.ID, nil, map[string]string{"operation": "revoke_sessions"})
+		if err != nil {
+			return err
+		}
+
+		return uc.commit()
+	})
+
+	return uc.result(err)
+}
+
+// ListUsers returns a page of users ordered by ID.
+func (uc *AdminUseCaseImpl) ListUsers(ctx context.Context, afterID int64, limit uint64) ([]*entity.User, error) {
+	return uc.users.ListUsers(ctx, afterID, limit)
+}
+
+// ListAssets returns a page of assets ordered by ID.
+func (uc *AdminUseCaseImpl) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	return uc.assets.ListAssets(ctx, filter)
+}
+
+// TransferAsset moves an asset to another user and returns it as it was
+// before the transfer.
+func (uc *AdminUseCaseImpl) TransferAsset(ctx context.Context, assetID int64, toUsername string) (*entity.Asset, error) {
+	to, err := uc.users.GetUserByUsername(ctx, toUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	var before *entity.Asset
+
+	err = uc.uow.Do(ctx, func(ctx context.Context, repos Repos) error {
+		before, err = repos.Assets().GetAssetByID(ctx, assetID, true)
+		if err != nil {
+			return err
+		}
+
+		if before == nil {
+			return ErrAssetNotFound
+		}
+
+		if before.UserID == to.ID {
+			return fmt.Errorf("%w: asset %d is already owned by %s", ErrInvalidAdminInput, assetID, toUsername)
+		}
+
+		err = repos.Assets().UpdateAssetOwner(ctx, assetID, to.ID)
+		if err != nil {
+			return err
+		}
+
+		after := *before
+		after.UserID = to.ID
+
+		err = appendAudit(ctx, repos.Audit(), 0, entity.AuditActionAssetUpdate,
+			entity.AuditEntityAsset, assetID, before, &after)
+		if err != nil {
+			return err
+		}
+
+		err = appendEvent(ctx, repos.Outbox(), entity.EventAssetTransferred, entity.AggregateAsset, assetID,
+			entity.AssetTransferredPayload{AssetID: assetID, FromUserID: before.UserID, ToUserID: to.ID})
+		if err != nil {
+			return err
+		}
+
+		return uc.commit()
+	})
+
+	return before, uc.result(err)
+}
+
+// DeleteAsset deletes an asset of any user and returns it.
+func (uc *AdminUseCaseImpl) DeleteAsset(ctx context.Context, assetID int64) (*entity.Asset, error) {
+	var before *entity.Asset
+
+	err := uc.uow.Do(ctx, func(ctx context.Context, repos Repos) error {
+		var err error
+
+		before, err = repos.Assets().GetAssetByID(ctx, assetID, true)
+		if err != nil {
+			return err
+		}
+
+		if before == nil {
+			return ErrAssetNotFound
+		}
+
+		err = repos.Assets().DeleteAsset(ctx, assetID, before.UserID)
+		if err != nil {
+			return err
+		}
+
+		err = appendAudit(ctx, repos.Audit(), 0, entity.AuditActionAssetDelete,
+			entity.AuditEntityAsset, assetID, before, nil)
+		if err != nil {
+			return err
+		}
+
+		err = appendEvent(ctx, repos.Outbox(), entity.EventAssetDeleted, entity.AggregateAsset, assetID,
+			entity.AssetDeletedPayload{AssetID: assetID, UserID: before.UserID})
+		if err != nil {
+			return err
+		}
+
+		return uc.commit()
+	})
+
+	return before, uc.result(err)
+}
+
+// CheckLedger verifies the audit hash chain and replays the asset history
+// in the audit log to check the owner of every asset.
+func (uc *AdminUseCaseImpl) CheckLedger(ctx context.Context) (*entity.LedgerReport, error) {
+	chain, err := uc.audit.VerifyChain(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.LedgerReport{Chain: chain, Mismatches: []*entity.LedgerMismatch{}, Untracked: []int64{}}
+
+	// Owner per asset after replaying its history; 0 when it was deleted.
+	owners := make(map[int64]int64)
+
+	var afterID int64
+
+	for {
+		entries, err := uc.audit.ListEntries(ctx, entity.AuditFilter{
+			EntityType: entity.AuditEntityAsset,
+			AfterID:    afterID,
+			Limit:      _adminPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			report.Entries++
+			afterID = e.ID
+
+			if e.Action == entity.AuditActionAssetDelete {
+				owners[e.EntityID] = 0
+				continue
+			}
+
+			var after entity.Asset
+			if len(e.After) == 0 || json.Unmarshal(e.After, &after) != nil || after.UserID == 0 {
+				continue
+			}
+
+			owners[e.EntityID] = after.UserID
+		}
+
+		if len(entries) < _adminPageSize {
+			break
+		}
+	}
+
+	err = uc.eachAsset(ctx, func(a *entity.Asset) {
+		report.Assets++
+
+		expected, tracked := owners[a.ID]
+		delete(owners, a.ID)
+
+		switch {
+		case !tracked:
+			report.Untracked = append(report.Untracked, a.ID)
+		case expected == 0:
+			report.Mismatches = append(report.Mismatches, &entity.LedgerMismatch{
+				AssetID: a.ID, ActualOwner: a.UserID, Reason: "asset was deleted in the audit log",
+			})
+		case expected != a.UserID:
+			report.Mismatches = append(report.Mismatches, &entity.LedgerMismatch{
+				AssetID: a.ID, ExpectedOwner: expected, ActualOwner: a.UserID, Reason: "owner differs from the audit log",
+			})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for id, expected := range owners {
+		if expected != 0 {
+			report.Mismatches = append(report.Mismatches, &entity.LedgerMismatch{
+				AssetID: id, ExpectedOwner: expected, Reason: "asset is missing",
+			})
+		}
+	}
+
+	report.Consistent = chain.Valid && len(report.Mismatches) == 0
+
+	return report, nil
+}
+
+// ExportData returns all users and assets.
+func (uc *AdminUseCaseImpl) ExportData(ctx context.Context) (*entity.DataExport, error) {
+	export := &entity.DataExport{ExportedAt: time.Now().UTC(), Users: []*entity.User{}, Assets: []*entity.Asset{}}
+
+	var afterID int64
+
+	for {
+		users, err := uc.users.ListUsers(ctx, afterID, _adminPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		export.Users = append(export.Users, users...)
+
+		if len(users) < _adminPageSize {
+			break
+		}
+
+		afterID = users[len(users)-1].ID
+	}
+
+	err := uc.eachAsset(ctx, func(a *entity.Asset) {
+		export.Assets = append(export.Assets, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// eachAsset calls fn for every asset in ID order.
+func (uc *AdminUseCaseImpl) eachAsset(ctx context.Context, fn func(*entity.Asset)) error {
+	var afterID int64
+
+	for {
+		assets, err := uc.assets.ListAssets(ctx, entity.AssetFilter{AfterID: afterID, Limit: _adminPageSize})
+		if err != nil {
+			return err
+		}
+
+		for _, a := range assets {
+			fn(a)
+		}
+
+		if len(assets) < _adminPageSize {
+			return nil
+		}
+
+		afterID = assets[len(assets)-1].ID
+	}
+}
+
+// commit ends a transaction callback, rolling it back in dry-run mode.
+func (uc *AdminUseCaseImpl) commit() error {
+	if uc.dryRun {
+		return errDryRun
+	}
+
+	return nil
+}
+
+// result hides the rollback of a dry run from the caller.
+func (uc *AdminUseCaseImpl) result(err error) error {
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+
+	return err
+}
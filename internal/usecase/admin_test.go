@@ -0,0 +1,241 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type AdminUseCaseSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	ctx  context.Context
+
+	// Mocked units
+	mockUserRepo     *MockUserRepo
+	mockAssetRepo    *MockAssetRepo
+	mockUnitOfWork   *MockUnitOfWork
+	mockRepos        *MockRepos
+	mockTxUserRepo   *MockUserRepo
+	mockTxAssetRepo  *MockAssetRepo
+	mockAuditRepo    *MockAuditRepo
+	mockOutboxRepo   *MockOutboxRepo
+	mockAuditUseCase *MockAuditUseCase
+
+	// Tested usecase
+	adminUseCase usecase.AdminUseCase
+}
+
+func (t *AdminUseCaseSuite) SetupTest() {
+	t.ctx = context.Background()
+	t.ctrl = gomock.NewController(t.T())
+	t.mockUserRepo = NewMockUserRepo(t.ctrl)
+	t.mockAssetRepo = NewMockAssetRepo(t.ctrl)
+	t.mockUnitOfWork = NewMockUnitOfWork(t.ctrl)
+	t.mockRepos = NewMockRepos(t.ctrl)
+	t.mockTxUserRepo = NewMockUserRepo(t.ctrl)
+	t.mockTxAssetRepo = NewMockAssetRepo(t.ctrl)
+	t.mockAuditRepo = NewMockAuditRepo(t.ctrl)
+	t.mockOutboxRepo = NewMockOutboxRepo(t.ctrl)
+	t.mockAuditUseCase = NewMockAuditUseCase(t.ctrl)
+	t.adminUseCase = usecase.NewAdminUseCase(t.mockUserRepo, t.mockAssetRepo, t.mockUnitOfWork, t.mockAuditUseCase, false)
+
+	t.mockRepos.EXPECT().Users().Return(t.mockTxUserRepo).AnyTimes()
+	t.mockRepos.EXPECT().Assets().Return(t.mockTxAssetRepo).AnyTimes()
+	t.mockRepos.EXPECT().Audit().Return(t.mockAuditRepo).AnyTimes()
+	t.mockRepos.EXPECT().Outbox().Return(t.mockOutboxRepo).AnyTimes()
+}
+
+func TestAdminUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(AdminUseCaseSuite))
+}
+
+// expectTx makes the unit of work run its callback against the mocked
+// repos and returns a pointer that receives the callback result.
+func (t *AdminUseCaseSuite) expectTx() *error {
+	var res error
+
+	t.mockUnitOfWork.EXPECT().Do(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(context.Context, usecase.Repos) error) error {
+			res = fn(ctx, t.mockRepos)
+			return res
+		},
+	)
+
+	return &res
+}
+
+// auditEntry returns an audit entry of an asset with the given owner after it.
+func auditEntry(id int64, action entity.AuditAction, assetID, ownerID int64) *entity.AuditEntry {
+	e := &entity.AuditEntry{ID: id, Action: action, EntityType: entity.AuditEntityAsset, EntityID: assetID}
+	if ownerID != 0 {
+		e.After, _ = json.Marshal(&entity.Asset{ID: assetID, UserID: ownerID})
+	}
+
+	return e
+}
+
+func (t *AdminUseCaseSuite) TestCreateUser_GreenPath() {
+	t.expectTx()
+	t.mockTxUserRepo.EXPECT().CreateUser(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, user *entity.User) error {
+			t.Equal(entity.RoleAdmin, user.Role)
+			user.ID = 5
+
+			return nil
+		},
+	)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionUserRegister, entry.Action)
+			t.Zero(entry.ActorID)
+
+			return nil
+		},
+	)
+	t.mockOutboxRepo.EXPECT().AddEvent(t.ctx, gomock.Any()).Return(nil)
+
+	user, err := t.adminUseCase.CreateUser(t.ctx, "root", "kapusta", entity.RoleAdmin)
+
+	t.NoError(err)
+	t.Equal(int64(5), user.ID)
+}
+
+func (t *AdminUseCaseSuite) TestCreateUser_RejectsInvalidInput() {
+	for _, args := range [][3]string{
+		{"", "kapusta", entity.RoleUser},
+		{"root", "", entity.RoleUser},
+		{"root", "kapusta", "superuser"},
+	} {
+		_, err := t.adminUseCase.CreateUser(t.ctx, args[0], args[1], args[2])
+
+		t.ErrorIs(err, usecase.ErrInvalidAdminInput)
+	}
+}
+
+func (t *AdminUseCaseSuite) TestDryRun_RollsBack() {
+	t.adminUseCase = usecase.NewAdminUseCase(t.mockUserRepo, t.mockAssetRepo, t.mockUnitOfWork, t.mockAuditUseCase, true)
+
+	rollback := t.expectTx()
+	t.mockTxAssetRepo.EXPECT().GetAssetByID(t.ctx, int64(3), true).Return(&entity.Asset{ID: 3, UserID: 2}, nil)
+	t.mockTxAssetRepo.EXPECT().DeleteAsset(t.ctx, int64(3), int64(2)).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).Return(nil)
+	t.mockOutboxRepo.EXPECT().AddEvent(t.ctx, gomock.Any()).Return(nil)
+
+	deleted, err := t.adminUseCase.DeleteAsset(t.ctx, 3)
+
+	t.NoError(err)
+	t.Equal(int64(2), deleted.UserID)
+	t.Error(*rollback, "transaction must be rolled back")
+}
+
+func (t *AdminUseCaseSuite) TestResetPassword_RevokesSessions() {
+	t.mockUserRepo.EXPECT().GetUserByUsername(t.ctx, "aboba").Return(&entity.User{ID: 4}, nil)
+	t.expectTx()
+	t.mockTxUserRepo.EXPECT().UpdatePasswordHash(t.ctx, int64(4), gomock.Any()).Return(nil)
+	t.mockTxUserRepo.EXPECT().RevokeSessions(t.ctx, int64(4), gomock.Any()).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionAdmin, entry.Action)
+			t.NotContains(string(entry.After), "kapusta")
+
+			return nil
+		},
+	)
+
+	err := t.adminUseCase.ResetPassword(t.ctx, "aboba", "kapusta")
+
+	t.NoError(err)
+}
+
+func (t *AdminUseCaseSuite) TestTransferAsset_GreenPath() {
+	t.mockUserRepo.EXPECT().GetUserByUsername(t.ctx, "aboba").Return(&entity.User{ID: 4}, nil)
+	t.expectTx()
+	t.mockTxAssetRepo.EXPECT().GetAssetByID(t.ctx, int64(3), true).Return(&entity.Asset{ID: 3, UserID: 2}, nil)
+	t.mockTxAssetRepo.EXPECT().UpdateAssetOwner(t.ctx, int64(3), int64(4)).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionAssetUpdate, entry.Action)
+			t.Contains(string(entry.After), `"user_id":4`)
+
+			return nil
+		},
+	)
+	t.mockOutboxRepo.EXPECT().AddEvent(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, event *entity.Event) error {
+			t.Equal(entity.EventAssetTransferred, event.Type)
+
+			return nil
+		},
+	)
+
+	before, err := t.adminUseCase.TransferAsset(t.ctx, 3, "aboba")
+
+	t.NoError(err)
+	t.Equal(int64(2), before.UserID)
+}
+
+func (t *AdminUseCaseSuite) TestTransferAsset_ReturnsError_WhenAlreadyOwned() {
+	t.mockUserRepo.EXPECT().GetUserByUsername(t.ctx, "aboba").Return(&entity.User{ID: 2}, nil)
+	t.expectTx()
+	t.mockTxAssetRepo.EXPECT().GetAssetByID(t.ctx, int64(3), true).Return(&entity.Asset{ID: 3, UserID: 2}, nil)
+
+	_, err := t.adminUseCase.TransferAsset(t.ctx, 3, "aboba")
+
+	t.ErrorIs(err, usecase.ErrInvalidAdminInput)
+}
+
+func (t *AdminUseCaseSuite) TestDeleteAsset_ReturnsError_WhenNotFound() {
+	t.expectTx()
+	t.mockTxAssetRepo.EXPECT().GetAssetByID(t.ctx, int64(3), true).Return(nil, nil)
+
+	_, err := t.adminUseCase.DeleteAsset(t.ctx, 3)
+
+	t.ErrorIs(err, usecase.ErrAssetNotFound)
+}
+
+func (t *AdminUseCaseSuite) TestCheckLedger() {
+	t.mockAuditUseCase.EXPECT().VerifyChain(t.ctx).Return(&entity.AuditVerification{Valid: true, Checked: 6}, nil)
+	t.mockAuditUseCase.EXPECT().ListEntries(t.ctx, gomock.Any()).Return([]*entity.AuditEntry{
+		auditEntry(1, entity.AuditActionAssetCreate, 10, 1),
+		auditEntry(2, entity.AuditActionAssetPurchase, 10, 2),
+		auditEntry(3, entity.AuditActionAssetCreate, 11, 1),
+		auditEntry(4, entity.AuditActionAssetCreate, 12, 1),
+		auditEntry(5, entity.AuditActionAssetDelete, 12, 0),
+		auditEntry(6, entity.AuditActionAssetCreate, 13, 3),
+	}, nil)
+	t.mockAssetRepo.EXPECT().ListAssets(t.ctx, gomock.Any()).Return([]*entity.Asset{
+		{ID: 9, UserID: 1},
+		{ID: 10, UserID: 2},
+		{ID: 11, UserID: 5},
+		{ID: 12, UserID: 1},
+	}, nil)
+
+	report, err := t.adminUseCase.CheckLedger(t.ctx)
+
+	t.Require().NoError(err)
+	t.False(report.Consistent)
+	t.Equal(4, report.Assets)
+	t.Equal(int64(6), report.Entries)
+	t.Equal([]int64{9}, report.Untracked)
+	t.ElementsMatch([]*entity.LedgerMismatch{
+		{AssetID: 11, ExpectedOwner: 1, ActualOwner: 5, Reason: "owner differs from the audit log"},
+		{AssetID: 12, ActualOwner: 1, Reason: "asset was deleted in the audit log"},
+		{AssetID: 13, ExpectedOwner: 3, Reason: "asset is missing"},
+	}, report.Mismatches)
+}
+
+func (t *AdminUseCaseSuite) TestCheckLedger_ReturnsError_WhenAuditFails() {
+	t.mockAuditUseCase.EXPECT().VerifyChain(t.ctx).Return(nil, assert.AnError)
+
+	_, err := t.adminUseCase.CheckLedger(t.ctx)
+
+	t.ErrorIs(err, assert.AnError)
+}
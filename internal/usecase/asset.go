@@ -3,58 +3,190 @@ package usecase
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/appxpy/hive-test/internal/entity"
 )
 
+var (
+	// ErrAssetNotFound is returned when the asset does not exist or is not owned by the user.
+	ErrAssetNotFound = errors.New("asset not found")
+	// ErrOwnAsset is returned when a user tries to buy an asset they already own.
+	ErrOwnAsset = errors.New("cannot purchase your own asset")
+)
+
 // AssetUseCaseImpl implements the AssetUseCase interface.
 type AssetUseCaseImpl struct {
-	repo AssetRepo
+	repo  AssetRepo
+	uow   UnitOfWork
+	flags FeatureFlags
 }
 
-// NewAssetUseCase creates a new AssetUseCase.
-func NewAssetUseCase(repo AssetRepo) AssetUseCase {
+// NewAssetUseCase creates a new AssetUseCase. Changes run in units of work
+// of uow; reads go straight to repo. flags gate the changes being rolled out.
+func NewAssetUseCase(repo AssetRepo, uow UnitOfWork, flags FeatureFlags) AssetUseCase {
 	return &AssetUseCaseImpl{
-		repo: repo,
+		repo:  repo,
+		uow:   uow,
+		flags: flags,
 	}
 }
 
 // AddAsset adds a new asset for a user.
 func (uc *AssetUseCaseImpl) AddAsset(ctx context.Context, asset *entity.Asset) error {
-	return uc.repo.CreateAsset(ctx, asset)
+	return uc.uow.Do(ctx, func(ctx context.Context, repos Repos) error {
+		err := repos.Assets().CreateAsset(ctx, asset)
+		if err != nil {
+			return err
+		}
+
+		err = appendAudit(ctx, repos.Audit(), asset.UserID, entity.AuditActionAssetCreate,
+			entity.AuditEntityAsset, asset.ID, nil, asset)
+		if err != nil {
+			return err
+		}
+
+		return appendEvent(ctx, repos.Outbox(), entity.EventAssetCreated, entity.AggregateAsset, asset.ID,
+			entity.AssetCreatedPayload{Asset: asset})
+	})
 }
 
 // RemoveAsset removes an asset owned by the user.
 func (uc *AssetUseCaseImpl) RemoveAsset(ctx context.Context, assetID, userID int64) error {
-	return uc.repo.DeleteAsset(ctx, assetID, userID)
+	return uc.uow.Do(ctx, func(ctx context.Context, repos Repos) error {
+		before, err := repos.Assets().GetAssetByID(ctx, assetID, true)
+		if err != nil {
+			return err
+		}
+
+		err = repos.Assets().DeleteAsset(ctx, assetID, userID)
+		if err != nil {
+			return err
+		}
+
+		err = appendAudit(ctx, repos.Audit(), userID, entity.AuditActionAssetDelete,
+			entity.AuditEntityAsset, assetID, before, nil)
+		if err != nil {
+			return err
+		}
+
+		return appendEvent(ctx, repos.Outbox(), entity.EventAssetDeleted, entity.AggregateAsset, assetID,
+			entity.AssetDeletedPayload{AssetID: assetID, UserID: userID})
+	})
 }
 
-// PurchaseAsset allows a user to purchase an asset.
+// PurchaseAsset allows a user to purchase an asset. With FlagPurchaseV2 on
+// for the buyer the purchase runs serializable rather than at the configured
+// isolation level, which the new purchase logic is rolled out on.
 func (uc *AssetUseCaseImpl) PurchaseAsset(ctx context.Context, assetID, buyerID int64) error {
-	return uc.repo.ExecuteTx(ctx, func(repo AssetRepo) error {
-		asset, err := repo.GetAssetByID(ctx, assetID, true)
+	var price float64
+
+	do := uc.uow.Do
+	if uc.flags.IsEnabled(ctx, entity.FlagPurchaseV2) {
+		do = func(ctx context.Context, fn func(ctx context.Context, repos Repos) error) error {
+			return uc.uow.DoIsolated(ctx, Serializable, fn)
+		}
+	}
+
+	err := do(ctx, func(ctx context.Context, repos Repos) error {
+		start := time.Now()
+		asset, err := repos.Assets().GetAssetByID(ctx, assetID, true)
+		purchaseLockWait.Observe(time.Since(start).Seconds())
+
 		if err != nil {
 			return err
 		}
 
 		if asset == nil {
-			return errors.New("asset not found")
+			return ErrAssetNotFound
 		}
 
 		if asset.UserID == buyerID {
-			return errors.New("cannot purchase your own asset")
+			return ErrOwnAsset
 		}
 
-		err = repo.UpdateAssetOwner(ctx, assetID, buyerID)
+		err = repos.Assets().UpdateAssetOwner(ctx, assetID, buyerID)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		after := *asset
+		after.UserID = buyerID
+
+		err = appendAudit(ctx, repos.Audit(), buyerID, entity.AuditActionAssetPurchase,
+			entity.AuditEntityAsset, assetID, asset, &after)
+		if err != nil {
+			return err
+		}
+
+		// The event goes first: the notifications carry its ID, so that it
+		// can publish them to live streams once committed.
+		event, err := newEvent(ctx, entity.EventAssetPurchased, entity.AggregateAsset, assetID,
+			entity.AssetPurchasedPayload{AssetID: assetID, SellerID: asset.UserID, BuyerID: buyerID, Price: asset.Price})
+		if err != nil {
+			return err
+		}
+
+		err = repos.Outbox().AddEvent(ctx, event)
+		if err != nil {
+			return err
+		}
+
+		sale := entity.AssetSoldData{
+			AssetID:   assetID,
+			AssetName: asset.Name,
+			SellerID:  asset.UserID,
+			BuyerID:   buyerID,
+			Price:     asset.Price,
+		}
+
+		err = addNotification(ctx, repos.Notifications(), event.ID, asset.UserID, entity.NotificationAssetSold, sale)
+		if err != nil {
+			return err
+		}
+
+		price = asset.Price
+
+		return addNotification(ctx, repos.Notifications(), event.ID, buyerID, entity.NotificationAssetBought, sale)
 	})
+	if err != nil {
+		purchaseFailed(err)
+		return err
+	}
+
+	purchases.Inc()
+	if price > 0 { // counters cannot go down; prices are not validated
+		purchaseVolume.Add(price)
+	}
+
+	return nil
 }
 
 // GetAssetsByUser retrieves all assets owned by the user.
 func (uc *AssetUseCaseImpl) GetAssetsByUser(ctx context.Context, userID int64) ([]*entity.Asset, error) {
 	return uc.repo.GetAssetsByUserID(ctx, userID)
 }
+
+// GetAssetsByIDs retrieves the assets with the given IDs in one call.
+// Missing assets are left out.
+func (uc *AssetUseCaseImpl) GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return uc.repo.GetAssetsByIDs(ctx, ids)
+}
+
+// GetAssetsByUsers retrieves the assets owned by any of the users in one call.
+func (uc *AssetUseCaseImpl) GetAssetsByUsers(ctx context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	return uc.repo.GetAssetsByUserIDs(ctx, userIDs)
+}
+
+// ListAssets returns a page of the asset catalog.
+func (uc *AssetUseCaseImpl) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	return uc.repo.ListAssets(ctx, filter)
+}
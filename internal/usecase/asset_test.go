@@ -6,6 +6,7 @@ import (
 
 	"github.com/appxpy/hive-test/internal/entity"
 	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/flagtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/mock/gomock"
@@ -21,7 +22,14 @@ type AssetUseCaseSuite struct {
 	someAsset *entity.Asset
 
 	// Mocked units
-	mockAssetRepo *MockAssetRepo
+	mockUnitOfWork *MockUnitOfWork
+	mockRepos      *MockRepos
+	mockAssetRepo  *MockAssetRepo
+	mockAuditRepo  *MockAuditRepo
+	mockOutboxRepo *MockOutboxRepo
+	mockNotifyRepo *MockNotificationRepo
+
+	flags *flagtest.Flags
 
 	// Tested usecase
 	assetUseCase usecase.AssetUseCase
@@ -39,16 +47,84 @@ func (t *AssetUseCaseSuite) SetupSuite() {
 func (t *AssetUseCaseSuite) SetupTest() {
 	t.ctx = context.Background()
 	t.ctrl = gomock.NewController(t.T())
+	t.mockUnitOfWork = NewMockUnitOfWork(t.ctrl)
+	t.mockRepos = NewMockRepos(t.ctrl)
 	t.mockAssetRepo = NewMockAssetRepo(t.ctrl)
-	t.assetUseCase = usecase.NewAssetUseCase(t.mockAssetRepo)
+	t.mockAuditRepo = NewMockAuditRepo(t.ctrl)
+	t.mockOutboxRepo = NewMockOutboxRepo(t.ctrl)
+	t.mockNotifyRepo = NewMockNotificationRepo(t.ctrl)
+	t.flags = flagtest.New()
+	t.assetUseCase = usecase.NewAssetUseCase(t.mockAssetRepo, t.mockUnitOfWork, t.flags)
+
+	t.mockRepos.EXPECT().Assets().Return(t.mockAssetRepo).AnyTimes()
+	t.mockRepos.EXPECT().Audit().Return(t.mockAuditRepo).AnyTimes()
+	t.mockRepos.EXPECT().Outbox().Return(t.mockOutboxRepo).AnyTimes()
+	t.mockRepos.EXPECT().Notifications().Return(t.mockNotifyRepo).AnyTimes()
 }
 
+// _eventID is the ID the mocked outbox assigns to events.
+const _eventID int64 = 9
+
 func TestAssetUseCaseSuite(t *testing.T) {
 	suite.Run(t, new(AssetUseCaseSuite))
 }
 
+// expectEvent expects a single outbox event of the given type, assigns it
+// _eventID like the repository would and returns a pointer that receives it.
+func (t *AssetUseCaseSuite) expectEvent(eventType entity.EventType) *entity.Event {
+	got := &entity.Event{}
+
+	t.mockOutboxRepo.EXPECT().AddEvent(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, event *entity.Event) error {
+			t.Equal(eventType, event.Type)
+			t.Equal(entity.AggregateAsset, event.AggregateType)
+			event.ID = _eventID
+			*got = *event
+
+			return nil
+		},
+	)
+
+	return got
+}
+
+// expectNotification expects an inbox notification of the given type for userID.
+func (t *AssetUseCaseSuite) expectNotification(userID int64, notificationType string) *gomock.Call {
+	return t.mockNotifyRepo.EXPECT().CreateNotification(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, n *entity.Notification) error {
+			t.Equal(userID, n.UserID)
+			t.Equal(notificationType, n.Type)
+			t.Equal(_eventID, n.EventID)
+			t.JSONEq(`{"asset_id":1,"asset_name":"","seller_id":3,"buyer_id":2,"price":0}`, string(n.Data))
+
+			return nil
+		},
+	)
+}
+
+// expectTx makes the unit of work run its callback against the mocked repos.
+func (t *AssetUseCaseSuite) expectTx() {
+	t.mockUnitOfWork.EXPECT().Do(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(context.Context, usecase.Repos) error) error {
+			return fn(ctx, t.mockRepos)
+		},
+	)
+}
+
 func (t *AssetUseCaseSuite) TestAddAsset_GreenPath() {
+	t.expectTx()
 	t.mockAssetRepo.EXPECT().CreateAsset(t.ctx, t.someAsset).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionAssetCreate, entry.Action)
+			t.Equal(t.someAsset.UserID, entry.ActorID)
+			t.Nil(entry.Before)
+			t.JSONEq(`{"id":0,"user_id":1,"name":"Test Asset","description":"A test asset","price":100}`, string(entry.After))
+
+			return nil
+		},
+	)
+	t.expectEvent(entity.EventAssetCreated)
 
 	err := t.assetUseCase.AddAsset(t.ctx, t.someAsset)
 
@@ -56,6 +132,7 @@ func (t *AssetUseCaseSuite) TestAddAsset_GreenPath() {
 }
 
 func (t *AssetUseCaseSuite) TestAddAsset_ReturnsError_WhenRepoReturnsError() {
+	t.expectTx()
 	t.mockAssetRepo.EXPECT().CreateAsset(t.ctx, t.someAsset).Return(assert.AnError)
 
 	err := t.assetUseCase.AddAsset(t.ctx, t.someAsset)
@@ -63,15 +140,40 @@ func (t *AssetUseCaseSuite) TestAddAsset_ReturnsError_WhenRepoReturnsError() {
 	t.ErrorIs(err, assert.AnError)
 }
 
+func (t *AssetUseCaseSuite) TestAddAsset_ReturnsError_WhenAuditFails() {
+	t.expectTx()
+	t.mockAssetRepo.EXPECT().CreateAsset(t.ctx, t.someAsset).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).Return(assert.AnError)
+
+	err := t.assetUseCase.AddAsset(t.ctx, t.someAsset)
+
+	t.ErrorIs(err, assert.AnError)
+}
+
 func (t *AssetUseCaseSuite) TestRemoveAsset_GreenPath() {
+	t.expectTx()
+	t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, t.someAsset.ID, true).Return(t.someAsset, nil)
 	t.mockAssetRepo.EXPECT().DeleteAsset(t.ctx, t.someAsset.ID, t.someAsset.UserID).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionAssetDelete, entry.Action)
+			t.NotNil(entry.Before)
+			t.Nil(entry.After)
+
+			return nil
+		},
+	)
+	event := t.expectEvent(entity.EventAssetDeleted)
 
 	err := t.assetUseCase.RemoveAsset(t.ctx, t.someAsset.ID, t.someAsset.UserID)
 
 	t.NoError(err)
+	t.JSONEq(`{"asset_id":0,"user_id":1}`, string(event.Payload))
 }
 
 func (t *AssetUseCaseSuite) TestRemoveAsset_ReturnsError_WhenRepoReturnsError() {
+	t.expectTx()
+	t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, t.someAsset.ID, true).Return(t.someAsset, nil)
 	t.mockAssetRepo.EXPECT().DeleteAsset(t.ctx, t.someAsset.ID, t.someAsset.UserID).Return(assert.AnError)
 
 	err := t.assetUseCase.RemoveAsset(t.ctx, t.someAsset.ID, t.someAsset.UserID)
@@ -84,34 +186,96 @@ func (t *AssetUseCaseSuite) TestPurchaseAsset_GreenPath() {
 	buyerID := int64(2)
 	originalAsset := &entity.Asset{ID: assetID, UserID: 3} // Asset owned by userID 3
 
-	t.mockAssetRepo.EXPECT().ExecuteTx(t.ctx, gomock.Any()).DoAndReturn(
-		func(ctx context.Context, fn func(usecase.AssetRepo) error) error {
-
-			// Expected calls within the transaction
-			t.mockAssetRepo.EXPECT().GetAssetByID(ctx, assetID, true).Return(originalAsset, nil)
-			t.mockAssetRepo.EXPECT().UpdateAssetOwner(ctx, assetID, buyerID).Return(nil)
-
-			return fn(t.mockAssetRepo)
+	t.expectTx()
+	t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, assetID, true).Return(originalAsset, nil)
+	t.mockAssetRepo.EXPECT().UpdateAssetOwner(t.ctx, assetID, buyerID).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionAssetPurchase, entry.Action)
+			t.Equal(buyerID, entry.ActorID)
+			t.JSONEq(`{"id":1,"user_id":3,"name":"","description":"","price":0}`, string(entry.Before))
+			t.JSONEq(`{"id":1,"user_id":2,"name":"","description":"","price":0}`, string(entry.After))
+
+			return nil
 		},
 	)
+	t.expectEvent(entity.EventAssetPurchased)
+	gomock.InOrder(
+		t.expectNotification(3, entity.NotificationAssetSold),
+		t.expectNotification(buyerID, entity.NotificationAssetBought),
+	)
 
 	err := t.assetUseCase.PurchaseAsset(t.ctx, assetID, buyerID)
 
 	t.NoError(err)
 }
 
-func (t *AssetUseCaseSuite) TestPurchaseAsset_ReturnsError_WhenAssetNotFound() {
+func (t *AssetUseCaseSuite) TestPurchaseAsset_PurchaseV2() {
 	assetID := int64(1)
 	buyerID := int64(2)
 
-	t.mockAssetRepo.EXPECT().ExecuteTx(t.ctx, gomock.Any()).DoAndReturn(
-		func(ctx context.Context, fn func(usecase.AssetRepo) error) error {
-			// Asset not found
-			t.mockAssetRepo.EXPECT().GetAssetByID(ctx, assetID, true).Return(nil, nil)
+	tests := []struct {
+		name      string
+		on        bool
+		isolation usecase.IsolationLevel
+	}{
+		{name: "off runs at the configured level", on: false},
+		{name: "on runs serializable", on: true, isolation: usecase.Serializable},
+	}
 
-			return fn(t.mockAssetRepo)
-		},
-	)
+	for _, tc := range tests {
+		t.Run(tc.name, func() {
+			t.flags.Force(t.T(), entity.FlagPurchaseV2, tc.on)
+
+			run := func(ctx context.Context, fn func(context.Context, usecase.Repos) error) error {
+				return fn(ctx, t.mockRepos)
+			}
+			if tc.on {
+				t.mockUnitOfWork.EXPECT().DoIsolated(t.ctx, tc.isolation, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, _ usecase.IsolationLevel, fn func(context.Context, usecase.Repos) error) error {
+						return run(ctx, fn)
+					},
+				)
+			} else {
+				t.mockUnitOfWork.EXPECT().Do(t.ctx, gomock.Any()).DoAndReturn(run)
+			}
+
+			t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, assetID, true).Return(&entity.Asset{ID: assetID, UserID: 3}, nil)
+			t.mockAssetRepo.EXPECT().UpdateAssetOwner(t.ctx, assetID, buyerID).Return(nil)
+			t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).Return(nil)
+			t.mockNotifyRepo.EXPECT().CreateNotification(t.ctx, gomock.Any()).Times(2).Return(nil)
+			t.expectEvent(entity.EventAssetPurchased)
+
+			err := t.assetUseCase.PurchaseAsset(t.ctx, assetID, buyerID)
+
+			t.NoError(err)
+		})
+	}
+}
+
+func (t *AssetUseCaseSuite) TestPurchaseAsset_ReturnsError_WhenNotificationFails() {
+	assetID := int64(1)
+	buyerID := int64(2)
+
+	t.expectTx()
+	t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, assetID, true).Return(&entity.Asset{ID: assetID, UserID: 3}, nil)
+	t.mockAssetRepo.EXPECT().UpdateAssetOwner(t.ctx, assetID, buyerID).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).Return(nil)
+	t.expectEvent(entity.EventAssetPurchased)
+	t.mockNotifyRepo.EXPECT().CreateNotification(t.ctx, gomock.Any()).Return(assert.AnError)
+
+	err := t.assetUseCase.PurchaseAsset(t.ctx, assetID, buyerID)
+
+	t.ErrorIs(err, assert.AnError)
+}
+
+func (t *AssetUseCaseSuite) TestPurchaseAsset_ReturnsError_WhenAssetNotFound() {
+	assetID := int64(1)
+	buyerID := int64(2)
+
+	t.expectTx()
+	// Asset not found
+	t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, assetID, true).Return(nil, nil)
 
 	err := t.assetUseCase.PurchaseAsset(t.ctx, assetID, buyerID)
 
@@ -124,13 +288,8 @@ func (t *AssetUseCaseSuite) TestPurchaseAsset_ReturnsError_WhenBuyerIsOwner() {
 	buyerID := int64(2)
 	originalAsset := &entity.Asset{ID: assetID, UserID: buyerID}
 
-	t.mockAssetRepo.EXPECT().ExecuteTx(t.ctx, gomock.Any()).DoAndReturn(
-		func(ctx context.Context, fn func(usecase.AssetRepo) error) error {
-			t.mockAssetRepo.EXPECT().GetAssetByID(ctx, assetID, true).Return(originalAsset, nil)
-
-			return fn(t.mockAssetRepo)
-		},
-	)
+	t.expectTx()
+	t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, assetID, true).Return(originalAsset, nil)
 
 	err := t.assetUseCase.PurchaseAsset(t.ctx, assetID, buyerID)
 
@@ -138,6 +297,14 @@ func (t *AssetUseCaseSuite) TestPurchaseAsset_ReturnsError_WhenBuyerIsOwner() {
 	t.Contains(err.Error(), "cannot purchase your own asset")
 }
 
+func (t *AssetUseCaseSuite) TestPurchaseAsset_ReturnsError_WhenTransactionFails() {
+	t.mockUnitOfWork.EXPECT().Do(t.ctx, gomock.Any()).Return(assert.AnError)
+
+	err := t.assetUseCase.PurchaseAsset(t.ctx, 1, 2)
+
+	t.ErrorIs(err, assert.AnError)
+}
+
 func (t *AssetUseCaseSuite) TestGetAssetsByUser_GreenPath() {
 	t.mockAssetRepo.EXPECT().GetAssetsByUserID(t.ctx, t.someAsset.UserID).Return([]*entity.Asset{t.someAsset, t.someAsset}, nil)
 
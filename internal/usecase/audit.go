@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const _auditVerifyBatch = 500
+
+// AuditUseCaseImpl implements the AuditUseCase interface.
+type AuditUseCaseImpl struct {
+	repo AuditRepo
+}
+
+// NewAuditUseCase creates a new AuditUseCase.
+func NewAuditUseCase(repo AuditRepo) AuditUseCase {
+	return &AuditUseCaseImpl{
+		repo: repo,
+	}
+}
+
+// ListEntries returns audit entries matching the filter.
+func (uc *AuditUseCaseImpl) ListEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	return uc.repo.ListEntries(ctx, filter)
+}
+
+// RecordConfigReload records the settings changed by a configuration reload,
+// keyed by setting, with their values before and after.
+func (uc *AuditUseCaseImpl) RecordConfigReload(ctx context.Context, before, after map[string]string) error {
+	return appendAudit(ctx, uc.repo, 0, entity.AuditActionConfigReload, entity.AuditEntityConfig, 0, before, after)
+}
+
+// VerifyChain walks the whole audit log in insertion order and reports the
+// first entry whose hash or link to the previous entry does not match.
+func (uc *AuditUseCaseImpl) VerifyChain(ctx context.Context) (*entity.AuditVerification, error) {
+	res := &entity.AuditVerification{Valid: true}
+	prevHash := entity.AuditGenesisHash
+
+	var afterID int64
+
+	for {
+		entries, err := uc.repo.ListEntries(ctx, entity.AuditFilter{AfterID: afterID, Limit: _auditVerifyBatch})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			res.Checked++
+
+			switch {
+			case e.PrevHash != prevHash:
+				res.Valid, res.BrokenAt = false, e.ID
+				res.Reason = "previous hash does not match preceding entry"
+
+				return res, nil
+			case e.Hash != e.ComputeHash():
+				res.Valid, res.BrokenAt = false, e.ID
+				res.Reason = "entry hash does not match its content"
+
+				return res, nil
+			}
+
+			prevHash = e.Hash
+			afterID = e.ID
+		}
+
+		if len(entries) < _auditVerifyBatch {
+			return res, nil
+		}
+	}
+}
+
+// newAuditEntry builds an audit entry for the request carried by ctx.
+// before and after are JSON snapshots of the entity; nil is recorded as absent.
+func newAuditEntry(
+	ctx context.Context,
+	actorID int64,
+	action entity.AuditAction,
+	entityType string,
+	entityID int64,
+	before, after interface{},
+) (*entity.AuditEntry, error) {
+	meta := reqctx.FromContext(ctx)
+
+	entry := &entity.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		RequestID:  meta.RequestID,
+	}
+
+	var err error
+
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return nil, err
+	}
+
+	if entry.After, err = auditSnapshot(after); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// appendAudit builds an audit entry and writes it through repo.
+func appendAudit(
+	ctx context.Context,
+	repo AuditRepo,
+	actorID int64,
+	action entity.AuditAction,
+	entityType string,
+	entityID int64,
+	before, after interface{},
+) error {
+	entry, err := newAuditEntry(ctx, actorID, action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+
+	return repo.AppendEntry(ctx, entry)
+}
+
+func auditSnapshot(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("audit snapshot: %w", err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type AuditUseCaseSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	ctx  context.Context
+
+	// Mocked units
+	mockAuditRepo *MockAuditRepo
+
+	// Tested usecase
+	auditUseCase usecase.AuditUseCase
+}
+
+func (t *AuditUseCaseSuite) SetupTest() {
+	t.ctx = context.Background()
+	t.ctrl = gomock.NewController(t.T())
+	t.mockAuditRepo = NewMockAuditRepo(t.ctrl)
+	t.auditUseCase = usecase.NewAuditUseCase(t.mockAuditRepo)
+}
+
+func TestAuditUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(AuditUseCaseSuite))
+}
+
+// chain builds n correctly linked audit entries.
+func (t *AuditUseCaseSuite) chain(n int) []*entity.AuditEntry {
+	entries := make([]*entity.AuditEntry, 0, n)
+	prevHash := entity.AuditGenesisHash
+
+	for i := 1; i <= n; i++ {
+		e := &entity.AuditEntry{
+			ID:         int64(i),
+			ActorID:    1,
+			Action:     entity.AuditActionAssetCreate,
+			EntityType: entity.AuditEntityAsset,
+			EntityID:   int64(i),
+			After:      []byte(`{"id":1}`),
+			PrevHash:   prevHash,
+			CreatedAt:  time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+		}
+		e.Hash = e.ComputeHash()
+		prevHash = e.Hash
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+func (t *AuditUseCaseSuite) TestListEntries_PassesFilter() {
+	filter := entity.AuditFilter{ActorID: 7, Action: entity.AuditActionAssetPurchase, Limit: 10}
+	t.mockAuditRepo.EXPECT().ListEntries(t.ctx, filter).Return(t.chain(2), nil)
+
+	res, err := t.auditUseCase.ListEntries(t.ctx, filter)
+
+	t.NoError(err)
+	t.Len(res, 2)
+}
+
+func (t *AuditUseCaseSuite) TestVerifyChain_GreenPath() {
+	t.mockAuditRepo.EXPECT().ListEntries(t.ctx, gomock.Any()).Return(t.chain(3), nil)
+
+	res, err := t.auditUseCase.VerifyChain(t.ctx)
+
+	t.NoError(err)
+	t.True(res.Valid)
+	t.Equal(int64(3), res.Checked)
+}
+
+func (t *AuditUseCaseSuite) TestVerifyChain_DetectsModifiedEntry() {
+	entries := t.chain(3)
+	entries[1].After = []byte(`{"id":2}`)
+	t.mockAuditRepo.EXPECT().ListEntries(t.ctx, gomock.Any()).Return(entries, nil)
+
+	res, err := t.auditUseCase.VerifyChain(t.ctx)
+
+	t.NoError(err)
+	t.False(res.Valid)
+	t.Equal(int64(2), res.BrokenAt)
+}
+
+func (t *AuditUseCaseSuite) TestVerifyChain_DetectsRemovedEntry() {
+	entries := t.chain(3)
+	entries = append(entries[:1], entries[2:]...)
+	t.mockAuditRepo.EXPECT().ListEntries(t.ctx, gomock.Any()).Return(entries, nil)
+
+	res, err := t.auditUseCase.VerifyChain(t.ctx)
+
+	t.NoError(err)
+	t.False(res.Valid)
+	t.Equal(int64(3), res.BrokenAt)
+}
+
+func (t *AuditUseCaseSuite) TestVerifyChain_ReturnsError_WhenRepoReturnsError() {
+	t.mockAuditRepo.EXPECT().ListEntries(t.ctx, gomock.Any()).Return(nil, assert.AnError)
+
+	res, err := t.auditUseCase.VerifyChain(t.ctx)
+
+	t.ErrorIs(err, assert.AnError)
+	t.Nil(res)
+}
+
+func (t *AuditUseCaseSuite) TestRecordConfigReload_AppendsChanges() {
+	before := map[string]string{"logger.log_level": "debug"}
+	after := map[string]string{"logger.log_level": "warn"}
+
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, e *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionConfigReload, e.Action)
+			t.Equal(entity.AuditEntityConfig, e.EntityType)
+			t.JSONEq(`{"logger.log_level":"debug"}`, string(e.Before))
+			t.JSONEq(`{"logger.log_level":"warn"}`, string(e.After))
+
+			return nil
+		})
+
+	t.NoError(t.auditUseCase.RecordConfigReload(t.ctx, before, after))
+}
+
+func (t *AuditUseCaseSuite) TestRecordConfigReload_ReturnsError_WhenRepoReturnsError() {
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).Return(assert.AnError)
+
+	err := t.auditUseCase.RecordConfigReload(t.ctx, map[string]string{}, map[string]string{})
+
+	t.ErrorIs(err, assert.AnError)
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+// appendEvent stores a domain event in the outbox of the current transaction.
+func appendEvent(
+	ctx context.Context,
+	repo OutboxRepo,
+	eventType entity.EventType,
+	aggregateType string,
+	aggregateID int64,
+	payload interface{},
+) error {
+	event, err := newEvent(ctx, eventType, aggregateType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+
+	return repo.AddEvent(ctx, event)
+}
+
+// newEvent builds a domain event for the outbox, carrying the request ID of ctx.
+func newEvent(
+	ctx context.Context,
+	eventType entity.EventType,
+	aggregateType string,
+	aggregateID int64,
+	payload interface{},
+) (*entity.Event, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("event payload: %w", err)
+	}
+
+	return &entity.Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       b,
+		RequestID:     reqctx.FromContext(ctx).RequestID,
+	}, nil
+}
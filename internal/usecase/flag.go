@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const _flagKeyMaxLen = 64
+
+var (
+	// ErrFlagNotFound is returned when the feature flag does not exist.
+	ErrFlagNotFound = errors.New("feature flag not found")
+	// ErrInvalidFlag is returned when the key, rollout or targets of a flag are not acceptable.
+	ErrInvalidFlag = errors.New("invalid feature flag")
+)
+
+// FlagUseCaseImpl implements the FlagUseCase interface. Flags are evaluated
+// from a cache of all flags, which changes made here update at once and
+// Refresh replaces.
+type FlagUseCaseImpl struct {
+	repo FlagRepo
+
+	mu    sync.RWMutex
+	flags map[string]*entity.FeatureFlag
+	// gen counts the changes to flags, so that Refresh does not overwrite
+	// a change made while it was reading.
+	gen uint64
+}
+
+// NewFlagUseCase creates a new FlagUseCase with an empty cache; call
+// Refresh to load it.
+func NewFlagUseCase(repo FlagRepo) FlagUseCase {
+	return &FlagUseCaseImpl{
+		repo:  repo,
+		flags: make(map[string]*entity.FeatureFlag),
+	}
+}
+
+// IsEnabled reports whether the flag is on for the user and role of ctx.
+func (uc *FlagUseCaseImpl) IsEnabled(ctx context.Context, key string) bool {
+	meta := reqctx.FromContext(ctx)
+
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+
+	flag, ok := uc.flags[key]
+
+	return ok && flag.EnabledFor(meta.UserID, meta.Role)
+}
+
+// EnabledFlags returns the keys of the flags on for the user of ctx, sorted.
+func (uc *FlagUseCaseImpl) EnabledFlags(ctx context.Context) []string {
+	meta := reqctx.FromContext(ctx)
+	keys := []string{}
+
+	uc.mu.RLock()
+	for key, flag := range uc.flags {
+		if flag.EnabledFor(meta.UserID, meta.Role) {
+			keys = append(keys, key)
+		}
+	}
+	uc.mu.RUnlock()
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ListFlags returns all flags from the database, sorted by key.
+func (uc *FlagUseCaseImpl) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	return uc.repo.ListFlags(ctx)
+}
+
+// GetFlag returns the flag from the database.
+func (uc *FlagUseCaseImpl) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	return uc.repo.GetFlag(ctx, key)
+}
+
+// SetFlag creates or replaces the flag, audited as a change by the user of ctx.
+func (uc *FlagUseCaseImpl) SetFlag(ctx context.Context, flag *entity.FeatureFlag) error {
+	err := validateFlag(flag)
+	if err != nil {
+		return err
+	}
+
+	if flag.Users == nil {
+		flag.Users = []int64{}
+	}
+
+	if flag.Roles == nil {
+		flag.Roles = []string{}
+	}
+
+	err = uc.repo.ExecuteTx(ctx, func(repo FlagRepo) error {
+		before, err := repo.GetFlag(ctx, flag.Key)
+		if err != nil && !errors.Is(err, ErrFlagNotFound) {
+			return err
+		}
+
+		err = repo.SaveFlag(ctx, flag)
+		if err != nil {
+			return err
+		}
+
+		return appendAudit(ctx, repo.Audit(), reqctx.FromContext(ctx).UserID, entity.AuditActionFlagUpdate,
+			entity.AuditEntityFlag, 0, before, flag)
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.store(flag.Key, copyFlag(flag))
+
+	return nil
+}
+
+// DeleteFlag deletes the flag, which turns it off, audited as a change by
+// the user of ctx.
+func (uc *FlagUseCaseImpl) DeleteFlag(ctx context.Context, key string) error {
+	err := uc.repo.ExecuteTx(ctx, func(repo FlagRepo) error {
+		before, err := repo.GetFlag(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		err = repo.DeleteFlag(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		return appendAudit(ctx, repo.Audit(), reqctx.FromContext(ctx).UserID, entity.AuditActionFlagDelete,
+			entity.AuditEntityFlag, 0, before, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.store(key, nil)
+
+	return nil
+}
+
+// Refresh replaces the cache with the flags in the database. Flags changed
+// here while it reads are reloaded by the next call instead.
+func (uc *FlagUseCaseImpl) Refresh(ctx context.Context) error {
+	uc.mu.RLock()
+	gen := uc.gen
+	uc.mu.RUnlock()
+
+	list, err := uc.repo.ListFlags(ctx)
+	if err != nil {
+		return fmt.Errorf("FlagUseCase - Refresh - repo.ListFlags: %w", err)
+	}
+
+	flags := make(map[string]*entity.FeatureFlag, len(list))
+	for _, f := range list {
+		flags[f.Key] = f
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.gen == gen {
+		uc.flags = flags
+	}
+
+	return nil
+}
+
+// store caches flag under key, or removes key when flag is nil.
+func (uc *FlagUseCaseImpl) store(key string, flag *entity.FeatureFlag) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if flag == nil {
+		delete(uc.flags, key)
+	} else {
+		uc.flags[key] = flag
+	}
+
+	uc.gen++
+}
+
+func validateFlag(flag *entity.FeatureFlag) error {
+	if !validFlagKey(flag.Key) {
+		return fmt.Errorf("%w: key must be 1 to %d lowercase letters, digits and \"_.-\"",
+			ErrInvalidFlag, _flagKeyMaxLen)
+	}
+
+	if flag.Rollout < 0 || flag.Rollout > 100 {
+		return fmt.Errorf("%w: rollout %d is not a percentage", ErrInvalidFlag, flag.Rollout)
+	}
+
+	for _, id := range flag.Users {
+		if id <= 0 {
+			return fmt.Errorf("%w: invalid user ID %d", ErrInvalidFlag, id)
+		}
+	}
+
+	for _, role := range flag.Roles {
+		if !entity.IsRole(role) {
+			return fmt.Errorf("%w: unknown role %q", ErrInvalidFlag, role)
+		}
+	}
+
+	return nil
+}
+
+func validFlagKey(key string) bool {
+	if key == "" || len(key) > _flagKeyMaxLen {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '_', c == '.', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func copyFlag(f *entity.FeatureFlag) *entity.FeatureFlag {
+	c := *f
+	c.Users = append([]int64{}, f.Users...)
+	c.Roles = append([]string{}, f.Roles...)
+
+	return &c
+}
@@ -0,0 +1,213 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+type FlagUseCaseSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	ctx  context.Context
+
+	// Mocked units
+	mockFlagRepo  *MockFlagRepo
+	mockAuditRepo *MockAuditRepo
+
+	// Tested usecase
+	flagUseCase usecase.FlagUseCase
+}
+
+func (t *FlagUseCaseSuite) SetupTest() {
+	t.ctx = reqctx.WithUser(context.Background(), 7, entity.RoleAdmin)
+	t.ctrl = gomock.NewController(t.T())
+	t.mockFlagRepo = NewMockFlagRepo(t.ctrl)
+	t.mockAuditRepo = NewMockAuditRepo(t.ctrl)
+
+	t.flagUseCase = usecase.NewFlagUseCase(t.mockFlagRepo)
+}
+
+func TestFlagUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(FlagUseCaseSuite))
+}
+
+// expectFlagTx makes ExecuteTx run its callback against the same mocked repo.
+func (t *FlagUseCaseSuite) expectFlagTx() {
+	t.mockFlagRepo.EXPECT().ExecuteTx(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(usecase.FlagRepo) error) error {
+			return fn(t.mockFlagRepo)
+		},
+	)
+}
+
+// refresh loads flags into the cache.
+func (t *FlagUseCaseSuite) refresh(flags ...*entity.FeatureFlag) {
+	t.mockFlagRepo.EXPECT().ListFlags(gomock.Any()).Return(flags, nil)
+	t.Require().NoError(t.flagUseCase.Refresh(t.ctx))
+}
+
+func (t *FlagUseCaseSuite) TestIsEnabled_EvaluatesTheCache() {
+	t.refresh(
+		&entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: true, Rollout: 100},
+		&entity.FeatureFlag{Key: entity.FlagRoyalties, Enabled: false, Rollout: 100},
+		&entity.FeatureFlag{Key: entity.FlagPurchaseV2, Enabled: true, Roles: []string{entity.RoleAdmin}},
+	)
+
+	user := reqctx.WithUser(context.Background(), 8, entity.RoleUser)
+
+	t.True(t.flagUseCase.IsEnabled(t.ctx, entity.FlagAuctions))
+	t.False(t.flagUseCase.IsEnabled(t.ctx, entity.FlagRoyalties))
+	t.True(t.flagUseCase.IsEnabled(t.ctx, entity.FlagPurchaseV2))
+	t.False(t.flagUseCase.IsEnabled(user, entity.FlagPurchaseV2))
+	t.False(t.flagUseCase.IsEnabled(t.ctx, "unknown"))
+
+	t.Equal([]string{entity.FlagAuctions, entity.FlagPurchaseV2}, t.flagUseCase.EnabledFlags(t.ctx))
+	t.Equal([]string{entity.FlagAuctions}, t.flagUseCase.EnabledFlags(user))
+}
+
+func (t *FlagUseCaseSuite) TestIsEnabled_RolloutIsStablePerUser() {
+	t.refresh(&entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: true, Rollout: 30})
+
+	on := 0
+
+	for id := int64(1); id <= 1000; id++ {
+		ctx := reqctx.WithUser(context.Background(), id, entity.RoleUser)
+
+		enabled := t.flagUseCase.IsEnabled(ctx, entity.FlagAuctions)
+		t.Equal(enabled, t.flagUseCase.IsEnabled(ctx, entity.FlagAuctions))
+
+		if enabled {
+			on++
+		}
+	}
+
+	t.InDelta(300, on, 60)
+	t.False(t.flagUseCase.IsEnabled(context.Background(), entity.FlagAuctions), "anonymous request")
+}
+
+func (t *FlagUseCaseSuite) TestSetFlag() {
+	before := &entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: false}
+	flag := &entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: true, Users: []int64{8}}
+
+	t.expectFlagTx()
+	t.mockFlagRepo.EXPECT().GetFlag(t.ctx, entity.FlagAuctions).Return(before, nil)
+	t.mockFlagRepo.EXPECT().SaveFlag(t.ctx, flag).Return(nil)
+	t.mockFlagRepo.EXPECT().Audit().Return(t.mockAuditRepo)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionFlagUpdate, entry.Action)
+			t.Equal(entity.AuditEntityFlag, entry.EntityType)
+			t.Equal(int64(7), entry.ActorID)
+			t.Contains(string(entry.Before), `"enabled":false`)
+			t.Contains(string(entry.After), `"enabled":true`)
+
+			return nil
+		},
+	)
+
+	err := t.flagUseCase.SetFlag(t.ctx, flag)
+
+	t.NoError(err)
+	t.Equal([]string{}, flag.Roles)
+
+	// The change applies at once, without waiting for a refresh.
+	user := reqctx.WithUser(context.Background(), 8, entity.RoleUser)
+	t.True(t.flagUseCase.IsEnabled(user, entity.FlagAuctions))
+
+	// Changing the argument afterwards does not change the cache.
+	flag.Users[0] = 9
+	t.True(t.flagUseCase.IsEnabled(user, entity.FlagAuctions))
+}
+
+func (t *FlagUseCaseSuite) TestSetFlag_Invalid() {
+	tests := []struct {
+		name string
+		flag *entity.FeatureFlag
+	}{
+		{name: "empty key", flag: &entity.FeatureFlag{}},
+		{name: "uppercase key", flag: &entity.FeatureFlag{Key: "Auctions"}},
+		{name: "rollout over 100", flag: &entity.FeatureFlag{Key: "auctions", Rollout: 101}},
+		{name: "negative rollout", flag: &entity.FeatureFlag{Key: "auctions", Rollout: -1}},
+		{name: "invalid user", flag: &entity.FeatureFlag{Key: "auctions", Users: []int64{0}}},
+		{name: "unknown role", flag: &entity.FeatureFlag{Key: "auctions", Roles: []string{"root"}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func() {
+			err := t.flagUseCase.SetFlag(t.ctx, tc.flag)
+			t.ErrorIs(err, usecase.ErrInvalidFlag)
+		})
+	}
+}
+
+func (t *FlagUseCaseSuite) TestSetFlag_FailedTxKeepsTheCache() {
+	errSave := errors.New("save failed")
+	flag := &entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: true, Rollout: 100}
+
+	t.expectFlagTx()
+	t.mockFlagRepo.EXPECT().GetFlag(t.ctx, entity.FlagAuctions).Return(nil, usecase.ErrFlagNotFound)
+	t.mockFlagRepo.EXPECT().SaveFlag(t.ctx, flag).Return(errSave)
+
+	err := t.flagUseCase.SetFlag(t.ctx, flag)
+
+	t.ErrorIs(err, errSave)
+	t.False(t.flagUseCase.IsEnabled(t.ctx, entity.FlagAuctions))
+}
+
+func (t *FlagUseCaseSuite) TestDeleteFlag() {
+	flag := &entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: true, Rollout: 100}
+	t.refresh(flag)
+
+	t.expectFlagTx()
+	t.mockFlagRepo.EXPECT().GetFlag(t.ctx, entity.FlagAuctions).Return(flag, nil)
+	t.mockFlagRepo.EXPECT().DeleteFlag(t.ctx, entity.FlagAuctions).Return(nil)
+	t.mockFlagRepo.EXPECT().Audit().Return(t.mockAuditRepo)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(entity.AuditActionFlagDelete, entry.Action)
+			t.Nil(entry.After)
+
+			return nil
+		},
+	)
+
+	err := t.flagUseCase.DeleteFlag(t.ctx, entity.FlagAuctions)
+
+	t.NoError(err)
+	t.False(t.flagUseCase.IsEnabled(t.ctx, entity.FlagAuctions))
+}
+
+func (t *FlagUseCaseSuite) TestDeleteFlag_NotFound() {
+	t.expectFlagTx()
+	t.mockFlagRepo.EXPECT().GetFlag(t.ctx, "missing").Return(nil, usecase.ErrFlagNotFound)
+
+	err := t.flagUseCase.DeleteFlag(t.ctx, "missing")
+
+	t.ErrorIs(err, usecase.ErrFlagNotFound)
+}
+
+func (t *FlagUseCaseSuite) TestRefresh_ReplacesTheCache() {
+	t.refresh(&entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: true, Rollout: 100})
+	t.refresh(&entity.FeatureFlag{Key: entity.FlagRoyalties, Enabled: true, Rollout: 100})
+
+	t.False(t.flagUseCase.IsEnabled(t.ctx, entity.FlagAuctions))
+	t.True(t.flagUseCase.IsEnabled(t.ctx, entity.FlagRoyalties))
+}
+
+func (t *FlagUseCaseSuite) TestRefresh_ErrorKeepsTheCache() {
+	t.refresh(&entity.FeatureFlag{Key: entity.FlagAuctions, Enabled: true, Rollout: 100})
+
+	t.mockFlagRepo.EXPECT().ListFlags(t.ctx).Return(nil, errors.New("connection refused"))
+
+	t.Error(t.flagUseCase.Refresh(t.ctx))
+	t.True(t.flagUseCase.IsEnabled(t.ctx, entity.FlagAuctions))
+}
@@ -0,0 +1,86 @@
+// Package flagtest provides feature flags for tests of use cases taking
+// usecase.FeatureFlags, so that both sides of a flag can be tested without
+// a database.
+package flagtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// Flags are feature flags forced on or off. Flags not forced are looked up
+// in the wrapped flags, or are off.
+type Flags struct {
+	base usecase.FeatureFlags
+
+	mu     sync.RWMutex
+	forced map[string]bool
+}
+
+var _ usecase.FeatureFlags = (*Flags)(nil)
+
+// New returns flags with the given keys on and every other flag off.
+func New(on ...string) *Flags {
+	f := Wrap(nil)
+	for _, key := range on {
+		f.Set(key, true)
+	}
+
+	return f
+}
+
+// Wrap returns flags evaluated by base, such as a FlagUseCase, unless forced.
+func Wrap(base usecase.FeatureFlags) *Flags {
+	return &Flags{
+		base:   base,
+		forced: make(map[string]bool),
+	}
+}
+
+// IsEnabled -.
+func (f *Flags) IsEnabled(ctx context.Context, key string) bool {
+	f.mu.RLock()
+	on, ok := f.forced[key]
+	f.mu.RUnlock()
+
+	switch {
+	case ok:
+		return on
+	case f.base != nil:
+		return f.base.IsEnabled(ctx, key)
+	default:
+		return false
+	}
+}
+
+// Set forces the flag on or off for every user.
+func (f *Flags) Set(key string, on bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.forced[key] = on
+}
+
+// Force forces the flag on or off until the end of the test, then restores it.
+func (f *Flags) Force(t testing.TB, key string, on bool) {
+	t.Helper()
+
+	f.mu.Lock()
+	prev, wasForced := f.forced[key]
+	f.forced[key] = on
+	f.mu.Unlock()
+
+	t.Cleanup(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if wasForced {
+			f.forced[key] = prev
+		} else {
+			delete(f.forced, key)
+		}
+	})
+}
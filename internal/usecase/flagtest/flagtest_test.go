@@ -0,0 +1,39 @@
+package flagtest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase/flagtest"
+)
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	flags := flagtest.New(entity.FlagAuctions)
+
+	assert.True(t, flags.IsEnabled(ctx, entity.FlagAuctions))
+	assert.False(t, flags.IsEnabled(ctx, entity.FlagRoyalties))
+
+	flags.Set(entity.FlagAuctions, false)
+	assert.False(t, flags.IsEnabled(ctx, entity.FlagAuctions))
+}
+
+func TestForce_RestoresAfterTheTest(t *testing.T) {
+	ctx := context.Background()
+	base := flagtest.New(entity.FlagAuctions)
+	flags := flagtest.Wrap(base)
+
+	t.Run("forced", func(t *testing.T) {
+		flags.Force(t, entity.FlagAuctions, false)
+		flags.Force(t, entity.FlagRoyalties, true)
+
+		assert.False(t, flags.IsEnabled(ctx, entity.FlagAuctions))
+		assert.True(t, flags.IsEnabled(ctx, entity.FlagRoyalties))
+	})
+
+	assert.True(t, flags.IsEnabled(ctx, entity.FlagAuctions), "wrapped flags apply again")
+	assert.False(t, flags.IsEnabled(ctx, entity.FlagRoyalties))
+}
@@ -3,6 +3,7 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/appxpy/hive-test/internal/entity"
 )
@@ -13,12 +14,42 @@ import (
 type UserUseCase interface {
 	Register(ctx context.Context, username, password string) error
 	Login(ctx context.Context, username, password string) (string, error)
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error)
+	CheckSession(ctx context.Context, userID int64, issuedAt time.Time) error
 }
 
 // UserRepo defines methods to interact with the users in the database.
 type UserRepo interface {
 	CreateUser(ctx context.Context, user *entity.User) error
 	GetUserByUsername(ctx context.Context, username string) (*entity.User, error)
+	GetUserByID(ctx context.Context, userID int64) (*entity.User, error)
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error)
+	ListUsers(ctx context.Context, afterID int64, limit uint64) ([]*entity.User, error)
+	UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error
+	RevokeSessions(ctx context.Context, userID int64, at time.Time) error
+	Audit() AuditRepo
+	Outbox() OutboxRepo
+	ExecuteTx(ctx context.Context, fn func(repo UserRepo) error) error
+}
+
+// Repos is the set of repositories bound to one unit of work.
+type Repos interface {
+	Users() UserRepo
+	Assets() AssetRepo
+	Audit() AuditRepo
+	Outbox() OutboxRepo
+	Webhooks() WebhookRepo
+	Notifications() NotificationRepo
+}
+
+// UnitOfWork runs fn in one transaction spanning all repositories, committing
+// it when fn returns nil. Called again with the ctx passed to fn, it runs in a
+// savepoint of that transaction instead, at the outer isolation level.
+// Serialization failures and deadlocks restart the whole transaction, so fn
+// may run more than once.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repos) error) error
+	DoIsolated(ctx context.Context, level IsolationLevel, fn func(ctx context.Context, repos Repos) error) error
 }
 
 // AssetUseCase defines methods related to asset operations.
@@ -27,6 +58,9 @@ type AssetUseCase interface {
 	RemoveAsset(ctx context.Context, assetID, userID int64) error
 	PurchaseAsset(ctx context.Context, assetID, buyerID int64) error
 	GetAssetsByUser(ctx context.Context, userID int64) ([]*entity.Asset, error)
+	GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error)
+	GetAssetsByUsers(ctx context.Context, userIDs []int64) ([]*entity.Asset, error)
+	ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error)
 }
 
 // AssetRepo defines methods to interact with assets in the database.
@@ -35,6 +69,166 @@ type AssetRepo interface {
 	DeleteAsset(ctx context.Context, assetID, userID int64) error
 	GetAssetByID(ctx context.Context, assetID int64, forUpdate bool) (*entity.Asset, error)
 	GetAssetsByUserID(ctx context.Context, userID int64) ([]*entity.Asset, error)
+	GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error)
+	GetAssetsByUserIDs(ctx context.Context, userIDs []int64) ([]*entity.Asset, error)
+	ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error)
+	CountAssets(ctx context.Context) (int64, error)
 	UpdateAssetOwner(ctx context.Context, assetID, newOwnerID int64) error
+	Audit() AuditRepo
+	Outbox() OutboxRepo
+	Notifications() NotificationRepo
 	ExecuteTx(ctx context.Context, fn func(repo AssetRepo) error) error
 }
+
+// AdminUseCase defines operator actions, run from the hivectl command.
+type AdminUseCase interface {
+	CreateUser(ctx context.Context, username, password, role string) (*entity.User, error)
+	ResetPassword(ctx context.Context, username, password string) error
+	RevokeSessions(ctx context.Context, username string) error
+	ListUsers(ctx context.Context, afterID int64, limit uint64) ([]*entity.User, error)
+	ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error)
+	TransferAsset(ctx context.Context, assetID int64, toUsername string) (*entity.Asset, error)
+	DeleteAsset(ctx context.Context, assetID int64) (*entity.Asset, error)
+	CheckLedger(ctx context.Context) (*entity.LedgerReport, error)
+	ExportData(ctx context.Context) (*entity.DataExport, error)
+}
+
+// AuditUseCase defines methods to record, query and verify the audit log.
+type AuditUseCase interface {
+	ListEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error)
+	VerifyChain(ctx context.Context) (*entity.AuditVerification, error)
+	RecordConfigReload(ctx context.Context, before, after map[string]string) error
+}
+
+// AuditRepo defines methods to interact with the audit log in the database.
+// AppendEntry fills PrevHash, Hash, CreatedAt and ID of the entry.
+type AuditRepo interface {
+	AppendEntry(ctx context.Context, entry *entity.AuditEntry) error
+	ListEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error)
+}
+
+// OutboxRepo defines methods to interact with the transactional outbox.
+// AddEvent must run in the transaction of the change it describes.
+// ClaimPending returns at most one event per aggregate, the oldest one, and
+// leases them by moving their next attempt lease into the future, so that
+// other relays skip them while they are published.
+type OutboxRepo interface {
+	AddEvent(ctx context.Context, event *entity.Event) error
+	ClaimPending(ctx context.Context, limit uint64, lease time.Duration) ([]*entity.Event, error)
+	MarkPublished(ctx context.Context, eventID int64) error
+	MarkFailed(ctx context.Context, eventID int64, lastErr string, nextAttemptAt time.Time) error
+	MoveToDeadLetter(ctx context.Context, event *entity.Event, lastErr string) error
+	// OldestPending returns when the oldest unpublished event was added, or
+	// the zero time when there is none.
+	OldestPending(ctx context.Context) (time.Time, error)
+	ExecuteTx(ctx context.Context, fn func(repo OutboxRepo) error) error
+}
+
+// EventPublisher delivers domain events to consumers. Publish must return
+// only after the event is accepted, since the relay deletes it afterwards.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *entity.Event) error
+}
+
+// WebhookUseCase defines methods related to user webhooks.
+type WebhookUseCase interface {
+	CreateWebhook(ctx context.Context, userID int64, url string, events []string) (*entity.Webhook, error)
+	ListWebhooks(ctx context.Context, userID int64) ([]*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID, userID int64) error
+	SetWebhookEnabled(ctx context.Context, webhookID, userID int64, enabled bool) error
+	ListDeliveries(ctx context.Context, webhookID, userID int64, limit uint64) ([]*entity.WebhookDelivery, error)
+	SendTestEvent(ctx context.Context, webhookID, userID int64) (*entity.WebhookDelivery, error)
+	HandleEvent(ctx context.Context, event *entity.Event) error
+	DispatchPending(ctx context.Context) (int, error)
+}
+
+// WebhookRepo defines methods to interact with webhooks and their deliveries in the database.
+// ClaimDueDeliveries hides the returned deliveries from other callers until lease expires.
+type WebhookRepo interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) error
+	GetWebhook(ctx context.Context, webhookID int64) (*entity.Webhook, error)
+	ListWebhooksByUser(ctx context.Context, userID int64) ([]*entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID, userID int64) error
+	SetWebhookEnabled(ctx context.Context, webhookID int64, enabled bool, reason string) error
+	RecordWebhookResult(ctx context.Context, webhookID int64, success bool, disableAfter int) (disabled bool, err error)
+	CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]*entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit uint64) ([]*entity.WebhookDelivery, error)
+}
+
+// WebhookSender POSTs a signed payload to a webhook endpoint and returns the
+// response status code. A non-2xx status is not an error. CheckURL reports
+// whether the sender may deliver to url at all.
+type WebhookSender interface {
+	CheckURL(ctx context.Context, url string) error
+	Send(ctx context.Context, url, secret string, body []byte) (int, error)
+}
+
+// NotificationUseCase defines methods related to user notifications.
+type NotificationUseCase interface {
+	HandleEvent(ctx context.Context, event *entity.Event) error
+	Subscribe(ctx context.Context, userID, lastEventID int64) (<-chan *entity.Notification, error)
+	ListNotifications(ctx context.Context, userID int64, filter entity.NotificationFilter) ([]*entity.Notification, error)
+	UnreadCount(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error)
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	GetPreferences(ctx context.Context, userID int64) ([]*entity.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, userID int64, prefs map[string]bool) ([]*entity.NotificationPreference, error)
+	SendAdminMessage(ctx context.Context, adminID, userID int64, message string) (*entity.Notification, error)
+	PurgeRead(ctx context.Context) (int, error)
+}
+
+// NotificationRepo defines methods to interact with the notification inbox in the database.
+// CreateNotification skips types the user has turned off, leaving the ID zero.
+// ListPreferences returns only the stored preferences; other types are on.
+type NotificationRepo interface {
+	CreateNotification(ctx context.Context, notification *entity.Notification) error
+	ListNotifications(ctx context.Context, userID int64, filter entity.NotificationFilter) ([]*entity.Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error)
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	ListPreferences(ctx context.Context, userID int64) ([]*entity.NotificationPreference, error)
+	SetPreferences(ctx context.Context, userID int64, prefs []*entity.NotificationPreference) error
+	DeleteReadBefore(ctx context.Context, before time.Time, limit uint64) (int64, error)
+}
+
+// NotificationHub fans notifications out to the subscribed clients of a user.
+// Subscribe only delivers what is published after it. The returned channel
+// is closed when ctx is done or when the subscriber falls too far behind, in
+// which case the client should resubscribe.
+type NotificationHub interface {
+	Publish(ctx context.Context, notification *entity.Notification) error
+	Subscribe(ctx context.Context, userID int64) (<-chan *entity.Notification, error)
+}
+
+// FeatureFlags reports whether a feature is on for the user of ctx, from a
+// cache, so it is cheap enough to call on every request. Use cases take it
+// to gate the changes being rolled out; an unknown flag is off.
+type FeatureFlags interface {
+	IsEnabled(ctx context.Context, key string) bool
+}
+
+// FlagUseCase defines methods to evaluate and manage feature flags.
+// Refresh reloads the cache from the database, for changes made by other
+// instances.
+type FlagUseCase interface {
+	FeatureFlags
+	EnabledFlags(ctx context.Context) []string
+	ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error)
+	GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error)
+	SetFlag(ctx context.Context, flag *entity.FeatureFlag) error
+	DeleteFlag(ctx context.Context, key string) error
+	Refresh(ctx context.Context) error
+}
+
+// FlagRepo defines methods to interact with feature flags in the database.
+// SaveFlag creates or replaces the flag and fills UpdatedAt.
+type FlagRepo interface {
+	ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error)
+	GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error)
+	SaveFlag(ctx context.Context, flag *entity.FeatureFlag) error
+	DeleteFlag(ctx context.Context, key string) error
+	Audit() AuditRepo
+	ExecuteTx(ctx context.Context, fn func(repo FlagRepo) error) error
+}
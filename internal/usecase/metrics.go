@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_metricsNamespace = "hive"
+
+	// _listingsCountTimeout bounds the asset count of one scrape.
+	_listingsCountTimeout = 3 * time.Second
+)
+
+// Failure reasons of hive_purchase_failures_total.
+const (
+	_purchaseNotFound = "not_found"
+	_purchaseOwnAsset = "own_asset"
+	_purchaseCanceled = "canceled"
+	_purchaseError    = "error"
+)
+
+// Results of hive_logins_total.
+const (
+	_loginSuccess = "success"
+	_loginFailure = "failure"
+)
+
+// Business metrics. They count whether registered or not, so the use cases
+// do not depend on a registry; RegisterMetrics exports them.
+var (
+	registrations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: _metricsNamespace,
+		Name:      "registrations_total",
+		Help:      "Registered users.",
+	})
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _metricsNamespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+	purchases = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: _metricsNamespace,
+		Name:      "purchases_total",
+		Help:      "Completed asset purchases.",
+	})
+	purchaseVolume = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: _metricsNamespace,
+		Name:      "purchase_volume_total",
+		Help:      "Sum of the prices of the purchased assets.",
+	})
+	purchaseFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: _metricsNamespace,
+		Name:      "purchase_failures_total",
+		Help:      "Failed asset purchases by reason.",
+	}, []string{"reason"})
+	purchaseLockWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: _metricsNamespace,
+		Name:      "purchase_lock_wait_seconds",
+		Help:      "Time a purchase waited for the row lock of the asset.",
+		Buckets:   []float64{.0005, .001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	})
+)
+
+func init() {
+	// Export the known labels at zero, so rates work from the first scrape.
+	for _, result := range []string{_loginSuccess, _loginFailure} {
+		logins.WithLabelValues(result)
+	}
+
+	for _, reason := range []string{_purchaseNotFound, _purchaseOwnAsset, _purchaseCanceled, _purchaseError} {
+		purchaseFailures.WithLabelValues(reason)
+	}
+}
+
+// RegisterMetrics registers the business metrics on reg. The number of
+// active listings is counted with assets on every scrape.
+func RegisterMetrics(reg prometheus.Registerer, assets AssetRepo) error {
+	for _, c := range []prometheus.Collector{
+		registrations,
+		logins,
+		purchases,
+		purchaseVolume,
+		purchaseFailures,
+		purchaseLockWait,
+		newListingsCollector(assets),
+	} {
+		if err := reg.Register(c); err != nil {
+			return fmt.Errorf("usecase - RegisterMetrics - reg.Register: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// purchaseFailed counts a failed purchase under the reason of err.
+func purchaseFailed(err error) {
+	reason := _purchaseError
+
+	switch {
+	case errors.Is(err, ErrAssetNotFound):
+		reason = _purchaseNotFound
+	case errors.Is(err, ErrOwnAsset):
+		reason = _purchaseOwnAsset
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		reason = _purchaseCanceled
+	}
+
+	purchaseFailures.WithLabelValues(reason).Inc()
+}
+
+// listingsCollector exports the number of assets on sale.
+type listingsCollector struct {
+	assets AssetRepo
+
+	listings *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*listingsCollector)(nil)
+
+func newListingsCollector(assets AssetRepo) *listingsCollector {
+	return &listingsCollector{
+		assets: assets,
+		listings: prometheus.NewDesc(prometheus.BuildFQName(_metricsNamespace, "", "active_listings"),
+			"Assets on sale.", nil, nil),
+	}
+}
+
+// Describe -.
+func (c *listingsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.listings
+}
+
+// Collect -.
+func (c *listingsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), _listingsCountTimeout)
+	defer cancel()
+
+	// Leave the gauge out rather than fail the whole scrape while the
+	// database is unavailable.
+	count, err := c.assets.CountAssets(ctx)
+	if err != nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.listings, prometheus.GaugeValue, float64(count))
+}
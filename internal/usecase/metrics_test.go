@@ -0,0 +1,185 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/flagtest"
+)
+
+type MetricsSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	ctx  context.Context
+	reg  *prometheus.Registry
+
+	mockUnitOfWork *MockUnitOfWork
+	mockRepos      *MockRepos
+	mockAssetRepo  *MockAssetRepo
+	mockAuditRepo  *MockAuditRepo
+	mockOutboxRepo *MockOutboxRepo
+	mockNotifyRepo *MockNotificationRepo
+
+	assetUseCase usecase.AssetUseCase
+}
+
+func (t *MetricsSuite) SetupTest() {
+	t.ctrl = gomock.NewController(t.T())
+	t.ctx = context.Background()
+	t.mockUnitOfWork = NewMockUnitOfWork(t.ctrl)
+	t.mockRepos = NewMockRepos(t.ctrl)
+	t.mockAssetRepo = NewMockAssetRepo(t.ctrl)
+	t.mockAuditRepo = NewMockAuditRepo(t.ctrl)
+	t.mockOutboxRepo = NewMockOutboxRepo(t.ctrl)
+	t.mockNotifyRepo = NewMockNotificationRepo(t.ctrl)
+	t.assetUseCase = usecase.NewAssetUseCase(t.mockAssetRepo, t.mockUnitOfWork, flagtest.New())
+
+	t.mockRepos.EXPECT().Assets().Return(t.mockAssetRepo).AnyTimes()
+	t.mockRepos.EXPECT().Audit().Return(t.mockAuditRepo).AnyTimes()
+	t.mockRepos.EXPECT().Outbox().Return(t.mockOutboxRepo).AnyTimes()
+	t.mockRepos.EXPECT().Notifications().Return(t.mockNotifyRepo).AnyTimes()
+	t.mockUnitOfWork.EXPECT().Do(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(context.Context, usecase.Repos) error) error {
+			return fn(ctx, t.mockRepos)
+		},
+	).AnyTimes()
+
+	listings := NewMockAssetRepo(t.ctrl)
+	listings.EXPECT().CountAssets(gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+	t.reg = t.newRegistry(listings)
+}
+
+func (t *MetricsSuite) newRegistry(listings usecase.AssetRepo) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	t.Require().NoError(usecase.RegisterMetrics(reg, listings))
+
+	return reg
+}
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(MetricsSuite))
+}
+
+// value returns the value of the counter or gauge name with the label
+// pairs, or of the sample count of the histogram name.
+func (t *MetricsSuite) value(name string, labels ...string) float64 {
+	families, err := t.reg.Gather()
+	t.Require().NoError(err)
+
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+
+		for _, m := range f.GetMetric() {
+			if !hasLabels(m.GetLabel(), labels) {
+				continue
+			}
+
+			switch {
+			case m.Counter != nil:
+				return m.GetCounter().GetValue()
+			case m.Gauge != nil:
+				return m.GetGauge().GetValue()
+			case m.Histogram != nil:
+				return float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	return 0
+}
+
+func hasLabels[L interface {
+	GetName() string
+	GetValue() string
+}](got []L, pairs []string) bool {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		found := false
+		for _, l := range got {
+			if l.GetName() == pairs[i] && l.GetValue() == pairs[i+1] {
+				found = true
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *MetricsSuite) TestPurchase_CountsVolumeAndLockWait() {
+	purchases := t.value("hive_purchases_total")
+	volume := t.value("hive_purchase_volume_total")
+	waits := t.value("hive_purchase_lock_wait_seconds")
+
+	t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, int64(1), true).Return(&entity.Asset{ID: 1, UserID: 3, Price: 12.5}, nil)
+	t.mockAssetRepo.EXPECT().UpdateAssetOwner(t.ctx, int64(1), int64(2)).Return(nil)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).Return(nil)
+	t.mockNotifyRepo.EXPECT().CreateNotification(t.ctx, gomock.Any()).Return(nil).Times(2)
+	t.mockOutboxRepo.EXPECT().AddEvent(t.ctx, gomock.Any()).Return(nil)
+
+	t.Require().NoError(t.assetUseCase.PurchaseAsset(t.ctx, 1, 2))
+
+	t.Equal(purchases+1, t.value("hive_purchases_total"))
+	t.Equal(volume+12.5, t.value("hive_purchase_volume_total"))
+	t.Equal(waits+1, t.value("hive_purchase_lock_wait_seconds"))
+}
+
+func (t *MetricsSuite) TestPurchase_CountsFailuresByReason() {
+	tests := []struct {
+		reason string
+		asset  *entity.Asset
+		err    error
+	}{
+		{"not_found", nil, nil},
+		{"own_asset", &entity.Asset{ID: 1, UserID: 2}, nil},
+		{"error", nil, assert.AnError},
+	}
+
+	for _, tc := range tests {
+		failures := t.value("hive_purchase_failures_total", "reason", tc.reason)
+		purchases := t.value("hive_purchases_total")
+
+		t.mockAssetRepo.EXPECT().GetAssetByID(t.ctx, int64(1), true).Return(tc.asset, tc.err)
+
+		t.Error(t.assetUseCase.PurchaseAsset(t.ctx, 1, 2))
+
+		t.Equal(failures+1, t.value("hive_purchase_failures_total", "reason", tc.reason), tc.reason)
+		t.Equal(purchases, t.value("hive_purchases_total"), tc.reason)
+	}
+}
+
+func (t *MetricsSuite) TestActiveListings() {
+	listings := NewMockAssetRepo(t.ctrl)
+	listings.EXPECT().CountAssets(gomock.Any()).Return(int64(7), nil)
+
+	t.NoError(testutil.GatherAndCompare(t.newRegistry(listings), strings.NewReader(`
+# HELP hive_active_listings Assets on sale.
+# TYPE hive_active_listings gauge
+hive_active_listings 7
+`), "hive_active_listings"))
+}
+
+func (t *MetricsSuite) TestActiveListings_LeftOutOnError() {
+	listings := NewMockAssetRepo(t.ctrl)
+	listings.EXPECT().CountAssets(gomock.Any()).Return(int64(0), assert.AnError)
+
+	count, err := testutil.GatherAndCount(t.newRegistry(listings), "hive_active_listings")
+
+	t.NoError(err)
+	t.Zero(count)
+}
@@ -12,6 +12,7 @@ package usecase_test
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/appxpy/hive-test/internal/entity"
 	usecase "github.com/appxpy/hive-test/internal/usecase"
@@ -41,6 +42,35 @@ func (m *MockUserUseCase) EXPECT() *MockUserUseCaseMockRecorder {
 	return m.recorder
 }
 
+// CheckSession mocks base method.
+func (m *MockUserUseCase) CheckSession(ctx context.Context, userID int64, issuedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSession", ctx, userID, issuedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSession indicates an expected call of CheckSession.
+func (mr *MockUserUseCaseMockRecorder) CheckSession(ctx, userID, issuedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockUserUseCase)(nil).CheckSession), ctx, userID, issuedAt)
+}
+
+// GetUsersByIDs mocks base method.
+func (m *MockUserUseCase) GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByIDs indicates an expected call of GetUsersByIDs.
+func (mr *MockUserUseCaseMockRecorder) GetUsersByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByIDs", reflect.TypeOf((*MockUserUseCase)(nil).GetUsersByIDs), ctx, ids)
+}
+
 // Login mocks base method.
 func (m *MockUserUseCase) Login(ctx context.Context, username, password string) (string, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +123,20 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 	return m.recorder
 }
 
+// Audit mocks base method.
+func (m *MockUserRepo) Audit() usecase.AuditRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit")
+	ret0, _ := ret[0].(usecase.AuditRepo)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockUserRepoMockRecorder) Audit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockUserRepo)(nil).Audit))
+}
+
 // CreateUser mocks base method.
 func (m *MockUserRepo) CreateUser(ctx context.Context, user *entity.User) error {
 	m.ctrl.T.Helper()
@@ -107,6 +151,35 @@ func (mr *MockUserRepoMockRecorder) CreateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepo)(nil).CreateUser), ctx, user)
 }
 
+// ExecuteTx mocks base method.
+func (m *MockUserRepo) ExecuteTx(ctx context.Context, fn func(usecase.UserRepo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteTx indicates an expected call of ExecuteTx.
+func (mr *MockUserRepoMockRecorder) ExecuteTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTx", reflect.TypeOf((*MockUserRepo)(nil).ExecuteTx), ctx, fn)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserRepo) GetUserByID(ctx context.Context, userID int64) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserRepoMockRecorder) GetUserByID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepo)(nil).GetUserByID), ctx, userID)
+}
+
 // GetUserByUsername mocks base method.
 func (m *MockUserRepo) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -122,6 +195,236 @@ func (mr *MockUserRepoMockRecorder) GetUserByUsername(ctx, username any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserRepo)(nil).GetUserByUsername), ctx, username)
 }
 
+// GetUsersByIDs mocks base method.
+func (m *MockUserRepo) GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByIDs indicates an expected call of GetUsersByIDs.
+func (mr *MockUserRepoMockRecorder) GetUsersByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByIDs", reflect.TypeOf((*MockUserRepo)(nil).GetUsersByIDs), ctx, ids)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserRepo) ListUsers(ctx context.Context, afterID int64, limit uint64) ([]*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, afterID, limit)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserRepoMockRecorder) ListUsers(ctx, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserRepo)(nil).ListUsers), ctx, afterID, limit)
+}
+
+// Outbox mocks base method.
+func (m *MockUserRepo) Outbox() usecase.OutboxRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outbox")
+	ret0, _ := ret[0].(usecase.OutboxRepo)
+	return ret0
+}
+
+// Outbox indicates an expected call of Outbox.
+func (mr *MockUserRepoMockRecorder) Outbox() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outbox", reflect.TypeOf((*MockUserRepo)(nil).Outbox))
+}
+
+// RevokeSessions mocks base method.
+func (m *MockUserRepo) RevokeSessions(ctx context.Context, userID int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockUserRepoMockRecorder) RevokeSessions(ctx, userID, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockUserRepo)(nil).RevokeSessions), ctx, userID, at)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockUserRepo) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUserRepoMockRecorder) UpdatePasswordHash(ctx, userID, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUserRepo)(nil).UpdatePasswordHash), ctx, userID, passwordHash)
+}
+
+// MockRepos is a mock of Repos interface.
+type MockRepos struct {
+	ctrl     *gomock.Controller
+	recorder *MockReposMockRecorder
+}
+
+// MockReposMockRecorder is the mock recorder for MockRepos.
+type MockReposMockRecorder struct {
+	mock *MockRepos
+}
+
+// NewMockRepos creates a new mock instance.
+func NewMockRepos(ctrl *gomock.Controller) *MockRepos {
+	mock := &MockRepos{ctrl: ctrl}
+	mock.recorder = &MockReposMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepos) EXPECT() *MockReposMockRecorder {
+	return m.recorder
+}
+
+// Assets mocks base method.
+func (m *MockRepos) Assets() usecase.AssetRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Assets")
+	ret0, _ := ret[0].(usecase.AssetRepo)
+	return ret0
+}
+
+// Assets indicates an expected call of Assets.
+func (mr *MockReposMockRecorder) Assets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assets", reflect.TypeOf((*MockRepos)(nil).Assets))
+}
+
+// Audit mocks base method.
+func (m *MockRepos) Audit() usecase.AuditRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit")
+	ret0, _ := ret[0].(usecase.AuditRepo)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockReposMockRecorder) Audit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockRepos)(nil).Audit))
+}
+
+// Notifications mocks base method.
+func (m *MockRepos) Notifications() usecase.NotificationRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notifications")
+	ret0, _ := ret[0].(usecase.NotificationRepo)
+	return ret0
+}
+
+// Notifications indicates an expected call of Notifications.
+func (mr *MockReposMockRecorder) Notifications() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notifications", reflect.TypeOf((*MockRepos)(nil).Notifications))
+}
+
+// Outbox mocks base method.
+func (m *MockRepos) Outbox() usecase.OutboxRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outbox")
+	ret0, _ := ret[0].(usecase.OutboxRepo)
+	return ret0
+}
+
+// Outbox indicates an expected call of Outbox.
+func (mr *MockReposMockRecorder) Outbox() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outbox", reflect.TypeOf((*MockRepos)(nil).Outbox))
+}
+
+// Users mocks base method.
+func (m *MockRepos) Users() usecase.UserRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Users")
+	ret0, _ := ret[0].(usecase.UserRepo)
+	return ret0
+}
+
+// Users indicates an expected call of Users.
+func (mr *MockReposMockRecorder) Users() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Users", reflect.TypeOf((*MockRepos)(nil).Users))
+}
+
+// Webhooks mocks base method.
+func (m *MockRepos) Webhooks() usecase.WebhookRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Webhooks")
+	ret0, _ := ret[0].(usecase.WebhookRepo)
+	return ret0
+}
+
+// Webhooks indicates an expected call of Webhooks.
+func (mr *MockReposMockRecorder) Webhooks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Webhooks", reflect.TypeOf((*MockRepos)(nil).Webhooks))
+}
+
+// MockUnitOfWork is a mock of UnitOfWork interface.
+type MockUnitOfWork struct {
+	ctrl     *gomock.Controller
+	recorder *MockUnitOfWorkMockRecorder
+}
+
+// MockUnitOfWorkMockRecorder is the mock recorder for MockUnitOfWork.
+type MockUnitOfWorkMockRecorder struct {
+	mock *MockUnitOfWork
+}
+
+// NewMockUnitOfWork creates a new mock instance.
+func NewMockUnitOfWork(ctrl *gomock.Controller) *MockUnitOfWork {
+	mock := &MockUnitOfWork{ctrl: ctrl}
+	mock.recorder = &MockUnitOfWorkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUnitOfWork) EXPECT() *MockUnitOfWorkMockRecorder {
+	return m.recorder
+}
+
+// Do mocks base method.
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(context.Context, usecase.Repos) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockUnitOfWorkMockRecorder) Do(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockUnitOfWork)(nil).Do), ctx, fn)
+}
+
+// DoIsolated mocks base method.
+func (m *MockUnitOfWork) DoIsolated(ctx context.Context, level usecase.IsolationLevel, fn func(context.Context, usecase.Repos) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoIsolated", ctx, level, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DoIsolated indicates an expected call of DoIsolated.
+func (mr *MockUnitOfWorkMockRecorder) DoIsolated(ctx, level, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoIsolated", reflect.TypeOf((*MockUnitOfWork)(nil).DoIsolated), ctx, level, fn)
+}
+
 // MockAssetUseCase is a mock of AssetUseCase interface.
 type MockAssetUseCase struct {
 	ctrl     *gomock.Controller
@@ -159,6 +462,21 @@ func (mr *MockAssetUseCaseMockRecorder) AddAsset(ctx, asset any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAsset", reflect.TypeOf((*MockAssetUseCase)(nil).AddAsset), ctx, asset)
 }
 
+// GetAssetsByIDs mocks base method.
+func (m *MockAssetUseCase) GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssetsByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssetsByIDs indicates an expected call of GetAssetsByIDs.
+func (mr *MockAssetUseCaseMockRecorder) GetAssetsByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetsByIDs", reflect.TypeOf((*MockAssetUseCase)(nil).GetAssetsByIDs), ctx, ids)
+}
+
 // GetAssetsByUser mocks base method.
 func (m *MockAssetUseCase) GetAssetsByUser(ctx context.Context, userID int64) ([]*entity.Asset, error) {
 	m.ctrl.T.Helper()
@@ -174,6 +492,36 @@ func (mr *MockAssetUseCaseMockRecorder) GetAssetsByUser(ctx, userID any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetsByUser", reflect.TypeOf((*MockAssetUseCase)(nil).GetAssetsByUser), ctx, userID)
 }
 
+// GetAssetsByUsers mocks base method.
+func (m *MockAssetUseCase) GetAssetsByUsers(ctx context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssetsByUsers", ctx, userIDs)
+	ret0, _ := ret[0].([]*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssetsByUsers indicates an expected call of GetAssetsByUsers.
+func (mr *MockAssetUseCaseMockRecorder) GetAssetsByUsers(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetsByUsers", reflect.TypeOf((*MockAssetUseCase)(nil).GetAssetsByUsers), ctx, userIDs)
+}
+
+// ListAssets mocks base method.
+func (m *MockAssetUseCase) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAssets", ctx, filter)
+	ret0, _ := ret[0].([]*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAssets indicates an expected call of ListAssets.
+func (mr *MockAssetUseCaseMockRecorder) ListAssets(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssets", reflect.TypeOf((*MockAssetUseCase)(nil).ListAssets), ctx, filter)
+}
+
 // PurchaseAsset mocks base method.
 func (m *MockAssetUseCase) PurchaseAsset(ctx context.Context, assetID, buyerID int64) error {
 	m.ctrl.T.Helper()
@@ -225,6 +573,35 @@ func (m *MockAssetRepo) EXPECT() *MockAssetRepoMockRecorder {
 	return m.recorder
 }
 
+// Audit mocks base method.
+func (m *MockAssetRepo) Audit() usecase.AuditRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit")
+	ret0, _ := ret[0].(usecase.AuditRepo)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockAssetRepoMockRecorder) Audit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockAssetRepo)(nil).Audit))
+}
+
+// CountAssets mocks base method.
+func (m *MockAssetRepo) CountAssets(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAssets", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAssets indicates an expected call of CountAssets.
+func (mr *MockAssetRepoMockRecorder) CountAssets(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAssets", reflect.TypeOf((*MockAssetRepo)(nil).CountAssets), ctx)
+}
+
 // CreateAsset mocks base method.
 func (m *MockAssetRepo) CreateAsset(ctx context.Context, asset *entity.Asset) error {
 	m.ctrl.T.Helper()
@@ -282,6 +659,21 @@ func (mr *MockAssetRepoMockRecorder) GetAssetByID(ctx, assetID, forUpdate any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetByID", reflect.TypeOf((*MockAssetRepo)(nil).GetAssetByID), ctx, assetID, forUpdate)
 }
 
+// GetAssetsByIDs mocks base method.
+func (m *MockAssetRepo) GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssetsByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssetsByIDs indicates an expected call of GetAssetsByIDs.
+func (mr *MockAssetRepoMockRecorder) GetAssetsByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetsByIDs", reflect.TypeOf((*MockAssetRepo)(nil).GetAssetsByIDs), ctx, ids)
+}
+
 // GetAssetsByUserID mocks base method.
 func (m *MockAssetRepo) GetAssetsByUserID(ctx context.Context, userID int64) ([]*entity.Asset, error) {
 	m.ctrl.T.Helper()
@@ -297,6 +689,64 @@ func (mr *MockAssetRepoMockRecorder) GetAssetsByUserID(ctx, userID any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetsByUserID", reflect.TypeOf((*MockAssetRepo)(nil).GetAssetsByUserID), ctx, userID)
 }
 
+// GetAssetsByUserIDs mocks base method.
+func (m *MockAssetRepo) GetAssetsByUserIDs(ctx context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAssetsByUserIDs", ctx, userIDs)
+	ret0, _ := ret[0].([]*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAssetsByUserIDs indicates an expected call of GetAssetsByUserIDs.
+func (mr *MockAssetRepoMockRecorder) GetAssetsByUserIDs(ctx, userIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAssetsByUserIDs", reflect.TypeOf((*MockAssetRepo)(nil).GetAssetsByUserIDs), ctx, userIDs)
+}
+
+// ListAssets mocks base method.
+func (m *MockAssetRepo) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAssets", ctx, filter)
+	ret0, _ := ret[0].([]*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAssets indicates an expected call of ListAssets.
+func (mr *MockAssetRepoMockRecorder) ListAssets(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssets", reflect.TypeOf((*MockAssetRepo)(nil).ListAssets), ctx, filter)
+}
+
+// Notifications mocks base method.
+func (m *MockAssetRepo) Notifications() usecase.NotificationRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notifications")
+	ret0, _ := ret[0].(usecase.NotificationRepo)
+	return ret0
+}
+
+// Notifications indicates an expected call of Notifications.
+func (mr *MockAssetRepoMockRecorder) Notifications() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notifications", reflect.TypeOf((*MockAssetRepo)(nil).Notifications))
+}
+
+// Outbox mocks base method.
+func (m *MockAssetRepo) Outbox() usecase.OutboxRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outbox")
+	ret0, _ := ret[0].(usecase.OutboxRepo)
+	return ret0
+}
+
+// Outbox indicates an expected call of Outbox.
+func (mr *MockAssetRepoMockRecorder) Outbox() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outbox", reflect.TypeOf((*MockAssetRepo)(nil).Outbox))
+}
+
 // UpdateAssetOwner mocks base method.
 func (m *MockAssetRepo) UpdateAssetOwner(ctx context.Context, assetID, newOwnerID int64) error {
 	m.ctrl.T.Helper()
@@ -310,3 +760,1432 @@ func (mr *MockAssetRepoMockRecorder) UpdateAssetOwner(ctx, assetID, newOwnerID a
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAssetOwner", reflect.TypeOf((*MockAssetRepo)(nil).UpdateAssetOwner), ctx, assetID, newOwnerID)
 }
+
+// MockAdminUseCase is a mock of AdminUseCase interface.
+type MockAdminUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminUseCaseMockRecorder
+}
+
+// MockAdminUseCaseMockRecorder is the mock recorder for MockAdminUseCase.
+type MockAdminUseCaseMockRecorder struct {
+	mock *MockAdminUseCase
+}
+
+// NewMockAdminUseCase creates a new mock instance.
+func NewMockAdminUseCase(ctrl *gomock.Controller) *MockAdminUseCase {
+	mock := &MockAdminUseCase{ctrl: ctrl}
+	mock.recorder = &MockAdminUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminUseCase) EXPECT() *MockAdminUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CheckLedger mocks base method.
+func (m *MockAdminUseCase) CheckLedger(ctx context.Context) (*entity.LedgerReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLedger", ctx)
+	ret0, _ := ret[0].(*entity.LedgerReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckLedger indicates an expected call of CheckLedger.
+func (mr *MockAdminUseCaseMockRecorder) CheckLedger(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLedger", reflect.TypeOf((*MockAdminUseCase)(nil).CheckLedger), ctx)
+}
+
+// CreateUser mocks base method.
+func (m *MockAdminUseCase) CreateUser(ctx context.Context, username, password, role string) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, username, password, role)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockAdminUseCaseMockRecorder) CreateUser(ctx, username, password, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAdminUseCase)(nil).CreateUser), ctx, username, password, role)
+}
+
+// DeleteAsset mocks base method.
+func (m *MockAdminUseCase) DeleteAsset(ctx context.Context, assetID int64) (*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAsset", ctx, assetID)
+	ret0, _ := ret[0].(*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAsset indicates an expected call of DeleteAsset.
+func (mr *MockAdminUseCaseMockRecorder) DeleteAsset(ctx, assetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAsset", reflect.TypeOf((*MockAdminUseCase)(nil).DeleteAsset), ctx, assetID)
+}
+
+// ExportData mocks base method.
+func (m *MockAdminUseCase) ExportData(ctx context.Context) (*entity.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportData", ctx)
+	ret0, _ := ret[0].(*entity.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportData indicates an expected call of ExportData.
+func (mr *MockAdminUseCaseMockRecorder) ExportData(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportData", reflect.TypeOf((*MockAdminUseCase)(nil).ExportData), ctx)
+}
+
+// ListAssets mocks base method.
+func (m *MockAdminUseCase) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAssets", ctx, filter)
+	ret0, _ := ret[0].([]*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAssets indicates an expected call of ListAssets.
+func (mr *MockAdminUseCaseMockRecorder) ListAssets(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAssets", reflect.TypeOf((*MockAdminUseCase)(nil).ListAssets), ctx, filter)
+}
+
+// ListUsers mocks base method.
+func (m *MockAdminUseCase) ListUsers(ctx context.Context, afterID int64, limit uint64) ([]*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, afterID, limit)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockAdminUseCaseMockRecorder) ListUsers(ctx, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAdminUseCase)(nil).ListUsers), ctx, afterID, limit)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAdminUseCase) ResetPassword(ctx context.Context, username, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, username, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAdminUseCaseMockRecorder) ResetPassword(ctx, username, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAdminUseCase)(nil).ResetPassword), ctx, username, password)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockAdminUseCase) RevokeSessions(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockAdminUseCaseMockRecorder) RevokeSessions(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockAdminUseCase)(nil).RevokeSessions), ctx, username)
+}
+
+// TransferAsset mocks base method.
+func (m *MockAdminUseCase) TransferAsset(ctx context.Context, assetID int64, toUsername string) (*entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferAsset", ctx, assetID, toUsername)
+	ret0, _ := ret[0].(*entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferAsset indicates an expected call of TransferAsset.
+func (mr *MockAdminUseCaseMockRecorder) TransferAsset(ctx, assetID, toUsername any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferAsset", reflect.TypeOf((*MockAdminUseCase)(nil).TransferAsset), ctx, assetID, toUsername)
+}
+
+// MockAuditUseCase is a mock of AuditUseCase interface.
+type MockAuditUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditUseCaseMockRecorder
+}
+
+// MockAuditUseCaseMockRecorder is the mock recorder for MockAuditUseCase.
+type MockAuditUseCaseMockRecorder struct {
+	mock *MockAuditUseCase
+}
+
+// NewMockAuditUseCase creates a new mock instance.
+func NewMockAuditUseCase(ctrl *gomock.Controller) *MockAuditUseCase {
+	mock := &MockAuditUseCase{ctrl: ctrl}
+	mock.recorder = &MockAuditUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditUseCase) EXPECT() *MockAuditUseCaseMockRecorder {
+	return m.recorder
+}
+
+// ListEntries mocks base method.
+func (m *MockAuditUseCase) ListEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", ctx, filter)
+	ret0, _ := ret[0].([]*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockAuditUseCaseMockRecorder) ListEntries(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockAuditUseCase)(nil).ListEntries), ctx, filter)
+}
+
+// RecordConfigReload mocks base method.
+func (m *MockAuditUseCase) RecordConfigReload(ctx context.Context, before, after map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordConfigReload", ctx, before, after)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordConfigReload indicates an expected call of RecordConfigReload.
+func (mr *MockAuditUseCaseMockRecorder) RecordConfigReload(ctx, before, after any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordConfigReload", reflect.TypeOf((*MockAuditUseCase)(nil).RecordConfigReload), ctx, before, after)
+}
+
+// VerifyChain mocks base method.
+func (m *MockAuditUseCase) VerifyChain(ctx context.Context) (*entity.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChain", ctx)
+	ret0, _ := ret[0].(*entity.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChain indicates an expected call of VerifyChain.
+func (mr *MockAuditUseCaseMockRecorder) VerifyChain(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChain", reflect.TypeOf((*MockAuditUseCase)(nil).VerifyChain), ctx)
+}
+
+// MockAuditRepo is a mock of AuditRepo interface.
+type MockAuditRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepoMockRecorder
+}
+
+// MockAuditRepoMockRecorder is the mock recorder for MockAuditRepo.
+type MockAuditRepoMockRecorder struct {
+	mock *MockAuditRepo
+}
+
+// NewMockAuditRepo creates a new mock instance.
+func NewMockAuditRepo(ctrl *gomock.Controller) *MockAuditRepo {
+	mock := &MockAuditRepo{ctrl: ctrl}
+	mock.recorder = &MockAuditRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepo) EXPECT() *MockAuditRepoMockRecorder {
+	return m.recorder
+}
+
+// AppendEntry mocks base method.
+func (m *MockAuditRepo) AppendEntry(ctx context.Context, entry *entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendEntry indicates an expected call of AppendEntry.
+func (mr *MockAuditRepoMockRecorder) AppendEntry(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendEntry", reflect.TypeOf((*MockAuditRepo)(nil).AppendEntry), ctx, entry)
+}
+
+// ListEntries mocks base method.
+func (m *MockAuditRepo) ListEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", ctx, filter)
+	ret0, _ := ret[0].([]*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockAuditRepoMockRecorder) ListEntries(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockAuditRepo)(nil).ListEntries), ctx, filter)
+}
+
+// MockOutboxRepo is a mock of OutboxRepo interface.
+type MockOutboxRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepoMockRecorder
+}
+
+// MockOutboxRepoMockRecorder is the mock recorder for MockOutboxRepo.
+type MockOutboxRepoMockRecorder struct {
+	mock *MockOutboxRepo
+}
+
+// NewMockOutboxRepo creates a new mock instance.
+func NewMockOutboxRepo(ctrl *gomock.Controller) *MockOutboxRepo {
+	mock := &MockOutboxRepo{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepo) EXPECT() *MockOutboxRepoMockRecorder {
+	return m.recorder
+}
+
+// AddEvent mocks base method.
+func (m *MockOutboxRepo) AddEvent(ctx context.Context, event *entity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvent indicates an expected call of AddEvent.
+func (mr *MockOutboxRepoMockRecorder) AddEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvent", reflect.TypeOf((*MockOutboxRepo)(nil).AddEvent), ctx, event)
+}
+
+// ClaimPending mocks base method.
+func (m *MockOutboxRepo) ClaimPending(ctx context.Context, limit uint64, lease time.Duration) ([]*entity.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPending", ctx, limit, lease)
+	ret0, _ := ret[0].([]*entity.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPending indicates an expected call of ClaimPending.
+func (mr *MockOutboxRepoMockRecorder) ClaimPending(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPending", reflect.TypeOf((*MockOutboxRepo)(nil).ClaimPending), ctx, limit, lease)
+}
+
+// ExecuteTx mocks base method.
+func (m *MockOutboxRepo) ExecuteTx(ctx context.Context, fn func(usecase.OutboxRepo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteTx indicates an expected call of ExecuteTx.
+func (mr *MockOutboxRepoMockRecorder) ExecuteTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTx", reflect.TypeOf((*MockOutboxRepo)(nil).ExecuteTx), ctx, fn)
+}
+
+// MarkFailed mocks base method.
+func (m *MockOutboxRepo) MarkFailed(ctx context.Context, eventID int64, lastErr string, nextAttemptAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFailed", ctx, eventID, lastErr, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkFailed indicates an expected call of MarkFailed.
+func (mr *MockOutboxRepoMockRecorder) MarkFailed(ctx, eventID, lastErr, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFailed", reflect.TypeOf((*MockOutboxRepo)(nil).MarkFailed), ctx, eventID, lastErr, nextAttemptAt)
+}
+
+// MarkPublished mocks base method.
+func (m *MockOutboxRepo) MarkPublished(ctx context.Context, eventID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPublished", ctx, eventID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPublished indicates an expected call of MarkPublished.
+func (mr *MockOutboxRepoMockRecorder) MarkPublished(ctx, eventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPublished", reflect.TypeOf((*MockOutboxRepo)(nil).MarkPublished), ctx, eventID)
+}
+
+// MoveToDeadLetter mocks base method.
+func (m *MockOutboxRepo) MoveToDeadLetter(ctx context.Context, event *entity.Event, lastErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveToDeadLetter", ctx, event, lastErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveToDeadLetter indicates an expected call of MoveToDeadLetter.
+func (mr *MockOutboxRepoMockRecorder) MoveToDeadLetter(ctx, event, lastErr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveToDeadLetter", reflect.TypeOf((*MockOutboxRepo)(nil).MoveToDeadLetter), ctx, event, lastErr)
+}
+
+// OldestPending mocks base method.
+func (m *MockOutboxRepo) OldestPending(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OldestPending", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OldestPending indicates an expected call of OldestPending.
+func (mr *MockOutboxRepoMockRecorder) OldestPending(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OldestPending", reflect.TypeOf((*MockOutboxRepo)(nil).OldestPending), ctx)
+}
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(ctx context.Context, event *entity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), ctx, event)
+}
+
+// MockWebhookUseCase is a mock of WebhookUseCase interface.
+type MockWebhookUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookUseCaseMockRecorder
+}
+
+// MockWebhookUseCaseMockRecorder is the mock recorder for MockWebhookUseCase.
+type MockWebhookUseCaseMockRecorder struct {
+	mock *MockWebhookUseCase
+}
+
+// NewMockWebhookUseCase creates a new mock instance.
+func NewMockWebhookUseCase(ctrl *gomock.Controller) *MockWebhookUseCase {
+	mock := &MockWebhookUseCase{ctrl: ctrl}
+	mock.recorder = &MockWebhookUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookUseCase) EXPECT() *MockWebhookUseCaseMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookUseCase) CreateWebhook(ctx context.Context, userID int64, url string, events []string) (*entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, userID, url, events)
+	ret0, _ := ret[0].(*entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookUseCaseMockRecorder) CreateWebhook(ctx, userID, url, events any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookUseCase)(nil).CreateWebhook), ctx, userID, url, events)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookUseCase) DeleteWebhook(ctx context.Context, webhookID, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, webhookID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookUseCaseMockRecorder) DeleteWebhook(ctx, webhookID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookUseCase)(nil).DeleteWebhook), ctx, webhookID, userID)
+}
+
+// DispatchPending mocks base method.
+func (m *MockWebhookUseCase) DispatchPending(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DispatchPending", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DispatchPending indicates an expected call of DispatchPending.
+func (mr *MockWebhookUseCaseMockRecorder) DispatchPending(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DispatchPending", reflect.TypeOf((*MockWebhookUseCase)(nil).DispatchPending), ctx)
+}
+
+// HandleEvent mocks base method.
+func (m *MockWebhookUseCase) HandleEvent(ctx context.Context, event *entity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleEvent indicates an expected call of HandleEvent.
+func (mr *MockWebhookUseCaseMockRecorder) HandleEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleEvent", reflect.TypeOf((*MockWebhookUseCase)(nil).HandleEvent), ctx, event)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhookUseCase) ListDeliveries(ctx context.Context, webhookID, userID int64, limit uint64) ([]*entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, webhookID, userID, limit)
+	ret0, _ := ret[0].([]*entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhookUseCaseMockRecorder) ListDeliveries(ctx, webhookID, userID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhookUseCase)(nil).ListDeliveries), ctx, webhookID, userID, limit)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWebhookUseCase) ListWebhooks(ctx context.Context, userID int64) ([]*entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, userID)
+	ret0, _ := ret[0].([]*entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWebhookUseCaseMockRecorder) ListWebhooks(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWebhookUseCase)(nil).ListWebhooks), ctx, userID)
+}
+
+// SendTestEvent mocks base method.
+func (m *MockWebhookUseCase) SendTestEvent(ctx context.Context, webhookID, userID int64) (*entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTestEvent", ctx, webhookID, userID)
+	ret0, _ := ret[0].(*entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendTestEvent indicates an expected call of SendTestEvent.
+func (mr *MockWebhookUseCaseMockRecorder) SendTestEvent(ctx, webhookID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTestEvent", reflect.TypeOf((*MockWebhookUseCase)(nil).SendTestEvent), ctx, webhookID, userID)
+}
+
+// SetWebhookEnabled mocks base method.
+func (m *MockWebhookUseCase) SetWebhookEnabled(ctx context.Context, webhookID, userID int64, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhookEnabled", ctx, webhookID, userID, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWebhookEnabled indicates an expected call of SetWebhookEnabled.
+func (mr *MockWebhookUseCaseMockRecorder) SetWebhookEnabled(ctx, webhookID, userID, enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhookEnabled", reflect.TypeOf((*MockWebhookUseCase)(nil).SetWebhookEnabled), ctx, webhookID, userID, enabled)
+}
+
+// MockWebhookRepo is a mock of WebhookRepo interface.
+type MockWebhookRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepoMockRecorder
+}
+
+// MockWebhookRepoMockRecorder is the mock recorder for MockWebhookRepo.
+type MockWebhookRepoMockRecorder struct {
+	mock *MockWebhookRepo
+}
+
+// NewMockWebhookRepo creates a new mock instance.
+func NewMockWebhookRepo(ctrl *gomock.Controller) *MockWebhookRepo {
+	mock := &MockWebhookRepo{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepo) EXPECT() *MockWebhookRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockWebhookRepo) ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", ctx, limit, lease)
+	ret0, _ := ret[0].([]*entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockWebhookRepoMockRecorder) ClaimDueDeliveries(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockWebhookRepo)(nil).ClaimDueDeliveries), ctx, limit, lease)
+}
+
+// CreateDelivery mocks base method.
+func (m *MockWebhookRepo) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDelivery indicates an expected call of CreateDelivery.
+func (mr *MockWebhookRepoMockRecorder) CreateDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDelivery", reflect.TypeOf((*MockWebhookRepo)(nil).CreateDelivery), ctx, delivery)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookRepo) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookRepoMockRecorder) CreateWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookRepo)(nil).CreateWebhook), ctx, webhook)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookRepo) DeleteWebhook(ctx context.Context, webhookID, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, webhookID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookRepoMockRecorder) DeleteWebhook(ctx, webhookID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookRepo)(nil).DeleteWebhook), ctx, webhookID, userID)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookRepo) GetWebhook(ctx context.Context, webhookID int64) (*entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, webhookID)
+	ret0, _ := ret[0].(*entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookRepoMockRecorder) GetWebhook(ctx, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookRepo)(nil).GetWebhook), ctx, webhookID)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhookRepo) ListDeliveries(ctx context.Context, webhookID int64, limit uint64) ([]*entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, webhookID, limit)
+	ret0, _ := ret[0].([]*entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhookRepoMockRecorder) ListDeliveries(ctx, webhookID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhookRepo)(nil).ListDeliveries), ctx, webhookID, limit)
+}
+
+// ListWebhooksByUser mocks base method.
+func (m *MockWebhookRepo) ListWebhooksByUser(ctx context.Context, userID int64) ([]*entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooksByUser", ctx, userID)
+	ret0, _ := ret[0].([]*entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooksByUser indicates an expected call of ListWebhooksByUser.
+func (mr *MockWebhookRepoMockRecorder) ListWebhooksByUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooksByUser", reflect.TypeOf((*MockWebhookRepo)(nil).ListWebhooksByUser), ctx, userID)
+}
+
+// RecordWebhookResult mocks base method.
+func (m *MockWebhookRepo) RecordWebhookResult(ctx context.Context, webhookID int64, success bool, disableAfter int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordWebhookResult", ctx, webhookID, success, disableAfter)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordWebhookResult indicates an expected call of RecordWebhookResult.
+func (mr *MockWebhookRepoMockRecorder) RecordWebhookResult(ctx, webhookID, success, disableAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordWebhookResult", reflect.TypeOf((*MockWebhookRepo)(nil).RecordWebhookResult), ctx, webhookID, success, disableAfter)
+}
+
+// SetWebhookEnabled mocks base method.
+func (m *MockWebhookRepo) SetWebhookEnabled(ctx context.Context, webhookID int64, enabled bool, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhookEnabled", ctx, webhookID, enabled, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWebhookEnabled indicates an expected call of SetWebhookEnabled.
+func (mr *MockWebhookRepoMockRecorder) SetWebhookEnabled(ctx, webhookID, enabled, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhookEnabled", reflect.TypeOf((*MockWebhookRepo)(nil).SetWebhookEnabled), ctx, webhookID, enabled, reason)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhookRepo) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhookRepoMockRecorder) UpdateDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhookRepo)(nil).UpdateDelivery), ctx, delivery)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// CheckURL mocks base method.
+func (m *MockWebhookSender) CheckURL(ctx context.Context, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckURL", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckURL indicates an expected call of CheckURL.
+func (mr *MockWebhookSenderMockRecorder) CheckURL(ctx, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckURL", reflect.TypeOf((*MockWebhookSender)(nil).CheckURL), ctx, url)
+}
+
+// Send mocks base method.
+func (m *MockWebhookSender) Send(ctx context.Context, url, secret string, body []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, url, secret, body)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockWebhookSenderMockRecorder) Send(ctx, url, secret, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWebhookSender)(nil).Send), ctx, url, secret, body)
+}
+
+// MockNotificationUseCase is a mock of NotificationUseCase interface.
+type MockNotificationUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationUseCaseMockRecorder
+}
+
+// MockNotificationUseCaseMockRecorder is the mock recorder for MockNotificationUseCase.
+type MockNotificationUseCaseMockRecorder struct {
+	mock *MockNotificationUseCase
+}
+
+// NewMockNotificationUseCase creates a new mock instance.
+func NewMockNotificationUseCase(ctrl *gomock.Controller) *MockNotificationUseCase {
+	mock := &MockNotificationUseCase{ctrl: ctrl}
+	mock.recorder = &MockNotificationUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationUseCase) EXPECT() *MockNotificationUseCaseMockRecorder {
+	return m.recorder
+}
+
+// GetPreferences mocks base method.
+func (m *MockNotificationUseCase) GetPreferences(ctx context.Context, userID int64) ([]*entity.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].([]*entity.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockNotificationUseCaseMockRecorder) GetPreferences(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockNotificationUseCase)(nil).GetPreferences), ctx, userID)
+}
+
+// HandleEvent mocks base method.
+func (m *MockNotificationUseCase) HandleEvent(ctx context.Context, event *entity.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleEvent indicates an expected call of HandleEvent.
+func (mr *MockNotificationUseCaseMockRecorder) HandleEvent(ctx, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleEvent", reflect.TypeOf((*MockNotificationUseCase)(nil).HandleEvent), ctx, event)
+}
+
+// ListNotifications mocks base method.
+func (m *MockNotificationUseCase) ListNotifications(ctx context.Context, userID int64, filter entity.NotificationFilter) ([]*entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", ctx, userID, filter)
+	ret0, _ := ret[0].([]*entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockNotificationUseCaseMockRecorder) ListNotifications(ctx, userID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockNotificationUseCase)(nil).ListNotifications), ctx, userID, filter)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationUseCase) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationUseCaseMockRecorder) MarkAllRead(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationUseCase)(nil).MarkAllRead), ctx, userID)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotificationUseCase) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, userID, ids)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationUseCaseMockRecorder) MarkRead(ctx, userID, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotificationUseCase)(nil).MarkRead), ctx, userID, ids)
+}
+
+// PurgeRead mocks base method.
+func (m *MockNotificationUseCase) PurgeRead(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeRead", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeRead indicates an expected call of PurgeRead.
+func (mr *MockNotificationUseCaseMockRecorder) PurgeRead(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeRead", reflect.TypeOf((*MockNotificationUseCase)(nil).PurgeRead), ctx)
+}
+
+// SendAdminMessage mocks base method.
+func (m *MockNotificationUseCase) SendAdminMessage(ctx context.Context, adminID, userID int64, message string) (*entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAdminMessage", ctx, adminID, userID, message)
+	ret0, _ := ret[0].(*entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendAdminMessage indicates an expected call of SendAdminMessage.
+func (mr *MockNotificationUseCaseMockRecorder) SendAdminMessage(ctx, adminID, userID, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAdminMessage", reflect.TypeOf((*MockNotificationUseCase)(nil).SendAdminMessage), ctx, adminID, userID, message)
+}
+
+// Subscribe mocks base method.
+func (m *MockNotificationUseCase) Subscribe(ctx context.Context, userID, lastEventID int64) (<-chan *entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, userID, lastEventID)
+	ret0, _ := ret[0].(<-chan *entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockNotificationUseCaseMockRecorder) Subscribe(ctx, userID, lastEventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNotificationUseCase)(nil).Subscribe), ctx, userID, lastEventID)
+}
+
+// UnreadCount mocks base method.
+func (m *MockNotificationUseCase) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnreadCount", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnreadCount indicates an expected call of UnreadCount.
+func (mr *MockNotificationUseCaseMockRecorder) UnreadCount(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnreadCount", reflect.TypeOf((*MockNotificationUseCase)(nil).UnreadCount), ctx, userID)
+}
+
+// UpdatePreferences mocks base method.
+func (m *MockNotificationUseCase) UpdatePreferences(ctx context.Context, userID int64, prefs map[string]bool) ([]*entity.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", ctx, userID, prefs)
+	ret0, _ := ret[0].([]*entity.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences.
+func (mr *MockNotificationUseCaseMockRecorder) UpdatePreferences(ctx, userID, prefs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockNotificationUseCase)(nil).UpdatePreferences), ctx, userID, prefs)
+}
+
+// MockNotificationRepo is a mock of NotificationRepo interface.
+type MockNotificationRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationRepoMockRecorder
+}
+
+// MockNotificationRepoMockRecorder is the mock recorder for MockNotificationRepo.
+type MockNotificationRepoMockRecorder struct {
+	mock *MockNotificationRepo
+}
+
+// NewMockNotificationRepo creates a new mock instance.
+func NewMockNotificationRepo(ctrl *gomock.Controller) *MockNotificationRepo {
+	mock := &MockNotificationRepo{ctrl: ctrl}
+	mock.recorder = &MockNotificationRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationRepo) EXPECT() *MockNotificationRepoMockRecorder {
+	return m.recorder
+}
+
+// CountUnread mocks base method.
+func (m *MockNotificationRepo) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockNotificationRepoMockRecorder) CountUnread(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockNotificationRepo)(nil).CountUnread), ctx, userID)
+}
+
+// CreateNotification mocks base method.
+func (m *MockNotificationRepo) CreateNotification(ctx context.Context, notification *entity.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockNotificationRepoMockRecorder) CreateNotification(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockNotificationRepo)(nil).CreateNotification), ctx, notification)
+}
+
+// DeleteReadBefore mocks base method.
+func (m *MockNotificationRepo) DeleteReadBefore(ctx context.Context, before time.Time, limit uint64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReadBefore", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReadBefore indicates an expected call of DeleteReadBefore.
+func (mr *MockNotificationRepoMockRecorder) DeleteReadBefore(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReadBefore", reflect.TypeOf((*MockNotificationRepo)(nil).DeleteReadBefore), ctx, before, limit)
+}
+
+// ListNotifications mocks base method.
+func (m *MockNotificationRepo) ListNotifications(ctx context.Context, userID int64, filter entity.NotificationFilter) ([]*entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", ctx, userID, filter)
+	ret0, _ := ret[0].([]*entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockNotificationRepoMockRecorder) ListNotifications(ctx, userID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockNotificationRepo)(nil).ListNotifications), ctx, userID, filter)
+}
+
+// ListPreferences mocks base method.
+func (m *MockNotificationRepo) ListPreferences(ctx context.Context, userID int64) ([]*entity.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPreferences", ctx, userID)
+	ret0, _ := ret[0].([]*entity.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPreferences indicates an expected call of ListPreferences.
+func (mr *MockNotificationRepoMockRecorder) ListPreferences(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPreferences", reflect.TypeOf((*MockNotificationRepo)(nil).ListPreferences), ctx, userID)
+}
+
+// MarkAllRead mocks base method.
+func (m *MockNotificationRepo) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAllRead", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkAllRead indicates an expected call of MarkAllRead.
+func (mr *MockNotificationRepoMockRecorder) MarkAllRead(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllRead", reflect.TypeOf((*MockNotificationRepo)(nil).MarkAllRead), ctx, userID)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotificationRepo) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, userID, ids)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationRepoMockRecorder) MarkRead(ctx, userID, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotificationRepo)(nil).MarkRead), ctx, userID, ids)
+}
+
+// SetPreferences mocks base method.
+func (m *MockNotificationRepo) SetPreferences(ctx context.Context, userID int64, prefs []*entity.NotificationPreference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferences", ctx, userID, prefs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreferences indicates an expected call of SetPreferences.
+func (mr *MockNotificationRepoMockRecorder) SetPreferences(ctx, userID, prefs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferences", reflect.TypeOf((*MockNotificationRepo)(nil).SetPreferences), ctx, userID, prefs)
+}
+
+// MockNotificationHub is a mock of NotificationHub interface.
+type MockNotificationHub struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationHubMockRecorder
+}
+
+// MockNotificationHubMockRecorder is the mock recorder for MockNotificationHub.
+type MockNotificationHubMockRecorder struct {
+	mock *MockNotificationHub
+}
+
+// NewMockNotificationHub creates a new mock instance.
+func NewMockNotificationHub(ctrl *gomock.Controller) *MockNotificationHub {
+	mock := &MockNotificationHub{ctrl: ctrl}
+	mock.recorder = &MockNotificationHubMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationHub) EXPECT() *MockNotificationHubMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockNotificationHub) Publish(ctx context.Context, notification *entity.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockNotificationHubMockRecorder) Publish(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockNotificationHub)(nil).Publish), ctx, notification)
+}
+
+// Subscribe mocks base method.
+func (m *MockNotificationHub) Subscribe(ctx context.Context, userID int64) (<-chan *entity.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, userID)
+	ret0, _ := ret[0].(<-chan *entity.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockNotificationHubMockRecorder) Subscribe(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNotificationHub)(nil).Subscribe), ctx, userID)
+}
+
+// MockFeatureFlags is a mock of FeatureFlags interface.
+type MockFeatureFlags struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeatureFlagsMockRecorder
+}
+
+// MockFeatureFlagsMockRecorder is the mock recorder for MockFeatureFlags.
+type MockFeatureFlagsMockRecorder struct {
+	mock *MockFeatureFlags
+}
+
+// NewMockFeatureFlags creates a new mock instance.
+func NewMockFeatureFlags(ctrl *gomock.Controller) *MockFeatureFlags {
+	mock := &MockFeatureFlags{ctrl: ctrl}
+	mock.recorder = &MockFeatureFlagsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeatureFlags) EXPECT() *MockFeatureFlagsMockRecorder {
+	return m.recorder
+}
+
+// IsEnabled mocks base method.
+func (m *MockFeatureFlags) IsEnabled(ctx context.Context, key string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, key)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsEnabled indicates an expected call of IsEnabled.
+func (mr *MockFeatureFlagsMockRecorder) IsEnabled(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockFeatureFlags)(nil).IsEnabled), ctx, key)
+}
+
+// MockFlagUseCase is a mock of FlagUseCase interface.
+type MockFlagUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlagUseCaseMockRecorder
+}
+
+// MockFlagUseCaseMockRecorder is the mock recorder for MockFlagUseCase.
+type MockFlagUseCaseMockRecorder struct {
+	mock *MockFlagUseCase
+}
+
+// NewMockFlagUseCase creates a new mock instance.
+func NewMockFlagUseCase(ctrl *gomock.Controller) *MockFlagUseCase {
+	mock := &MockFlagUseCase{ctrl: ctrl}
+	mock.recorder = &MockFlagUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlagUseCase) EXPECT() *MockFlagUseCaseMockRecorder {
+	return m.recorder
+}
+
+// DeleteFlag mocks base method.
+func (m *MockFlagUseCase) DeleteFlag(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFlag", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFlag indicates an expected call of DeleteFlag.
+func (mr *MockFlagUseCaseMockRecorder) DeleteFlag(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFlag", reflect.TypeOf((*MockFlagUseCase)(nil).DeleteFlag), ctx, key)
+}
+
+// EnabledFlags mocks base method.
+func (m *MockFlagUseCase) EnabledFlags(ctx context.Context) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnabledFlags", ctx)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// EnabledFlags indicates an expected call of EnabledFlags.
+func (mr *MockFlagUseCaseMockRecorder) EnabledFlags(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnabledFlags", reflect.TypeOf((*MockFlagUseCase)(nil).EnabledFlags), ctx)
+}
+
+// GetFlag mocks base method.
+func (m *MockFlagUseCase) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlag", ctx, key)
+	ret0, _ := ret[0].(*entity.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlag indicates an expected call of GetFlag.
+func (mr *MockFlagUseCaseMockRecorder) GetFlag(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlag", reflect.TypeOf((*MockFlagUseCase)(nil).GetFlag), ctx, key)
+}
+
+// IsEnabled mocks base method.
+func (m *MockFlagUseCase) IsEnabled(ctx context.Context, key string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, key)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsEnabled indicates an expected call of IsEnabled.
+func (mr *MockFlagUseCaseMockRecorder) IsEnabled(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockFlagUseCase)(nil).IsEnabled), ctx, key)
+}
+
+// ListFlags mocks base method.
+func (m *MockFlagUseCase) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFlags", ctx)
+	ret0, _ := ret[0].([]*entity.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFlags indicates an expected call of ListFlags.
+func (mr *MockFlagUseCaseMockRecorder) ListFlags(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFlags", reflect.TypeOf((*MockFlagUseCase)(nil).ListFlags), ctx)
+}
+
+// Refresh mocks base method.
+func (m *MockFlagUseCase) Refresh(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockFlagUseCaseMockRecorder) Refresh(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockFlagUseCase)(nil).Refresh), ctx)
+}
+
+// SetFlag mocks base method.
+func (m *MockFlagUseCase) SetFlag(ctx context.Context, flag *entity.FeatureFlag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFlag", ctx, flag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFlag indicates an expected call of SetFlag.
+func (mr *MockFlagUseCaseMockRecorder) SetFlag(ctx, flag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFlag", reflect.TypeOf((*MockFlagUseCase)(nil).SetFlag), ctx, flag)
+}
+
+// MockFlagRepo is a mock of FlagRepo interface.
+type MockFlagRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlagRepoMockRecorder
+}
+
+// MockFlagRepoMockRecorder is the mock recorder for MockFlagRepo.
+type MockFlagRepoMockRecorder struct {
+	mock *MockFlagRepo
+}
+
+// NewMockFlagRepo creates a new mock instance.
+func NewMockFlagRepo(ctrl *gomock.Controller) *MockFlagRepo {
+	mock := &MockFlagRepo{ctrl: ctrl}
+	mock.recorder = &MockFlagRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlagRepo) EXPECT() *MockFlagRepoMockRecorder {
+	return m.recorder
+}
+
+// Audit mocks base method.
+func (m *MockFlagRepo) Audit() usecase.AuditRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Audit")
+	ret0, _ := ret[0].(usecase.AuditRepo)
+	return ret0
+}
+
+// Audit indicates an expected call of Audit.
+func (mr *MockFlagRepoMockRecorder) Audit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Audit", reflect.TypeOf((*MockFlagRepo)(nil).Audit))
+}
+
+// DeleteFlag mocks base method.
+func (m *MockFlagRepo) DeleteFlag(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFlag", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFlag indicates an expected call of DeleteFlag.
+func (mr *MockFlagRepoMockRecorder) DeleteFlag(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFlag", reflect.TypeOf((*MockFlagRepo)(nil).DeleteFlag), ctx, key)
+}
+
+// ExecuteTx mocks base method.
+func (m *MockFlagRepo) ExecuteTx(ctx context.Context, fn func(usecase.FlagRepo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteTx indicates an expected call of ExecuteTx.
+func (mr *MockFlagRepoMockRecorder) ExecuteTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteTx", reflect.TypeOf((*MockFlagRepo)(nil).ExecuteTx), ctx, fn)
+}
+
+// GetFlag mocks base method.
+func (m *MockFlagRepo) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlag", ctx, key)
+	ret0, _ := ret[0].(*entity.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlag indicates an expected call of GetFlag.
+func (mr *MockFlagRepoMockRecorder) GetFlag(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlag", reflect.TypeOf((*MockFlagRepo)(nil).GetFlag), ctx, key)
+}
+
+// ListFlags mocks base method.
+func (m *MockFlagRepo) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFlags", ctx)
+	ret0, _ := ret[0].([]*entity.FeatureFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFlags indicates an expected call of ListFlags.
+func (mr *MockFlagRepoMockRecorder) ListFlags(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFlags", reflect.TypeOf((*MockFlagRepo)(nil).ListFlags), ctx)
+}
+
+// SaveFlag mocks base method.
+func (m *MockFlagRepo) SaveFlag(ctx context.Context, flag *entity.FeatureFlag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFlag", ctx, flag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFlag indicates an expected call of SaveFlag.
+func (mr *MockFlagRepoMockRecorder) SaveFlag(ctx, flag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFlag", reflect.TypeOf((*MockFlagRepo)(nil).SaveFlag), ctx, flag)
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+)
+
+const (
+	_notificationMaxMarkRead   = 500
+	_notificationMaxMessageLen = 2000
+	_notificationPurgeBatch    = 1000
+	_notificationMaxReplay     = 500
+)
+
+// ErrInvalidNotification is returned when a notification request is not acceptable.
+var ErrInvalidNotification = errors.New("invalid notification")
+
+// NotificationUseCaseImpl implements the NotificationUseCase interface.
+type NotificationUseCaseImpl struct {
+	hub       NotificationHub
+	repo      NotificationRepo
+	retention time.Duration
+}
+
+// NewNotificationUseCase creates a new NotificationUseCase. Read
+// notifications older than retention are removed by PurgeRead.
+func NewNotificationUseCase(hub NotificationHub, repo NotificationRepo, retention time.Duration) NotificationUseCase {
+	return &NotificationUseCaseImpl{
+		hub:       hub,
+		repo:      repo,
+		retention: retention,
+	}
+}
+
+// HandleEvent publishes the inbox notifications written with a committed
+// domain event to the live streams of their users. A redelivered event
+// publishes the same notifications, which the hub drops.
+func (uc *NotificationUseCaseImpl) HandleEvent(ctx context.Context, event *entity.Event) error {
+	if event.Type != entity.EventAssetPurchased {
+		return nil
+	}
+
+	var p entity.AssetPurchasedPayload
+	if err := json.Unmarshal(event.Payload, &p); err != nil {
+		return fmt.Errorf("notification - HandleEvent - json.Unmarshal: %w", err)
+	}
+
+	for _, userID := range []int64{p.SellerID, p.BuyerID} {
+		notifications, err := uc.repo.ListNotifications(ctx, userID, entity.NotificationFilter{EventID: event.ID})
+		if err != nil {
+			return fmt.Errorf("notification - HandleEvent - uc.repo.ListNotifications: %w", err)
+		}
+
+		for _, n := range notifications {
+			if err = uc.hub.Publish(ctx, n); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Subscribe streams the user's notifications until ctx is done. A client
+// resuming after lastEventID first gets the inbox notifications it missed,
+// at most _notificationMaxReplay of them, oldest first.
+func (uc *NotificationUseCaseImpl) Subscribe(
+	ctx context.Context,
+	userID, lastEventID int64,
+) (<-chan *entity.Notification, error) {
+	// Subscribing before reading the inbox loses nothing committed in between.
+	live, err := uc.hub.Subscribe(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastEventID <= 0 {
+		return live, nil
+	}
+
+	missed, err := uc.repo.ListNotifications(ctx, userID, entity.NotificationFilter{
+		AfterID: lastEventID,
+		Limit:   _notificationMaxReplay,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("notification - Subscribe - uc.repo.ListNotifications: %w", err)
+	}
+
+	out := make(chan *entity.Notification)
+
+	go func() {
+		defer close(out)
+
+		replayed := make(map[int64]bool, len(missed))
+
+		for _, n := range missed {
+			select {
+			case out <- n:
+				replayed[n.ID] = true
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for n := range live {
+			if replayed[n.ID] {
+				continue
+			}
+
+			select {
+			case out <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// ListNotifications returns the user's inbox, newest first.
+func (uc *NotificationUseCaseImpl) ListNotifications(
+	ctx context.Context,
+	userID int64,
+	filter entity.NotificationFilter,
+) ([]*entity.Notification, error) {
+	return uc.repo.ListNotifications(ctx, userID, filter)
+}
+
+// UnreadCount returns the number of unread notifications of the user.
+func (uc *NotificationUseCaseImpl) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	return uc.repo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks the given notifications of the user as read and returns
+// how many changed. IDs of other users' notifications are ignored.
+func (uc *NotificationUseCaseImpl) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: at least one id is required", ErrInvalidNotification)
+	}
+
+	if len(ids) > _notificationMaxMarkRead {
+		return 0, fmt.Errorf("%w: at most %d ids per request", ErrInvalidNotification, _notificationMaxMarkRead)
+	}
+
+	return uc.repo.MarkRead(ctx, userID, ids)
+}
+
+// MarkAllRead marks every notification of the user as read.
+func (uc *NotificationUseCaseImpl) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return uc.repo.MarkAllRead(ctx, userID)
+}
+
+// GetPreferences returns the user's preference for every notification type.
+func (uc *NotificationUseCaseImpl) GetPreferences(
+	ctx context.Context,
+	userID int64,
+) ([]*entity.NotificationPreference, error) {
+	stored, err := uc.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	disabled := make(map[string]bool, len(stored))
+	for _, p := range stored {
+		disabled[p.Type] = !p.Enabled
+	}
+
+	prefs := make([]*entity.NotificationPreference, 0, len(entity.NotificationTypes))
+	for _, t := range entity.NotificationTypes {
+		prefs = append(prefs, &entity.NotificationPreference{Type: t, Enabled: !disabled[t]})
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences turns the given notification types on or off and
+// returns the resulting preferences.
+func (uc *NotificationUseCaseImpl) UpdatePreferences(
+	ctx context.Context,
+	userID int64,
+	updates map[string]bool,
+) ([]*entity.NotificationPreference, error) {
+	prefs := make([]*entity.NotificationPreference, 0, len(updates))
+
+	for t, enabled := range updates {
+		if !entity.IsNotificationType(t) {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidNotification, t)
+		}
+
+		prefs = append(prefs, &entity.NotificationPreference{Type: t, Enabled: enabled})
+	}
+
+	if err := uc.repo.SetPreferences(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+
+	return uc.GetPreferences(ctx, userID)
+}
+
+// SendAdminMessage puts a message from an administrator into the user's
+// inbox and publishes it to the user's live streams.
+func (uc *NotificationUseCaseImpl) SendAdminMessage(
+	ctx context.Context,
+	adminID, userID int64,
+	message string,
+) (*entity.Notification, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("%w: message is required", ErrInvalidNotification)
+	}
+
+	if len(message) > _notificationMaxMessageLen {
+		return nil, fmt.Errorf("%w: message is longer than %d bytes", ErrInvalidNotification, _notificationMaxMessageLen)
+	}
+
+	n, err := newNotification(userID, entity.NotificationAdminMessage,
+		entity.AdminMessageData{SenderID: adminID, Message: message})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = uc.repo.CreateNotification(ctx, n); err != nil {
+		return nil, err
+	}
+
+	// Stored already: a client that misses it live finds it in the inbox.
+	if err = uc.hub.Publish(ctx, n); err != nil {
+		return nil, fmt.Errorf("notification - SendAdminMessage - uc.hub.Publish: %w", err)
+	}
+
+	return n, nil
+}
+
+// PurgeRead removes one batch of read notifications past the retention
+// period and returns how many were removed.
+func (uc *NotificationUseCaseImpl) PurgeRead(ctx context.Context) (int, error) {
+	n, err := uc.repo.DeleteReadBefore(ctx, time.Now().Add(-uc.retention), _notificationPurgeBatch)
+	if err != nil {
+		return 0, fmt.Errorf("notification - PurgeRead - uc.repo.DeleteReadBefore: %w", err)
+	}
+
+	return int(n), nil
+}
+
+// addNotification stores a notification in the inbox of the current
+// transaction, to be published to live streams by the event eventID.
+func addNotification(
+	ctx context.Context,
+	repo NotificationRepo,
+	eventID, userID int64,
+	notificationType string,
+	data interface{},
+) error {
+	n, err := newNotification(userID, notificationType, data)
+	if err != nil {
+		return err
+	}
+
+	n.EventID = eventID
+
+	return repo.CreateNotification(ctx, n)
+}
+
+func newNotification(userID int64, notificationType string, data interface{}) (*entity.Notification, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("notification data: %w", err)
+	}
+
+	return &entity.Notification{
+		UserID: userID,
+		Type:   notificationType,
+		Data:   b,
+	}, nil
+}
@@ -0,0 +1,249 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type NotificationUseCaseSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	ctx  context.Context
+
+	// Mocked units
+	mockHub  *MockNotificationHub
+	mockRepo *MockNotificationRepo
+
+	// Tested usecase
+	notificationUseCase usecase.NotificationUseCase
+}
+
+func (t *NotificationUseCaseSuite) SetupTest() {
+	t.ctx = context.Background()
+	t.ctrl = gomock.NewController(t.T())
+	t.mockHub = NewMockNotificationHub(t.ctrl)
+	t.mockRepo = NewMockNotificationRepo(t.ctrl)
+	t.notificationUseCase = usecase.NewNotificationUseCase(t.mockHub, t.mockRepo, 24*time.Hour)
+}
+
+func TestNotificationUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(NotificationUseCaseSuite))
+}
+
+func (t *NotificationUseCaseSuite) TestHandleEvent_PublishesInboxNotifications() {
+	sold := &entity.Notification{ID: 40, UserID: 2, Type: entity.NotificationAssetSold, EventID: 7}
+	bought := &entity.Notification{ID: 41, UserID: 3, Type: entity.NotificationAssetBought, EventID: 7}
+
+	t.mockRepo.EXPECT().ListNotifications(t.ctx, int64(2), entity.NotificationFilter{EventID: 7}).
+		Return([]*entity.Notification{sold}, nil)
+	t.mockRepo.EXPECT().ListNotifications(t.ctx, int64(3), entity.NotificationFilter{EventID: 7}).
+		Return([]*entity.Notification{bought}, nil)
+	gomock.InOrder(
+		t.mockHub.EXPECT().Publish(t.ctx, sold).Return(nil),
+		t.mockHub.EXPECT().Publish(t.ctx, bought).Return(nil),
+	)
+
+	err := t.notificationUseCase.HandleEvent(t.ctx, &entity.Event{
+		ID:      7,
+		Type:    entity.EventAssetPurchased,
+		Payload: json.RawMessage(`{"asset_id":1,"seller_id":2,"buyer_id":3,"price":10}`),
+	})
+
+	t.NoError(err)
+}
+
+func (t *NotificationUseCaseSuite) TestHandleEvent_IgnoresOtherEvents() {
+	err := t.notificationUseCase.HandleEvent(t.ctx, &entity.Event{Type: entity.EventAssetCreated})
+
+	t.NoError(err)
+}
+
+func (t *NotificationUseCaseSuite) TestHandleEvent_SkipsUsersWithoutNotification() {
+	// The seller turned asset.sold off, so the inbox has nothing for them.
+	t.mockRepo.EXPECT().ListNotifications(t.ctx, int64(2), gomock.Any()).Return([]*entity.Notification{}, nil)
+	t.mockRepo.EXPECT().ListNotifications(t.ctx, int64(3), gomock.Any()).
+		Return([]*entity.Notification{{ID: 41, UserID: 3}}, nil)
+	t.mockHub.EXPECT().Publish(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, n *entity.Notification) error {
+			t.Equal(int64(3), n.UserID)
+
+			return nil
+		},
+	)
+
+	err := t.notificationUseCase.HandleEvent(t.ctx, &entity.Event{
+		Type:    entity.EventAssetPurchased,
+		Payload: json.RawMessage(`{"asset_id":1,"seller_id":2,"buyer_id":3}`),
+	})
+
+	t.NoError(err)
+}
+
+func (t *NotificationUseCaseSuite) TestHandleEvent_ReturnsError_WhenHubFails() {
+	t.mockRepo.EXPECT().ListNotifications(t.ctx, gomock.Any(), gomock.Any()).
+		Return([]*entity.Notification{{ID: 40, UserID: 2}}, nil)
+	t.mockHub.EXPECT().Publish(t.ctx, gomock.Any()).Return(assert.AnError)
+
+	err := t.notificationUseCase.HandleEvent(t.ctx, &entity.Event{
+		Type:    entity.EventAssetPurchased,
+		Payload: json.RawMessage(`{"asset_id":1,"seller_id":2,"buyer_id":3}`),
+	})
+
+	t.ErrorIs(err, assert.AnError)
+}
+
+func (t *NotificationUseCaseSuite) TestHandleEvent_ReturnsError_WhenRepoFails() {
+	t.mockRepo.EXPECT().ListNotifications(t.ctx, gomock.Any(), gomock.Any()).Return(nil, assert.AnError)
+
+	err := t.notificationUseCase.HandleEvent(t.ctx, &entity.Event{
+		Type:    entity.EventAssetPurchased,
+		Payload: json.RawMessage(`{"asset_id":1,"seller_id":2,"buyer_id":3}`),
+	})
+
+	t.ErrorIs(err, assert.AnError)
+}
+
+func (t *NotificationUseCaseSuite) TestSubscribe_WithoutLastEventIDStreamsLive() {
+	live := make(chan *entity.Notification)
+	t.mockHub.EXPECT().Subscribe(t.ctx, int64(1)).Return(live, nil)
+
+	ch, err := t.notificationUseCase.Subscribe(t.ctx, 1, 0)
+
+	t.NoError(err)
+	t.Equal((<-chan *entity.Notification)(live), ch)
+}
+
+func (t *NotificationUseCaseSuite) TestSubscribe_ResumesFromInbox() {
+	ctx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
+
+	live := make(chan *entity.Notification, 2)
+	t.mockHub.EXPECT().Subscribe(ctx, int64(1)).Return(live, nil)
+	t.mockRepo.EXPECT().ListNotifications(ctx, int64(1), entity.NotificationFilter{AfterID: 5, Limit: 500}).
+		Return([]*entity.Notification{{ID: 6, UserID: 1}, {ID: 8, UserID: 1}}, nil)
+
+	ch, err := t.notificationUseCase.Subscribe(ctx, 1, 5)
+	t.Require().NoError(err)
+
+	// Notification 8 was committed between subscribing and reading the inbox.
+	live <- &entity.Notification{ID: 8, UserID: 1}
+	live <- &entity.Notification{ID: 7, UserID: 1}
+	close(live)
+
+	var got []int64
+	for n := range ch {
+		got = append(got, n.ID)
+	}
+
+	t.Equal([]int64{6, 8, 7}, got)
+}
+
+func (t *NotificationUseCaseSuite) TestSubscribe_ReturnsError_WhenRepoFails() {
+	t.mockHub.EXPECT().Subscribe(t.ctx, int64(1)).Return(make(chan *entity.Notification), nil)
+	t.mockRepo.EXPECT().ListNotifications(t.ctx, int64(1), gomock.Any()).Return(nil, assert.AnError)
+
+	_, err := t.notificationUseCase.Subscribe(t.ctx, 1, 5)
+
+	t.ErrorIs(err, assert.AnError)
+}
+
+func (t *NotificationUseCaseSuite) TestMarkRead_GreenPath() {
+	t.mockRepo.EXPECT().MarkRead(t.ctx, int64(1), []int64{4, 5}).Return(int64(2), nil)
+
+	n, err := t.notificationUseCase.MarkRead(t.ctx, 1, []int64{4, 5})
+
+	t.NoError(err)
+	t.Equal(int64(2), n)
+}
+
+func (t *NotificationUseCaseSuite) TestMarkRead_ReturnsError_WhenNoIDs() {
+	_, err := t.notificationUseCase.MarkRead(t.ctx, 1, nil)
+
+	t.ErrorIs(err, usecase.ErrInvalidNotification)
+}
+
+func (t *NotificationUseCaseSuite) TestGetPreferences_DefaultsToEnabled() {
+	t.mockRepo.EXPECT().ListPreferences(t.ctx, int64(1)).Return([]*entity.NotificationPreference{
+		{Type: entity.NotificationAssetBought, Enabled: false},
+	}, nil)
+
+	prefs, err := t.notificationUseCase.GetPreferences(t.ctx, 1)
+
+	t.NoError(err)
+	t.Len(prefs, len(entity.NotificationTypes))
+
+	for _, p := range prefs {
+		t.Equal(p.Type != entity.NotificationAssetBought, p.Enabled, p.Type)
+	}
+}
+
+func (t *NotificationUseCaseSuite) TestUpdatePreferences_ReturnsError_WhenTypeUnknown() {
+	_, err := t.notificationUseCase.UpdatePreferences(t.ctx, 1, map[string]bool{entity.NotificationAdminMessage: false})
+
+	t.ErrorIs(err, usecase.ErrInvalidNotification)
+}
+
+func (t *NotificationUseCaseSuite) TestSendAdminMessage_GreenPath() {
+	t.mockRepo.EXPECT().CreateNotification(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, n *entity.Notification) error {
+			t.Equal(int64(2), n.UserID)
+			t.Equal(entity.NotificationAdminMessage, n.Type)
+			t.JSONEq(`{"sender_id":1,"message":"hello"}`, string(n.Data))
+			n.ID = 9
+
+			return nil
+		},
+	)
+	t.mockHub.EXPECT().Publish(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, n *entity.Notification) error {
+			t.Equal(int64(9), n.ID)
+			t.Equal(int64(2), n.UserID)
+
+			return nil
+		},
+	)
+
+	n, err := t.notificationUseCase.SendAdminMessage(t.ctx, 1, 2, "  hello ")
+
+	t.NoError(err)
+	t.Equal(int64(9), n.ID)
+}
+
+func (t *NotificationUseCaseSuite) TestSendAdminMessage_ReturnsError_WhenHubFails() {
+	t.mockRepo.EXPECT().CreateNotification(t.ctx, gomock.Any()).Return(nil)
+	t.mockHub.EXPECT().Publish(t.ctx, gomock.Any()).Return(assert.AnError)
+
+	_, err := t.notificationUseCase.SendAdminMessage(t.ctx, 1, 2, "hello")
+
+	t.ErrorIs(err, assert.AnError)
+}
+
+func (t *NotificationUseCaseSuite) TestSendAdminMessage_ReturnsError_WhenEmpty() {
+	_, err := t.notificationUseCase.SendAdminMessage(t.ctx, 1, 2, " ")
+
+	t.ErrorIs(err, usecase.ErrInvalidNotification)
+}
+
+func (t *NotificationUseCaseSuite) TestPurgeRead_DeletesPastRetention() {
+	t.mockRepo.EXPECT().DeleteReadBefore(t.ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, before time.Time, limit uint64) (int64, error) {
+			t.WithinDuration(time.Now().Add(-24*time.Hour), before, time.Minute)
+
+			return 3, nil
+		},
+	)
+
+	n, err := t.notificationUseCase.PurgeRead(t.ctx)
+
+	t.NoError(err)
+	t.Equal(3, n)
+}
@@ -2,60 +2,83 @@ package repo
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
 
 	"github.com/appxpy/hive-test/internal/entity"
 	"github.com/appxpy/hive-test/internal/usecase"
-	"github.com/jmoiron/sqlx"
+	"github.com/appxpy/hive-test/pkg/postgres"
 )
 
+const _assetsDefaultLimit = 50
+
 type AssetRepoImpl struct {
-	db sqlx.ExtContext
+	pg     *postgres.Postgres
+	db     postgres.Querier
+	router *ReadRouter
 }
 
-// NewAssetRepo creates a new AssetRepo with a database connection.
-func NewAssetRepo(db *sqlx.DB) usecase.AssetRepo {
+// NewAssetRepo creates a new AssetRepo on the connection pool. Reads that
+// do not lock go through router, which may be nil to read from the pool only.
+func NewAssetRepo(pg *postgres.Postgres, router *ReadRouter) usecase.AssetRepo {
 	return &AssetRepoImpl{
-		db: db,
+		pg:     pg,
+		db:     pg.Pool,
+		router: router,
 	}
 }
 
+const _assetColumns = `id, user_id, name, description, price`
+
+func scanAsset(row pgx.Row) (*entity.Asset, error) {
+	asset := &entity.Asset{}
+	err := row.Scan(&asset.ID, &asset.UserID, &asset.Name, &asset.Description, &asset.Price)
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
 func (r *AssetRepoImpl) CreateAsset(ctx context.Context, asset *entity.Asset) error {
 	query := `
         INSERT INTO assets (user_id, name, description, price)
         VALUES ($1, $2, $3, $4)
         RETURNING id`
-	return sqlx.GetContext(ctx, r.db, &asset.ID, query, asset.UserID, asset.Name, asset.Description, asset.Price)
+	err := r.db.QueryRow(ctx, query, asset.UserID, asset.Name, asset.Description, asset.Price).Scan(&asset.ID)
+	if err == nil {
+		r.router.wrote(ctx)
+	}
+	return err
 }
 
 func (r *AssetRepoImpl) DeleteAsset(ctx context.Context, assetID, userID int64) error {
 	query := `DELETE FROM assets WHERE id = $1 AND user_id = $2`
-	result, err := r.db.ExecContext(ctx, query, assetID, userID)
+	tag, err := r.db.Exec(ctx, query, assetID, userID)
 	if err != nil {
 		return err
 	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if rowsAffected == 0 {
-		return errors.New("asset not found or not owned by user")
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w or not owned by user", usecase.ErrAssetNotFound)
 	}
 
+	r.router.wrote(ctx)
 	return nil
 }
 
 func (r *AssetRepoImpl) GetAssetByID(ctx context.Context, assetID int64, forUpdate bool) (*entity.Asset, error) {
-	asset := &entity.Asset{}
-	query := `SELECT id, user_id, name, description, price FROM assets WHERE id = $1`
+	query := `SELECT ` + _assetColumns + ` FROM assets WHERE id = $1`
+	db := r.db
 	if forUpdate {
 		query += ` FOR UPDATE`
+	} else {
+		db = r.router.reader(ctx, r.db)
 	}
-	err := sqlx.GetContext(ctx, r.db, asset, query, assetID)
+	asset, err := scanAsset(db.QueryRow(ctx, query, assetID))
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
@@ -65,42 +88,92 @@ func (r *AssetRepoImpl) GetAssetByID(ctx context.Context, assetID int64, forUpda
 
 func (r *AssetRepoImpl) UpdateAssetOwner(ctx context.Context, assetID, newOwnerID int64) error {
 	query := `UPDATE assets SET user_id = $1 WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, newOwnerID, assetID)
+	_, err := r.db.Exec(ctx, query, newOwnerID, assetID)
+	if err == nil {
+		r.router.wrote(ctx)
+	}
 	return err
 }
 
 func (r *AssetRepoImpl) GetAssetsByUserID(ctx context.Context, userID int64) ([]*entity.Asset, error) {
-	var assets []*entity.Asset
-	query := `SELECT id, user_id, name, description, price FROM assets WHERE user_id = $1`
-	err := sqlx.SelectContext(ctx, r.db, &assets, query, userID)
-	if err != nil {
-		return nil, err
-	}
-	return assets, nil
+	query := `SELECT ` + _assetColumns + ` FROM assets WHERE user_id = $1`
+	rows, err := r.router.reader(ctx, r.db).Query(ctx, query, userID)
+	return collect(rows, err, scanAsset)
 }
 
-func (r *AssetRepoImpl) ExecuteTx(ctx context.Context, fn func(repo usecase.AssetRepo) error) error {
-	db, ok := r.db.(*sqlx.DB)
-	if !ok {
-		return errors.New("ExecuteTx: cannot start a transaction within an existing transaction")
-	}
+func (r *AssetRepoImpl) GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error) {
+	query := `SELECT ` + _assetColumns + ` FROM assets WHERE id = ANY($1) ORDER BY id`
+	rows, err := r.router.reader(ctx, r.db).Query(ctx, query, ids)
+	return collect(rows, err, scanAsset)
+}
 
-	tx, err := db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
+func (r *AssetRepoImpl) GetAssetsByUserIDs(ctx context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	query := `SELECT ` + _assetColumns + ` FROM assets WHERE user_id = ANY($1) ORDER BY id`
+	rows, err := r.router.reader(ctx, r.db).Query(ctx, query, userIDs)
+	return collect(rows, err, scanAsset)
+}
+
+func (r *AssetRepoImpl) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	q := r.pg.Builder.
+		Select(_assetColumns).
+		From("assets").
+		OrderBy("id")
+
+	if filter.UserID != 0 {
+		q = q.Where(squirrel.Eq{"user_id": filter.UserID})
+	}
+	if filter.ExcludeUserID != 0 {
+		q = q.Where(squirrel.NotEq{"user_id": filter.ExcludeUserID})
+	}
+	if filter.MinPrice != 0 {
+		q = q.Where(squirrel.GtOrEq{"price": filter.MinPrice})
+	}
+	if filter.MaxPrice != 0 {
+		q = q.Where(squirrel.LtOrEq{"price": filter.MaxPrice})
+	}
+	if filter.AfterID != 0 {
+		q = q.Where(squirrel.Gt{"id": filter.AfterID})
 	}
 
-	txRepo := &AssetRepoImpl{
-		db: tx,
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _assetsDefaultLimit
 	}
+	q = q.Limit(limit)
 
-	err = fn(txRepo)
+	query, args, err := q.ToSql()
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return rbErr
-		}
-		return err
+		return nil, err
 	}
 
-	return tx.Commit()
+	rows, err := r.router.reader(ctx, r.db).Query(ctx, query, args...)
+	return collect(rows, err, scanAsset)
+}
+
+func (r *AssetRepoImpl) CountAssets(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.router.reader(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM assets`).Scan(&count)
+	return count, err
+}
+
+func (r *AssetRepoImpl) Audit() usecase.AuditRepo {
+	return &AuditRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *AssetRepoImpl) Outbox() usecase.OutboxRepo {
+	return &OutboxRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *AssetRepoImpl) Notifications() usecase.NotificationRepo {
+	return &NotificationRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *AssetRepoImpl) ExecuteTx(ctx context.Context, fn func(repo usecase.AssetRepo) error) error {
+	err := postgres.ExecuteTx(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&AssetRepoImpl{pg: r.pg, db: tx})
+	})
+	if err == nil {
+		r.router.wrote(ctx)
+	}
+	return err
 }
@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+const _auditDefaultLimit = 100
+
+var _auditColumns = []string{
+	"id", "actor_id", "action", "entity_type", "entity_id", "ip", "user_agent", "request_id",
+	"before_state", "after_state", "prev_hash", "hash", "created_at",
+}
+
+type AuditRepoImpl struct {
+	pg *postgres.Postgres
+	db postgres.Querier
+}
+
+// NewAuditRepo creates a new AuditRepo on the connection pool.
+func NewAuditRepo(pg *postgres.Postgres) usecase.AuditRepo {
+	return &AuditRepoImpl{
+		pg: pg,
+		db: pg.Pool,
+	}
+}
+
+func (r *AuditRepoImpl) AppendEntry(ctx context.Context, entry *entity.AuditEntry) error {
+	// The chain lock must be held until commit, so a standalone append runs
+	// in its own short transaction.
+	if _, ok := r.db.(pgx.Tx); !ok {
+		return postgres.ExecuteTx(ctx, r.db, func(tx pgx.Tx) error {
+			return (&AuditRepoImpl{pg: r.pg, db: tx}).AppendEntry(ctx, entry)
+		})
+	}
+
+	// Locking the head row serializes appends until commit. Under READ
+	// COMMITTED the lock returns the latest head; under REPEATABLE READ and
+	// SERIALIZABLE a head committed after the snapshot fails the statement
+	// with a serialization error, and the unit of work retries.
+	var prevHash string
+	err := r.db.QueryRow(ctx, `SELECT hash FROM audit_chain_head FOR UPDATE`).Scan(&prevHash)
+	if err != nil {
+		return err
+	}
+
+	entry.PrevHash = prevHash
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+
+	query := `
+        INSERT INTO audit_log (actor_id, action, entity_type, entity_id, ip, user_agent, request_id,
+                               before_state, after_state, prev_hash, hash, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        RETURNING id`
+	err = r.db.QueryRow(ctx, query,
+		entry.ActorID, string(entry.Action), entry.EntityType, entry.EntityID, entry.IP, entry.UserAgent,
+		entry.RequestID, jsonParam(entry.Before), jsonParam(entry.After), entry.PrevHash, entry.Hash,
+		entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, `UPDATE audit_chain_head SET hash = $1`, entry.Hash)
+	return err
+}
+
+func (r *AuditRepoImpl) ListEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	q := r.pg.Builder.Select(_auditColumns...).From("audit_log")
+
+	if filter.ActorID != 0 {
+		q = q.Where(squirrel.Eq{"actor_id": filter.ActorID})
+	}
+	if filter.Action != "" {
+		q = q.Where(squirrel.Eq{"action": string(filter.Action)})
+	}
+	if filter.EntityType != "" {
+		q = q.Where(squirrel.Eq{"entity_type": filter.EntityType})
+	}
+	if filter.EntityID != 0 {
+		q = q.Where(squirrel.Eq{"entity_id": filter.EntityID})
+	}
+	if len(filter.EntityIDs) > 0 {
+		q = q.Where(squirrel.Eq{"entity_id": filter.EntityIDs})
+	}
+	if filter.RequestID != "" {
+		q = q.Where(squirrel.Eq{"request_id": filter.RequestID})
+	}
+	if !filter.From.IsZero() {
+		q = q.Where(squirrel.GtOrEq{"created_at": filter.From})
+	}
+	if !filter.To.IsZero() {
+		q = q.Where(squirrel.Lt{"created_at": filter.To})
+	}
+	if filter.AfterID != 0 {
+		q = q.Where(squirrel.Gt{"id": filter.AfterID})
+	}
+	if filter.LimitPerEntity > 0 {
+		ranked := q.Column("ROW_NUMBER() OVER (PARTITION BY entity_type, entity_id ORDER BY id) AS entity_row")
+		q = r.pg.Builder.Select(_auditColumns...).FromSelect(ranked, "ranked").
+			Where(squirrel.LtOrEq{"entity_row": filter.LimitPerEntity})
+	}
+	q = q.OrderBy("id")
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _auditDefaultLimit
+	}
+	q = q.Limit(limit).Offset(filter.Offset)
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	entries, err := collect(rows, err, scanAuditEntry)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []*entity.AuditEntry{}
+	}
+	return entries, nil
+}
+
+// scanAuditEntry reads the nullable JSON snapshots as bytes, since
+// json.RawMessage cannot hold NULL.
+func scanAuditEntry(row pgx.Row) (*entity.AuditEntry, error) {
+	var (
+		e             entity.AuditEntry
+		action        string
+		before, after []byte
+	)
+
+	err := row.Scan(&e.ID, &e.ActorID, &action, &e.EntityType, &e.EntityID, &e.IP, &e.UserAgent, &e.RequestID,
+		&before, &after, &e.PrevHash, &e.Hash, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Action = entity.AuditAction(action)
+	e.Before, e.After = before, after
+	e.CreatedAt = e.CreatedAt.UTC()
+	return &e, nil
+}
+
+// jsonParam passes a JSON snapshot as text, keeping NULL for absent snapshots.
+func jsonParam(raw []byte) interface{} {
+	if raw == nil {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,196 @@
+package cached
+
+import (
+	"context"
+	"sort"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// AssetRepo caches assets by ID, including missing ones, and the assets
+// of each owner. Catalog pages and multi-owner reads are not cached.
+type AssetRepo struct {
+	next  usecase.AssetRepo
+	cache *AssetCache
+}
+
+// NewAssetRepo decorates next with c.
+func NewAssetRepo(next usecase.AssetRepo, c *AssetCache) usecase.AssetRepo {
+	return &AssetRepo{
+		next:  next,
+		cache: c,
+	}
+}
+
+func (r *AssetRepo) CreateAsset(ctx context.Context, asset *entity.Asset) error {
+	if err := r.next.CreateAsset(ctx, asset); err != nil {
+		return err
+	}
+
+	r.cache.invalidate(ctx, []string{assetKey(asset.ID), userAssetsKey(asset.UserID)})
+	return nil
+}
+
+func (r *AssetRepo) DeleteAsset(ctx context.Context, assetID, userID int64) error {
+	if err := r.next.DeleteAsset(ctx, assetID, userID); err != nil {
+		return err
+	}
+
+	r.cache.invalidate(ctx, []string{assetKey(assetID), userAssetsKey(userID)})
+	return nil
+}
+
+// UpdateAssetOwner runs in a transaction to learn the previous owner,
+// whose assets change too.
+func (r *AssetRepo) UpdateAssetOwner(ctx context.Context, assetID, newOwnerID int64) error {
+	return r.ExecuteTx(ctx, func(repo usecase.AssetRepo) error {
+		return repo.UpdateAssetOwner(ctx, assetID, newOwnerID)
+	})
+}
+
+func (r *AssetRepo) GetAssetByID(ctx context.Context, assetID int64, forUpdate bool) (*entity.Asset, error) {
+	if forUpdate {
+		return r.next.GetAssetByID(ctx, assetID, true)
+	}
+
+	return load(ctx, r.cache, _kindAsset, assetKey(assetID), func() (*entity.Asset, error) {
+		return r.next.GetAssetByID(ctx, assetID, false)
+	})
+}
+
+func (r *AssetRepo) GetAssetsByUserID(ctx context.Context, userID int64) ([]*entity.Asset, error) {
+	return load(ctx, r.cache, _kindUserAssets, userAssetsKey(userID), func() ([]*entity.Asset, error) {
+		return r.next.GetAssetsByUserID(ctx, userID)
+	})
+}
+
+// GetAssetsByIDs serves the cached assets and loads the rest in one call.
+func (r *AssetRepo) GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = assetKey(id)
+	}
+
+	cached := get[*entity.Asset](ctx, r.cache, _kindAsset, keys...)
+
+	assets := make([]*entity.Asset, 0, len(ids))
+	missing := make([]int64, 0, len(ids)-len(cached))
+
+	for i, id := range ids {
+		asset, ok := cached[keys[i]]
+		switch {
+		case !ok:
+			missing = append(missing, id)
+		case asset != nil:
+			assets = append(assets, asset)
+		}
+	}
+
+	if len(missing) > 0 {
+		gen := r.cache.gen.Load()
+
+		loaded, err := r.next.GetAssetsByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, asset := range loaded {
+			r.cache.set(ctx, gen, assetKey(asset.ID), asset)
+		}
+
+		assets = append(assets, loaded...)
+		sort.Slice(assets, func(i, j int) bool { return assets[i].ID < assets[j].ID })
+	}
+
+	return assets, nil
+}
+
+func (r *AssetRepo) GetAssetsByUserIDs(ctx context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	return r.next.GetAssetsByUserIDs(ctx, userIDs)
+}
+
+func (r *AssetRepo) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	return r.next.ListAssets(ctx, filter)
+}
+
+func (r *AssetRepo) CountAssets(ctx context.Context) (int64, error) {
+	return r.next.CountAssets(ctx)
+}
+
+func (r *AssetRepo) Audit() usecase.AuditRepo {
+	return r.next.Audit()
+}
+
+func (r *AssetRepo) Outbox() usecase.OutboxRepo {
+	return r.next.Outbox()
+}
+
+func (r *AssetRepo) Notifications() usecase.NotificationRepo {
+	return r.next.Notifications()
+}
+
+// ExecuteTx runs fn uncached and invalidates what it changed after the commit.
+func (r *AssetRepo) ExecuteTx(ctx context.Context, fn func(repo usecase.AssetRepo) error) error {
+	keys := newKeySet()
+
+	err := r.next.ExecuteTx(ctx, func(repo usecase.AssetRepo) error {
+		return fn(&txAssetRepo{AssetRepo: repo, keys: keys})
+	})
+	if err != nil {
+		return err
+	}
+
+	r.cache.invalidate(ctx, keys.list())
+	return nil
+}
+
+// txAssetRepo is the AssetRepo of a transaction. It reads from the
+// database and records the keys its writes invalidate.
+type txAssetRepo struct {
+	usecase.AssetRepo
+	keys *keySet
+}
+
+func (r *txAssetRepo) CreateAsset(ctx context.Context, asset *entity.Asset) error {
+	if err := r.AssetRepo.CreateAsset(ctx, asset); err != nil {
+		return err
+	}
+
+	r.keys.add(assetKey(asset.ID), userAssetsKey(asset.UserID))
+	return nil
+}
+
+func (r *txAssetRepo) DeleteAsset(ctx context.Context, assetID, userID int64) error {
+	if err := r.AssetRepo.DeleteAsset(ctx, assetID, userID); err != nil {
+		return err
+	}
+
+	r.keys.add(assetKey(assetID), userAssetsKey(userID))
+	return nil
+}
+
+// UpdateAssetOwner locks the asset first, so the previous owner it reads
+// is the one the update replaces.
+func (r *txAssetRepo) UpdateAssetOwner(ctx context.Context, assetID, newOwnerID int64) error {
+	before, err := r.AssetRepo.GetAssetByID(ctx, assetID, true)
+	if err != nil {
+		return err
+	}
+
+	if err = r.AssetRepo.UpdateAssetOwner(ctx, assetID, newOwnerID); err != nil {
+		return err
+	}
+
+	r.keys.add(assetKey(assetID), userAssetsKey(newOwnerID))
+	if before != nil {
+		r.keys.add(userAssetsKey(before.UserID))
+	}
+	return nil
+}
+
+func (r *txAssetRepo) ExecuteTx(ctx context.Context, fn func(repo usecase.AssetRepo) error) error {
+	return r.AssetRepo.ExecuteTx(ctx, func(repo usecase.AssetRepo) error {
+		return fn(&txAssetRepo{AssetRepo: repo, keys: r.keys})
+	})
+}
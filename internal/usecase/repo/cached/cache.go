@@ -0,0 +1,207 @@
+// Package cached decorates repositories with a read-through cache. Reads
+// outside transactions are served from the cache; writes, including those
+// of units of work, invalidate the affected keys after the commit. Reads
+// inside transactions always go to the database.
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/appxpy/hive-test/pkg/cache"
+)
+
+// Kinds of cached values, the label of their metrics.
+const (
+	_kindAsset      = "asset"
+	_kindUserAssets = "user_assets"
+)
+
+func assetKey(assetID int64) string {
+	return _kindAsset + ":" + strconv.FormatInt(assetID, 10)
+}
+
+func userAssetsKey(userID int64) string {
+	return _kindUserAssets + ":" + strconv.FormatInt(userID, 10)
+}
+
+// AssetCache holds the cached assets shared by the decorated AssetRepo
+// and UnitOfWork.
+type AssetCache struct {
+	cache cache.Cache
+	bus   cache.Invalidator
+	ttl   time.Duration
+
+	group singleflight.Group
+	// gen changes with every invalidation. A load that started before
+	// one may have read the old rows and does not cache its result.
+	gen atomic.Uint64
+
+	stats  map[string]*stats
+	errors atomic.Uint64
+}
+
+type stats struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewAssetCache keeps entries in c for ttl. Invalidations are also
+// published on bus, which may be nil, so that other instances drop their
+// copies; see Listen.
+func NewAssetCache(c cache.Cache, bus cache.Invalidator, ttl time.Duration) *AssetCache {
+	return &AssetCache{
+		cache: c,
+		bus:   bus,
+		ttl:   ttl,
+		stats: map[string]*stats{
+			_kindAsset:      {},
+			_kindUserAssets: {},
+		},
+	}
+}
+
+// Listen drops the keys invalidated by other instances until ctx is done.
+func (c *AssetCache) Listen(ctx context.Context) error {
+	if c.bus == nil {
+		return nil
+	}
+
+	return c.bus.Subscribe(ctx, func(keys []string) {
+		c.gen.Add(1)
+
+		if err := c.cache.Delete(ctx, keys...); err != nil {
+			c.errors.Add(1)
+		}
+	})
+}
+
+// get returns the cached values of keys, decoded into T. Cache errors count
+// as misses, so a broken cache only slows reads down.
+func get[T any](ctx context.Context, c *AssetCache, kind string, keys ...string) map[string]T {
+	values := make(map[string]T, len(keys))
+
+	raw, err := c.cache.Get(ctx, keys...)
+	if err != nil {
+		c.errors.Add(1)
+	}
+
+	for key, b := range raw {
+		var v T
+		if json.Unmarshal(b, &v) == nil {
+			values[key] = v
+		}
+	}
+
+	c.stats[kind].hits.Add(uint64(len(values)))
+	c.stats[kind].misses.Add(uint64(len(keys) - len(values)))
+
+	return values
+}
+
+// set caches v under key unless an invalidation happened since gen.
+func (c *AssetCache) set(ctx context.Context, gen uint64, key string, v interface{}) {
+	if c.gen.Load() != gen {
+		return
+	}
+
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = c.cache.Set(ctx, key, b, c.ttl)
+	}
+	if err != nil {
+		c.errors.Add(1)
+	}
+}
+
+// load returns the value of key, loading and caching it on a miss.
+// Concurrent misses of one key share a single load.
+func load[T any](ctx context.Context, c *AssetCache, kind, key string, fn func() (T, error)) (T, error) {
+	if v, ok := get[T](ctx, c, kind, key)[key]; ok {
+		return v, nil
+	}
+
+	b, err, _ := c.group.Do(key, func() (interface{}, error) {
+		gen := c.gen.Load()
+
+		v, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		c.set(ctx, gen, key, v)
+
+		// Every caller decodes its own copy.
+		return json.Marshal(v)
+	})
+
+	var v T
+	if err != nil {
+		return v, err
+	}
+
+	err = json.Unmarshal(b.([]byte), &v)
+	return v, err
+}
+
+// invalidate deletes keys here and on the other instances. The write has
+// committed by now, so a failure is only counted; the entries expire after
+// the TTL anyway.
+func (c *AssetCache) invalidate(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	// Loads in flight may have read the old rows; forget them so the next
+	// miss loads again.
+	c.gen.Add(1)
+	for _, key := range keys {
+		c.group.Forget(key)
+	}
+
+	if err := c.cache.Delete(ctx, keys...); err != nil {
+		c.errors.Add(1)
+	}
+
+	if c.bus != nil {
+		if err := c.bus.Publish(ctx, keys...); err != nil {
+			c.errors.Add(1)
+		}
+	}
+}
+
+// keySet collects the keys a transaction invalidates.
+type keySet struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func newKeySet() *keySet {
+	return &keySet{keys: make(map[string]struct{})}
+}
+
+func (s *keySet) add(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		s.keys[key] = struct{}{}
+	}
+}
+
+func (s *keySet) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,240 @@
+package cached_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/repo/cached"
+	"github.com/appxpy/hive-test/internal/usecase/repo/memory"
+	"github.com/appxpy/hive-test/internal/usecase/repo/repotest"
+	"github.com/appxpy/hive-test/pkg/cache"
+)
+
+const _ttl = time.Minute
+
+func newRedis(t *testing.T) *cache.Redis {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+
+	r, err := cache.NewRedis("redis://" + srv.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	return r
+}
+
+func TestContract_LRU(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) (usecase.UserRepo, usecase.AssetRepo) {
+		s := memory.New()
+		c := cached.NewAssetCache(cache.NewLRU(), nil, _ttl)
+		return memory.NewUserRepo(s), cached.NewAssetRepo(memory.NewAssetRepo(s), c)
+	})
+}
+
+func TestContract_Redis(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) (usecase.UserRepo, usecase.AssetRepo) {
+		s := memory.New()
+		c := cached.NewAssetCache(newRedis(t), nil, _ttl)
+		return memory.NewUserRepo(s), cached.NewAssetRepo(memory.NewAssetRepo(s), c)
+	})
+}
+
+// countingRepo counts the reads that reach the database.
+type countingRepo struct {
+	usecase.AssetRepo
+	reads atomic.Int64
+	delay time.Duration
+}
+
+func (r *countingRepo) GetAssetByID(ctx context.Context, assetID int64, forUpdate bool) (*entity.Asset, error) {
+	r.reads.Add(1)
+	time.Sleep(r.delay)
+	return r.AssetRepo.GetAssetByID(ctx, assetID, forUpdate)
+}
+
+func (r *countingRepo) GetAssetsByUserID(ctx context.Context, userID int64) ([]*entity.Asset, error) {
+	r.reads.Add(1)
+	time.Sleep(r.delay)
+	return r.AssetRepo.GetAssetsByUserID(ctx, userID)
+}
+
+type fixture struct {
+	ctx    context.Context
+	store  *memory.Store
+	next   *countingRepo
+	cache  *cached.AssetCache
+	assets usecase.AssetRepo
+	uow    usecase.UnitOfWork
+	alice  *entity.User
+	bob    *entity.User
+}
+
+func newFixture(t *testing.T, c cache.Cache, bus cache.Invalidator) *fixture {
+	t.Helper()
+
+	f := &fixture{ctx: context.Background(), store: memory.New()}
+	f.next = &countingRepo{AssetRepo: memory.NewAssetRepo(f.store)}
+	f.cache = cached.NewAssetCache(c, bus, _ttl)
+	f.assets = cached.NewAssetRepo(f.next, f.cache)
+	f.uow = cached.NewUnitOfWork(memory.NewUnitOfWork(f.store, 0), f.cache)
+
+	users := memory.NewUserRepo(f.store)
+	f.alice = &entity.User{Username: "alice", PasswordHash: "x"}
+	f.bob = &entity.User{Username: "bob", PasswordHash: "x"}
+	require.NoError(t, users.CreateUser(f.ctx, f.alice))
+	require.NoError(t, users.CreateUser(f.ctx, f.bob))
+
+	return f
+}
+
+func TestAssetRepo_ServesRepeatedReadsFromCache(t *testing.T) {
+	f := newFixture(t, cache.NewLRU(), nil)
+	asset := &entity.Asset{UserID: f.alice.ID, Name: "lamp", Price: 1}
+	require.NoError(t, f.assets.CreateAsset(f.ctx, asset))
+
+	for i := 0; i < 3; i++ {
+		got, err := f.assets.GetAssetByID(f.ctx, asset.ID, false)
+		require.NoError(t, err)
+		require.Equal(t, asset, got)
+
+		owned, err := f.assets.GetAssetsByUserID(f.ctx, f.alice.ID)
+		require.NoError(t, err)
+		require.Equal(t, []*entity.Asset{asset}, owned)
+	}
+
+	require.EqualValues(t, 2, f.next.reads.Load())
+
+	// Locking reads always go to the database.
+	_, err := f.assets.GetAssetByID(f.ctx, asset.ID, true)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, f.next.reads.Load())
+}
+
+func TestAssetRepo_CoalescesConcurrentMisses(t *testing.T) {
+	f := newFixture(t, cache.NewLRU(), nil)
+	f.next.delay = 50 * time.Millisecond
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.assets.GetAssetsByUserID(f.ctx, f.alice.ID)
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	require.EqualValues(t, 1, f.next.reads.Load())
+}
+
+func TestUnitOfWork_InvalidatesAfterCommit(t *testing.T) {
+	f := newFixture(t, cache.NewLRU(), nil)
+	asset := &entity.Asset{UserID: f.alice.ID, Name: "lamp", Price: 1}
+	require.NoError(t, f.assets.CreateAsset(f.ctx, asset))
+
+	// Warm the cache.
+	_, err := f.assets.GetAssetsByUserID(f.ctx, f.alice.ID)
+	require.NoError(t, err)
+	_, err = f.assets.GetAssetsByUserID(f.ctx, f.bob.ID)
+	require.NoError(t, err)
+
+	err = f.uow.Do(f.ctx, func(ctx context.Context, repos usecase.Repos) error {
+		reads := f.next.reads.Load()
+
+		// Reads inside the transaction bypass the cache.
+		got, err := repos.Assets().GetAssetByID(ctx, asset.ID, true)
+		require.NoError(t, err)
+		require.Equal(t, reads, f.next.reads.Load())
+
+		return f.uow.Do(ctx, func(ctx context.Context, repos usecase.Repos) error {
+			return repos.Assets().UpdateAssetOwner(ctx, got.ID, f.bob.ID)
+		})
+	})
+	require.NoError(t, err)
+
+	owned, err := f.assets.GetAssetsByUserID(f.ctx, f.alice.ID)
+	require.NoError(t, err)
+	require.Empty(t, owned, "the previous owner is invalidated")
+
+	owned, err = f.assets.GetAssetsByUserID(f.ctx, f.bob.ID)
+	require.NoError(t, err)
+	require.Len(t, owned, 1, "the new owner is invalidated")
+}
+
+func TestUnitOfWork_KeepsCacheOnRollback(t *testing.T) {
+	f := newFixture(t, cache.NewLRU(), nil)
+
+	_, err := f.assets.GetAssetsByUserID(f.ctx, f.alice.ID)
+	require.NoError(t, err)
+
+	err = f.uow.Do(f.ctx, func(ctx context.Context, repos usecase.Repos) error {
+		require.NoError(t, repos.Assets().CreateAsset(ctx, &entity.Asset{UserID: f.alice.ID, Name: "lamp"}))
+		return context.Canceled
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	owned, err := f.assets.GetAssetsByUserID(f.ctx, f.alice.ID)
+	require.NoError(t, err)
+	require.Empty(t, owned)
+	require.EqualValues(t, 1, f.next.reads.Load())
+}
+
+func TestAssetCache_InvalidatesOtherInstances(t *testing.T) {
+	bus := newRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// Two instances with their own in-process caches on one database.
+	a := newFixture(t, cache.NewLRU(), bus)
+	b := &fixture{ctx: a.ctx, store: a.store, next: &countingRepo{AssetRepo: memory.NewAssetRepo(a.store)}}
+	b.cache = cached.NewAssetCache(cache.NewLRU(), bus, _ttl)
+	b.assets = cached.NewAssetRepo(b.next, b.cache)
+	require.NoError(t, b.cache.Listen(ctx))
+
+	asset := &entity.Asset{UserID: a.alice.ID, Name: "lamp", Price: 1}
+	require.NoError(t, a.assets.CreateAsset(a.ctx, asset))
+
+	got, err := b.assets.GetAssetByID(b.ctx, asset.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, a.alice.ID, got.UserID)
+
+	require.NoError(t, a.assets.UpdateAssetOwner(a.ctx, asset.ID, a.bob.ID))
+
+	require.Eventually(t, func() bool {
+		got, err := b.assets.GetAssetByID(b.ctx, asset.ID, false)
+		return err == nil && got.UserID == a.bob.ID
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAssetRepo_GetAssetsByIDsLoadsOnlyMisses(t *testing.T) {
+	f := newFixture(t, cache.NewLRU(), nil)
+
+	lamp := &entity.Asset{UserID: f.alice.ID, Name: "lamp", Price: 1}
+	desk := &entity.Asset{UserID: f.bob.ID, Name: "desk", Price: 2}
+	require.NoError(t, f.assets.CreateAsset(f.ctx, lamp))
+	require.NoError(t, f.assets.CreateAsset(f.ctx, desk))
+
+	_, err := f.assets.GetAssetByID(f.ctx, desk.ID, false)
+	require.NoError(t, err)
+
+	got, err := f.assets.GetAssetsByIDs(f.ctx, []int64{desk.ID, lamp.ID, desk.ID + 100})
+	require.NoError(t, err)
+	require.Equal(t, []*entity.Asset{lamp, desk}, got)
+
+	// The loaded asset is cached now.
+	reads := f.next.reads.Load()
+	cachedLamp, err := f.assets.GetAssetByID(f.ctx, lamp.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, lamp, cachedLamp)
+	require.Equal(t, reads, f.next.reads.Load())
+}
@@ -0,0 +1,48 @@
+package cached
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const _metricsNamespace = "asset_cache"
+
+// collector exports the hit and miss counters of an AssetCache.
+type collector struct {
+	c *AssetCache
+
+	hits   *prometheus.Desc
+	misses *prometheus.Desc
+	errors *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*collector)(nil)
+
+// NewCollector returns a Prometheus collector of the lookups of c.
+func NewCollector(c *AssetCache) prometheus.Collector {
+	return &collector{
+		c: c,
+		hits: prometheus.NewDesc(prometheus.BuildFQName(_metricsNamespace, "", "hits_total"),
+			"Lookups served from the cache.", []string{"kind"}, nil),
+		misses: prometheus.NewDesc(prometheus.BuildFQName(_metricsNamespace, "", "misses_total"),
+			"Lookups that went to the database.", []string{"kind"}, nil),
+		errors: prometheus.NewDesc(prometheus.BuildFQName(_metricsNamespace, "", "errors_total"),
+			"Failed cache operations.", nil, nil),
+	}
+}
+
+// Describe -.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.errors
+}
+
+// Collect -.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	for kind, s := range c.c.stats {
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.hits.Load()), kind)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.misses.Load()), kind)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(c.c.errors.Load()))
+}
@@ -0,0 +1,75 @@
+package cached
+
+import (
+	"context"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// keysKey is the context key of the keys the running unit of work invalidates.
+type keysKey struct{}
+
+type UnitOfWork struct {
+	next  usecase.UnitOfWork
+	cache *AssetCache
+}
+
+// NewUnitOfWork decorates next so that the assets its units of work change
+// are invalidated in c after the outermost commit.
+func NewUnitOfWork(next usecase.UnitOfWork, c *AssetCache) usecase.UnitOfWork {
+	return &UnitOfWork{
+		next:  next,
+		cache: c,
+	}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos usecase.Repos) error) error {
+	return u.run(ctx, fn, u.next.Do)
+}
+
+func (u *UnitOfWork) DoIsolated(
+	ctx context.Context,
+	level usecase.IsolationLevel,
+	fn func(ctx context.Context, repos usecase.Repos) error,
+) error {
+	return u.run(ctx, fn, func(ctx context.Context, fn func(ctx context.Context, repos usecase.Repos) error) error {
+		return u.next.DoIsolated(ctx, level, fn)
+	})
+}
+
+func (u *UnitOfWork) run(
+	ctx context.Context,
+	fn func(ctx context.Context, repos usecase.Repos) error,
+	do func(ctx context.Context, fn func(ctx context.Context, repos usecase.Repos) error) error,
+) error {
+	// A nested unit of work commits with the outer one, which invalidates.
+	if keys, ok := ctx.Value(keysKey{}).(*keySet); ok {
+		return do(ctx, func(ctx context.Context, repos usecase.Repos) error {
+			return fn(ctx, &txRepos{Repos: repos, keys: keys})
+		})
+	}
+
+	// Keys of attempts that were rolled back and retried stay in the set;
+	// invalidating them as well is harmless.
+	keys := newKeySet()
+
+	err := do(context.WithValue(ctx, keysKey{}, keys), func(ctx context.Context, repos usecase.Repos) error {
+		return fn(ctx, &txRepos{Repos: repos, keys: keys})
+	})
+	if err != nil {
+		return err
+	}
+
+	u.cache.invalidate(ctx, keys.list())
+	return nil
+}
+
+// txRepos are the repositories of one transaction with the recording AssetRepo.
+type txRepos struct {
+	usecase.Repos
+	keys *keySet
+}
+
+func (r *txRepos) Assets() usecase.AssetRepo {
+	return &txAssetRepo{AssetRepo: r.Repos.Assets(), keys: r.keys}
+}
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// SQLSTATE codes of constraint violations mapped to use case errors.
+const (
+	_pgForeignKeyViolation = "23503"
+	_pgUniqueViolation     = "23505"
+)
+
+// isPgError reports whether err is a Postgres error with the given SQLSTATE.
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// collect scans all rows with scan and closes them.
+func collect[T any](rows pgx.Rows, err error, scan func(row pgx.Row) (*T, error)) ([]*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+// FlagsChannel is notified with the key of a feature flag when a
+// transaction changing it commits, by a trigger of the feature_flags table.
+const FlagsChannel = "hive_feature_flags"
+
+type FlagRepoImpl struct {
+	pg *postgres.Postgres
+	db postgres.Querier
+}
+
+// NewFlagRepo creates a FlagRepo on the connection pool.
+func NewFlagRepo(pg *postgres.Postgres) usecase.FlagRepo {
+	return &FlagRepoImpl{pg: pg, db: pg.Pool}
+}
+
+const _flagColumns = `key, description, enabled, rollout, user_ids, roles, updated_at`
+
+func scanFlag(row pgx.Row) (*entity.FeatureFlag, error) {
+	f := &entity.FeatureFlag{}
+	err := row.Scan(&f.Key, &f.Description, &f.Enabled, &f.Rollout, &f.Users, &f.Roles, &f.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (r *FlagRepoImpl) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	query := `SELECT ` + _flagColumns + ` FROM feature_flags ORDER BY key`
+	rows, err := r.db.Query(ctx, query)
+	flags, err := collect(rows, err, scanFlag)
+	if err != nil {
+		return nil, err
+	}
+	if flags == nil {
+		flags = []*entity.FeatureFlag{}
+	}
+	return flags, nil
+}
+
+func (r *FlagRepoImpl) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	query := `SELECT ` + _flagColumns + ` FROM feature_flags WHERE key = $1`
+	flag, err := scanFlag(r.db.QueryRow(ctx, query, key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrFlagNotFound
+	}
+	return flag, err
+}
+
+func (r *FlagRepoImpl) SaveFlag(ctx context.Context, flag *entity.FeatureFlag) error {
+	query := `
+        INSERT INTO feature_flags (key, description, enabled, rollout, user_ids, roles, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, now())
+        ON CONFLICT (key) DO UPDATE
+        SET description = EXCLUDED.description,
+            enabled = EXCLUDED.enabled,
+            rollout = EXCLUDED.rollout,
+            user_ids = EXCLUDED.user_ids,
+            roles = EXCLUDED.roles,
+            updated_at = EXCLUDED.updated_at
+        RETURNING updated_at`
+	return r.db.QueryRow(ctx, query, flag.Key, flag.Description, flag.Enabled, flag.Rollout, flag.Users,
+		flag.Roles).Scan(&flag.UpdatedAt)
+}
+
+func (r *FlagRepoImpl) DeleteFlag(ctx context.Context, key string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM feature_flags WHERE key = $1`, key)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrFlagNotFound
+	}
+
+	return nil
+}
+
+func (r *FlagRepoImpl) Audit() usecase.AuditRepo {
+	return &AuditRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *FlagRepoImpl) ExecuteTx(ctx context.Context, fn func(repo usecase.FlagRepo) error) error {
+	return postgres.ExecuteTx(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&FlagRepoImpl{pg: r.pg, db: tx})
+	})
+}
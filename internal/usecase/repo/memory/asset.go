@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+const _assetsDefaultLimit = 50
+
+type AssetRepo struct {
+	store
+}
+
+// NewAssetRepo creates an AssetRepo on the store.
+func NewAssetRepo(s *Store) usecase.AssetRepo {
+	return &AssetRepo{store{s: s}}
+}
+
+// errUnknownUser stands in for the foreign key violation on assets.user_id.
+func errUnknownUser(userID int64) error {
+	return fmt.Errorf("memory: user %d does not exist", userID)
+}
+
+func (r *AssetRepo) CreateAsset(_ context.Context, asset *entity.Asset) error {
+	return r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if _, ok := get(t.s.committed.users, t.changes.users, asset.UserID); !ok {
+			return errUnknownUser(asset.UserID)
+		}
+
+		asset.ID = t.s.nextID("assets")
+
+		row := *asset
+		row.Price = math.Round(row.Price*100) / 100 // NUMERIC(10, 2)
+		t.changes.assets[row.ID] = &row
+		t.take(lockKey{table: "assets", id: row.ID})
+		return nil
+	})
+}
+
+func (r *AssetRepo) DeleteAsset(ctx context.Context, assetID, userID int64) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "assets", id: assetID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		a, ok := get(t.s.committed.assets, t.changes.assets, assetID)
+		if !ok || a.UserID != userID {
+			return fmt.Errorf("%w or not owned by user", usecase.ErrAssetNotFound)
+		}
+
+		t.changes.assets[assetID] = nil
+		return nil
+	})
+}
+
+func (r *AssetRepo) GetAssetByID(ctx context.Context, assetID int64, forUpdate bool) (*entity.Asset, error) {
+	var asset *entity.Asset
+
+	err := r.run(func(t *tx) error {
+		if forUpdate {
+			if err := t.lock(ctx, lockKey{table: "assets", id: assetID}); err != nil {
+				return err
+			}
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if a, ok := get(t.s.committed.assets, t.changes.assets, assetID); ok {
+			c := *a
+			asset = &c
+		}
+		return nil
+	})
+	return asset, err
+}
+
+func (r *AssetRepo) UpdateAssetOwner(ctx context.Context, assetID, newOwnerID int64) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "assets", id: assetID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		a, ok := get(t.s.committed.assets, t.changes.assets, assetID)
+		if !ok {
+			return nil
+		}
+
+		if _, ok = get(t.s.committed.users, t.changes.users, newOwnerID); !ok {
+			return errUnknownUser(newOwnerID)
+		}
+
+		row := *a
+		row.UserID = newOwnerID
+		t.changes.assets[assetID] = &row
+		return nil
+	})
+}
+
+func (r *AssetRepo) GetAssetsByUserID(ctx context.Context, userID int64) ([]*entity.Asset, error) {
+	return r.GetAssetsByUserIDs(ctx, []int64{userID})
+}
+
+func (r *AssetRepo) GetAssetsByIDs(_ context.Context, ids []int64) ([]*entity.Asset, error) {
+	want := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+
+	return r.selectAssets(func(a *entity.Asset) bool { return want[a.ID] }, 0)
+}
+
+func (r *AssetRepo) GetAssetsByUserIDs(_ context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	want := make(map[int64]bool, len(userIDs))
+	for _, id := range userIDs {
+		want[id] = true
+	}
+
+	return r.selectAssets(func(a *entity.Asset) bool { return want[a.UserID] }, 0)
+}
+
+func (r *AssetRepo) ListAssets(_ context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _assetsDefaultLimit
+	}
+
+	return r.selectAssets(func(a *entity.Asset) bool {
+		return (filter.UserID == 0 || a.UserID == filter.UserID) &&
+			(filter.ExcludeUserID == 0 || a.UserID != filter.ExcludeUserID) &&
+			(filter.MinPrice == 0 || a.Price >= filter.MinPrice) &&
+			(filter.MaxPrice == 0 || a.Price <= filter.MaxPrice) &&
+			a.ID > filter.AfterID
+	}, limit)
+}
+
+func (r *AssetRepo) CountAssets(_ context.Context) (int64, error) {
+	var count int64
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		count = int64(len(all(t.s.committed.assets, t.changes.assets)))
+		return nil
+	})
+	return count, err
+}
+
+// selectAssets returns copies of the matching assets ordered by ID, at most
+// limit of them unless limit is zero.
+func (r *AssetRepo) selectAssets(match func(a *entity.Asset) bool, limit uint64) ([]*entity.Asset, error) {
+	var assets []*entity.Asset
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		rows := all(t.s.committed.assets, t.changes.assets)
+		sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+		for _, a := range rows {
+			if limit != 0 && uint64(len(assets)) == limit {
+				break
+			}
+			if match(a) {
+				c := *a
+				assets = append(assets, &c)
+			}
+		}
+		return nil
+	})
+	return assets, err
+}
+
+func (r *AssetRepo) Audit() usecase.AuditRepo {
+	return &AuditRepo{r.store}
+}
+
+func (r *AssetRepo) Outbox() usecase.OutboxRepo {
+	return &OutboxRepo{r.store}
+}
+
+func (r *AssetRepo) Notifications() usecase.NotificationRepo {
+	return &NotificationRepo{r.store}
+}
+
+func (r *AssetRepo) ExecuteTx(_ context.Context, fn func(repo usecase.AssetRepo) error) error {
+	return r.executeTx(func(bound store) error {
+		return fn(&AssetRepo{bound})
+	})
+}
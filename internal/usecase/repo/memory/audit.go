@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+const _auditDefaultLimit = 100
+
+// _auditChainLock serializes appends like the chain head row lock of the Postgres repo.
+var _auditChainLock = lockKey{table: "audit_log.chain"}
+
+type AuditRepo struct {
+	store
+}
+
+// NewAuditRepo creates an AuditRepo on the store.
+func NewAuditRepo(s *Store) usecase.AuditRepo {
+	return &AuditRepo{store{s: s}}
+}
+
+func (r *AuditRepo) AppendEntry(ctx context.Context, entry *entity.AuditEntry) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, _auditChainLock); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		entry.PrevHash = entity.AuditGenesisHash
+
+		var lastID int64
+		for _, e := range all(t.s.committed.audit, t.changes.audit) {
+			if e.ID > lastID {
+				lastID, entry.PrevHash = e.ID, e.Hash
+			}
+		}
+
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.Hash = entry.ComputeHash()
+		entry.ID = t.s.nextID("audit_log")
+
+		t.changes.audit[entry.ID] = copyAuditEntry(entry)
+		return nil
+	})
+}
+
+func (r *AuditRepo) ListEntries(_ context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _auditDefaultLimit
+	}
+
+	entries := []*entity.AuditEntry{}
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		rows := all(t.s.committed.audit, t.changes.audit)
+		sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+		type entityKey struct {
+			entityType string
+			entityID   int64
+		}
+		perEntity := make(map[entityKey]uint64)
+
+		skip := filter.Offset
+		for _, e := range rows {
+			if uint64(len(entries)) == limit {
+				break
+			}
+			if !matchAudit(e, &filter) {
+				continue
+			}
+			if filter.LimitPerEntity > 0 {
+				key := entityKey{e.EntityType, e.EntityID}
+				if perEntity[key] == filter.LimitPerEntity {
+					continue
+				}
+				perEntity[key]++
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			entries = append(entries, copyAuditEntry(e))
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func matchAudit(e *entity.AuditEntry, f *entity.AuditFilter) bool {
+	return (f.ActorID == 0 || e.ActorID == f.ActorID) &&
+		(f.Action == "" || e.Action == f.Action) &&
+		(f.EntityType == "" || e.EntityType == f.EntityType) &&
+		(f.EntityID == 0 || e.EntityID == f.EntityID) &&
+		(len(f.EntityIDs) == 0 || containsID(f.EntityIDs, e.EntityID)) &&
+		(f.RequestID == "" || e.RequestID == f.RequestID) &&
+		(f.From.IsZero() || !e.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || e.CreatedAt.Before(f.To)) &&
+		e.ID > f.AfterID
+}
+
+func copyAuditEntry(e *entity.AuditEntry) *entity.AuditEntry {
+	c := *e
+	c.Before = append([]byte(nil), e.Before...)
+	c.After = append([]byte(nil), e.After...)
+	return &c
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+type FlagRepo struct {
+	store
+}
+
+// NewFlagRepo creates a FlagRepo on the store.
+func NewFlagRepo(s *Store) usecase.FlagRepo {
+	return &FlagRepo{store{s: s}}
+}
+
+func (r *FlagRepo) ListFlags(_ context.Context) ([]*entity.FeatureFlag, error) {
+	flags := []*entity.FeatureFlag{}
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, f := range all(t.s.committed.flags, t.changes.flags) {
+			flags = append(flags, copyFlag(f))
+		}
+		return nil
+	})
+
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags, err
+}
+
+func (r *FlagRepo) GetFlag(_ context.Context, key string) (*entity.FeatureFlag, error) {
+	var flag *entity.FeatureFlag
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		f, ok := get(t.s.committed.flags, t.changes.flags, key)
+		if !ok {
+			return usecase.ErrFlagNotFound
+		}
+
+		flag = copyFlag(f)
+		return nil
+	})
+	return flag, err
+}
+
+func (r *FlagRepo) SaveFlag(ctx context.Context, flag *entity.FeatureFlag) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "feature_flags", value: flag.Key}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		flag.UpdatedAt = time.Now().UTC()
+		t.changes.flags[flag.Key] = copyFlag(flag)
+		return nil
+	})
+}
+
+func (r *FlagRepo) DeleteFlag(ctx context.Context, key string) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "feature_flags", value: key}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if _, ok := get(t.s.committed.flags, t.changes.flags, key); !ok {
+			return usecase.ErrFlagNotFound
+		}
+
+		t.changes.flags[key] = nil
+		return nil
+	})
+}
+
+func (r *FlagRepo) Audit() usecase.AuditRepo {
+	return &AuditRepo{r.store}
+}
+
+func (r *FlagRepo) ExecuteTx(_ context.Context, fn func(repo usecase.FlagRepo) error) error {
+	return r.executeTx(func(bound store) error {
+		return fn(&FlagRepo{bound})
+	})
+}
+
+func copyFlag(f *entity.FeatureFlag) *entity.FeatureFlag {
+	c := *f
+	c.Users = append([]int64{}, f.Users...)
+	c.Roles = append([]string{}, f.Roles...)
+	return &c
+}
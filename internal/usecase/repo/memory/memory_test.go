@@ -0,0 +1,74 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/repo/memory"
+	"github.com/appxpy/hive-test/internal/usecase/repo/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) (usecase.UserRepo, usecase.AssetRepo) {
+		s := memory.New()
+		return memory.NewUserRepo(s), memory.NewAssetRepo(s)
+	})
+}
+
+func TestFlagContract(t *testing.T) {
+	repotest.RunFlags(t, func(t *testing.T) usecase.FlagRepo {
+		return memory.NewFlagRepo(memory.New())
+	})
+}
+
+func TestUnitOfWorkContract(t *testing.T) {
+	repotest.RunUnitOfWork(t, func(t *testing.T) (usecase.UnitOfWork, usecase.UserRepo) {
+		s := memory.New()
+		return memory.NewUnitOfWork(s, 0), memory.NewUserRepo(s)
+	})
+}
+
+func TestUnitOfWork_RetriesDeadlocks(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	users := memory.NewUserRepo(s)
+	uow := memory.NewUnitOfWork(s, 2)
+
+	alice := &entity.User{Username: "alice", PasswordHash: "x"}
+	bob := &entity.User{Username: "bob", PasswordHash: "x"}
+	require.NoError(t, users.CreateUser(ctx, alice))
+	require.NoError(t, users.CreateUser(ctx, bob))
+
+	// Two transactions update the same users in opposite order; one of
+	// them is picked as the deadlock victim and retried.
+	locked := make(chan struct{})
+	errs := make(chan error, 2)
+
+	update := func(first, second int64, wait bool) {
+		attempt := 0
+		errs <- uow.Do(ctx, func(ctx context.Context, repos usecase.Repos) error {
+			attempt++
+			if err := repos.Users().UpdatePasswordHash(ctx, first, "y"); err != nil {
+				return err
+			}
+			if attempt == 1 {
+				if wait {
+					<-locked
+				} else {
+					close(locked)
+				}
+			}
+			return repos.Users().UpdatePasswordHash(ctx, second, "y")
+		})
+	}
+
+	go update(alice.ID, bob.ID, true)
+	go update(bob.ID, alice.ID, false)
+
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+}
@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+const _notificationsDefaultLimit = 50
+
+type preferenceKey struct {
+	userID int64
+	typ    string
+}
+
+type NotificationRepo struct {
+	store
+}
+
+// NewNotificationRepo creates a NotificationRepo on the store.
+func NewNotificationRepo(s *Store) usecase.NotificationRepo {
+	return &NotificationRepo{store{s: s}}
+}
+
+func (r *NotificationRepo) CreateNotification(_ context.Context, n *entity.Notification) error {
+	return r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if _, ok := get(t.s.committed.users, t.changes.users, n.UserID); !ok {
+			return fmt.Errorf("%w: unknown user %d", usecase.ErrInvalidNotification, n.UserID)
+		}
+
+		pref, ok := get(t.s.committed.preferences, t.changes.preferences, preferenceKey{n.UserID, n.Type})
+		if ok && !pref.Enabled {
+			return nil
+		}
+
+		n.ID = t.s.nextID("notifications")
+		n.CreatedAt = time.Now().UTC()
+
+		t.changes.notifications[n.ID] = copyNotification(n)
+		return nil
+	})
+}
+
+func (r *NotificationRepo) ListNotifications(
+	_ context.Context,
+	userID int64,
+	filter entity.NotificationFilter,
+) ([]*entity.Notification, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _notificationsDefaultLimit
+	}
+
+	notifications := []*entity.Notification{}
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		rows := all(t.s.committed.notifications, t.changes.notifications)
+		sort.Slice(rows, func(i, j int) bool {
+			if filter.AfterID != 0 {
+				return rows[i].ID < rows[j].ID
+			}
+			return rows[i].ID > rows[j].ID
+		})
+
+		for _, n := range rows {
+			if uint64(len(notifications)) == limit {
+				break
+			}
+			if n.UserID != userID || (filter.UnreadOnly && n.ReadAt != nil) ||
+				(filter.BeforeID != 0 && n.ID >= filter.BeforeID) ||
+				(filter.AfterID != 0 && n.ID <= filter.AfterID) ||
+				(filter.EventID != 0 && n.EventID != filter.EventID) {
+				continue
+			}
+			notifications = append(notifications, copyNotification(n))
+		}
+		return nil
+	})
+	return notifications, err
+}
+
+func (r *NotificationRepo) CountUnread(_ context.Context, userID int64) (int64, error) {
+	var count int64
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, n := range all(t.s.committed.notifications, t.changes.notifications) {
+			if n.UserID == userID && n.ReadAt == nil {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (r *NotificationRepo) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	want := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+
+	return r.markRead(ctx, func(n *entity.Notification) bool {
+		return n.UserID == userID && want[n.ID]
+	})
+}
+
+func (r *NotificationRepo) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return r.markRead(ctx, func(n *entity.Notification) bool {
+		return n.UserID == userID
+	})
+}
+
+// markRead marks the matching unread notifications as read.
+func (r *NotificationRepo) markRead(ctx context.Context, match func(n *entity.Notification) bool) (int64, error) {
+	var marked int64
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		var ids []int64
+		for _, n := range all(t.s.committed.notifications, t.changes.notifications) {
+			if n.ReadAt == nil && match(n) {
+				ids = append(ids, n.ID)
+			}
+		}
+		t.s.mu.Unlock()
+
+		now := time.Now().UTC()
+
+		for _, id := range ids {
+			if err := t.lock(ctx, lockKey{table: "notifications", id: id}); err != nil {
+				return err
+			}
+
+			t.s.mu.Lock()
+			if n, ok := get(t.s.committed.notifications, t.changes.notifications, id); ok && n.ReadAt == nil {
+				c := copyNotification(n)
+				c.ReadAt = &now
+				t.changes.notifications[id] = c
+				marked++
+			}
+			t.s.mu.Unlock()
+		}
+		return nil
+	})
+	return marked, err
+}
+
+func (r *NotificationRepo) ListPreferences(_ context.Context, userID int64) ([]*entity.NotificationPreference, error) {
+	var prefs []*entity.NotificationPreference
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for k, p := range t.s.committed.preferences {
+			if _, changed := t.changes.preferences[k]; !changed && k.userID == userID {
+				prefs = append(prefs, &entity.NotificationPreference{Type: p.Type, Enabled: p.Enabled})
+			}
+		}
+		for k, p := range t.changes.preferences {
+			if p != nil && k.userID == userID {
+				prefs = append(prefs, &entity.NotificationPreference{Type: p.Type, Enabled: p.Enabled})
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(prefs, func(i, j int) bool { return prefs[i].Type < prefs[j].Type })
+	return prefs, err
+}
+
+func (r *NotificationRepo) SetPreferences(
+	ctx context.Context,
+	userID int64,
+	prefs []*entity.NotificationPreference,
+) error {
+	return r.run(func(t *tx) error {
+		for _, p := range prefs {
+			if err := t.lock(ctx, lockKey{table: "notification_preferences", id: userID, value: p.Type}); err != nil {
+				return err
+			}
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, p := range prefs {
+			t.changes.preferences[preferenceKey{userID, p.Type}] = &entity.NotificationPreference{
+				Type:    p.Type,
+				Enabled: p.Enabled,
+			}
+		}
+		return nil
+	})
+}
+
+func (r *NotificationRepo) DeleteReadBefore(_ context.Context, before time.Time, limit uint64) (int64, error) {
+	var deleted int64
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, n := range all(t.s.committed.notifications, t.changes.notifications) {
+			if uint64(deleted) == limit {
+				break
+			}
+			if n.ReadAt == nil || !n.ReadAt.Before(before) || !t.tryLock(lockKey{table: "notifications", id: n.ID}) {
+				continue
+			}
+			t.changes.notifications[n.ID] = nil
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func copyNotification(n *entity.Notification) *entity.Notification {
+	c := *n
+	c.Data = append([]byte(nil), n.Data...)
+	if n.ReadAt != nil {
+		at := *n.ReadAt
+		c.ReadAt = &at
+	}
+	return &c
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// outboxRow is an outbox event with its delivery state.
+type outboxRow struct {
+	event         entity.Event
+	lastError     string
+	nextAttemptAt time.Time
+}
+
+type OutboxRepo struct {
+	store
+}
+
+// NewOutboxRepo creates an OutboxRepo on the store.
+func NewOutboxRepo(s *Store) usecase.OutboxRepo {
+	return &OutboxRepo{store{s: s}}
+}
+
+func (r *OutboxRepo) AddEvent(_ context.Context, event *entity.Event) error {
+	return r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		event.ID = t.s.nextID("outbox")
+		event.CreatedAt = time.Now().UTC()
+		event.Attempts = 0
+
+		row := &outboxRow{event: *event, nextAttemptAt: event.CreatedAt}
+		row.event.Payload = append([]byte(nil), event.Payload...)
+		t.changes.outbox[event.ID] = row
+		t.take(lockKey{table: "outbox", id: event.ID})
+		return nil
+	})
+}
+
+func (r *OutboxRepo) ClaimPending(_ context.Context, limit uint64, lease time.Duration) ([]*entity.Event, error) {
+	var events []*entity.Event
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		rows := all(t.s.committed.outbox, t.changes.outbox)
+		sort.Slice(rows, func(i, j int) bool { return rows[i].event.ID < rows[j].event.ID })
+
+		// Only the head event of every aggregate is eligible, locked or not.
+		type aggregate struct {
+			typ string
+			id  int64
+		}
+		seen := make(map[aggregate]bool)
+		now := time.Now()
+
+		for _, row := range rows {
+			if uint64(len(events)) == limit {
+				break
+			}
+
+			agg := aggregate{row.event.AggregateType, row.event.AggregateID}
+			if seen[agg] {
+				continue
+			}
+			seen[agg] = true
+
+			if row.nextAttemptAt.After(now) || !t.tryLock(lockKey{table: "outbox", id: row.event.ID}) {
+				continue
+			}
+
+			c := *row
+			c.nextAttemptAt = now.Add(lease)
+			t.changes.outbox[row.event.ID] = &c
+
+			e := row.event
+			events = append(events, &e)
+		}
+		return nil
+	})
+	return events, err
+}
+
+func (r *OutboxRepo) MarkPublished(ctx context.Context, eventID int64) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "outbox", id: eventID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if _, ok := get(t.s.committed.outbox, t.changes.outbox, eventID); ok {
+			t.changes.outbox[eventID] = nil
+		}
+		return nil
+	})
+}
+
+func (r *OutboxRepo) MarkFailed(ctx context.Context, eventID int64, lastErr string, nextAttemptAt time.Time) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "outbox", id: eventID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		row, ok := get(t.s.committed.outbox, t.changes.outbox, eventID)
+		if !ok {
+			return nil
+		}
+
+		c := *row
+		c.event.Attempts++
+		c.lastError = lastErr
+		c.nextAttemptAt = nextAttemptAt
+		t.changes.outbox[eventID] = &c
+		return nil
+	})
+}
+
+func (r *OutboxRepo) MoveToDeadLetter(ctx context.Context, event *entity.Event, lastErr string) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "outbox", id: event.ID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		row := &outboxRow{event: *event, lastError: lastErr}
+		row.event.Attempts++
+		t.changes.deadLetters[event.ID] = row
+
+		if _, ok := get(t.s.committed.outbox, t.changes.outbox, event.ID); ok {
+			t.changes.outbox[event.ID] = nil
+		}
+		return nil
+	})
+}
+
+func (r *OutboxRepo) OldestPending(_ context.Context) (time.Time, error) {
+	var oldest time.Time
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, row := range all(t.s.committed.outbox, t.changes.outbox) {
+			if oldest.IsZero() || row.event.CreatedAt.Before(oldest) {
+				oldest = row.event.CreatedAt
+			}
+		}
+		return nil
+	})
+	return oldest, err
+}
+
+func (r *OutboxRepo) ExecuteTx(_ context.Context, fn func(repo usecase.OutboxRepo) error) error {
+	return r.executeTx(func(bound store) error {
+		return fn(&OutboxRepo{bound})
+	})
+}
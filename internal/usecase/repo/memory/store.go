@@ -0,0 +1,314 @@
+// Package memory implements the repositories in process memory, for tests
+// and for running the server without a database.
+//
+// Every operation runs in a transaction that buffers its changes and
+// applies them on commit, so other callers never see uncommitted rows.
+// Rows are locked the way Postgres locks them: by writes and by reads
+// for update, until the transaction ends.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/appxpy/hive-test/internal/entity"
+)
+
+// ErrDeadlock is returned when waiting for a row lock would never end.
+var ErrDeadlock = errors.New("memory: deadlock detected")
+
+// errNestedTx mirrors the Postgres repositories, whose ExecuteTx cannot nest.
+var errNestedTx = errors.New("ExecuteTx: cannot start a transaction within an existing transaction")
+
+// Store holds the rows of all in-memory repositories. Committed rows are
+// replaced on change and never modified in place.
+type Store struct {
+	mu        sync.Mutex
+	committed tables
+	seq       map[string]int64
+	locks     map[lockKey]*tx
+}
+
+// New creates an empty store.
+func New() *Store {
+	return &Store{
+		committed: newTables(),
+		seq:       make(map[string]int64),
+		locks:     make(map[lockKey]*tx),
+	}
+}
+
+// tables are the rows of a store or the changes of a transaction, where a
+// nil row marks a deletion.
+type tables struct {
+	users         map[int64]*entity.User
+	assets        map[int64]*entity.Asset
+	audit         map[int64]*entity.AuditEntry
+	outbox        map[int64]*outboxRow
+	deadLetters   map[int64]*outboxRow
+	webhooks      map[int64]*entity.Webhook
+	deliveries    map[int64]*entity.WebhookDelivery
+	notifications map[int64]*entity.Notification
+	preferences   map[preferenceKey]*entity.NotificationPreference
+	flags         map[string]*entity.FeatureFlag
+}
+
+func newTables() tables {
+	return tables{
+		users:         make(map[int64]*entity.User),
+		assets:        make(map[int64]*entity.Asset),
+		audit:         make(map[int64]*entity.AuditEntry),
+		outbox:        make(map[int64]*outboxRow),
+		deadLetters:   make(map[int64]*outboxRow),
+		webhooks:      make(map[int64]*entity.Webhook),
+		deliveries:    make(map[int64]*entity.WebhookDelivery),
+		notifications: make(map[int64]*entity.Notification),
+		preferences:   make(map[preferenceKey]*entity.NotificationPreference),
+		flags:         make(map[string]*entity.FeatureFlag),
+	}
+}
+
+// clone copies the maps, not the rows, which are never modified in place.
+func (t *tables) clone() tables {
+	return tables{
+		users:         cloneMap(t.users),
+		assets:        cloneMap(t.assets),
+		audit:         cloneMap(t.audit),
+		outbox:        cloneMap(t.outbox),
+		deadLetters:   cloneMap(t.deadLetters),
+		webhooks:      cloneMap(t.webhooks),
+		deliveries:    cloneMap(t.deliveries),
+		notifications: cloneMap(t.notifications),
+		preferences:   cloneMap(t.preferences),
+		flags:         cloneMap(t.flags),
+	}
+}
+
+// apply writes the changes of a transaction.
+func (t *tables) apply(changes *tables) {
+	applyMap(t.users, changes.users)
+	applyMap(t.assets, changes.assets)
+	applyMap(t.audit, changes.audit)
+	applyMap(t.outbox, changes.outbox)
+	applyMap(t.deadLetters, changes.deadLetters)
+	applyMap(t.webhooks, changes.webhooks)
+	applyMap(t.deliveries, changes.deliveries)
+	applyMap(t.notifications, changes.notifications)
+	applyMap(t.preferences, changes.preferences)
+	applyMap(t.flags, changes.flags)
+}
+
+func cloneMap[K comparable, T any](m map[K]*T) map[K]*T {
+	c := make(map[K]*T, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func applyMap[K comparable, T any](dst, changes map[K]*T) {
+	for k, v := range changes {
+		if v == nil {
+			delete(dst, k)
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+// get returns the row with key k as seen by a transaction with changes.
+func get[K comparable, T any](committed, changes map[K]*T, k K) (*T, bool) {
+	if v, ok := changes[k]; ok {
+		return v, v != nil
+	}
+	v, ok := committed[k]
+	return v, ok
+}
+
+// all returns the rows seen by a transaction with changes, in no order.
+func all[K comparable, T any](committed, changes map[K]*T) []*T {
+	rows := make([]*T, 0, len(committed)+len(changes))
+	for k, v := range committed {
+		if _, changed := changes[k]; !changed {
+			rows = append(rows, v)
+		}
+	}
+	for _, v := range changes {
+		if v != nil {
+			rows = append(rows, v)
+		}
+	}
+	return rows
+}
+
+// nextID returns the next ID of a table. Like a Postgres sequence it is not
+// reused after a rollback. The caller holds s.mu.
+func (s *Store) nextID(table string) int64 {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+// lockKey names a locked row, or a unique value such as a username.
+type lockKey struct {
+	table string
+	id    int64
+	value string
+}
+
+// tx is a transaction. It is used by one goroutine at a time; its changes
+// are guarded by the store mutex like the committed rows.
+type tx struct {
+	s       *Store
+	changes tables
+	held    []lockKey
+	done    chan struct{}
+
+	// waitingFor is the transaction holding the lock this one waits for.
+	waitingFor *tx
+}
+
+func (s *Store) begin() *tx {
+	return &tx{
+		s:       s,
+		changes: newTables(),
+		done:    make(chan struct{}),
+	}
+}
+
+// commit applies the changes and releases the locks.
+func (t *tx) commit() {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	t.s.committed.apply(&t.changes)
+	t.end()
+}
+
+// rollback drops the changes and releases the locks.
+func (t *tx) rollback() {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	t.end()
+}
+
+func (t *tx) end() {
+	for _, k := range t.held {
+		delete(t.s.locks, k)
+	}
+	t.held = nil
+	t.changes = newTables()
+	close(t.done)
+}
+
+// savepoint returns the changes to restore when a nested unit of work fails.
+// Locks taken after it are kept, as in Postgres.
+func (t *tx) savepoint() tables {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	return t.changes.clone()
+}
+
+func (t *tx) restore(sp tables) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+
+	t.changes = sp
+}
+
+// lock takes the lock on k, waiting until its holder ends. The caller must
+// not hold s.mu.
+func (t *tx) lock(ctx context.Context, k lockKey) error {
+	s := t.s
+
+	for {
+		s.mu.Lock()
+
+		holder := s.locks[k]
+		if holder == nil || holder == t {
+			t.take(k)
+			s.mu.Unlock()
+			return nil
+		}
+
+		for h := holder; h != nil; h = h.waitingFor {
+			if h == t {
+				s.mu.Unlock()
+				return ErrDeadlock
+			}
+		}
+
+		t.waitingFor = holder
+		s.mu.Unlock()
+
+		select {
+		case <-holder.done:
+		case <-ctx.Done():
+		}
+
+		s.mu.Lock()
+		t.waitingFor = nil
+		s.mu.Unlock()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// tryLock takes the lock on k unless another transaction holds it, like
+// SKIP LOCKED. The caller holds s.mu.
+func (t *tx) tryLock(k lockKey) bool {
+	holder := t.s.locks[k]
+	if holder != nil && holder != t {
+		return false
+	}
+
+	t.take(k)
+	return true
+}
+
+func (t *tx) take(k lockKey) {
+	if t.s.locks[k] == t {
+		return
+	}
+
+	t.s.locks[k] = t
+	t.held = append(t.held, k)
+}
+
+// store is embedded by the repositories. tx is nil outside of ExecuteTx
+// and of units of work, where every call runs in a transaction of its own.
+type store struct {
+	s  *Store
+	tx *tx
+}
+
+// run calls fn in the transaction of the repository or in a new one.
+func (r store) run(fn func(t *tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	t := r.s.begin()
+	if err := fn(t); err != nil {
+		t.rollback()
+		return err
+	}
+
+	t.commit()
+	return nil
+}
+
+// executeTx runs fn with a repository bound to a new transaction.
+func (r store) executeTx(fn func(bound store) error) error {
+	if r.tx != nil {
+		return errNestedTx
+	}
+
+	return r.run(func(t *tx) error {
+		return fn(store{s: r.s, tx: t})
+	})
+}
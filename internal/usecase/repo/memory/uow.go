@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// _txRetryDelay grows linearly with every retry of a unit of work.
+const _txRetryDelay = 10 * time.Millisecond
+
+// txKey is the context key of the transaction of the running unit of work.
+type txKey struct{}
+
+type UnitOfWork struct {
+	s          *Store
+	maxRetries int
+}
+
+// NewUnitOfWork creates a UnitOfWork on the store. Transactions never see
+// uncommitted rows of others, whatever the isolation level; a transaction
+// that ends up in a deadlock is retried up to maxRetries times.
+func NewUnitOfWork(s *Store, maxRetries int) usecase.UnitOfWork {
+	return &UnitOfWork{
+		s:          s,
+		maxRetries: maxRetries,
+	}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos usecase.Repos) error) error {
+	return u.DoIsolated(ctx, "", fn)
+}
+
+func (u *UnitOfWork) DoIsolated(
+	ctx context.Context,
+	_ usecase.IsolationLevel,
+	fn func(ctx context.Context, repos usecase.Repos) error,
+) error {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok && t.s == u.s {
+		sp := t.savepoint()
+
+		err := fn(ctx, &txRepos{store{s: u.s, tx: t}})
+		if err != nil {
+			t.restore(sp)
+		}
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		t := u.s.begin()
+
+		err := fn(context.WithValue(ctx, txKey{}, t), &txRepos{store{s: u.s, tx: t}})
+		if err == nil {
+			t.commit()
+			return nil
+		}
+
+		t.rollback()
+
+		if attempt >= u.maxRetries || !errors.Is(err, ErrDeadlock) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt+1) * _txRetryDelay):
+		}
+	}
+}
+
+// txRepos are the repositories of one transaction.
+type txRepos struct {
+	store
+}
+
+func (r *txRepos) Users() usecase.UserRepo {
+	return &UserRepo{r.store}
+}
+
+func (r *txRepos) Assets() usecase.AssetRepo {
+	return &AssetRepo{r.store}
+}
+
+func (r *txRepos) Audit() usecase.AuditRepo {
+	return &AuditRepo{r.store}
+}
+
+func (r *txRepos) Outbox() usecase.OutboxRepo {
+	return &OutboxRepo{r.store}
+}
+
+func (r *txRepos) Webhooks() usecase.WebhookRepo {
+	return &WebhookRepo{r.store}
+}
+
+func (r *txRepos) Notifications() usecase.NotificationRepo {
+	return &NotificationRepo{r.store}
+}
@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+const _usersDefaultLimit = 100
+
+type UserRepo struct {
+	store
+}
+
+// NewUserRepo creates a UserRepo on the store.
+func NewUserRepo(s *Store) usecase.UserRepo {
+	return &UserRepo{store{s: s}}
+}
+
+func (r *UserRepo) CreateUser(ctx context.Context, user *entity.User) error {
+	return r.run(func(t *tx) error {
+		// Like the unique index, a concurrent insert of the username waits
+		// for this transaction.
+		if err := t.lock(ctx, lockKey{table: "users.username", value: user.Username}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if findUser(t, user.Username) != nil {
+			return usecase.ErrUserExists
+		}
+
+		user.ID = t.s.nextID("users")
+
+		row := *user
+		row.SessionsRevokedAt = nil
+		t.changes.users[row.ID] = &row
+		t.take(lockKey{table: "users", id: row.ID})
+		return nil
+	})
+}
+
+func findUser(t *tx, username string) *entity.User {
+	for _, u := range all(t.s.committed.users, t.changes.users) {
+		if u.Username == username {
+			return u
+		}
+	}
+	return nil
+}
+
+func (r *UserRepo) GetUserByUsername(_ context.Context, username string) (*entity.User, error) {
+	var user *entity.User
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if u := findUser(t, username); u != nil {
+			user = copyUser(u)
+			return nil
+		}
+		return usecase.ErrUserNotFound
+	})
+	return user, err
+}
+
+func (r *UserRepo) GetUserByID(_ context.Context, userID int64) (*entity.User, error) {
+	var user *entity.User
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if u, ok := get(t.s.committed.users, t.changes.users, userID); ok {
+			user = copyUser(u)
+			return nil
+		}
+		return usecase.ErrUserNotFound
+	})
+	return user, err
+}
+
+func (r *UserRepo) GetUsersByIDs(_ context.Context, ids []int64) ([]*entity.User, error) {
+	var users []*entity.User
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, id := range uniqueIDs(ids) {
+			if u, ok := get(t.s.committed.users, t.changes.users, id); ok {
+				users = append(users, copyUser(u))
+			}
+		}
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepo) ListUsers(_ context.Context, afterID int64, limit uint64) ([]*entity.User, error) {
+	if limit == 0 {
+		limit = _usersDefaultLimit
+	}
+
+	var users []*entity.User
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		rows := all(t.s.committed.users, t.changes.users)
+		sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+
+		for _, u := range rows {
+			if u.ID > afterID && uint64(len(users)) < limit {
+				users = append(users, copyUser(u))
+			}
+		}
+		return nil
+	})
+	return users, err
+}
+
+func (r *UserRepo) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	return r.update(ctx, userID, func(u *entity.User) {
+		u.PasswordHash = passwordHash
+	})
+}
+
+func (r *UserRepo) RevokeSessions(ctx context.Context, userID int64, at time.Time) error {
+	at = at.UTC().Truncate(time.Microsecond)
+
+	return r.update(ctx, userID, func(u *entity.User) {
+		u.SessionsRevokedAt = &at
+	})
+}
+
+// update changes the row of an existing user.
+func (r *UserRepo) update(ctx context.Context, userID int64, change func(u *entity.User)) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "users", id: userID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		u, ok := get(t.s.committed.users, t.changes.users, userID)
+		if !ok {
+			return fmt.Errorf("%w: id %d", usecase.ErrUserNotFound, userID)
+		}
+
+		row := copyUser(u)
+		change(row)
+		t.changes.users[userID] = row
+		return nil
+	})
+}
+
+func (r *UserRepo) Audit() usecase.AuditRepo {
+	return &AuditRepo{r.store}
+}
+
+func (r *UserRepo) Outbox() usecase.OutboxRepo {
+	return &OutboxRepo{r.store}
+}
+
+func (r *UserRepo) ExecuteTx(_ context.Context, fn func(repo usecase.UserRepo) error) error {
+	return r.executeTx(func(bound store) error {
+		return fn(&UserRepo{bound})
+	})
+}
+
+func copyUser(u *entity.User) *entity.User {
+	c := *u
+	if u.SessionsRevokedAt != nil {
+		at := *u.SessionsRevokedAt
+		c.SessionsRevokedAt = &at
+	}
+	return &c
+}
+
+// uniqueIDs returns the IDs sorted and without duplicates, like the
+// ORDER BY id of an = ANY($1) query.
+func uniqueIDs(ids []int64) []int64 {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	unique := sorted[:0]
+	for i, id := range sorted {
+		if i == 0 || id != sorted[i-1] {
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,298 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+const _webhookDeliveriesDefaultLimit = 50
+
+type WebhookRepo struct {
+	store
+}
+
+// NewWebhookRepo creates a WebhookRepo on the store.
+func NewWebhookRepo(s *Store) usecase.WebhookRepo {
+	return &WebhookRepo{store{s: s}}
+}
+
+func (r *WebhookRepo) CreateWebhook(_ context.Context, webhook *entity.Webhook) error {
+	return r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if _, ok := get(t.s.committed.users, t.changes.users, webhook.UserID); !ok {
+			return errUnknownUser(webhook.UserID)
+		}
+
+		webhook.ID = t.s.nextID("webhooks")
+		webhook.CreatedAt = time.Now().UTC()
+
+		t.changes.webhooks[webhook.ID] = copyWebhook(webhook)
+		t.take(lockKey{table: "webhooks", id: webhook.ID})
+		return nil
+	})
+}
+
+func (r *WebhookRepo) GetWebhook(_ context.Context, webhookID int64) (*entity.Webhook, error) {
+	var webhook *entity.Webhook
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		if w, ok := get(t.s.committed.webhooks, t.changes.webhooks, webhookID); ok {
+			webhook = copyWebhook(w)
+		}
+		return nil
+	})
+	return webhook, err
+}
+
+func (r *WebhookRepo) ListWebhooksByUser(_ context.Context, userID int64) ([]*entity.Webhook, error) {
+	webhooks := []*entity.Webhook{}
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, w := range all(t.s.committed.webhooks, t.changes.webhooks) {
+			if w.UserID == userID {
+				webhooks = append(webhooks, copyWebhook(w))
+			}
+		}
+		return nil
+	})
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, err
+}
+
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, webhookID, userID int64) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "webhooks", id: webhookID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		w, ok := get(t.s.committed.webhooks, t.changes.webhooks, webhookID)
+		if !ok || w.UserID != userID {
+			return usecase.ErrWebhookNotFound
+		}
+
+		// Deliveries are deleted with their webhook, as by ON DELETE CASCADE.
+		t.changes.webhooks[webhookID] = nil
+		for _, d := range all(t.s.committed.deliveries, t.changes.deliveries) {
+			if d.WebhookID == webhookID {
+				t.changes.deliveries[d.ID] = nil
+			}
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepo) SetWebhookEnabled(ctx context.Context, webhookID int64, enabled bool, reason string) error {
+	return r.updateWebhook(ctx, webhookID, func(w *entity.Webhook) {
+		w.Enabled = enabled
+		w.DisabledReason = reason
+		if enabled {
+			w.FailureCount = 0
+		}
+	})
+}
+
+func (r *WebhookRepo) RecordWebhookResult(
+	ctx context.Context,
+	webhookID int64,
+	success bool,
+	disableAfter int,
+) (bool, error) {
+	var disabled bool
+
+	err := r.updateWebhook(ctx, webhookID, func(w *entity.Webhook) {
+		if success {
+			w.FailureCount = 0
+			return
+		}
+
+		// Disabling happens exactly once, when the counter reaches the threshold.
+		w.FailureCount++
+		w.Enabled = w.Enabled && w.FailureCount < disableAfter
+		if w.FailureCount == disableAfter {
+			w.DisabledReason = "too many consecutive failed deliveries"
+			disabled = true
+		}
+	})
+	return disabled, err
+}
+
+// updateWebhook changes a webhook; a missing webhook is left alone.
+func (r *WebhookRepo) updateWebhook(ctx context.Context, webhookID int64, change func(w *entity.Webhook)) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "webhooks", id: webhookID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		w, ok := get(t.s.committed.webhooks, t.changes.webhooks, webhookID)
+		if !ok {
+			return nil
+		}
+
+		c := copyWebhook(w)
+		change(c)
+		t.changes.webhooks[webhookID] = c
+		return nil
+	})
+}
+
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	return r.run(func(t *tx) error {
+		// Deliveries of an outbox event are unique per webhook, so a
+		// redelivered event does not queue a second call.
+		if delivery.SourceEventID != 0 {
+			err := t.lock(ctx, lockKey{table: "webhook_deliveries.source", id: delivery.WebhookID,
+				value: strconv.FormatInt(delivery.SourceEventID, 10)})
+			if err != nil {
+				return err
+			}
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		for _, d := range all(t.s.committed.deliveries, t.changes.deliveries) {
+			if delivery.SourceEventID != 0 && d.WebhookID == delivery.WebhookID &&
+				d.SourceEventID == delivery.SourceEventID {
+				return nil
+			}
+		}
+
+		now := time.Now().UTC()
+
+		delivery.ID = t.s.nextID("webhook_deliveries")
+		delivery.CreatedAt, delivery.UpdatedAt = now, now
+		if delivery.NextAttemptAt.IsZero() {
+			delivery.NextAttemptAt = now
+		}
+
+		t.changes.deliveries[delivery.ID] = copyDelivery(delivery)
+		t.take(lockKey{table: "webhook_deliveries", id: delivery.ID})
+		return nil
+	})
+}
+
+func (r *WebhookRepo) ClaimDueDeliveries(
+	_ context.Context,
+	limit uint64,
+	lease time.Duration,
+) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		rows := all(t.s.committed.deliveries, t.changes.deliveries)
+		sort.Slice(rows, func(i, j int) bool { return rows[i].NextAttemptAt.Before(rows[j].NextAttemptAt) })
+
+		now := time.Now().UTC()
+
+		for _, d := range rows {
+			if uint64(len(deliveries)) == limit {
+				break
+			}
+			if d.Status != entity.DeliveryPending || d.NextAttemptAt.After(now) ||
+				!t.tryLock(lockKey{table: "webhook_deliveries", id: d.ID}) {
+				continue
+			}
+
+			c := copyDelivery(d)
+			c.NextAttemptAt = now.Add(lease)
+			t.changes.deliveries[d.ID] = c
+			deliveries = append(deliveries, copyDelivery(c))
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	return r.run(func(t *tx) error {
+		if err := t.lock(ctx, lockKey{table: "webhook_deliveries", id: delivery.ID}); err != nil {
+			return err
+		}
+
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		d, ok := get(t.s.committed.deliveries, t.changes.deliveries, delivery.ID)
+		if !ok {
+			return nil
+		}
+
+		c := copyDelivery(d)
+		c.Status = delivery.Status
+		c.Attempts = delivery.Attempts
+		c.ResponseStatus = delivery.ResponseStatus
+		c.LastError = delivery.LastError
+		if !delivery.NextAttemptAt.IsZero() {
+			c.NextAttemptAt = delivery.NextAttemptAt
+		}
+		c.UpdatedAt = time.Now().UTC()
+		t.changes.deliveries[delivery.ID] = c
+		return nil
+	})
+}
+
+func (r *WebhookRepo) ListDeliveries(
+	_ context.Context,
+	webhookID int64,
+	limit uint64,
+) ([]*entity.WebhookDelivery, error) {
+	if limit == 0 {
+		limit = _webhookDeliveriesDefaultLimit
+	}
+
+	var deliveries []*entity.WebhookDelivery
+
+	err := r.run(func(t *tx) error {
+		t.s.mu.Lock()
+		defer t.s.mu.Unlock()
+
+		rows := all(t.s.committed.deliveries, t.changes.deliveries)
+		sort.Slice(rows, func(i, j int) bool { return rows[i].ID > rows[j].ID })
+
+		for _, d := range rows {
+			if uint64(len(deliveries)) == limit {
+				break
+			}
+			if d.WebhookID == webhookID {
+				deliveries = append(deliveries, copyDelivery(d))
+			}
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+func copyWebhook(w *entity.Webhook) *entity.Webhook {
+	c := *w
+	c.Events = append([]string(nil), w.Events...)
+	return &c
+}
+
+func copyDelivery(d *entity.WebhookDelivery) *entity.WebhookDelivery {
+	c := *d
+	c.Payload = append([]byte(nil), d.Payload...)
+	return &c
+}
@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+const _notificationsDefaultLimit = 50
+
+type NotificationRepoImpl struct {
+	pg *postgres.Postgres
+	db postgres.Querier
+}
+
+// NewNotificationRepo creates a new NotificationRepo on the connection pool.
+func NewNotificationRepo(pg *postgres.Postgres) usecase.NotificationRepo {
+	return &NotificationRepoImpl{
+		pg: pg,
+		db: pg.Pool,
+	}
+}
+
+func scanNotification(row pgx.Row) (*entity.Notification, error) {
+	var (
+		n    entity.Notification
+		data []byte
+	)
+
+	err := row.Scan(&n.ID, &n.UserID, &n.Type, &data, &n.ReadAt, &n.CreatedAt, &n.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	n.Data = data
+	return &n, nil
+}
+
+func (r *NotificationRepoImpl) CreateNotification(ctx context.Context, n *entity.Notification) error {
+	query := `
+        INSERT INTO notifications (user_id, type, data, event_id)
+        SELECT $1, $2, $3, NULLIF($4, 0)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM notification_preferences
+            WHERE user_id = $1 AND type = $2 AND NOT enabled
+        )
+        RETURNING id, created_at`
+	err := r.db.QueryRow(ctx, query, n.UserID, n.Type, string(n.Data), n.EventID).Scan(&n.ID, &n.CreatedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil
+	case isPgError(err, _pgForeignKeyViolation):
+		return fmt.Errorf("%w: unknown user %d", usecase.ErrInvalidNotification, n.UserID)
+	}
+	return err
+}
+
+func (r *NotificationRepoImpl) ListNotifications(
+	ctx context.Context,
+	userID int64,
+	filter entity.NotificationFilter,
+) ([]*entity.Notification, error) {
+	q := r.pg.Builder.
+		Select("id", "user_id", "type", "data", "read_at", "created_at", "COALESCE(event_id, 0)").
+		From("notifications").
+		Where(squirrel.Eq{"user_id": userID})
+
+	if filter.AfterID != 0 {
+		q = q.Where(squirrel.Gt{"id": filter.AfterID}).OrderBy("id")
+	} else {
+		q = q.OrderBy("id DESC")
+	}
+
+	if filter.UnreadOnly {
+		q = q.Where(squirrel.Eq{"read_at": nil})
+	}
+	if filter.BeforeID != 0 {
+		q = q.Where(squirrel.Lt{"id": filter.BeforeID})
+	}
+	if filter.EventID != 0 {
+		q = q.Where(squirrel.Eq{"event_id": filter.EventID})
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _notificationsDefaultLimit
+	}
+	q = q.Limit(limit)
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(ctx, query, args...)
+	notifications, err := collect(rows, err, scanNotification)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []*entity.Notification{}
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepoImpl) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	query := `SELECT count(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *NotificationRepoImpl) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	query := `
+        UPDATE notifications SET read_at = now()
+        WHERE user_id = $1 AND id = ANY($2) AND read_at IS NULL`
+	return r.execRows(ctx, query, userID, ids)
+}
+
+func (r *NotificationRepoImpl) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	query := `UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL`
+	return r.execRows(ctx, query, userID)
+}
+
+func (r *NotificationRepoImpl) ListPreferences(
+	ctx context.Context,
+	userID int64,
+) ([]*entity.NotificationPreference, error) {
+	query := `SELECT type, enabled FROM notification_preferences WHERE user_id = $1 ORDER BY type`
+	rows, err := r.db.Query(ctx, query, userID)
+	return collect(rows, err, func(row pgx.Row) (*entity.NotificationPreference, error) {
+		p := &entity.NotificationPreference{}
+		if err := row.Scan(&p.Type, &p.Enabled); err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+}
+
+func (r *NotificationRepoImpl) SetPreferences(
+	ctx context.Context,
+	userID int64,
+	prefs []*entity.NotificationPreference,
+) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+
+	q := r.pg.Builder.
+		Insert("notification_preferences").
+		Columns("user_id", "type", "enabled").
+		Suffix("ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled")
+
+	for _, p := range prefs {
+		q = q.Values(userID, p.Type, p.Enabled)
+	}
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
+func (r *NotificationRepoImpl) DeleteReadBefore(ctx context.Context, before time.Time, limit uint64) (int64, error) {
+	query := `
+        DELETE FROM notifications
+        WHERE id IN (
+            SELECT id FROM notifications
+            WHERE read_at < $1
+            LIMIT $2
+        )`
+	return r.execRows(ctx, query, before, limit)
+}
+
+func (r *NotificationRepoImpl) execRows(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
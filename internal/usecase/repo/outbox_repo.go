@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+type OutboxRepoImpl struct {
+	pg *postgres.Postgres
+	db postgres.Querier
+}
+
+// NewOutboxRepo creates a new OutboxRepo on the connection pool.
+func NewOutboxRepo(pg *postgres.Postgres) usecase.OutboxRepo {
+	return &OutboxRepoImpl{
+		pg: pg,
+		db: pg.Pool,
+	}
+}
+
+func (r *OutboxRepoImpl) AddEvent(ctx context.Context, event *entity.Event) error {
+	query := `
+        INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload, request_id)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`
+	return r.db.QueryRow(ctx, query,
+		string(event.Type), event.AggregateType, event.AggregateID, string(event.Payload), event.RequestID).
+		Scan(&event.ID, &event.CreatedAt)
+}
+
+func scanEvent(row pgx.Row) (*entity.Event, error) {
+	var (
+		e         entity.Event
+		eventType string
+		payload   []byte
+	)
+
+	err := row.Scan(&e.ID, &eventType, &e.AggregateType, &e.AggregateID, &payload, &e.CreatedAt, &e.RequestID,
+		&e.Attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Type = entity.EventType(eventType)
+	e.Payload = payload
+	return &e, nil
+}
+
+// ClaimPending leases the due events in one short statement; the publishing
+// and marking happen outside of it.
+func (r *OutboxRepoImpl) ClaimPending(ctx context.Context, limit uint64, lease time.Duration) ([]*entity.Event, error) {
+	// Only the head event of every aggregate is eligible, which keeps
+	// per-aggregate order even with several relays running concurrently:
+	// a leased head keeps the next events of its aggregate waiting.
+	query := `
+        WITH claimed AS (
+            UPDATE outbox SET next_attempt_at = now() + $2 * interval '1 second'
+            WHERE id IN (
+                SELECT o.id FROM outbox o
+                WHERE o.next_attempt_at <= now()
+                  AND NOT EXISTS (
+                      SELECT 1 FROM outbox p
+                      WHERE p.aggregate_type = o.aggregate_type
+                        AND p.aggregate_id = o.aggregate_id
+                        AND p.id < o.id)
+                ORDER BY o.id
+                LIMIT $1
+                FOR UPDATE SKIP LOCKED)
+            RETURNING id, event_type, aggregate_type, aggregate_id, payload, created_at, request_id, attempts)
+        SELECT * FROM claimed ORDER BY id`
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	return collect(rows, err, scanEvent)
+}
+
+func (r *OutboxRepoImpl) MarkPublished(ctx context.Context, eventID int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE id = $1`, eventID)
+	return err
+}
+
+func (r *OutboxRepoImpl) MarkFailed(ctx context.Context, eventID int64, lastErr string, nextAttemptAt time.Time) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(ctx, query, lastErr, nextAttemptAt, eventID)
+	return err
+}
+
+func (r *OutboxRepoImpl) MoveToDeadLetter(ctx context.Context, event *entity.Event, lastErr string) error {
+	// One statement, so that the event is never in both tables or neither.
+	query := `
+        WITH moved AS (DELETE FROM outbox WHERE id = $1 RETURNING *)
+        INSERT INTO outbox_dead_letter (id, event_type, aggregate_type, aggregate_id, payload, created_at,
+                                        request_id, attempts, last_error)
+        SELECT id, event_type, aggregate_type, aggregate_id, payload, created_at, request_id, attempts + 1, $2
+        FROM moved`
+	_, err := r.db.Exec(ctx, query, event.ID, lastErr)
+	return err
+}
+
+func (r *OutboxRepoImpl) OldestPending(ctx context.Context) (time.Time, error) {
+	var createdAt time.Time
+	err := r.db.QueryRow(ctx, `SELECT created_at FROM outbox ORDER BY id LIMIT 1`).Scan(&createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return createdAt, err
+}
+
+func (r *OutboxRepoImpl) ExecuteTx(ctx context.Context, fn func(repo usecase.OutboxRepo) error) error {
+	return postgres.ExecuteTx(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&OutboxRepoImpl{pg: r.pg, db: tx})
+	})
+}
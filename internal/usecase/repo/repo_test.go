@@ -0,0 +1,64 @@
+package repo_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/repo"
+	"github.com/appxpy/hive-test/internal/usecase/repo/repotest"
+	"github.com/appxpy/hive-test/migrations"
+	"github.com/appxpy/hive-test/pkg/migrator"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+const _truncate = `TRUNCATE users, assets, audit_log, outbox, outbox_dead_letter,
+	webhooks, webhook_deliveries, notifications, notification_preferences, feature_flags RESTART IDENTITY CASCADE;
+	UPDATE audit_chain_head SET hash = '` + entity.AuditGenesisHash + `'`
+
+// TestPostgresContract runs the repository contract against the database
+// of PG_TEST_URL. Every table of that database is truncated.
+func TestPostgresContract(t *testing.T) {
+	url := os.Getenv("PG_TEST_URL")
+	if url == "" {
+		t.Skip("PG_TEST_URL is not set")
+	}
+
+	ctx := context.Background()
+
+	mg, err := migrator.New(url, migrations.FS)
+	require.NoError(t, err)
+	require.NoError(t, mg.Up(ctx))
+	require.NoError(t, mg.Close())
+
+	pg, err := postgres.New(url, postgres.MaxPoolSize(4), postgres.ConnAttempts(1))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+
+	repotest.Run(t, func(t *testing.T) (usecase.UserRepo, usecase.AssetRepo) {
+		_, err := pg.Pool.Exec(ctx, _truncate)
+		require.NoError(t, err)
+
+		return repo.NewUserRepo(pg, nil), repo.NewAssetRepo(pg, nil)
+	})
+
+	repotest.RunFlags(t, func(t *testing.T) usecase.FlagRepo {
+		_, err := pg.Pool.Exec(ctx, _truncate)
+		require.NoError(t, err)
+
+		return repo.NewFlagRepo(pg)
+	})
+
+	repotest.RunUnitOfWork(t, func(t *testing.T) (usecase.UnitOfWork, usecase.UserRepo) {
+		_, err := pg.Pool.Exec(ctx, _truncate)
+		require.NoError(t, err)
+
+		// Concurrent appends under REPEATABLE READ and SERIALIZABLE
+		// conflict and are retried.
+		return repo.NewUnitOfWork(pg, nil, usecase.ReadCommitted, 20), repo.NewUserRepo(pg, nil)
+	})
+}
@@ -0,0 +1,492 @@
+// Package repotest holds the contracts that every repository and UnitOfWork
+// implementation must pass, whatever its storage.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// _lockWait is how long a blocked transaction is given to show it waits.
+const _lockWait = 100 * time.Millisecond
+
+var errRollback = errors.New("rollback")
+
+// Factory returns repositories on a fresh, empty storage.
+type Factory func(t *testing.T) (usecase.UserRepo, usecase.AssetRepo)
+
+// Run runs the contract against the repositories returned by newRepos,
+// which is called before every test.
+func Run(t *testing.T, newRepos Factory) {
+	suite.Run(t, &contractSuite{newRepos: newRepos})
+}
+
+type contractSuite struct {
+	suite.Suite
+
+	newRepos Factory
+	ctx      context.Context
+
+	users  usecase.UserRepo
+	assets usecase.AssetRepo
+}
+
+func (s *contractSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.users, s.assets = s.newRepos(s.T())
+}
+
+func (s *contractSuite) createUser(username string) *entity.User {
+	user := &entity.User{Username: username, PasswordHash: "hash-" + username, Role: entity.RoleUser}
+	s.Require().NoError(s.users.CreateUser(s.ctx, user))
+
+	return user
+}
+
+func (s *contractSuite) createAsset(userID int64, name string, price float64) *entity.Asset {
+	asset := &entity.Asset{UserID: userID, Name: name, Description: name + " description", Price: price}
+	s.Require().NoError(s.assets.CreateAsset(s.ctx, asset))
+
+	return asset
+}
+
+func assetIDs(assets []*entity.Asset) []int64 {
+	ids := make([]int64, 0, len(assets))
+	for _, a := range assets {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func (s *contractSuite) TestCreateUser_AssignsIDAndRejectsDuplicates() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+
+	s.NotZero(alice.ID)
+	s.Greater(bob.ID, alice.ID)
+
+	err := s.users.CreateUser(s.ctx, &entity.User{Username: "alice", PasswordHash: "x", Role: entity.RoleUser})
+	s.ErrorIs(err, usecase.ErrUserExists)
+}
+
+func (s *contractSuite) TestGetUser() {
+	alice := s.createUser("alice")
+
+	got, err := s.users.GetUserByUsername(s.ctx, "alice")
+	s.Require().NoError(err)
+	s.Equal(alice.ID, got.ID)
+	s.Equal("hash-alice", got.PasswordHash)
+	s.Equal(entity.RoleUser, got.Role)
+	s.Nil(got.SessionsRevokedAt)
+
+	got, err = s.users.GetUserByID(s.ctx, alice.ID)
+	s.Require().NoError(err)
+	s.Equal("alice", got.Username)
+
+	_, err = s.users.GetUserByUsername(s.ctx, "nobody")
+	s.ErrorIs(err, usecase.ErrUserNotFound)
+
+	_, err = s.users.GetUserByID(s.ctx, alice.ID+100)
+	s.ErrorIs(err, usecase.ErrUserNotFound)
+}
+
+func (s *contractSuite) TestGetUsersByIDs_OrdersByIDAndSkipsMissing() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+
+	users, err := s.users.GetUsersByIDs(s.ctx, []int64{bob.ID, bob.ID + 100, alice.ID})
+
+	s.Require().NoError(err)
+	s.Require().Len(users, 2)
+	s.Equal(alice.ID, users[0].ID)
+	s.Equal(bob.ID, users[1].ID)
+}
+
+func (s *contractSuite) TestListUsers_Pages() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	carol := s.createUser("carol")
+
+	page, err := s.users.ListUsers(s.ctx, 0, 2)
+	s.Require().NoError(err)
+	s.Require().Len(page, 2)
+	s.Equal(alice.ID, page[0].ID)
+	s.Equal(bob.ID, page[1].ID)
+
+	page, err = s.users.ListUsers(s.ctx, bob.ID, 2)
+	s.Require().NoError(err)
+	s.Require().Len(page, 1)
+	s.Equal(carol.ID, page[0].ID)
+}
+
+func (s *contractSuite) TestUpdateUser() {
+	alice := s.createUser("alice")
+	at := time.Now().UTC().Truncate(time.Microsecond)
+
+	s.Require().NoError(s.users.UpdatePasswordHash(s.ctx, alice.ID, "new-hash"))
+	s.Require().NoError(s.users.RevokeSessions(s.ctx, alice.ID, at))
+
+	got, err := s.users.GetUserByID(s.ctx, alice.ID)
+	s.Require().NoError(err)
+	s.Equal("new-hash", got.PasswordHash)
+	s.Require().NotNil(got.SessionsRevokedAt)
+	s.True(at.Equal(*got.SessionsRevokedAt))
+
+	s.ErrorIs(s.users.UpdatePasswordHash(s.ctx, alice.ID+100, "x"), usecase.ErrUserNotFound)
+	s.ErrorIs(s.users.RevokeSessions(s.ctx, alice.ID+100, at), usecase.ErrUserNotFound)
+}
+
+func (s *contractSuite) TestUserExecuteTx_CommitsAndRollsBack() {
+	err := s.users.ExecuteTx(s.ctx, func(repo usecase.UserRepo) error {
+		return repo.CreateUser(s.ctx, &entity.User{Username: "alice", PasswordHash: "x", Role: entity.RoleUser})
+	})
+	s.Require().NoError(err)
+
+	err = s.users.ExecuteTx(s.ctx, func(repo usecase.UserRepo) error {
+		s.Require().NoError(repo.CreateUser(s.ctx, &entity.User{Username: "bob", PasswordHash: "x"}))
+
+		// The transaction sees its own changes.
+		_, err := repo.GetUserByUsername(s.ctx, "bob")
+		s.Require().NoError(err)
+
+		return errRollback
+	})
+	s.ErrorIs(err, errRollback)
+
+	_, err = s.users.GetUserByUsername(s.ctx, "alice")
+	s.NoError(err)
+
+	_, err = s.users.GetUserByUsername(s.ctx, "bob")
+	s.ErrorIs(err, usecase.ErrUserNotFound)
+}
+
+func (s *contractSuite) TestUserExecuteTx_RejectsNesting() {
+	err := s.users.ExecuteTx(s.ctx, func(repo usecase.UserRepo) error {
+		return repo.ExecuteTx(s.ctx, func(usecase.UserRepo) error { return nil })
+	})
+
+	s.Error(err)
+}
+
+func (s *contractSuite) TestCreateAndGetAsset() {
+	alice := s.createUser("alice")
+	asset := s.createAsset(alice.ID, "lamp", 12.5)
+
+	s.NotZero(asset.ID)
+
+	got, err := s.assets.GetAssetByID(s.ctx, asset.ID, false)
+	s.Require().NoError(err)
+	s.Equal(asset, got)
+
+	got, err = s.assets.GetAssetByID(s.ctx, asset.ID+100, false)
+	s.NoError(err)
+	s.Nil(got)
+}
+
+func (s *contractSuite) TestCreateAsset_RejectsUnknownOwner() {
+	err := s.assets.CreateAsset(s.ctx, &entity.Asset{UserID: 100, Name: "lamp", Price: 1})
+
+	s.Error(err)
+}
+
+func (s *contractSuite) TestDeleteAsset_ChecksOwner() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	asset := s.createAsset(alice.ID, "lamp", 1)
+
+	s.ErrorIs(s.assets.DeleteAsset(s.ctx, asset.ID, bob.ID), usecase.ErrAssetNotFound)
+	s.Require().NoError(s.assets.DeleteAsset(s.ctx, asset.ID, alice.ID))
+	s.ErrorIs(s.assets.DeleteAsset(s.ctx, asset.ID, alice.ID), usecase.ErrAssetNotFound)
+
+	got, err := s.assets.GetAssetByID(s.ctx, asset.ID, false)
+	s.NoError(err)
+	s.Nil(got)
+}
+
+func (s *contractSuite) TestUpdateAssetOwner() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	asset := s.createAsset(alice.ID, "lamp", 1)
+
+	s.Require().NoError(s.assets.UpdateAssetOwner(s.ctx, asset.ID, bob.ID))
+
+	got, err := s.assets.GetAssetByID(s.ctx, asset.ID, false)
+	s.Require().NoError(err)
+	s.Equal(bob.ID, got.UserID)
+}
+
+func (s *contractSuite) TestAssetLookups() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	carol := s.createUser("carol")
+	lamp := s.createAsset(alice.ID, "lamp", 1)
+	desk := s.createAsset(bob.ID, "desk", 2)
+	sofa := s.createAsset(alice.ID, "sofa", 3)
+
+	assets, err := s.assets.GetAssetsByUserID(s.ctx, alice.ID)
+	s.Require().NoError(err)
+	s.ElementsMatch([]int64{lamp.ID, sofa.ID}, assetIDs(assets))
+
+	assets, err = s.assets.GetAssetsByUserID(s.ctx, carol.ID)
+	s.Require().NoError(err)
+	s.Empty(assets)
+
+	assets, err = s.assets.GetAssetsByIDs(s.ctx, []int64{sofa.ID, lamp.ID, sofa.ID + 100})
+	s.Require().NoError(err)
+	s.Equal([]int64{lamp.ID, sofa.ID}, assetIDs(assets))
+
+	assets, err = s.assets.GetAssetsByUserIDs(s.ctx, []int64{bob.ID, alice.ID})
+	s.Require().NoError(err)
+	s.Equal([]int64{lamp.ID, desk.ID, sofa.ID}, assetIDs(assets))
+}
+
+func (s *contractSuite) TestListAssets_Filters() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	lamp := s.createAsset(alice.ID, "lamp", 1)
+	desk := s.createAsset(bob.ID, "desk", 2)
+	sofa := s.createAsset(alice.ID, "sofa", 3)
+
+	tests := []struct {
+		name   string
+		filter entity.AssetFilter
+		want   []int64
+	}{
+		{"all", entity.AssetFilter{}, []int64{lamp.ID, desk.ID, sofa.ID}},
+		{"owner", entity.AssetFilter{UserID: alice.ID}, []int64{lamp.ID, sofa.ID}},
+		{"exclude owner", entity.AssetFilter{ExcludeUserID: alice.ID}, []int64{desk.ID}},
+		{"price range", entity.AssetFilter{MinPrice: 1.5, MaxPrice: 3}, []int64{desk.ID, sofa.ID}},
+		{"page", entity.AssetFilter{AfterID: lamp.ID, Limit: 1}, []int64{desk.ID}},
+	}
+
+	for _, tt := range tests {
+		assets, err := s.assets.ListAssets(s.ctx, tt.filter)
+
+		s.Require().NoError(err, tt.name)
+		s.Equal(tt.want, assetIDs(assets), tt.name)
+	}
+}
+
+func (s *contractSuite) TestCountAssets() {
+	alice := s.createUser("alice")
+
+	count, err := s.assets.CountAssets(s.ctx)
+	s.Require().NoError(err)
+	s.Zero(count)
+
+	lamp := s.createAsset(alice.ID, "lamp", 1)
+	s.createAsset(alice.ID, "desk", 2)
+	s.Require().NoError(s.assets.DeleteAsset(s.ctx, lamp.ID, alice.ID))
+
+	count, err = s.assets.CountAssets(s.ctx)
+	s.Require().NoError(err)
+	s.EqualValues(1, count)
+}
+
+func (s *contractSuite) TestListEntries_LimitPerEntity() {
+	audit := s.assets.Audit()
+
+	// Entries of assets 1, 2 and 3, interleaved, and of user 1.
+	for i := 0; i < 3; i++ {
+		for _, id := range []int64{1, 2, 3} {
+			s.Require().NoError(audit.AppendEntry(s.ctx, &entity.AuditEntry{
+				Action: entity.AuditActionAssetCreate, EntityType: entity.AuditEntityAsset, EntityID: id,
+			}))
+		}
+	}
+	s.Require().NoError(audit.AppendEntry(s.ctx, &entity.AuditEntry{
+		Action: entity.AuditActionUserRegister, EntityType: entity.AuditEntityUser, EntityID: 1,
+	}))
+
+	entries, err := audit.ListEntries(s.ctx, entity.AuditFilter{
+		EntityType:     entity.AuditEntityAsset,
+		EntityIDs:      []int64{1, 3},
+		LimitPerEntity: 2,
+	})
+	s.Require().NoError(err)
+
+	var got []int64
+	for _, e := range entries {
+		s.Equal(entity.AuditEntityAsset, e.EntityType)
+		got = append(got, e.EntityID)
+	}
+	// The first two entries of each asset, in insertion order.
+	s.Equal([]int64{1, 3, 1, 3}, got)
+}
+
+func (s *contractSuite) TestAssetExecuteTx_RollsBack() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	asset := s.createAsset(alice.ID, "lamp", 1)
+
+	err := s.assets.ExecuteTx(s.ctx, func(repo usecase.AssetRepo) error {
+		s.Require().NoError(repo.UpdateAssetOwner(s.ctx, asset.ID, bob.ID))
+		s.Require().NoError(repo.CreateAsset(s.ctx, &entity.Asset{UserID: bob.ID, Name: "desk", Price: 2}))
+
+		got, err := repo.GetAssetByID(s.ctx, asset.ID, false)
+		s.Require().NoError(err)
+		s.Equal(bob.ID, got.UserID)
+
+		return errRollback
+	})
+	s.ErrorIs(err, errRollback)
+
+	got, err := s.assets.GetAssetByID(s.ctx, asset.ID, false)
+	s.Require().NoError(err)
+	s.Equal(alice.ID, got.UserID)
+
+	assets, err := s.assets.GetAssetsByUserID(s.ctx, bob.ID)
+	s.Require().NoError(err)
+	s.Empty(assets)
+}
+
+func (s *contractSuite) TestGetAssetByID_ForUpdateLocksUntilCommit() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	carol := s.createUser("carol")
+	asset := s.createAsset(alice.ID, "lamp", 1)
+
+	seen := make(chan int64, 1)
+
+	err := s.assets.ExecuteTx(s.ctx, func(repo usecase.AssetRepo) error {
+		_, err := repo.GetAssetByID(s.ctx, asset.ID, true)
+		s.Require().NoError(err)
+
+		go func() {
+			_ = s.assets.ExecuteTx(s.ctx, func(other usecase.AssetRepo) error {
+				got, err := other.GetAssetByID(s.ctx, asset.ID, true)
+				if err != nil {
+					seen <- 0
+					return err
+				}
+
+				seen <- got.UserID
+				return other.UpdateAssetOwner(s.ctx, asset.ID, carol.ID)
+			})
+		}()
+
+		select {
+		case <-seen:
+			s.Fail("the row lock was not held")
+		case <-time.After(_lockWait):
+		}
+
+		return repo.UpdateAssetOwner(s.ctx, asset.ID, bob.ID)
+	})
+	s.Require().NoError(err)
+
+	select {
+	case owner := <-seen:
+		s.Equal(bob.ID, owner, "the blocked transaction reads the committed row")
+	case <-time.After(5 * time.Second):
+		s.FailNow("the row lock was not released")
+	}
+
+	s.Eventually(func() bool {
+		got, err := s.assets.GetAssetByID(s.ctx, asset.ID, false)
+		return err == nil && got.UserID == carol.ID
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *contractSuite) TestUncommittedChangesAreInvisible() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	asset := s.createAsset(alice.ID, "lamp", 1)
+
+	err := s.assets.ExecuteTx(s.ctx, func(repo usecase.AssetRepo) error {
+		s.Require().NoError(repo.UpdateAssetOwner(s.ctx, asset.ID, bob.ID))
+
+		got, err := s.assets.GetAssetByID(s.ctx, asset.ID, false)
+		s.Require().NoError(err)
+		s.Equal(alice.ID, got.UserID)
+
+		return nil
+	})
+	s.Require().NoError(err)
+
+	got, err := s.assets.GetAssetByID(s.ctx, asset.ID, false)
+	s.Require().NoError(err)
+	s.Equal(bob.ID, got.UserID)
+}
+
+func (s *contractSuite) TestListNotifications_AfterIDAndEvent() {
+	alice := s.createUser("alice")
+	bob := s.createUser("bob")
+	repo := s.assets.Notifications()
+
+	var ids []int64
+	for _, n := range []*entity.Notification{
+		{UserID: alice.ID, Type: entity.NotificationAssetSold, Data: []byte(`{}`), EventID: 7},
+		{UserID: bob.ID, Type: entity.NotificationAssetBought, Data: []byte(`{}`), EventID: 7},
+		{UserID: alice.ID, Type: entity.NotificationAdminMessage, Data: []byte(`{}`)},
+		{UserID: alice.ID, Type: entity.NotificationAssetBought, Data: []byte(`{}`), EventID: 8},
+	} {
+		s.Require().NoError(repo.CreateNotification(s.ctx, n))
+		ids = append(ids, n.ID)
+	}
+
+	notificationIDs := func(filter entity.NotificationFilter) []int64 {
+		notifications, err := repo.ListNotifications(s.ctx, alice.ID, filter)
+		s.Require().NoError(err)
+
+		got := []int64{}
+		for _, n := range notifications {
+			got = append(got, n.ID)
+		}
+		return got
+	}
+
+	s.Equal([]int64{ids[3], ids[2], ids[0]}, notificationIDs(entity.NotificationFilter{}))
+	s.Equal([]int64{ids[2], ids[3]}, notificationIDs(entity.NotificationFilter{AfterID: ids[0]}))
+	s.Equal([]int64{ids[2]}, notificationIDs(entity.NotificationFilter{AfterID: ids[0], Limit: 1}))
+	s.Equal([]int64{ids[0]}, notificationIDs(entity.NotificationFilter{EventID: 7}))
+
+	notifications, err := repo.ListNotifications(s.ctx, alice.ID, entity.NotificationFilter{EventID: 8})
+	s.Require().NoError(err)
+	s.Require().Len(notifications, 1)
+	s.EqualValues(8, notifications[0].EventID)
+}
+
+func (s *contractSuite) TestOutboxClaimPending_LeasesHeadOfAggregate() {
+	repo := s.assets.Outbox()
+
+	var events []*entity.Event
+	for _, aggregateID := range []int64{1, 1, 2} {
+		e := &entity.Event{
+			Type:          entity.EventAssetCreated,
+			AggregateType: entity.AggregateAsset,
+			AggregateID:   aggregateID,
+			Payload:       []byte(`{}`),
+		}
+		s.Require().NoError(repo.AddEvent(s.ctx, e))
+		events = append(events, e)
+	}
+
+	claim := func() []int64 {
+		claimed, err := repo.ClaimPending(s.ctx, 10, time.Hour)
+		s.Require().NoError(err)
+
+		ids := []int64{}
+		for _, e := range claimed {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	s.Equal([]int64{events[0].ID, events[2].ID}, claim())
+	s.Empty(claim(), "leased events are not claimed again")
+
+	// A failed attempt ends the lease at the next attempt.
+	s.Require().NoError(repo.MarkFailed(s.ctx, events[0].ID, "down", time.Now().Add(-time.Second)))
+	s.Equal([]int64{events[0].ID}, claim())
+
+	s.Require().NoError(repo.MoveToDeadLetter(s.ctx, events[0], "down"))
+	s.Equal([]int64{events[1].ID}, claim())
+}
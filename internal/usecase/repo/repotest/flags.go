@@ -0,0 +1,122 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// FlagFactory returns a FlagRepo on a fresh, empty storage.
+type FlagFactory func(t *testing.T) usecase.FlagRepo
+
+// RunFlags runs the contract of FlagRepo against the repositories returned
+// by newRepo, which is called before every test.
+func RunFlags(t *testing.T, newRepo FlagFactory) {
+	suite.Run(t, &flagSuite{newRepo: newRepo})
+}
+
+type flagSuite struct {
+	suite.Suite
+
+	newRepo FlagFactory
+	ctx     context.Context
+
+	flags usecase.FlagRepo
+}
+
+func (s *flagSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.flags = s.newRepo(s.T())
+}
+
+func (s *flagSuite) TestSaveAndGetFlag() {
+	flag := &entity.FeatureFlag{
+		Key:         entity.FlagAuctions,
+		Description: "Auction listings",
+		Enabled:     true,
+		Rollout:     25,
+		Users:       []int64{3, 5},
+		Roles:       []string{entity.RoleAdmin},
+	}
+	s.Require().NoError(s.flags.SaveFlag(s.ctx, flag))
+	s.False(flag.UpdatedAt.IsZero())
+
+	got, err := s.flags.GetFlag(s.ctx, entity.FlagAuctions)
+	s.Require().NoError(err)
+	s.Equal(flag.Description, got.Description)
+	s.True(got.Enabled)
+	s.Equal(25, got.Rollout)
+	s.Equal([]int64{3, 5}, got.Users)
+	s.Equal([]string{entity.RoleAdmin}, got.Roles)
+
+	_, err = s.flags.GetFlag(s.ctx, "missing")
+	s.ErrorIs(err, usecase.ErrFlagNotFound)
+}
+
+func (s *flagSuite) TestSaveFlag_Replaces() {
+	s.Require().NoError(s.flags.SaveFlag(s.ctx, &entity.FeatureFlag{
+		Key: entity.FlagAuctions, Enabled: true, Rollout: 100, Users: []int64{3}, Roles: []string{},
+	}))
+	s.Require().NoError(s.flags.SaveFlag(s.ctx, &entity.FeatureFlag{
+		Key: entity.FlagAuctions, Users: []int64{}, Roles: []string{},
+	}))
+
+	got, err := s.flags.GetFlag(s.ctx, entity.FlagAuctions)
+	s.Require().NoError(err)
+	s.False(got.Enabled)
+	s.Zero(got.Rollout)
+	s.Empty(got.Users)
+}
+
+func (s *flagSuite) TestListFlags_SortsByKey() {
+	flags, err := s.flags.ListFlags(s.ctx)
+	s.Require().NoError(err)
+	s.NotNil(flags)
+	s.Empty(flags)
+
+	for _, key := range []string{entity.FlagRoyalties, entity.FlagAuctions, entity.FlagPurchaseV2} {
+		s.Require().NoError(s.flags.SaveFlag(s.ctx, &entity.FeatureFlag{Key: key, Users: []int64{}, Roles: []string{}}))
+	}
+
+	flags, err = s.flags.ListFlags(s.ctx)
+	s.Require().NoError(err)
+
+	keys := make([]string, 0, len(flags))
+	for _, f := range flags {
+		keys = append(keys, f.Key)
+	}
+	s.Equal([]string{entity.FlagAuctions, entity.FlagPurchaseV2, entity.FlagRoyalties}, keys)
+}
+
+func (s *flagSuite) TestDeleteFlag() {
+	s.Require().NoError(s.flags.SaveFlag(s.ctx, &entity.FeatureFlag{
+		Key: entity.FlagAuctions, Users: []int64{}, Roles: []string{},
+	}))
+
+	s.Require().NoError(s.flags.DeleteFlag(s.ctx, entity.FlagAuctions))
+
+	_, err := s.flags.GetFlag(s.ctx, entity.FlagAuctions)
+	s.ErrorIs(err, usecase.ErrFlagNotFound)
+	s.ErrorIs(s.flags.DeleteFlag(s.ctx, entity.FlagAuctions), usecase.ErrFlagNotFound)
+}
+
+func (s *flagSuite) TestFlagExecuteTx_RollsBack() {
+	err := s.flags.ExecuteTx(s.ctx, func(repo usecase.FlagRepo) error {
+		s.Require().NoError(repo.SaveFlag(s.ctx, &entity.FeatureFlag{
+			Key: entity.FlagAuctions, Users: []int64{}, Roles: []string{},
+		}))
+
+		_, err := repo.GetFlag(s.ctx, entity.FlagAuctions)
+		s.Require().NoError(err, "a transaction sees its own changes")
+
+		return errRollback
+	})
+	s.ErrorIs(err, errRollback)
+
+	_, err = s.flags.GetFlag(s.ctx, entity.FlagAuctions)
+	s.ErrorIs(err, usecase.ErrFlagNotFound)
+}
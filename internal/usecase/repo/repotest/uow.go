@@ -0,0 +1,132 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+// UnitOfWorkFactory returns a UnitOfWork and a UserRepo on a fresh, empty
+// storage.
+type UnitOfWorkFactory func(t *testing.T) (usecase.UnitOfWork, usecase.UserRepo)
+
+// RunUnitOfWork runs the contract of UnitOfWork and OutboxRepo against the
+// repositories returned by newRepos, which is called before every test.
+func RunUnitOfWork(t *testing.T, newRepos UnitOfWorkFactory) {
+	suite.Run(t, &uowSuite{newRepos: newRepos})
+}
+
+type uowSuite struct {
+	suite.Suite
+
+	newRepos UnitOfWorkFactory
+	ctx      context.Context
+
+	uow   usecase.UnitOfWork
+	users usecase.UserRepo
+}
+
+func (s *uowSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.uow, s.users = s.newRepos(s.T())
+}
+
+func (s *uowSuite) TestNestedFailureRollsBackToSavepoint() {
+	errNested := errors.New("nested")
+
+	err := s.uow.Do(s.ctx, func(ctx context.Context, repos usecase.Repos) error {
+		s.Require().NoError(repos.Users().CreateUser(ctx, &entity.User{Username: "alice", PasswordHash: "x", Role: entity.RoleUser}))
+
+		err := s.uow.Do(ctx, func(ctx context.Context, repos usecase.Repos) error {
+			s.Require().NoError(repos.Users().CreateUser(ctx, &entity.User{Username: "bob", PasswordHash: "x", Role: entity.RoleUser}))
+			return errNested
+		})
+		s.Require().ErrorIs(err, errNested)
+
+		return nil
+	})
+	s.Require().NoError(err)
+
+	_, err = s.users.GetUserByUsername(s.ctx, "alice")
+	s.NoError(err)
+
+	_, err = s.users.GetUserByUsername(s.ctx, "bob")
+	s.ErrorIs(err, usecase.ErrUserNotFound)
+}
+
+func (s *uowSuite) TestOutboxOldestPending() {
+	outbox := s.users.Outbox()
+
+	oldest, err := outbox.OldestPending(s.ctx)
+	s.Require().NoError(err)
+	s.True(oldest.IsZero())
+
+	first := &entity.Event{Type: entity.EventAssetCreated, AggregateType: entity.AggregateAsset, AggregateID: 1}
+	second := &entity.Event{Type: entity.EventAssetCreated, AggregateType: entity.AggregateAsset, AggregateID: 2}
+	s.Require().NoError(outbox.AddEvent(s.ctx, first))
+	s.Require().NoError(outbox.AddEvent(s.ctx, second))
+
+	oldest, err = outbox.OldestPending(s.ctx)
+	s.Require().NoError(err)
+	s.True(oldest.Equal(first.CreatedAt), "oldest %s, first %s", oldest, first.CreatedAt)
+
+	s.Require().NoError(outbox.MarkPublished(s.ctx, first.ID))
+
+	oldest, err = outbox.OldestPending(s.ctx)
+	s.Require().NoError(err)
+	s.True(oldest.Equal(second.CreatedAt), "oldest %s, second %s", oldest, second.CreatedAt)
+}
+
+// TestConcurrentAuditAppends appends from concurrent units of work that
+// read before appending, so that a snapshot is taken before the chain head
+// is locked. The chain must not fork under any accepted isolation level.
+func (s *uowSuite) TestConcurrentAuditAppends() {
+	const appends = 8
+
+	levels := []usecase.IsolationLevel{"", usecase.ReadCommitted, usecase.RepeatableRead, usecase.Serializable}
+
+	for _, level := range levels {
+		var wg sync.WaitGroup
+
+		errs := make(chan error, appends)
+
+		for i := 0; i < appends; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				errs <- s.uow.DoIsolated(s.ctx, level, func(ctx context.Context, repos usecase.Repos) error {
+					if _, err := repos.Audit().ListEntries(ctx, entity.AuditFilter{Limit: 1}); err != nil {
+						return err
+					}
+
+					return repos.Audit().AppendEntry(ctx, &entity.AuditEntry{
+						Action: entity.AuditActionAssetPurchase, EntityType: entity.AuditEntityAsset, EntityID: int64(i + 1),
+					})
+				})
+			}(i)
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			s.Require().NoError(err, "level %q", level)
+		}
+
+		res, err := usecase.NewAuditUseCase(s.users.Audit()).VerifyChain(s.ctx)
+		s.Require().NoError(err)
+		s.True(res.Valid, "level %q: chain broken at %d: %s", level, res.BrokenAt, res.Reason)
+	}
+
+	res, err := usecase.NewAuditUseCase(s.users.Audit()).VerifyChain(s.ctx)
+	s.Require().NoError(err)
+	s.EqualValues(appends*len(levels), res.Checked)
+}
@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/appxpy/hive-test/pkg/postgres"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+// _stickyPruneSize is the number of sticky users above which expired
+// entries are dropped on the next write.
+const _stickyPruneSize = 1024
+
+// ReadRouter sends read-only queries to the read replicas. A user who wrote
+// recently reads from the primary for the sticky window, so they see their
+// own writes despite replication lag.
+//
+// Recent writes are remembered by the instance that made them only, so with
+// several instances read-your-writes holds only if the load balancer keeps
+// each user on one instance (session affinity).
+type ReadRouter struct {
+	replicas *postgres.Replicas
+	sticky   time.Duration
+
+	mu     sync.Mutex
+	writes map[int64]time.Time // per instance, see the type comment
+}
+
+// NewReadRouter -.
+func NewReadRouter(replicas *postgres.Replicas, sticky time.Duration) *ReadRouter {
+	return &ReadRouter{
+		replicas: replicas,
+		sticky:   sticky,
+		writes:   make(map[int64]time.Time),
+	}
+}
+
+// reader returns the querier for a read-only query of the user of ctx:
+// a usable replica, or primary when the user is sticky or no replica is
+// usable. A nil router always returns primary.
+func (r *ReadRouter) reader(ctx context.Context, primary postgres.Querier) postgres.Querier {
+	if r == nil || r.isSticky(reqctx.FromContext(ctx).UserID, time.Now()) {
+		return primary
+	}
+
+	if pg := r.replicas.Pick(); pg != nil {
+		return pg.Pool
+	}
+
+	return primary
+}
+
+// wrote makes the user of ctx read from the primary for the sticky window.
+func (r *ReadRouter) wrote(ctx context.Context) {
+	userID := reqctx.FromContext(ctx).UserID
+	if r == nil || userID == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.writes) >= _stickyPruneSize {
+		for id, until := range r.writes {
+			if !now.Before(until) {
+				delete(r.writes, id)
+			}
+		}
+	}
+
+	r.writes[userID] = now.Add(r.sticky)
+}
+
+func (r *ReadRouter) isSticky(userID int64, now time.Time) bool {
+	if userID == 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.writes[userID]
+	return ok && now.Before(until)
+}
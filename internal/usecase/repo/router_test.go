@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+func TestReadRouterStickiness(t *testing.T) {
+	r := NewReadRouter(nil, time.Minute)
+	ctx := reqctx.WithUserID(context.Background(), 1)
+
+	assert.False(t, r.isSticky(1, time.Now()))
+
+	r.wrote(ctx)
+
+	assert.True(t, r.isSticky(1, time.Now()), "writer reads from the primary")
+	assert.False(t, r.isSticky(2, time.Now()), "other users are not sticky")
+	assert.False(t, r.isSticky(1, time.Now().Add(2*time.Minute)), "stickiness expires")
+
+	r.wrote(context.Background())
+	assert.False(t, r.isSticky(0, time.Now()), "anonymous writes are not tracked")
+}
+
+func TestReadRouterStickinessIsPerInstance(t *testing.T) {
+	// Another instance does not know about the write: read-your-writes
+	// across instances relies on session affinity.
+	writer := NewReadRouter(nil, time.Minute)
+	other := NewReadRouter(nil, time.Minute)
+
+	writer.wrote(reqctx.WithUserID(context.Background(), 1))
+
+	assert.True(t, writer.isSticky(1, time.Now()))
+	assert.False(t, other.isSticky(1, time.Now()))
+}
+
+func TestReadRouterNil(t *testing.T) {
+	var r *ReadRouter
+
+	r.wrote(context.Background())
+	assert.Nil(t, r.reader(context.Background(), nil))
+}
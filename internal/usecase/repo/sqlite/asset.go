@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+const _assetsDefaultLimit = 50
+
+type AssetRepo struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+// NewAssetRepo creates an AssetRepo on the database.
+func NewAssetRepo(s *sqlite.SQLite) usecase.AssetRepo {
+	return &AssetRepo{s: s, db: s.DB}
+}
+
+const _assetColumns = `id, user_id, name, description, price`
+
+func scanAsset(row row) (*entity.Asset, error) {
+	var (
+		asset       entity.Asset
+		description sql.NullString
+	)
+
+	err := row.Scan(&asset.ID, &asset.UserID, &asset.Name, &description, &asset.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	asset.Description = description.String
+	return &asset, nil
+}
+
+func (r *AssetRepo) CreateAsset(ctx context.Context, asset *entity.Asset) error {
+	// Prices are kept in cents, like NUMERIC(10, 2) in Postgres.
+	query := `INSERT INTO assets (user_id, name, description, price) VALUES (?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, asset.UserID, asset.Name, asset.Description,
+		math.Round(asset.Price*100)/100)
+	if err != nil {
+		return err
+	}
+
+	asset.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *AssetRepo) DeleteAsset(ctx context.Context, assetID, userID int64) error {
+	query := `DELETE FROM assets WHERE id = ? AND user_id = ?`
+	n, err := rowsAffected(r.db.ExecContext(ctx, query, assetID, userID))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w or not owned by user", usecase.ErrAssetNotFound)
+	}
+
+	return nil
+}
+
+// GetAssetByID ignores forUpdate: the transaction already holds the write lock.
+func (r *AssetRepo) GetAssetByID(ctx context.Context, assetID int64, _ bool) (*entity.Asset, error) {
+	query := `SELECT ` + _assetColumns + ` FROM assets WHERE id = ?`
+	asset, err := scanAsset(r.db.QueryRowContext(ctx, query, assetID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return asset, nil
+}
+
+func (r *AssetRepo) UpdateAssetOwner(ctx context.Context, assetID, newOwnerID int64) error {
+	query := `UPDATE assets SET user_id = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, newOwnerID, assetID)
+	return err
+}
+
+func (r *AssetRepo) GetAssetsByUserID(ctx context.Context, userID int64) ([]*entity.Asset, error) {
+	query := `SELECT ` + _assetColumns + ` FROM assets WHERE user_id = ?`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	return collect(rows, err, scanAsset)
+}
+
+func (r *AssetRepo) GetAssetsByIDs(ctx context.Context, ids []int64) ([]*entity.Asset, error) {
+	return r.selectAssets(ctx, squirrel.Eq{"id": ids})
+}
+
+func (r *AssetRepo) GetAssetsByUserIDs(ctx context.Context, userIDs []int64) ([]*entity.Asset, error) {
+	return r.selectAssets(ctx, squirrel.Eq{"user_id": userIDs})
+}
+
+func (r *AssetRepo) selectAssets(ctx context.Context, where squirrel.Sqlizer) ([]*entity.Asset, error) {
+	query, args, err := r.s.Builder.
+		Select(_assetColumns).
+		From("assets").
+		Where(where).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	return collect(rows, err, scanAsset)
+}
+
+func (r *AssetRepo) ListAssets(ctx context.Context, filter entity.AssetFilter) ([]*entity.Asset, error) {
+	q := r.s.Builder.
+		Select(_assetColumns).
+		From("assets").
+		OrderBy("id")
+
+	if filter.UserID != 0 {
+		q = q.Where(squirrel.Eq{"user_id": filter.UserID})
+	}
+	if filter.ExcludeUserID != 0 {
+		q = q.Where(squirrel.NotEq{"user_id": filter.ExcludeUserID})
+	}
+	if filter.MinPrice != 0 {
+		q = q.Where(squirrel.GtOrEq{"price": filter.MinPrice})
+	}
+	if filter.MaxPrice != 0 {
+		q = q.Where(squirrel.LtOrEq{"price": filter.MaxPrice})
+	}
+	if filter.AfterID != 0 {
+		q = q.Where(squirrel.Gt{"id": filter.AfterID})
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _assetsDefaultLimit
+	}
+	q = q.Limit(limit)
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	return collect(rows, err, scanAsset)
+}
+
+func (r *AssetRepo) CountAssets(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM assets`).Scan(&count)
+	return count, err
+}
+
+func (r *AssetRepo) Audit() usecase.AuditRepo {
+	return &AuditRepo{s: r.s, db: r.db}
+}
+
+func (r *AssetRepo) Outbox() usecase.OutboxRepo {
+	return &OutboxRepo{s: r.s, db: r.db}
+}
+
+func (r *AssetRepo) Notifications() usecase.NotificationRepo {
+	return &NotificationRepo{s: r.s, db: r.db}
+}
+
+func (r *AssetRepo) ExecuteTx(ctx context.Context, fn func(repo usecase.AssetRepo) error) error {
+	return sqlite.ExecuteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&AssetRepo{s: r.s, db: tx})
+	})
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+const _auditDefaultLimit = 100
+
+var _auditColumns = []string{
+	"id", "actor_id", "action", "entity_type", "entity_id", "ip", "user_agent", "request_id",
+	"before_state", "after_state", "prev_hash", "hash", "created_at",
+}
+
+type AuditRepo struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+// NewAuditRepo creates an AuditRepo on the database.
+func NewAuditRepo(s *sqlite.SQLite) usecase.AuditRepo {
+	return &AuditRepo{s: s, db: s.DB}
+}
+
+func (r *AuditRepo) AppendEntry(ctx context.Context, entry *entity.AuditEntry) error {
+	// Reading the latest hash and appending must happen under one write
+	// lock, so a standalone append runs in its own short transaction.
+	if _, ok := r.db.(*sql.Tx); !ok {
+		return sqlite.ExecuteTx(ctx, r.db, func(tx *sql.Tx) error {
+			return (&AuditRepo{s: r.s, db: tx}).AppendEntry(ctx, entry)
+		})
+	}
+
+	prevHash := entity.AuditGenesisHash
+	err := r.db.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	entry.PrevHash = prevHash
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+
+	query := `
+        INSERT INTO audit_log (actor_id, action, entity_type, entity_id, ip, user_agent, request_id,
+                               before_state, after_state, prev_hash, hash, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query,
+		entry.ActorID, string(entry.Action), entry.EntityType, entry.EntityID, entry.IP, entry.UserAgent,
+		entry.RequestID, jsonParam(entry.Before), jsonParam(entry.After), entry.PrevHash, entry.Hash,
+		entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	entry.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *AuditRepo) ListEntries(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEntry, error) {
+	q := r.s.Builder.Select(_auditColumns...).From("audit_log")
+
+	if filter.ActorID != 0 {
+		q = q.Where(squirrel.Eq{"actor_id": filter.ActorID})
+	}
+	if filter.Action != "" {
+		q = q.Where(squirrel.Eq{"action": string(filter.Action)})
+	}
+	if filter.EntityType != "" {
+		q = q.Where(squirrel.Eq{"entity_type": filter.EntityType})
+	}
+	if filter.EntityID != 0 {
+		q = q.Where(squirrel.Eq{"entity_id": filter.EntityID})
+	}
+	if len(filter.EntityIDs) > 0 {
+		q = q.Where(squirrel.Eq{"entity_id": filter.EntityIDs})
+	}
+	if filter.RequestID != "" {
+		q = q.Where(squirrel.Eq{"request_id": filter.RequestID})
+	}
+	if !filter.From.IsZero() {
+		q = q.Where(squirrel.GtOrEq{"created_at": filter.From.UTC()})
+	}
+	if !filter.To.IsZero() {
+		q = q.Where(squirrel.Lt{"created_at": filter.To.UTC()})
+	}
+	if filter.AfterID != 0 {
+		q = q.Where(squirrel.Gt{"id": filter.AfterID})
+	}
+	if filter.LimitPerEntity > 0 {
+		ranked := q.Column("ROW_NUMBER() OVER (PARTITION BY entity_type, entity_id ORDER BY id) AS entity_row")
+		q = r.s.Builder.Select(_auditColumns...).FromSelect(ranked, "ranked").
+			Where(squirrel.LtOrEq{"entity_row": filter.LimitPerEntity})
+	}
+	q = q.OrderBy("id")
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _auditDefaultLimit
+	}
+	q = q.Limit(limit).Offset(filter.Offset)
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	entries, err := collect(rows, err, scanAuditEntry)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []*entity.AuditEntry{}
+	}
+	return entries, nil
+}
+
+// scanAuditEntry reads the nullable JSON snapshots as bytes, since
+// json.RawMessage cannot hold NULL.
+func scanAuditEntry(row row) (*entity.AuditEntry, error) {
+	var (
+		e             entity.AuditEntry
+		action        string
+		before, after []byte
+	)
+
+	err := row.Scan(&e.ID, &e.ActorID, &action, &e.EntityType, &e.EntityID, &e.IP, &e.UserAgent, &e.RequestID,
+		&before, &after, &e.PrevHash, &e.Hash, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Action = entity.AuditAction(action)
+	e.Before, e.After = before, after
+	e.CreatedAt = e.CreatedAt.UTC()
+	return &e, nil
+}
+
+// jsonParam passes a JSON snapshot as text, keeping NULL for absent snapshots.
+func jsonParam(raw []byte) interface{} {
+	if raw == nil {
+		return nil
+	}
+	return string(raw)
+}
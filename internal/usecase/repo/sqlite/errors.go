@@ -0,0 +1,74 @@
+// Package sqlite implements the repositories on a SQLite database for
+// single-node installs. SQLite has no row locks: every transaction begins
+// IMMEDIATE and holds the database write lock until it ends, which is
+// what FOR UPDATE and SKIP LOCKED of the Postgres repositories provide.
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Constraint violations mapped to use case errors.
+const (
+	_constraintForeignKey = sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+	_constraintUnique     = sqlite3.SQLITE_CONSTRAINT_UNIQUE
+)
+
+// row is a *sql.Row or the current row of *sql.Rows.
+type row interface {
+	Scan(dest ...interface{}) error
+}
+
+// collect scans all rows with scan and closes them.
+func collect[T any](rows *sql.Rows, err error, scan func(row row) (*T, error)) ([]*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// rowsAffected returns the number of rows changed by a statement.
+func rowsAffected(res sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// now is the current time as stored: UTC, so that the text sorts chronologically.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// isConstraintError reports whether err is a SQLite constraint violation of the given kind.
+func isConstraintError(err error, code int) bool {
+	var liteErr *sqlite.Error
+	return errors.As(err, &liteErr) && liteErr.Code() == code
+}
+
+// isBusy reports whether err is a lock that was not released within the busy timeout.
+func isBusy(err error) bool {
+	var liteErr *sqlite.Error
+	if !errors.As(err, &liteErr) {
+		return false
+	}
+
+	// The primary result code is the low byte of the extended one.
+	code := liteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
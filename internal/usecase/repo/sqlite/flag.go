@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+type FlagRepo struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+// NewFlagRepo creates a FlagRepo on the database.
+func NewFlagRepo(s *sqlite.SQLite) usecase.FlagRepo {
+	return &FlagRepo{s: s, db: s.DB}
+}
+
+const _flagColumns = `key, description, enabled, rollout, user_ids, roles, updated_at`
+
+// scanFlag decodes user_ids and roles, which are stored as JSON arrays.
+func scanFlag(row row) (*entity.FeatureFlag, error) {
+	var (
+		f            entity.FeatureFlag
+		users, roles []byte
+	)
+
+	err := row.Scan(&f.Key, &f.Description, &f.Enabled, &f.Rollout, &users, &roles, &f.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(users, &f.Users); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(roles, &f.Roles); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *FlagRepo) ListFlags(ctx context.Context) ([]*entity.FeatureFlag, error) {
+	query := `SELECT ` + _flagColumns + ` FROM feature_flags ORDER BY key`
+	rows, err := r.db.QueryContext(ctx, query)
+	flags, err := collect(rows, err, scanFlag)
+	if err != nil {
+		return nil, err
+	}
+	if flags == nil {
+		flags = []*entity.FeatureFlag{}
+	}
+	return flags, nil
+}
+
+func (r *FlagRepo) GetFlag(ctx context.Context, key string) (*entity.FeatureFlag, error) {
+	query := `SELECT ` + _flagColumns + ` FROM feature_flags WHERE key = ?`
+	flag, err := scanFlag(r.db.QueryRowContext(ctx, query, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.ErrFlagNotFound
+	}
+	return flag, err
+}
+
+func (r *FlagRepo) SaveFlag(ctx context.Context, flag *entity.FeatureFlag) error {
+	users, err := json.Marshal(flag.Users)
+	if err != nil {
+		return err
+	}
+
+	roles, err := json.Marshal(flag.Roles)
+	if err != nil {
+		return err
+	}
+
+	updatedAt := now()
+
+	query := `
+        INSERT INTO feature_flags (key, description, enabled, rollout, user_ids, roles, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT (key) DO UPDATE
+        SET description = excluded.description,
+            enabled = excluded.enabled,
+            rollout = excluded.rollout,
+            user_ids = excluded.user_ids,
+            roles = excluded.roles,
+            updated_at = excluded.updated_at`
+	_, err = r.db.ExecContext(ctx, query, flag.Key, flag.Description, flag.Enabled, flag.Rollout,
+		string(users), string(roles), updatedAt)
+	if err != nil {
+		return err
+	}
+
+	flag.UpdatedAt = updatedAt
+	return nil
+}
+
+func (r *FlagRepo) DeleteFlag(ctx context.Context, key string) error {
+	n, err := rowsAffected(r.db.ExecContext(ctx, `DELETE FROM feature_flags WHERE key = ?`, key))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return usecase.ErrFlagNotFound
+	}
+
+	return nil
+}
+
+func (r *FlagRepo) Audit() usecase.AuditRepo {
+	return &AuditRepo{s: r.s, db: r.db}
+}
+
+func (r *FlagRepo) ExecuteTx(ctx context.Context, fn func(repo usecase.FlagRepo) error) error {
+	return sqlite.ExecuteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&FlagRepo{s: r.s, db: tx})
+	})
+}
@@ -0,0 +1,200 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+const _notificationsDefaultLimit = 50
+
+type NotificationRepo struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+// NewNotificationRepo creates a NotificationRepo on the database.
+func NewNotificationRepo(s *sqlite.SQLite) usecase.NotificationRepo {
+	return &NotificationRepo{s: s, db: s.DB}
+}
+
+func scanNotification(row row) (*entity.Notification, error) {
+	var (
+		n    entity.Notification
+		data []byte
+	)
+
+	err := row.Scan(&n.ID, &n.UserID, &n.Type, &data, &n.ReadAt, &n.CreatedAt, &n.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	n.Data = data
+	return &n, nil
+}
+
+func (r *NotificationRepo) CreateNotification(ctx context.Context, n *entity.Notification) error {
+	createdAt := now()
+
+	query := `
+        INSERT INTO notifications (user_id, type, data, created_at, event_id)
+        SELECT ?1, ?2, ?3, ?4, NULLIF(?5, 0)
+        WHERE NOT EXISTS (
+            SELECT 1 FROM notification_preferences
+            WHERE user_id = ?1 AND type = ?2 AND NOT enabled
+        )`
+	res, err := r.db.ExecContext(ctx, query, n.UserID, n.Type, string(n.Data), createdAt, n.EventID)
+	if isConstraintError(err, _constraintForeignKey) {
+		return fmt.Errorf("%w: unknown user %d", usecase.ErrInvalidNotification, n.UserID)
+	}
+
+	inserted, err := rowsAffected(res, err)
+	if err != nil || inserted == 0 {
+		return err
+	}
+
+	n.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	n.CreatedAt = createdAt
+	return nil
+}
+
+func (r *NotificationRepo) ListNotifications(
+	ctx context.Context,
+	userID int64,
+	filter entity.NotificationFilter,
+) ([]*entity.Notification, error) {
+	q := r.s.Builder.
+		Select("id", "user_id", "type", "data", "read_at", "created_at", "COALESCE(event_id, 0)").
+		From("notifications").
+		Where(squirrel.Eq{"user_id": userID})
+
+	if filter.AfterID != 0 {
+		q = q.Where(squirrel.Gt{"id": filter.AfterID}).OrderBy("id")
+	} else {
+		q = q.OrderBy("id DESC")
+	}
+
+	if filter.UnreadOnly {
+		q = q.Where(squirrel.Eq{"read_at": nil})
+	}
+	if filter.BeforeID != 0 {
+		q = q.Where(squirrel.Lt{"id": filter.BeforeID})
+	}
+	if filter.EventID != 0 {
+		q = q.Where(squirrel.Eq{"event_id": filter.EventID})
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = _notificationsDefaultLimit
+	}
+	q = q.Limit(limit)
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	notifications, err := collect(rows, err, scanNotification)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []*entity.Notification{}
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepo) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	query := `SELECT count(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *NotificationRepo) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	return r.markRead(ctx, squirrel.Eq{"user_id": userID, "id": ids})
+}
+
+func (r *NotificationRepo) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return r.markRead(ctx, squirrel.Eq{"user_id": userID})
+}
+
+// markRead marks the matching unread notifications as read.
+func (r *NotificationRepo) markRead(ctx context.Context, where squirrel.Sqlizer) (int64, error) {
+	query, args, err := r.s.Builder.
+		Update("notifications").
+		Set("read_at", now()).
+		Where(where).
+		Where(squirrel.Eq{"read_at": nil}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected(r.db.ExecContext(ctx, query, args...))
+}
+
+func (r *NotificationRepo) ListPreferences(
+	ctx context.Context,
+	userID int64,
+) ([]*entity.NotificationPreference, error) {
+	query := `SELECT type, enabled FROM notification_preferences WHERE user_id = ? ORDER BY type`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	return collect(rows, err, func(row row) (*entity.NotificationPreference, error) {
+		p := &entity.NotificationPreference{}
+		if err := row.Scan(&p.Type, &p.Enabled); err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+}
+
+func (r *NotificationRepo) SetPreferences(
+	ctx context.Context,
+	userID int64,
+	prefs []*entity.NotificationPreference,
+) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+
+	q := r.s.Builder.
+		Insert("notification_preferences").
+		Columns("user_id", "type", "enabled").
+		Suffix("ON CONFLICT (user_id, type) DO UPDATE SET enabled = excluded.enabled")
+
+	for _, p := range prefs {
+		q = q.Values(userID, p.Type, p.Enabled)
+	}
+
+	query, args, err := q.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *NotificationRepo) DeleteReadBefore(ctx context.Context, before time.Time, limit uint64) (int64, error) {
+	query := `
+        DELETE FROM notifications
+        WHERE id IN (
+            SELECT id FROM notifications
+            WHERE read_at < ?
+            LIMIT ?
+        )`
+	return rowsAffected(r.db.ExecContext(ctx, query, before.UTC(), limit))
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+type OutboxRepo struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+// NewOutboxRepo creates an OutboxRepo on the database.
+func NewOutboxRepo(s *sqlite.SQLite) usecase.OutboxRepo {
+	return &OutboxRepo{s: s, db: s.DB}
+}
+
+func (r *OutboxRepo) AddEvent(ctx context.Context, event *entity.Event) error {
+	createdAt := now()
+
+	query := `
+        INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload, request_id, created_at,
+                            next_attempt_at)
+        VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6)`
+	res, err := r.db.ExecContext(ctx, query,
+		string(event.Type), event.AggregateType, event.AggregateID, string(event.Payload), event.RequestID, createdAt)
+	if err != nil {
+		return err
+	}
+
+	event.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	event.CreatedAt = createdAt
+	return nil
+}
+
+func scanEvent(row row) (*entity.Event, error) {
+	var (
+		e         entity.Event
+		eventType string
+		payload   []byte
+	)
+
+	err := row.Scan(&e.ID, &eventType, &e.AggregateType, &e.AggregateID, &payload, &e.CreatedAt, &e.RequestID,
+		&e.Attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Type = entity.EventType(eventType)
+	e.Payload = payload
+	return &e, nil
+}
+
+// ClaimPending leases the due events in one transaction, which keeps other
+// relays from claiming them too.
+func (r *OutboxRepo) ClaimPending(ctx context.Context, limit uint64, lease time.Duration) ([]*entity.Event, error) {
+	var events []*entity.Event
+
+	err := r.inTx(ctx, func(r *OutboxRepo) error {
+		at := now()
+
+		// Only the head event of every aggregate is eligible: a leased head
+		// keeps the next events of its aggregate waiting.
+		query := `
+            SELECT o.id, o.event_type, o.aggregate_type, o.aggregate_id, o.payload, o.created_at, o.request_id,
+                   o.attempts
+            FROM outbox o
+            WHERE o.next_attempt_at <= ?
+              AND NOT EXISTS (
+                  SELECT 1 FROM outbox p
+                  WHERE p.aggregate_type = o.aggregate_type
+                    AND p.aggregate_id = o.aggregate_id
+                    AND p.id < o.id)
+            ORDER BY o.id
+            LIMIT ?`
+		rows, err := r.db.QueryContext(ctx, query, at, limit)
+		events, err = collect(rows, err, scanEvent)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			_, err = r.db.ExecContext(ctx, `UPDATE outbox SET next_attempt_at = ? WHERE id = ?`, at.Add(lease), e.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return events, err
+}
+
+func (r *OutboxRepo) MarkPublished(ctx context.Context, eventID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE id = ?`, eventID)
+	return err
+}
+
+func (r *OutboxRepo) MarkFailed(ctx context.Context, eventID int64, lastErr string, nextAttemptAt time.Time) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, lastErr, nextAttemptAt.UTC(), eventID)
+	return err
+}
+
+func (r *OutboxRepo) MoveToDeadLetter(ctx context.Context, event *entity.Event, lastErr string) error {
+	return r.inTx(ctx, func(r *OutboxRepo) error {
+		query := `
+            INSERT INTO outbox_dead_letter (id, event_type, aggregate_type, aggregate_id, payload, created_at,
+                                            request_id, attempts, last_error, failed_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		_, err := r.db.ExecContext(ctx, query, event.ID, string(event.Type), event.AggregateType, event.AggregateID,
+			string(event.Payload), event.CreatedAt.UTC(), event.RequestID, event.Attempts+1, lastErr, now())
+		if err != nil {
+			return err
+		}
+
+		return r.MarkPublished(ctx, event.ID)
+	})
+}
+
+func (r *OutboxRepo) OldestPending(ctx context.Context) (time.Time, error) {
+	var createdAt time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT created_at FROM outbox ORDER BY id LIMIT 1`).Scan(&createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return createdAt, err
+}
+
+func (r *OutboxRepo) ExecuteTx(ctx context.Context, fn func(repo usecase.OutboxRepo) error) error {
+	return sqlite.ExecuteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&OutboxRepo{s: r.s, db: tx})
+	})
+}
+
+// inTx runs fn in the current transaction or in a new one.
+func (r *OutboxRepo) inTx(ctx context.Context, fn func(r *OutboxRepo) error) error {
+	if _, ok := r.db.(*sql.Tx); ok {
+		return fn(r)
+	}
+
+	return sqlite.ExecuteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&OutboxRepo{s: r.s, db: tx})
+	})
+}
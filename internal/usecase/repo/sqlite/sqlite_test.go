@@ -0,0 +1,88 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/repo/repotest"
+	repo "github.com/appxpy/hive-test/internal/usecase/repo/sqlite"
+	"github.com/appxpy/hive-test/migrations"
+	"github.com/appxpy/hive-test/pkg/migrator"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+// newDB opens a migrated database in a temporary directory.
+func newDB(t *testing.T) *sqlite.SQLite {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "hive.db")
+
+	mg, err := migrator.NewSQLite(path, migrations.SQLiteFS)
+	require.NoError(t, err)
+	require.NoError(t, mg.Up(context.Background()))
+	require.NoError(t, mg.Close())
+
+	db, err := sqlite.New(path)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+// TestOpen is the smoke test of the backend, run by make test-nocgo and the
+// Docker build with CGO_ENABLED=0, as the shipped image is built.
+func TestOpen(t *testing.T) {
+	db := newDB(t)
+	ctx := context.Background()
+
+	pragmas := map[string]string{
+		"foreign_keys": "1",
+		"journal_mode": "wal",
+		"busy_timeout": "5000",
+	}
+	for pragma, want := range pragmas {
+		var got string
+		require.NoError(t, db.DB.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(&got), pragma)
+		require.Equal(t, want, got, pragma)
+	}
+
+	users := repo.NewUserRepo(db)
+	user := &entity.User{Username: "alice", PasswordHash: "hash", Role: entity.RoleUser}
+	require.NoError(t, users.CreateUser(ctx, user))
+	require.ErrorIs(t, users.CreateUser(ctx, &entity.User{Username: "alice", PasswordHash: "hash", Role: entity.RoleUser}), usecase.ErrUserExists)
+
+	revokedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, users.RevokeSessions(ctx, user.ID, revokedAt))
+
+	got, err := users.GetUserByUsername(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, user.ID, got.ID)
+	require.NotNil(t, got.SessionsRevokedAt)
+	require.True(t, revokedAt.Equal(*got.SessionsRevokedAt))
+}
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) (usecase.UserRepo, usecase.AssetRepo) {
+		db := newDB(t)
+		return repo.NewUserRepo(db), repo.NewAssetRepo(db)
+	})
+}
+
+func TestFlagContract(t *testing.T) {
+	repotest.RunFlags(t, func(t *testing.T) usecase.FlagRepo {
+		return repo.NewFlagRepo(newDB(t))
+	})
+}
+
+func TestUnitOfWorkContract(t *testing.T) {
+	repotest.RunUnitOfWork(t, func(t *testing.T) (usecase.UnitOfWork, usecase.UserRepo) {
+		db := newDB(t)
+		return repo.NewUnitOfWork(db, 0), repo.NewUserRepo(db)
+	})
+}
@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+const _txRetryDelay = 10 * time.Millisecond
+
+// txKey is the context key of the transaction of the running unit of work.
+type txKey struct{}
+
+type UnitOfWork struct {
+	s          *sqlite.SQLite
+	maxRetries int
+}
+
+// NewUnitOfWork creates a UnitOfWork on the database. SQLite transactions
+// are serializable whatever the isolation level; a transaction that could
+// not take the write lock within the busy timeout is retried up to
+// maxRetries times.
+func NewUnitOfWork(s *sqlite.SQLite, maxRetries int) usecase.UnitOfWork {
+	return &UnitOfWork{
+		s:          s,
+		maxRetries: maxRetries,
+	}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos usecase.Repos) error) error {
+	return u.DoIsolated(ctx, "", fn)
+}
+
+func (u *UnitOfWork) DoIsolated(
+	ctx context.Context,
+	_ usecase.IsolationLevel,
+	fn func(ctx context.Context, repos usecase.Repos) error,
+) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return u.savepoint(ctx, tx, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := sqlite.ExecuteTx(ctx, u.s.DB, func(tx *sql.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx), &txRepos{s: u.s, db: tx})
+		})
+		if err == nil || attempt >= u.maxRetries || !isBusy(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt+1) * _txRetryDelay):
+		}
+	}
+}
+
+// savepoint runs fn in a savepoint of tx. Savepoints of the same name
+// nest: RELEASE and ROLLBACK TO apply to the most recent one.
+func (u *UnitOfWork) savepoint(
+	ctx context.Context,
+	tx *sql.Tx,
+	fn func(ctx context.Context, repos usecase.Repos) error,
+) error {
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT uow`); err != nil {
+		return err
+	}
+
+	err := fn(ctx, &txRepos{s: u.s, db: tx})
+	if err != nil {
+		if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO uow`); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+	}
+
+	if _, relErr := tx.ExecContext(ctx, `RELEASE uow`); relErr != nil && err == nil {
+		return relErr
+	}
+
+	return err
+}
+
+// txRepos are the repositories of one transaction.
+type txRepos struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+func (r *txRepos) Users() usecase.UserRepo {
+	return &UserRepo{s: r.s, db: r.db}
+}
+
+func (r *txRepos) Assets() usecase.AssetRepo {
+	return &AssetRepo{s: r.s, db: r.db}
+}
+
+func (r *txRepos) Audit() usecase.AuditRepo {
+	return &AuditRepo{s: r.s, db: r.db}
+}
+
+func (r *txRepos) Outbox() usecase.OutboxRepo {
+	return &OutboxRepo{s: r.s, db: r.db}
+}
+
+func (r *txRepos) Webhooks() usecase.WebhookRepo {
+	return &WebhookRepo{s: r.s, db: r.db}
+}
+
+func (r *txRepos) Notifications() usecase.NotificationRepo {
+	return &NotificationRepo{s: r.s, db: r.db}
+}
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+const _usersDefaultLimit = 100
+
+type UserRepo struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+// NewUserRepo creates a UserRepo on the database.
+func NewUserRepo(s *sqlite.SQLite) usecase.UserRepo {
+	return &UserRepo{s: s, db: s.DB}
+}
+
+const _userColumns = `id, username, password_hash, role, sessions_revoked_at`
+
+func scanUser(row row) (*entity.User, error) {
+	user := &entity.User{}
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.SessionsRevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepo) CreateUser(ctx context.Context, user *entity.User) error {
+	query := `INSERT INTO users (username, password_hash, role) VALUES (?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, user.Username, user.PasswordHash, user.Role)
+	if isConstraintError(err, _constraintUnique) {
+		return usecase.ErrUserExists
+	}
+	if err != nil {
+		return err
+	}
+
+	user.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+	query := `SELECT ` + _userColumns + ` FROM users WHERE username = ?`
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, username))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.ErrUserNotFound
+	}
+	return user, err
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, userID int64) (*entity.User, error) {
+	query := `SELECT ` + _userColumns + ` FROM users WHERE id = ?`
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, usecase.ErrUserNotFound
+	}
+	return user, err
+}
+
+func (r *UserRepo) GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	query, args, err := r.s.Builder.
+		Select(_userColumns).
+		From("users").
+		Where(squirrel.Eq{"id": ids}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	return collect(rows, err, scanUser)
+}
+
+func (r *UserRepo) ListUsers(ctx context.Context, afterID int64, limit uint64) ([]*entity.User, error) {
+	if limit == 0 {
+		limit = _usersDefaultLimit
+	}
+
+	query := `SELECT ` + _userColumns + ` FROM users WHERE id > ? ORDER BY id LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	return collect(rows, err, scanUser)
+}
+
+func (r *UserRepo) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	query := `UPDATE users SET password_hash = ? WHERE id = ?`
+	return r.execOne(ctx, query, passwordHash, userID)
+}
+
+func (r *UserRepo) RevokeSessions(ctx context.Context, userID int64, at time.Time) error {
+	query := `UPDATE users SET sessions_revoked_at = ? WHERE id = ?`
+	return r.execOne(ctx, query, at.UTC(), userID)
+}
+
+// execOne runs a statement that must change the row of an existing user.
+func (r *UserRepo) execOne(ctx context.Context, query string, args ...interface{}) error {
+	n, err := rowsAffected(r.db.ExecContext(ctx, query, args...))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: id %v", usecase.ErrUserNotFound, args[len(args)-1])
+	}
+
+	return nil
+}
+
+func (r *UserRepo) Audit() usecase.AuditRepo {
+	return &AuditRepo{s: r.s, db: r.db}
+}
+
+func (r *UserRepo) Outbox() usecase.OutboxRepo {
+	return &OutboxRepo{s: r.s, db: r.db}
+}
+
+func (r *UserRepo) ExecuteTx(ctx context.Context, fn func(repo usecase.UserRepo) error) error {
+	return sqlite.ExecuteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&UserRepo{s: r.s, db: tx})
+	})
+}
@@ -0,0 +1,270 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/sqlite"
+)
+
+const _webhookDeliveriesDefaultLimit = 50
+
+type WebhookRepo struct {
+	s  *sqlite.SQLite
+	db sqlite.Querier
+}
+
+// NewWebhookRepo creates a WebhookRepo on the database.
+func NewWebhookRepo(s *sqlite.SQLite) usecase.WebhookRepo {
+	return &WebhookRepo{s: s, db: s.DB}
+}
+
+const _webhookColumns = `id, user_id, url, secret, events, enabled, failure_count, disabled_reason, created_at`
+
+// scanWebhook decodes events, which are stored as a JSON array.
+func scanWebhook(row row) (*entity.Webhook, error) {
+	var (
+		w      entity.Webhook
+		events []byte
+	)
+
+	err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Secret, &events, &w.Enabled, &w.FailureCount,
+		&w.DisabledReason, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(events, &w.Events); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return err
+	}
+
+	createdAt := now()
+
+	query := `
+        INSERT INTO webhooks (user_id, url, secret, events, enabled, created_at)
+        VALUES (?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, webhook.UserID, webhook.URL, webhook.Secret, string(events),
+		webhook.Enabled, createdAt)
+	if err != nil {
+		return err
+	}
+
+	webhook.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	webhook.CreatedAt = createdAt
+	return nil
+}
+
+func (r *WebhookRepo) GetWebhook(ctx context.Context, webhookID int64) (*entity.Webhook, error) {
+	query := `SELECT ` + _webhookColumns + ` FROM webhooks WHERE id = ?`
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, webhookID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepo) ListWebhooksByUser(ctx context.Context, userID int64) ([]*entity.Webhook, error) {
+	query := `SELECT ` + _webhookColumns + ` FROM webhooks WHERE user_id = ? ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	webhooks, err := collect(rows, err, scanWebhook)
+	if err != nil {
+		return nil, err
+	}
+	if webhooks == nil {
+		webhooks = []*entity.Webhook{}
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, webhookID, userID int64) error {
+	query := `DELETE FROM webhooks WHERE id = ? AND user_id = ?`
+	n, err := rowsAffected(r.db.ExecContext(ctx, query, webhookID, userID))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return usecase.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) SetWebhookEnabled(ctx context.Context, webhookID int64, enabled bool, reason string) error {
+	query := `
+        UPDATE webhooks
+        SET enabled = ?2,
+            disabled_reason = ?3,
+            failure_count = CASE WHEN ?2 THEN 0 ELSE failure_count END
+        WHERE id = ?1`
+	_, err := r.db.ExecContext(ctx, query, webhookID, enabled, reason)
+	return err
+}
+
+func (r *WebhookRepo) RecordWebhookResult(
+	ctx context.Context,
+	webhookID int64,
+	success bool,
+	disableAfter int,
+) (bool, error) {
+	// Disabling happens exactly once, when the counter reaches the threshold.
+	query := `
+        UPDATE webhooks
+        SET failure_count = CASE WHEN ?2 THEN 0 ELSE failure_count + 1 END,
+            enabled = enabled AND (?2 OR failure_count + 1 < ?3),
+            disabled_reason = CASE WHEN NOT ?2 AND failure_count + 1 = ?3 THEN ?4 ELSE disabled_reason END
+        WHERE id = ?1
+        RETURNING NOT ?2 AND failure_count = ?3`
+	var disabled bool
+	err := r.db.QueryRowContext(ctx, query, webhookID, success, disableAfter,
+		"too many consecutive failed deliveries").Scan(&disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return disabled, err
+}
+
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	createdAt := now()
+	nextAttemptAt := delivery.NextAttemptAt.UTC()
+	if delivery.NextAttemptAt.IsZero() {
+		nextAttemptAt = createdAt
+	}
+
+	// Deliveries of an outbox event are unique per webhook, so a redelivered
+	// event does not queue a second call.
+	query := `
+        INSERT INTO webhook_deliveries (webhook_id, source_event_id, event, payload, status, next_attempt_at,
+                                        created_at, updated_at, request_id)
+        VALUES (?1, NULLIF(?2, 0), ?3, ?4, ?5, ?6, ?7, ?7, ?8)
+        ON CONFLICT (webhook_id, source_event_id) DO NOTHING`
+	res, err := r.db.ExecContext(ctx, query, delivery.WebhookID, delivery.SourceEventID, delivery.Event,
+		string(delivery.Payload), delivery.Status, nextAttemptAt, createdAt, delivery.RequestID)
+	n, err := rowsAffected(res, err)
+	if err != nil || n == 0 {
+		return err
+	}
+
+	delivery.ID, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt = nextAttemptAt, createdAt, createdAt
+	return nil
+}
+
+const _deliveryColumns = `id, webhook_id, COALESCE(source_event_id, 0) AS source_event_id, event, payload, status,
+        attempts, response_status, last_error, next_attempt_at, created_at, updated_at, request_id`
+
+func scanDelivery(row row) (*entity.WebhookDelivery, error) {
+	var (
+		d       entity.WebhookDelivery
+		payload []byte
+	)
+
+	err := row.Scan(&d.ID, &d.WebhookID, &d.SourceEventID, &d.Event, &payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt, &d.RequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Payload = payload
+	return &d, nil
+}
+
+// ClaimDueDeliveries leases the due deliveries in one transaction, which
+// keeps other dispatchers from claiming them too.
+func (r *WebhookRepo) ClaimDueDeliveries(
+	ctx context.Context,
+	limit uint64,
+	lease time.Duration,
+) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*entity.WebhookDelivery
+
+	err := r.inTx(ctx, func(r *WebhookRepo) error {
+		at := now()
+
+		query := `
+            SELECT ` + _deliveryColumns + ` FROM webhook_deliveries
+            WHERE status = 'pending' AND next_attempt_at <= ?
+            ORDER BY next_attempt_at
+            LIMIT ?`
+		rows, err := r.db.QueryContext(ctx, query, at, limit)
+		deliveries, err = collect(rows, err, scanDelivery)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range deliveries {
+			d.NextAttemptAt = at.Add(lease)
+
+			_, err = r.db.ExecContext(ctx, `UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ?`,
+				d.NextAttemptAt, d.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deliveries, err
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := `
+        UPDATE webhook_deliveries
+        SET status = ?2, attempts = ?3, response_status = ?4, last_error = ?5,
+            next_attempt_at = COALESCE(?6, next_attempt_at), updated_at = ?7
+        WHERE id = ?1`
+	var nextAttemptAt interface{}
+	if !delivery.NextAttemptAt.IsZero() {
+		nextAttemptAt = delivery.NextAttemptAt.UTC()
+	}
+	_, err := r.db.ExecContext(ctx, query, delivery.ID, delivery.Status, delivery.Attempts,
+		delivery.ResponseStatus, delivery.LastError, nextAttemptAt, now())
+	return err
+}
+
+func (r *WebhookRepo) ListDeliveries(
+	ctx context.Context,
+	webhookID int64,
+	limit uint64,
+) ([]*entity.WebhookDelivery, error) {
+	if limit == 0 {
+		limit = _webhookDeliveriesDefaultLimit
+	}
+
+	query := `SELECT ` + _deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, webhookID, limit)
+	return collect(rows, err, scanDelivery)
+}
+
+// inTx runs fn in the current transaction or in a new one.
+func (r *WebhookRepo) inTx(ctx context.Context, fn func(r *WebhookRepo) error) error {
+	if _, ok := r.db.(*sql.Tx); ok {
+		return fn(r)
+	}
+
+	return sqlite.ExecuteTx(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&WebhookRepo{s: r.s, db: tx})
+	})
+}
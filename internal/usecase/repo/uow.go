@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+// SQLSTATE codes of transient conflicts, after which the transaction can be retried.
+const (
+	_pgSerializationFailure = "40001"
+	_pgDeadlockDetected     = "40P01"
+)
+
+const _txRetryDelay = 10 * time.Millisecond
+
+// txKey is the context key of the transaction of the running unit of work.
+type txKey struct{}
+
+type UnitOfWorkImpl struct {
+	pg         *postgres.Postgres
+	router     *ReadRouter
+	isolation  usecase.IsolationLevel
+	maxRetries int
+}
+
+// NewUnitOfWork creates a UnitOfWork on the connection pool. Transactions
+// use the given isolation level unless DoIsolated overrides it and are
+// retried up to maxRetries times after a serialization failure or deadlock.
+// Transactions always run on the primary; after a commit router, which may
+// be nil, keeps the reads of the user on the primary too.
+func NewUnitOfWork(
+	pg *postgres.Postgres,
+	router *ReadRouter,
+	isolation usecase.IsolationLevel,
+	maxRetries int,
+) usecase.UnitOfWork {
+	return &UnitOfWorkImpl{
+		pg:         pg,
+		router:     router,
+		isolation:  isolation,
+		maxRetries: maxRetries,
+	}
+}
+
+func (u *UnitOfWorkImpl) Do(ctx context.Context, fn func(ctx context.Context, repos usecase.Repos) error) error {
+	return u.DoIsolated(ctx, u.isolation, fn)
+}
+
+func (u *UnitOfWorkImpl) DoIsolated(
+	ctx context.Context,
+	level usecase.IsolationLevel,
+	fn func(ctx context.Context, repos usecase.Repos) error,
+) error {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		// Begin on a transaction creates a savepoint. It commits or rolls
+		// back with the outer transaction, which is the one retried after
+		// a conflict.
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		return u.run(ctx, sp, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := u.attempt(ctx, level, fn)
+		if err == nil {
+			u.router.wrote(ctx)
+			return nil
+		}
+		if attempt >= u.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt+1) * _txRetryDelay):
+		}
+	}
+}
+
+func (u *UnitOfWorkImpl) attempt(
+	ctx context.Context,
+	level usecase.IsolationLevel,
+	fn func(ctx context.Context, repos usecase.Repos) error,
+) error {
+	tx, err := u.pg.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(level)})
+	if err != nil {
+		return err
+	}
+
+	return u.run(ctx, tx, fn)
+}
+
+// run calls fn with the repositories of tx and commits tx when fn succeeds.
+func (u *UnitOfWorkImpl) run(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context, repos usecase.Repos) error) error {
+	err := fn(context.WithValue(ctx, txKey{}, tx), &txRepos{pg: u.pg, db: tx})
+	if err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// isRetryable reports whether err is a conflict that a new attempt may not hit.
+func isRetryable(err error) bool {
+	return isPgError(err, _pgSerializationFailure) || isPgError(err, _pgDeadlockDetected)
+}
+
+// txRepos are the repositories of one transaction.
+type txRepos struct {
+	pg *postgres.Postgres
+	db postgres.Querier
+}
+
+func (r *txRepos) Users() usecase.UserRepo {
+	return &UserRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *txRepos) Assets() usecase.AssetRepo {
+	return &AssetRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *txRepos) Audit() usecase.AuditRepo {
+	return &AuditRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *txRepos) Outbox() usecase.OutboxRepo {
+	return &OutboxRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *txRepos) Webhooks() usecase.WebhookRepo {
+	return &WebhookRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *txRepos) Notifications() usecase.NotificationRepo {
+	return &NotificationRepoImpl{pg: r.pg, db: r.db}
+}
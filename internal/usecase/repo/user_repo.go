@@ -2,29 +2,128 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
 
 	"github.com/appxpy/hive-test/internal/entity"
 	"github.com/appxpy/hive-test/internal/usecase"
-	"github.com/jmoiron/sqlx"
+	"github.com/appxpy/hive-test/pkg/postgres"
 )
 
+const _usersDefaultLimit = 100
+
 type UserRepoImpl struct {
-	DB *sqlx.DB
+	pg     *postgres.Postgres
+	db     postgres.Querier
+	router *ReadRouter
+}
+
+// NewUserRepo creates a UserRepo on the connection pool. Batch and list
+// reads go through router, which may be nil to read from the pool only.
+// Lookups by ID and username always use the primary, since they
+// authenticate requests and must see a password change or revocation at once.
+func NewUserRepo(pg *postgres.Postgres, router *ReadRouter) usecase.UserRepo {
+	return &UserRepoImpl{pg: pg, db: pg.Pool, router: router}
 }
 
-func NewUserRepo(db *sqlx.DB) usecase.UserRepo {
-	return &UserRepoImpl{DB: db}
+const _userColumns = `id, username, password_hash, role, sessions_revoked_at`
+
+func scanUser(row pgx.Row) (*entity.User, error) {
+	user := &entity.User{}
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.SessionsRevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (r *UserRepoImpl) CreateUser(ctx context.Context, user *entity.User) error {
-	query := `INSERT INTO users (username, password_hash) VALUES ($1, $2)`
-	_, err := r.DB.ExecContext(ctx, query, user.Username, user.PasswordHash)
+	query := `INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3) RETURNING id`
+	err := r.db.QueryRow(ctx, query, user.Username, user.PasswordHash, user.Role).Scan(&user.ID)
+	if isPgError(err, _pgUniqueViolation) {
+		return usecase.ErrUserExists
+	}
+	if err == nil {
+		r.router.wrote(ctx)
+	}
 	return err
 }
 
 func (r *UserRepoImpl) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
-	user := &entity.User{}
-	query := `SELECT id, username, password_hash FROM users WHERE username = $1`
-	err := r.DB.GetContext(ctx, user, query, username)
+	query := `SELECT ` + _userColumns + ` FROM users WHERE username = $1`
+	user, err := scanUser(r.db.QueryRow(ctx, query, username))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrUserNotFound
+	}
 	return user, err
 }
+
+func (r *UserRepoImpl) GetUserByID(ctx context.Context, userID int64) (*entity.User, error) {
+	query := `SELECT ` + _userColumns + ` FROM users WHERE id = $1`
+	user, err := scanUser(r.db.QueryRow(ctx, query, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrUserNotFound
+	}
+	return user, err
+}
+
+func (r *UserRepoImpl) GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	query := `SELECT ` + _userColumns + ` FROM users WHERE id = ANY($1) ORDER BY id`
+	rows, err := r.router.reader(ctx, r.db).Query(ctx, query, ids)
+	return collect(rows, err, scanUser)
+}
+
+func (r *UserRepoImpl) ListUsers(ctx context.Context, afterID int64, limit uint64) ([]*entity.User, error) {
+	if limit == 0 {
+		limit = _usersDefaultLimit
+	}
+
+	query := `SELECT ` + _userColumns + ` FROM users WHERE id > $1 ORDER BY id LIMIT $2`
+	rows, err := r.router.reader(ctx, r.db).Query(ctx, query, afterID, limit)
+	return collect(rows, err, scanUser)
+}
+
+func (r *UserRepoImpl) UpdatePasswordHash(ctx context.Context, userID int64, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+	return r.execOne(ctx, query, passwordHash, userID)
+}
+
+func (r *UserRepoImpl) RevokeSessions(ctx context.Context, userID int64, at time.Time) error {
+	query := `UPDATE users SET sessions_revoked_at = $1 WHERE id = $2`
+	return r.execOne(ctx, query, at, userID)
+}
+
+// execOne runs a statement that must change the row of an existing user.
+func (r *UserRepoImpl) execOne(ctx context.Context, query string, args ...interface{}) error {
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: id %v", usecase.ErrUserNotFound, args[len(args)-1])
+	}
+
+	r.router.wrote(ctx)
+	return nil
+}
+
+func (r *UserRepoImpl) Audit() usecase.AuditRepo {
+	return &AuditRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *UserRepoImpl) Outbox() usecase.OutboxRepo {
+	return &OutboxRepoImpl{pg: r.pg, db: r.db}
+}
+
+func (r *UserRepoImpl) ExecuteTx(ctx context.Context, fn func(repo usecase.UserRepo) error) error {
+	err := postgres.ExecuteTx(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(&UserRepoImpl{pg: r.pg, db: tx})
+	})
+	if err == nil {
+		r.router.wrote(ctx)
+	}
+	return err
+}
@@ -0,0 +1,210 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+const _webhookDeliveriesDefaultLimit = 50
+
+type WebhookRepoImpl struct {
+	pg *postgres.Postgres
+	db postgres.Querier
+}
+
+// NewWebhookRepo creates a new WebhookRepo on the connection pool.
+func NewWebhookRepo(pg *postgres.Postgres) usecase.WebhookRepo {
+	return &WebhookRepoImpl{
+		pg: pg,
+		db: pg.Pool,
+	}
+}
+
+const _webhookColumns = `id, user_id, url, secret, events, enabled, failure_count, disabled_reason, created_at`
+
+func scanWebhook(row pgx.Row) (*entity.Webhook, error) {
+	w := &entity.Webhook{}
+	err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.Secret, &w.Events, &w.Enabled, &w.FailureCount,
+		&w.DisabledReason, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *WebhookRepoImpl) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	query := `
+        INSERT INTO webhooks (user_id, url, secret, events, enabled)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`
+	return r.db.QueryRow(ctx, query, webhook.UserID, webhook.URL, webhook.Secret, webhook.Events, webhook.Enabled).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+}
+
+func (r *WebhookRepoImpl) GetWebhook(ctx context.Context, webhookID int64) (*entity.Webhook, error) {
+	query := `SELECT ` + _webhookColumns + ` FROM webhooks WHERE id = $1`
+	webhook, err := scanWebhook(r.db.QueryRow(ctx, query, webhookID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (r *WebhookRepoImpl) ListWebhooksByUser(ctx context.Context, userID int64) ([]*entity.Webhook, error) {
+	query := `SELECT ` + _webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY id`
+	rows, err := r.db.Query(ctx, query, userID)
+	webhooks, err := collect(rows, err, scanWebhook)
+	if err != nil {
+		return nil, err
+	}
+	if webhooks == nil {
+		webhooks = []*entity.Webhook{}
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepoImpl) DeleteWebhook(ctx context.Context, webhookID, userID int64) error {
+	query := `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+	tag, err := r.db.Exec(ctx, query, webhookID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepoImpl) SetWebhookEnabled(ctx context.Context, webhookID int64, enabled bool, reason string) error {
+	query := `
+        UPDATE webhooks
+        SET enabled = $2,
+            disabled_reason = $3,
+            failure_count = CASE WHEN $2 THEN 0 ELSE failure_count END
+        WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, webhookID, enabled, reason)
+	return err
+}
+
+func (r *WebhookRepoImpl) RecordWebhookResult(
+	ctx context.Context,
+	webhookID int64,
+	success bool,
+	disableAfter int,
+) (bool, error) {
+	// Disabling happens exactly once, when the counter reaches the threshold.
+	query := `
+        UPDATE webhooks
+        SET failure_count = CASE WHEN $2 THEN 0 ELSE failure_count + 1 END,
+            enabled = enabled AND ($2 OR failure_count + 1 < $3),
+            disabled_reason = CASE WHEN NOT $2 AND failure_count + 1 = $3 THEN $4 ELSE disabled_reason END
+        WHERE id = $1
+        RETURNING NOT $2 AND failure_count = $3`
+	var disabled bool
+	err := r.db.QueryRow(ctx, query, webhookID, success, disableAfter,
+		"too many consecutive failed deliveries").Scan(&disabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return disabled, err
+}
+
+func (r *WebhookRepoImpl) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	// Deliveries of an outbox event are unique per webhook, so a redelivered
+	// event does not queue a second call.
+	query := `
+        INSERT INTO webhook_deliveries (webhook_id, source_event_id, event, payload, status, next_attempt_at,
+                                        request_id)
+        VALUES ($1, NULLIF($2, 0), $3, $4, $5, COALESCE($6, now()), $7)
+        ON CONFLICT (webhook_id, source_event_id) DO NOTHING
+        RETURNING id, next_attempt_at, created_at, updated_at`
+	var nextAttemptAt interface{}
+	if !delivery.NextAttemptAt.IsZero() {
+		nextAttemptAt = delivery.NextAttemptAt
+	}
+	err := r.db.QueryRow(ctx, query, delivery.WebhookID, delivery.SourceEventID, delivery.Event,
+		string(delivery.Payload), delivery.Status, nextAttemptAt, delivery.RequestID).
+		Scan(&delivery.ID, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+const _deliveryColumns = `id, webhook_id, COALESCE(source_event_id, 0) AS source_event_id, event, payload, status,
+        attempts, response_status, last_error, next_attempt_at, created_at, updated_at, request_id`
+
+func scanDelivery(row pgx.Row) (*entity.WebhookDelivery, error) {
+	var (
+		d       entity.WebhookDelivery
+		payload []byte
+	)
+
+	err := row.Scan(&d.ID, &d.WebhookID, &d.SourceEventID, &d.Event, &payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt, &d.RequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Payload = payload
+	return &d, nil
+}
+
+func (r *WebhookRepoImpl) ClaimDueDeliveries(
+	ctx context.Context,
+	limit uint64,
+	lease time.Duration,
+) ([]*entity.WebhookDelivery, error) {
+	query := `
+        UPDATE webhook_deliveries
+        SET next_attempt_at = now() + $2 * interval '1 second'
+        WHERE id IN (
+            SELECT id FROM webhook_deliveries
+            WHERE status = 'pending' AND next_attempt_at <= now()
+            ORDER BY next_attempt_at
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED)
+        RETURNING ` + _deliveryColumns
+	rows, err := r.db.Query(ctx, query, limit, lease.Seconds())
+	return collect(rows, err, scanDelivery)
+}
+
+func (r *WebhookRepoImpl) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := `
+        UPDATE webhook_deliveries
+        SET status = $2, attempts = $3, response_status = $4, last_error = $5,
+            next_attempt_at = COALESCE($6, next_attempt_at), updated_at = now()
+        WHERE id = $1`
+	var nextAttemptAt interface{}
+	if !delivery.NextAttemptAt.IsZero() {
+		nextAttemptAt = delivery.NextAttemptAt
+	}
+	_, err := r.db.Exec(ctx, query, delivery.ID, delivery.Status, delivery.Attempts,
+		delivery.ResponseStatus, delivery.LastError, nextAttemptAt)
+	return err
+}
+
+func (r *WebhookRepoImpl) ListDeliveries(
+	ctx context.Context,
+	webhookID int64,
+	limit uint64,
+) ([]*entity.WebhookDelivery, error) {
+	if limit == 0 {
+		limit = _webhookDeliveriesDefaultLimit
+	}
+
+	query := `SELECT ` + _deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`
+	rows, err := r.db.Query(ctx, query, webhookID, limit)
+	return collect(rows, err, scanDelivery)
+}
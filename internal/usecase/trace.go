@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/appxpy/hive-test/internal/entity"
+)
+
+const _tracerName = "github.com/appxpy/hive-test/internal/usecase"
+
+// startSpan starts a span named name as a child of the span in ctx.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(_tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, marking it failed when err is set.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// hashPassword hashes password in a span of its own, since bcrypt is slow
+// on purpose.
+func hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := startSpan(ctx, "bcrypt.GenerateFromPassword")
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	endSpan(span, err)
+
+	return string(hash), err
+}
+
+// comparePassword checks password against hash in a span of its own. A
+// mismatch is an expected outcome, not a span error.
+func comparePassword(ctx context.Context, hash, password string) error {
+	_, span := startSpan(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+
+// tracedUserUseCase records a span around every UserUseCase method.
+type tracedUserUseCase struct {
+	next UserUseCase
+}
+
+// NewTracedUserUseCase -.
+func NewTracedUserUseCase(next UserUseCase) UserUseCase {
+	return &tracedUserUseCase{next: next}
+}
+
+func (t *tracedUserUseCase) Register(ctx context.Context, username, password string) (err error) {
+	ctx, span := startSpan(ctx, "UserUseCase.Register")
+	defer func() { endSpan(span, err) }()
+
+	return t.next.Register(ctx, username, password)
+}
+
+func (t *tracedUserUseCase) Login(ctx context.Context, username, password string) (_ string, err error) {
+	ctx, span := startSpan(ctx, "UserUseCase.Login")
+	defer func() { endSpan(span, err) }()
+
+	return t.next.Login(ctx, username, password)
+}
+
+func (t *tracedUserUseCase) GetUsersByIDs(ctx context.Context, ids []int64) (_ []*entity.User, err error) {
+	ctx, span := startSpan(ctx, "UserUseCase.GetUsersByIDs", attribute.Int("user.count", len(ids)))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.GetUsersByIDs(ctx, ids)
+}
+
+func (t *tracedUserUseCase) CheckSession(ctx context.Context, userID int64, issuedAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, "UserUseCase.CheckSession", attribute.Int64("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.CheckSession(ctx, userID, issuedAt)
+}
+
+// tracedAssetUseCase records a span around every AssetUseCase method.
+type tracedAssetUseCase struct {
+	next AssetUseCase
+}
+
+// NewTracedAssetUseCase -.
+func NewTracedAssetUseCase(next AssetUseCase) AssetUseCase {
+	return &tracedAssetUseCase{next: next}
+}
+
+func (t *tracedAssetUseCase) AddAsset(ctx context.Context, asset *entity.Asset) (err error) {
+	ctx, span := startSpan(ctx, "AssetUseCase.AddAsset", attribute.Int64("user.id", asset.UserID))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.AddAsset(ctx, asset)
+}
+
+func (t *tracedAssetUseCase) RemoveAsset(ctx context.Context, assetID, userID int64) (err error) {
+	ctx, span := startSpan(ctx, "AssetUseCase.RemoveAsset",
+		attribute.Int64("asset.id", assetID), attribute.Int64("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.RemoveAsset(ctx, assetID, userID)
+}
+
+func (t *tracedAssetUseCase) PurchaseAsset(ctx context.Context, assetID, buyerID int64) (err error) {
+	ctx, span := startSpan(ctx, "AssetUseCase.PurchaseAsset",
+		attribute.Int64("asset.id", assetID), attribute.Int64("user.id", buyerID))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.PurchaseAsset(ctx, assetID, buyerID)
+}
+
+func (t *tracedAssetUseCase) GetAssetsByUser(ctx context.Context, userID int64) (_ []*entity.Asset, err error) {
+	ctx, span := startSpan(ctx, "AssetUseCase.GetAssetsByUser", attribute.Int64("user.id", userID))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.GetAssetsByUser(ctx, userID)
+}
+
+func (t *tracedAssetUseCase) GetAssetsByIDs(ctx context.Context, ids []int64) (_ []*entity.Asset, err error) {
+	ctx, span := startSpan(ctx, "AssetUseCase.GetAssetsByIDs", attribute.Int("asset.count", len(ids)))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.GetAssetsByIDs(ctx, ids)
+}
+
+func (t *tracedAssetUseCase) GetAssetsByUsers(ctx context.Context, userIDs []int64) (_ []*entity.Asset, err error) {
+	ctx, span := startSpan(ctx, "AssetUseCase.GetAssetsByUsers", attribute.Int("user.count", len(userIDs)))
+	defer func() { endSpan(span, err) }()
+
+	return t.next.GetAssetsByUsers(ctx, userIDs)
+}
+
+func (t *tracedAssetUseCase) ListAssets(
+	ctx context.Context,
+	filter entity.AssetFilter,
+) (_ []*entity.Asset, err error) {
+	ctx, span := startSpan(ctx, "AssetUseCase.ListAssets")
+	defer func() { endSpan(span, err) }()
+
+	return t.next.ListAssets(ctx, filter)
+}
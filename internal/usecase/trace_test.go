@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+)
+
+type TraceSuite struct {
+	suite.Suite
+
+	ctrl     *gomock.Controller
+	ctx      context.Context
+	recorder *tracetest.SpanRecorder
+	previous trace.TracerProvider
+}
+
+func (t *TraceSuite) SetupTest() {
+	t.ctx = context.Background()
+	t.ctrl = gomock.NewController(t.T())
+	t.recorder = tracetest.NewSpanRecorder()
+	t.previous = otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(t.recorder)))
+}
+
+func (t *TraceSuite) TearDownTest() {
+	otel.SetTracerProvider(t.previous)
+}
+
+func (t *TraceSuite) spans() map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range t.recorder.Ended() {
+		spans[s.Name()] = s
+	}
+	return spans
+}
+
+func (t *TraceSuite) TestAssetUseCase_RecordsFailedCall() {
+	next := NewMockAssetUseCase(t.ctrl)
+	next.EXPECT().PurchaseAsset(gomock.Any(), int64(1), int64(2)).Return(usecase.ErrOwnAsset)
+
+	err := usecase.NewTracedAssetUseCase(next).PurchaseAsset(t.ctx, 1, 2)
+	t.ErrorIs(err, usecase.ErrOwnAsset)
+
+	span, ok := t.spans()["AssetUseCase.PurchaseAsset"]
+	t.Require().True(ok)
+	t.Equal(codes.Error, span.Status().Code)
+	t.Equal(usecase.ErrOwnAsset.Error(), span.Status().Description)
+}
+
+func (t *TraceSuite) TestUserUseCase_RecordsBcryptAsChild() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("kapusta"), bcrypt.MinCost)
+	t.Require().NoError(err)
+
+	repo := NewMockUserRepo(t.ctrl)
+	repo.EXPECT().GetUserByUsername(gomock.Any(), "aboba").
+		Return(&entity.User{ID: 1, Username: "aboba", PasswordHash: string(hash)}, nil)
+
+	audit := NewMockAuditRepo(t.ctrl)
+	audit.EXPECT().AppendEntry(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().Audit().Return(audit).AnyTimes()
+
+	uc := usecase.NewTracedUserUseCase(usecase.NewUserUseCase(repo, "secret"))
+
+	_, err = uc.Login(t.ctx, "aboba", "wrong")
+	t.ErrorIs(err, usecase.ErrInvalidCredentials)
+
+	spans := t.spans()
+	login, bcryptSpan := spans["UserUseCase.Login"], spans["bcrypt.CompareHashAndPassword"]
+	t.Require().NotNil(login)
+	t.Require().NotNil(bcryptSpan)
+	t.Equal(login.SpanContext().SpanID(), bcryptSpan.Parent().SpanID())
+	t.NotEqual(codes.Error, bcryptSpan.Status().Code, "a wrong password is not a span error")
+}
+
+func TestTraceSuite(t *testing.T) {
+	suite.Run(t, new(TraceSuite))
+}
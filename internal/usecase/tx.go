@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// IsolationLevel is the SQL isolation level of a unit of work.
+type IsolationLevel string
+
+// Isolation levels. The zero value uses the database default.
+const (
+	ReadCommitted  IsolationLevel = "read committed"
+	RepeatableRead IsolationLevel = "repeatable read"
+	Serializable   IsolationLevel = "serializable"
+)
+
+// ErrInvalidIsolationLevel is returned for an unknown isolation level name.
+var ErrInvalidIsolationLevel = errors.New("invalid isolation level")
+
+// ParseIsolationLevel parses a level name such as "repeatable read" or
+// "repeatable_read"; an empty name is the database default.
+func ParseIsolationLevel(name string) (IsolationLevel, error) {
+	level := IsolationLevel(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", " "))
+
+	switch level {
+	case "", ReadCommitted, RepeatableRead, Serializable:
+		return level, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidIsolationLevel, name)
+	}
+}
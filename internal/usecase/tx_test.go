@@ -0,0 +1,30 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIsolationLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		want usecase.IsolationLevel
+	}{
+		{"", ""},
+		{"read committed", usecase.ReadCommitted},
+		{"REPEATABLE_READ", usecase.RepeatableRead},
+		{" serializable ", usecase.Serializable},
+	}
+
+	for _, tt := range tests {
+		level, err := usecase.ParseIsolationLevel(tt.name)
+
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, level, tt.name)
+	}
+
+	_, err := usecase.ParseIsolationLevel("read uncommitted")
+	assert.ErrorIs(t, err, usecase.ErrInvalidIsolationLevel)
+}
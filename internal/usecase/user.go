@@ -6,11 +6,21 @@ import (
 	"time"
 
 	"github.com/appxpy/hive-test/internal/entity"
-	"golang.org/x/crypto/bcrypt"
 
 	"github.com/dgrijalva/jwt-go"
 )
 
+var (
+	// ErrUserNotFound is returned by UserRepo when no user has the username.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists is returned when the username is already taken.
+	ErrUserExists = errors.New("user already exists")
+	// ErrInvalidCredentials is returned when the username or password is wrong.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrSessionRevoked is returned for a token issued before the user's sessions were revoked.
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
 // UserUseCaseImpl implements UserUseCase.
 type UserUseCaseImpl struct {
 	Repo      UserRepo
@@ -27,34 +37,69 @@ func NewUserUseCase(repo UserRepo, jwtSecret string) UserUseCase {
 
 // Register registers a new user.
 func (uc *UserUseCaseImpl) Register(ctx context.Context, username, password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := hashPassword(ctx, password)
 	if err != nil {
 		return err
 	}
 
 	user := &entity.User{
 		Username:     username,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
+		Role:         entity.RoleUser,
+	}
+
+	err = uc.Repo.ExecuteTx(ctx, func(repo UserRepo) error {
+		err := repo.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		err = appendAudit(ctx, repo.Audit(), user.ID, entity.AuditActionUserRegister,
+			entity.AuditEntityUser, user.ID, nil, user)
+		if err != nil {
+			return err
+		}
+
+		return appendEvent(ctx, repo.Outbox(), entity.EventUserRegistered, entity.AggregateUser, user.ID,
+			entity.UserRegisteredPayload{UserID: user.ID, Username: user.Username})
+	})
+	if err != nil {
+		return err
 	}
 
-	return uc.Repo.CreateUser(ctx, user)
+	registrations.Inc()
+
+	return nil
 }
 
 // Login authenticates a user and returns a JWT token.
 func (uc *UserUseCaseImpl) Login(ctx context.Context, username, password string) (string, error) {
 	user, err := uc.Repo.GetUserByUsername(ctx, username)
 	if err != nil {
+		logins.WithLabelValues(_loginFailure).Inc()
+		uc.auditLoginFailure(ctx, 0, username)
+
+		if errors.Is(err, ErrUserNotFound) {
+			return "", ErrInvalidCredentials
+		}
+
 		return "", err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	err = comparePassword(ctx, user.PasswordHash, password)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		logins.WithLabelValues(_loginFailure).Inc()
+		uc.auditLoginFailure(ctx, user.ID, username)
+
+		return "", ErrInvalidCredentials
 	}
 
+	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+		"role":    user.Role,
+		"iat":     now.Unix(),
+		"exp":     now.Add(time.Hour * 72).Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(uc.JWTSecret))
@@ -62,5 +107,50 @@ func (uc *UserUseCaseImpl) Login(ctx context.Context, username, password string)
 		return "", err
 	}
 
+	err = appendAudit(ctx, uc.Repo.Audit(), user.ID, entity.AuditActionLoginSuccess,
+		entity.AuditEntityUser, user.ID, nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	logins.WithLabelValues(_loginSuccess).Inc()
+
 	return tokenString, nil
 }
+
+// GetUsersByIDs retrieves the users with the given IDs in one call.
+// Missing users are left out.
+func (uc *UserUseCaseImpl) GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return uc.Repo.GetUsersByIDs(ctx, ids)
+}
+
+// CheckSession returns ErrSessionRevoked when the user's sessions were
+// revoked after the token was issued, or the user no longer exists.
+func (uc *UserUseCaseImpl) CheckSession(ctx context.Context, userID int64, issuedAt time.Time) error {
+	user, err := uc.Repo.GetUserByID(ctx, userID)
+	if errors.Is(err, ErrUserNotFound) {
+		return ErrSessionRevoked
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// iat has second precision; a token issued in the second of the revocation is revoked too.
+	if user.SessionsRevokedAt != nil && !issuedAt.After(*user.SessionsRevokedAt) {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+// auditLoginFailure records a failed login attempt. The attempt is already
+// failing, so an audit write error must not replace the original error.
+func (uc *UserUseCaseImpl) auditLoginFailure(ctx context.Context, userID int64, username string) {
+	_ = appendAudit(ctx, uc.Repo.Audit(), 0, entity.AuditActionLoginFailure,
+		entity.AuditEntityUser, userID, nil, map[string]string{"username": username})
+}
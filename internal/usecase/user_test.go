@@ -3,6 +3,7 @@ package usecase_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/appxpy/hive-test/internal/entity"
 	"github.com/appxpy/hive-test/internal/usecase"
@@ -24,7 +25,9 @@ type UserUseCaseSuite struct {
 	someUser     *entity.User
 
 	// Mocked units
-	mockUserRepo *MockUserRepo
+	mockUserRepo   *MockUserRepo
+	mockAuditRepo  *MockAuditRepo
+	mockOutboxRepo *MockOutboxRepo
 
 	// Tested usecase
 	userUseCase usecase.UserUseCase
@@ -51,6 +54,8 @@ func (t *UserUseCaseSuite) SetupTest() {
 	t.ctx = context.Background()
 	t.ctrl = gomock.NewController(t.T())
 	t.mockUserRepo = NewMockUserRepo(t.ctrl)
+	t.mockAuditRepo = NewMockAuditRepo(t.ctrl)
+	t.mockOutboxRepo = NewMockOutboxRepo(t.ctrl)
 	t.userUseCase = usecase.NewUserUseCase(t.mockUserRepo, t.jwtSecret)
 }
 
@@ -58,10 +63,35 @@ func TestUserUseCaseSuite(t *testing.T) {
 	suite.Run(t, new(UserUseCaseSuite))
 }
 
+// expectTx makes ExecuteTx run its callback against the same mocked repo.
+func (t *UserUseCaseSuite) expectTx() {
+	t.mockUserRepo.EXPECT().ExecuteTx(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(usecase.UserRepo) error) error {
+			return fn(t.mockUserRepo)
+		},
+	)
+}
+
+// expectAudit expects a single audit entry with the given action.
+func (t *UserUseCaseSuite) expectAudit(action entity.AuditAction) {
+	t.mockUserRepo.EXPECT().Audit().Return(t.mockAuditRepo)
+	t.mockAuditRepo.EXPECT().AppendEntry(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, entry *entity.AuditEntry) error {
+			t.Equal(action, entry.Action)
+			t.Equal(entity.AuditEntityUser, entry.EntityType)
+			t.NotContains(string(entry.After), t.somePassword)
+
+			return nil
+		},
+	)
+}
+
 func (t *UserUseCaseSuite) TestRegister_GreenPath() {
+	t.expectTx()
 	t.mockUserRepo.EXPECT().CreateUser(t.ctx, gomock.Any()).DoAndReturn(
 		func(ctx context.Context, user *entity.User) error {
 			t.Equal(t.someUser.Username, user.Username)
+			t.Equal(entity.RoleUser, user.Role)
 
 			err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(t.somePassword))
 			t.NoError(err)
@@ -69,6 +99,17 @@ func (t *UserUseCaseSuite) TestRegister_GreenPath() {
 			return nil
 		},
 	)
+	t.expectAudit(entity.AuditActionUserRegister)
+	t.mockUserRepo.EXPECT().Outbox().Return(t.mockOutboxRepo)
+	t.mockOutboxRepo.EXPECT().AddEvent(t.ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, event *entity.Event) error {
+			t.Equal(entity.EventUserRegistered, event.Type)
+			t.Equal(entity.AggregateUser, event.AggregateType)
+			t.NotContains(string(event.Payload), t.somePassword)
+
+			return nil
+		},
+	)
 
 	err := t.userUseCase.Register(t.ctx, t.someUser.Username, t.somePassword)
 
@@ -76,6 +117,7 @@ func (t *UserUseCaseSuite) TestRegister_GreenPath() {
 }
 
 func (t *UserUseCaseSuite) TestRegister_ReturnsError_WhenRepoReturnsError() {
+	t.expectTx()
 	t.mockUserRepo.EXPECT().CreateUser(t.ctx, gomock.Any()).Return(assert.AnError)
 
 	err := t.userUseCase.Register(t.ctx, t.someUser.Username, t.somePassword)
@@ -85,6 +127,7 @@ func (t *UserUseCaseSuite) TestRegister_ReturnsError_WhenRepoReturnsError() {
 
 func (t *UserUseCaseSuite) TestLogin_GreenPath() {
 	t.mockUserRepo.EXPECT().GetUserByUsername(t.ctx, t.someUser.Username).Return(t.someUser, nil)
+	t.expectAudit(entity.AuditActionLoginSuccess)
 
 	token, err := t.userUseCase.Login(t.ctx, t.someUser.Username, t.somePassword)
 
@@ -99,10 +142,12 @@ func (t *UserUseCaseSuite) TestLogin_GreenPath() {
 	t.True(parsedToken.Valid)
 	claims := parsedToken.Claims.(jwt.MapClaims)
 	t.Equal(float64(t.someUser.ID), claims["user_id"])
+	t.Contains(claims, "iat")
 }
 
 func (t *UserUseCaseSuite) TestLogin_ReturnsError_WhenPasswordIncorrect() {
 	t.mockUserRepo.EXPECT().GetUserByUsername(t.ctx, t.someUser.Username).Return(t.someUser, nil)
+	t.expectAudit(entity.AuditActionLoginFailure)
 
 	token, err := t.userUseCase.Login(t.ctx, t.someUser.Username, "wrongpassword")
 
@@ -113,9 +158,52 @@ func (t *UserUseCaseSuite) TestLogin_ReturnsError_WhenPasswordIncorrect() {
 
 func (t *UserUseCaseSuite) TestLogin_ReturnsError_WhenUserNotFound() {
 	t.mockUserRepo.EXPECT().GetUserByUsername(t.ctx, t.someUser.Username).Return(nil, assert.AnError)
+	t.expectAudit(entity.AuditActionLoginFailure)
 
 	token, err := t.userUseCase.Login(t.ctx, t.someUser.Username, t.somePassword)
 
 	t.ErrorIs(err, assert.AnError)
 	t.Empty(token)
 }
+
+func (t *UserUseCaseSuite) TestLogin_ReturnsInvalidCredentials_WhenUserUnknown() {
+	t.mockUserRepo.EXPECT().GetUserByUsername(t.ctx, t.someUser.Username).Return(nil, usecase.ErrUserNotFound)
+	t.expectAudit(entity.AuditActionLoginFailure)
+
+	token, err := t.userUseCase.Login(t.ctx, t.someUser.Username, t.somePassword)
+
+	t.ErrorIs(err, usecase.ErrInvalidCredentials)
+	t.Empty(token)
+}
+
+func (t *UserUseCaseSuite) TestCheckSession() {
+	revokedAt := time.Date(2026, 10, 19, 12, 0, 0, 500, time.UTC)
+
+	for _, tc := range []struct {
+		name     string
+		user     *entity.User
+		err      error
+		issuedAt time.Time
+		want     error
+	}{
+		{"never revoked", &entity.User{ID: 1}, nil, revokedAt, nil},
+		{"issued after revocation", &entity.User{ID: 1, SessionsRevokedAt: &revokedAt}, nil, revokedAt.Add(time.Second), nil},
+		{"issued before revocation", &entity.User{ID: 1, SessionsRevokedAt: &revokedAt}, nil, revokedAt.Add(-time.Hour), usecase.ErrSessionRevoked},
+		{"issued in the same second", &entity.User{ID: 1, SessionsRevokedAt: &revokedAt}, nil, revokedAt.Truncate(time.Second), usecase.ErrSessionRevoked},
+		{"token without iat", &entity.User{ID: 1, SessionsRevokedAt: &revokedAt}, nil, time.Time{}, usecase.ErrSessionRevoked},
+		{"user deleted", nil, usecase.ErrUserNotFound, revokedAt, usecase.ErrSessionRevoked},
+		{"repo error", nil, assert.AnError, revokedAt, assert.AnError},
+	} {
+		t.Run(tc.name, func() {
+			t.mockUserRepo.EXPECT().GetUserByID(t.ctx, int64(1)).Return(tc.user, tc.err)
+
+			err := t.userUseCase.CheckSession(t.ctx, 1, tc.issuedAt)
+
+			if tc.want == nil {
+				t.NoError(err)
+			} else {
+				t.ErrorIs(err, tc.want)
+			}
+		})
+	}
+}
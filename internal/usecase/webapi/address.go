@@ -0,0 +1,62 @@
+package webapi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for an endpoint on an address webhooks may
+// not be delivered to.
+var ErrForbiddenAddress = errors.New("forbidden address")
+
+// _forbiddenNets are the ranges not covered by the net.IP predicates in
+// forbiddenIP: "this network" and carrier-grade NAT.
+var _forbiddenNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+// forbiddenIP reports whether ip is not a public unicast address: loopback,
+// private, link-local, which holds the cloud metadata endpoints such as
+// 169.254.169.254, unspecified, multicast or one of _forbiddenNets.
+func forbiddenIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+
+	for _, n := range _forbiddenNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dialControl refuses connections to forbidden addresses. It runs after
+// name resolution, so a host that resolved to a public address when the
+// webhook was registered cannot be repointed at the internal network.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("webapi - dialControl - net.SplitHostPort: %w", err)
+	}
+
+	if ip := net.ParseIP(host); ip == nil || forbiddenIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return n
+}
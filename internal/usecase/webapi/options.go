@@ -0,0 +1,26 @@
+package webapi
+
+// Option -.
+type Option func(*WebhookSender)
+
+// AllowHTTP accepts plain http endpoints besides https, for development.
+func AllowHTTP() Option {
+	return func(s *WebhookSender) {
+		s.allowHTTP = true
+	}
+}
+
+// AllowPrivateNetworks accepts endpoints on loopback, private and link-local
+// addresses, for installs whose receivers run on the internal network.
+func AllowPrivateNetworks() Option {
+	return func(s *WebhookSender) {
+		s.allowPrivate = true
+	}
+}
+
+// WithResolver sets the resolver CheckURL looks endpoint hosts up with.
+func WithResolver(r Resolver) Option {
+	return func(s *WebhookSender) {
+		s.resolver = r
+	}
+}
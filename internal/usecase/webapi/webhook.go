@@ -0,0 +1,161 @@
+// Package webapi implements calls to external HTTP APIs.
+package webapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+// Webhook request headers.
+const (
+	SignatureHeader = "X-Hive-Signature"
+	TimestampHeader = "X-Hive-Timestamp"
+)
+
+const _maxResponseBody = 64 << 10
+
+// Resolver looks up the addresses of a host, as *net.Resolver does.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// WebhookSender implements usecase.WebhookSender over net/http.
+type WebhookSender struct {
+	client       *http.Client
+	resolver     Resolver
+	allowHTTP    bool
+	allowPrivate bool
+}
+
+var _ usecase.WebhookSender = (*WebhookSender)(nil)
+
+// NewWebhookSender returns a sender that only delivers to https endpoints
+// on public addresses unless options allow more. The addresses are checked
+// when connecting, whatever the host resolved to when it was registered.
+func NewWebhookSender(timeout time.Duration, opts ...Option) *WebhookSender {
+	s := &WebhookSender{
+		resolver: net.DefaultResolver,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !s.allowPrivate {
+		dialer.Control = dialControl
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the endpoint, escaping the check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	s.client = &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		// A redirect is reported as the endpoint's response instead of being followed.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return s
+}
+
+// CheckURL reports whether webhooks may be delivered to rawURL: an https
+// URL, or http if allowed, whose host resolves only to public addresses.
+// The error explains why not to the user registering the webhook.
+func (s *WebhookSender) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "https" && !(s.allowHTTP && u.Scheme == "http") {
+		return errors.New("url must use https")
+	}
+
+	if s.allowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if forbiddenIP(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+
+		return nil
+	}
+
+	addrs, err := s.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %w", host, err)
+	}
+
+	for _, addr := range addrs {
+		if forbiddenIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, addr.IP)
+		}
+	}
+
+	return nil
+}
+
+// Send POSTs body with the timestamp and signature headers and the request
+// ID of ctx, if any.
+func (s *WebhookSender) Send(ctx context.Context, url, secret string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("webapi - WebhookSender - http.NewRequest: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hive-webhooks/1.0")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(secret, timestamp, body))
+
+	if id := reqctx.FromContext(ctx).RequestID; id != "" {
+		req.Header.Set(reqctx.RequestIDHeader, id)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webapi - WebhookSender - client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain a bounded amount so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, _maxResponseBody))
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by secret.
+// Receivers recompute it and should reject stale timestamps to stop replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
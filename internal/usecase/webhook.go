@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+)
+
+const (
+	_webhookSecretBytes = 32
+	_webhookMaxPerUser  = 20
+	_webhookClaimLimit  = 50
+	_webhookClaimLease  = 2 * time.Minute
+	_webhookBaseBackoff = 30 * time.Second
+	_webhookMaxBackoff  = 6 * time.Hour
+)
+
+var (
+	// ErrWebhookNotFound is returned when the webhook does not exist or belongs to another user.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrInvalidWebhook is returned when the webhook URL or event filter is not acceptable.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// WebhookUseCaseImpl implements the WebhookUseCase interface.
+type WebhookUseCaseImpl struct {
+	repo         WebhookRepo
+	sender       WebhookSender
+	maxAttempts  int
+	disableAfter int
+}
+
+// NewWebhookUseCase creates a new WebhookUseCase. A delivery is given up
+// after maxAttempts attempts; a webhook is disabled after disableAfter
+// consecutive failed attempts.
+func NewWebhookUseCase(repo WebhookRepo, sender WebhookSender, maxAttempts, disableAfter int) WebhookUseCase {
+	return &WebhookUseCaseImpl{
+		repo:         repo,
+		sender:       sender,
+		maxAttempts:  maxAttempts,
+		disableAfter: disableAfter,
+	}
+}
+
+// CreateWebhook registers a webhook and returns it with its signing secret.
+func (uc *WebhookUseCaseImpl) CreateWebhook(
+	ctx context.Context,
+	userID int64,
+	rawURL string,
+	events []string,
+) (*entity.Webhook, error) {
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	if err := uc.sender.CheckURL(ctx, rawURL); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	events, err := normalizeWebhookEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.repo.ListWebhooksByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(existing) >= _webhookMaxPerUser {
+		return nil, fmt.Errorf("%w: at most %d webhooks per user", ErrInvalidWebhook, _webhookMaxPerUser)
+	}
+
+	secret := make([]byte, _webhookSecretBytes)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	webhook := &entity.Webhook{
+		UserID:  userID,
+		URL:     rawURL,
+		Secret:  "whsec_" + hex.EncodeToString(secret),
+		Events:  events,
+		Enabled: true,
+	}
+
+	err = uc.repo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks returns the user's webhooks without their secrets.
+func (uc *WebhookUseCaseImpl) ListWebhooks(ctx context.Context, userID int64) ([]*entity.Webhook, error) {
+	webhooks, err := uc.repo.ListWebhooksByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range webhooks {
+		w.Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook owned by the user.
+func (uc *WebhookUseCaseImpl) DeleteWebhook(ctx context.Context, webhookID, userID int64) error {
+	return uc.repo.DeleteWebhook(ctx, webhookID, userID)
+}
+
+// SetWebhookEnabled enables or disables a webhook owned by the user.
+// Enabling resets the consecutive failure counter.
+func (uc *WebhookUseCaseImpl) SetWebhookEnabled(ctx context.Context, webhookID, userID int64, enabled bool) error {
+	if _, err := uc.ownedWebhook(ctx, webhookID, userID); err != nil {
+		return err
+	}
+
+	reason := ""
+	if !enabled {
+		reason = "disabled by user"
+	}
+
+	return uc.repo.SetWebhookEnabled(ctx, webhookID, enabled, reason)
+}
+
+// ListDeliveries returns the latest deliveries of a webhook owned by the user.
+func (uc *WebhookUseCaseImpl) ListDeliveries(
+	ctx context.Context,
+	webhookID, userID int64,
+	limit uint64,
+) ([]*entity.WebhookDelivery, error) {
+	if _, err := uc.ownedWebhook(ctx, webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListDeliveries(ctx, webhookID, limit)
+}
+
+// SendTestEvent delivers a ping event right away, once, and records it in
+// the delivery log. It works on disabled webhooks and does not count
+// towards automatic disabling.
+func (uc *WebhookUseCaseImpl) SendTestEvent(
+	ctx context.Context,
+	webhookID, userID int64,
+) (*entity.WebhookDelivery, error) {
+	webhook, err := uc.ownedWebhook(ctx, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"webhook_id": webhook.ID, "message": "test event"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the dispatcher away from the ping while it is sent inline.
+	delivery := &entity.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		Event:         entity.WebhookEventPing,
+		Payload:       data,
+		Status:        entity.DeliveryPending,
+		NextAttemptAt: time.Now().Add(_webhookClaimLease),
+		RequestID:     reqctx.FromContext(ctx).RequestID,
+	}
+
+	err = uc.repo.CreateDelivery(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.attempt(ctx, webhook, delivery, 1)
+
+	err = uc.repo.UpdateDelivery(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// HandleEvent queues deliveries of a domain event for subscribed webhooks.
+// It is safe to call more than once for the same event.
+func (uc *WebhookUseCaseImpl) HandleEvent(ctx context.Context, event *entity.Event) error {
+	if event.Type != entity.EventAssetPurchased {
+		return nil
+	}
+
+	var p entity.AssetPurchasedPayload
+	if err := json.Unmarshal(event.Payload, &p); err != nil {
+		return fmt.Errorf("webhook - HandleEvent - json.Unmarshal: %w", err)
+	}
+
+	err := uc.enqueue(ctx, event, p.SellerID, entity.WebhookEventAssetSold)
+	if err != nil {
+		return err
+	}
+
+	return uc.enqueue(ctx, event, p.BuyerID, entity.WebhookEventAssetBought)
+}
+
+// DispatchPending attempts one batch of due deliveries and returns its size.
+func (uc *WebhookUseCaseImpl) DispatchPending(ctx context.Context) (int, error) {
+	deliveries, err := uc.repo.ClaimDueDeliveries(ctx, _webhookClaimLimit, _webhookClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[int64]*entity.Webhook)
+
+	for _, d := range deliveries {
+		webhook, ok := webhooks[d.WebhookID]
+		if !ok {
+			webhook, err = uc.repo.GetWebhook(ctx, d.WebhookID)
+			if err != nil {
+				return 0, err
+			}
+
+			webhooks[d.WebhookID] = webhook
+		}
+
+		if webhook == nil || !webhook.Enabled {
+			d.Status = entity.DeliveryFailed
+			d.LastError = "webhook disabled"
+		} else {
+			uc.attempt(ctx, webhook, d, uc.maxAttempts)
+
+			disabled, err := uc.repo.RecordWebhookResult(ctx, webhook.ID, d.Status == entity.DeliverySucceeded,
+				uc.disableAfter)
+			if err != nil {
+				return 0, err
+			}
+
+			if disabled {
+				webhook.Enabled = false
+			}
+		}
+
+		err = uc.repo.UpdateDelivery(ctx, d)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (uc *WebhookUseCaseImpl) enqueue(ctx context.Context, event *entity.Event, userID int64, name string) error {
+	webhooks, err := uc.repo.ListWebhooksByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range webhooks {
+		if !w.Enabled || !w.Subscribed(name) {
+			continue
+		}
+
+		err = uc.repo.CreateDelivery(ctx, &entity.WebhookDelivery{
+			WebhookID:     w.ID,
+			SourceEventID: event.ID,
+			Event:         name,
+			Payload:       event.Payload,
+			Status:        entity.DeliveryPending,
+			RequestID:     event.RequestID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attempt sends the delivery once and updates its status, scheduling a
+// retry with exponential backoff until maxAttempts is reached.
+func (uc *WebhookUseCaseImpl) attempt(
+	ctx context.Context,
+	webhook *entity.Webhook,
+	d *entity.WebhookDelivery,
+	maxAttempts int,
+) {
+	d.Attempts++
+	d.ResponseStatus = 0
+	d.LastError = ""
+
+	body, err := json.Marshal(entity.WebhookPayload{ID: d.ID, Event: d.Event, CreatedAt: d.CreatedAt, Data: d.Payload})
+	if err == nil {
+		// The sender forwards the request ID of the context.
+		d.ResponseStatus, err = uc.sender.Send(reqctx.WithRequestID(ctx, d.RequestID), webhook.URL, webhook.Secret,
+			body)
+	}
+
+	switch {
+	case err != nil:
+		d.LastError = err.Error()
+	case d.ResponseStatus < http.StatusOK || d.ResponseStatus >= http.StatusMultipleChoices:
+		d.LastError = fmt.Sprintf("unexpected response status %d", d.ResponseStatus)
+	default:
+		d.Status = entity.DeliverySucceeded
+
+		return
+	}
+
+	if d.Attempts >= maxAttempts {
+		d.Status = entity.DeliveryFailed
+
+		return
+	}
+
+	d.Status = entity.DeliveryPending
+	d.NextAttemptAt = time.Now().Add(webhookBackoff(d.Attempts))
+}
+
+func (uc *WebhookUseCaseImpl) ownedWebhook(ctx context.Context, webhookID, userID int64) (*entity.Webhook, error) {
+	webhook, err := uc.repo.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook == nil || webhook.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+// webhookBackoff returns the delay after the given number of failed attempts.
+func webhookBackoff(attempts int) time.Duration {
+	d := _webhookBaseBackoff
+	for i := 1; i < attempts && d < _webhookMaxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > _webhookMaxBackoff {
+		return _webhookMaxBackoff
+	}
+
+	return d
+}
+
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+
+	return nil
+}
+
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+	}
+
+	seen := make(map[string]bool, len(events))
+	res := make([]string, 0, len(events))
+
+	for _, e := range events {
+		if !entity.IsWebhookEvent(e) {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+
+		if !seen[e] {
+			seen[e] = true
+			res = append(res, e)
+		}
+	}
+
+	return res, nil
+}
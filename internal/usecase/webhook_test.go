@@ -0,0 +1,378 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/appxpy/hive-test/internal/entity"
+	"github.com/appxpy/hive-test/internal/usecase"
+	"github.com/appxpy/hive-test/internal/usecase/webapi"
+	"github.com/appxpy/hive-test/pkg/reqctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+type WebhookUseCaseSuite struct {
+	suite.Suite
+
+	ctrl *gomock.Controller
+	ctx  context.Context
+
+	// httptest receiver
+	receiver       *httptest.Server
+	receiverStatus atomic.Int32
+	received       chan *http.Request
+	receivedBodies chan []byte
+
+	// Intermidiate variables
+	someWebhook *entity.Webhook
+
+	// Mocked units
+	mockWebhookRepo *MockWebhookRepo
+
+	// Tested usecase
+	webhookUseCase usecase.WebhookUseCase
+}
+
+func (t *WebhookUseCaseSuite) SetupTest() {
+	t.ctx = context.Background()
+	t.ctrl = gomock.NewController(t.T())
+	t.mockWebhookRepo = NewMockWebhookRepo(t.ctrl)
+
+	t.received = make(chan *http.Request, 10)
+	t.receivedBodies = make(chan []byte, 10)
+	t.receiverStatus.Store(http.StatusOK)
+	t.receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		t.received <- r
+		t.receivedBodies <- body
+		w.WriteHeader(int(t.receiverStatus.Load()))
+	}))
+
+	t.someWebhook = &entity.Webhook{
+		ID:      5,
+		UserID:  1,
+		URL:     t.receiver.URL,
+		Secret:  "whsec_test",
+		Events:  []string{entity.WebhookEventAssetSold},
+		Enabled: true,
+	}
+
+	// The receiver listens on plain http on loopback.
+	sender := webapi.NewWebhookSender(time.Second, webapi.AllowHTTP(), webapi.AllowPrivateNetworks())
+	t.webhookUseCase = usecase.NewWebhookUseCase(t.mockWebhookRepo, sender, 3, 2)
+}
+
+func (t *WebhookUseCaseSuite) TearDownTest() {
+	t.receiver.Close()
+}
+
+func TestWebhookUseCaseSuite(t *testing.T) {
+	suite.Run(t, new(WebhookUseCaseSuite))
+}
+
+func (t *WebhookUseCaseSuite) someDelivery() *entity.WebhookDelivery {
+	return &entity.WebhookDelivery{
+		ID:        11,
+		WebhookID: t.someWebhook.ID,
+		Event:     entity.WebhookEventAssetSold,
+		Payload:   json.RawMessage(`{"asset_id":7}`),
+		Status:    entity.DeliveryPending,
+	}
+}
+
+func (t *WebhookUseCaseSuite) TestCreateWebhook_GreenPath() {
+	t.mockWebhookRepo.EXPECT().ListWebhooksByUser(t.ctx, int64(1)).Return(nil, nil)
+	t.mockWebhookRepo.EXPECT().CreateWebhook(t.ctx, gomock.Any()).Return(nil)
+
+	webhook, err := t.webhookUseCase.CreateWebhook(t.ctx, 1, "https://example.com/hook",
+		[]string{entity.WebhookEventAssetSold, entity.WebhookEventAssetSold, entity.WebhookEventAssetBought})
+
+	t.NoError(err)
+	t.True(webhook.Enabled)
+	t.Contains(webhook.Secret, "whsec_")
+	t.Equal([]string{entity.WebhookEventAssetSold, entity.WebhookEventAssetBought}, webhook.Events)
+}
+
+func (t *WebhookUseCaseSuite) TestCreateWebhook_ReturnsError_WhenInvalid() {
+	_, err := t.webhookUseCase.CreateWebhook(t.ctx, 1, "ftp://example.com", []string{entity.WebhookEventAssetSold})
+	t.ErrorIs(err, usecase.ErrInvalidWebhook)
+
+	_, err = t.webhookUseCase.CreateWebhook(t.ctx, 1, "https://example.com", []string{"asset.stolen"})
+	t.ErrorIs(err, usecase.ErrInvalidWebhook)
+
+	_, err = t.webhookUseCase.CreateWebhook(t.ctx, 1, "https://example.com", nil)
+	t.ErrorIs(err, usecase.ErrInvalidWebhook)
+}
+
+// staticResolver resolves the hosts in the map and fails for the others.
+type staticResolver map[string][]string
+
+func (r staticResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+
+	return addrs, nil
+}
+
+func (t *WebhookUseCaseSuite) TestCreateWebhook_RejectsInternalEndpoints() {
+	sender := webapi.NewWebhookSender(time.Second, webapi.WithResolver(staticResolver{
+		"hooks.example.com":    {"93.184.216.34"},
+		"internal.example.com": {"93.184.216.34", "10.0.0.5"},
+		"metadata.example.com": {"169.254.169.254"},
+	}))
+	webhookUseCase := usecase.NewWebhookUseCase(t.mockWebhookRepo, sender, 3, 2)
+	events := []string{entity.WebhookEventAssetSold}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "plain http", url: "http://hooks.example.com/hook"},
+		{name: "loopback", url: "https://127.0.0.1/hook"},
+		{name: "loopback v6", url: "https://[::1]:8443/hook"},
+		{name: "private", url: "https://192.168.1.10/hook"},
+		{name: "unique local v6", url: "https://[fd00:ec2::254]/hook"},
+		{name: "link-local metadata", url: "https://169.254.169.254/latest/meta-data"},
+		{name: "unspecified", url: "https://0.0.0.0/hook"},
+		{name: "carrier-grade NAT", url: "https://100.64.0.1/hook"},
+		{name: "v4-mapped loopback", url: "https://[::ffff:127.0.0.1]/hook"},
+		{name: "host resolving to a private address", url: "https://internal.example.com/hook"},
+		{name: "host resolving to metadata", url: "https://metadata.example.com/hook"},
+		{name: "unresolvable host", url: "https://missing.example.com/hook"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func() {
+			_, err := webhookUseCase.CreateWebhook(t.ctx, 1, tc.url, events)
+			t.ErrorIs(err, usecase.ErrInvalidWebhook)
+		})
+	}
+
+	t.mockWebhookRepo.EXPECT().ListWebhooksByUser(t.ctx, int64(1)).Return(nil, nil)
+	t.mockWebhookRepo.EXPECT().CreateWebhook(t.ctx, gomock.Any()).Return(nil)
+
+	_, err := webhookUseCase.CreateWebhook(t.ctx, 1, "https://hooks.example.com/hook", events)
+	t.NoError(err)
+}
+
+func (t *WebhookUseCaseSuite) TestDispatchPending_RefusesToDialInternalAddresses() {
+	// The URL passed the check when it was registered, but now points at
+	// loopback, as a DNS record changed after validation would.
+	webhookUseCase := usecase.NewWebhookUseCase(t.mockWebhookRepo,
+		webapi.NewWebhookSender(time.Second, webapi.AllowHTTP()), 3, 2)
+	delivery := t.someDelivery()
+
+	t.mockWebhookRepo.EXPECT().ClaimDueDeliveries(t.ctx, gomock.Any(), gomock.Any()).
+		Return([]*entity.WebhookDelivery{delivery}, nil)
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+	t.mockWebhookRepo.EXPECT().RecordWebhookResult(t.ctx, t.someWebhook.ID, false, 2).Return(false, nil)
+	t.mockWebhookRepo.EXPECT().UpdateDelivery(t.ctx, delivery).Return(nil)
+
+	_, err := webhookUseCase.DispatchPending(t.ctx)
+
+	t.NoError(err)
+	t.Equal(entity.DeliveryPending, delivery.Status)
+	t.Contains(delivery.LastError, webapi.ErrForbiddenAddress.Error())
+	t.Empty(t.received, "the receiver was not called")
+}
+
+func (t *WebhookUseCaseSuite) TestListDeliveries_ReturnsNotFound_ForOtherUser() {
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+
+	_, err := t.webhookUseCase.ListDeliveries(t.ctx, t.someWebhook.ID, 2, 10)
+
+	t.ErrorIs(err, usecase.ErrWebhookNotFound)
+}
+
+func (t *WebhookUseCaseSuite) TestHandleEvent_QueuesForSubscribedWebhooks() {
+	buyerWebhook := &entity.Webhook{ID: 6, UserID: 2, Events: []string{entity.WebhookEventAssetBought}, Enabled: true}
+	payload := `{"asset_id":7,"seller_id":1,"buyer_id":2,"price":10}`
+
+	t.mockWebhookRepo.EXPECT().ListWebhooksByUser(t.ctx, int64(1)).Return([]*entity.Webhook{t.someWebhook}, nil)
+	t.mockWebhookRepo.EXPECT().ListWebhooksByUser(t.ctx, int64(2)).Return([]*entity.Webhook{buyerWebhook}, nil)
+
+	var queued []*entity.WebhookDelivery
+
+	t.mockWebhookRepo.EXPECT().CreateDelivery(t.ctx, gomock.Any()).Times(2).DoAndReturn(
+		func(ctx context.Context, d *entity.WebhookDelivery) error {
+			queued = append(queued, d)
+
+			return nil
+		},
+	)
+
+	err := t.webhookUseCase.HandleEvent(t.ctx, &entity.Event{
+		ID:        99,
+		Type:      entity.EventAssetPurchased,
+		Payload:   json.RawMessage(payload),
+		RequestID: "req-1",
+	})
+
+	t.NoError(err)
+	t.Require().Len(queued, 2)
+	t.Equal(entity.WebhookEventAssetSold, queued[0].Event)
+	t.Equal(int64(99), queued[0].SourceEventID)
+	t.Equal("req-1", queued[0].RequestID)
+	t.Equal(entity.WebhookEventAssetBought, queued[1].Event)
+	t.Equal(buyerWebhook.ID, queued[1].WebhookID)
+}
+
+func (t *WebhookUseCaseSuite) TestHandleEvent_SkipsUnsubscribedAndDisabled() {
+	disabled := &entity.Webhook{ID: 6, UserID: 1, Events: []string{entity.WebhookEventAssetSold}}
+	buyerOnly := &entity.Webhook{ID: 7, UserID: 1, Events: []string{entity.WebhookEventAssetBought}, Enabled: true}
+
+	t.mockWebhookRepo.EXPECT().ListWebhooksByUser(t.ctx, int64(1)).Return([]*entity.Webhook{disabled, buyerOnly}, nil)
+	t.mockWebhookRepo.EXPECT().ListWebhooksByUser(t.ctx, int64(2)).Return(nil, nil)
+
+	err := t.webhookUseCase.HandleEvent(t.ctx, &entity.Event{
+		ID:      99,
+		Type:    entity.EventAssetPurchased,
+		Payload: json.RawMessage(`{"asset_id":7,"seller_id":1,"buyer_id":2}`),
+	})
+
+	t.NoError(err)
+}
+
+func (t *WebhookUseCaseSuite) TestDispatchPending_DeliversSignedPayload() {
+	delivery := t.someDelivery()
+
+	t.mockWebhookRepo.EXPECT().ClaimDueDeliveries(t.ctx, gomock.Any(), gomock.Any()).
+		Return([]*entity.WebhookDelivery{delivery}, nil)
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+	t.mockWebhookRepo.EXPECT().RecordWebhookResult(t.ctx, t.someWebhook.ID, true, 2).Return(false, nil)
+	t.mockWebhookRepo.EXPECT().UpdateDelivery(t.ctx, delivery).Return(nil)
+
+	n, err := t.webhookUseCase.DispatchPending(t.ctx)
+
+	t.NoError(err)
+	t.Equal(1, n)
+	t.Equal(entity.DeliverySucceeded, delivery.Status)
+	t.Equal(http.StatusOK, delivery.ResponseStatus)
+
+	req, body := <-t.received, <-t.receivedBodies
+	ts := req.Header.Get(webapi.TimestampHeader)
+	t.NotEmpty(ts)
+	t.Equal("sha256="+webapi.Sign(t.someWebhook.Secret, ts, body), req.Header.Get(webapi.SignatureHeader))
+
+	var p entity.WebhookPayload
+	t.NoError(json.Unmarshal(body, &p))
+	t.Equal(delivery.ID, p.ID)
+	t.JSONEq(`{"asset_id":7}`, string(p.Data))
+}
+
+func (t *WebhookUseCaseSuite) TestDispatchPending_ForwardsRequestID() {
+	delivery := t.someDelivery()
+	delivery.RequestID = "req-1"
+
+	t.mockWebhookRepo.EXPECT().ClaimDueDeliveries(t.ctx, gomock.Any(), gomock.Any()).
+		Return([]*entity.WebhookDelivery{delivery}, nil)
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+	t.mockWebhookRepo.EXPECT().RecordWebhookResult(t.ctx, t.someWebhook.ID, true, 2).Return(false, nil)
+	t.mockWebhookRepo.EXPECT().UpdateDelivery(t.ctx, delivery).Return(nil)
+
+	_, err := t.webhookUseCase.DispatchPending(t.ctx)
+
+	t.NoError(err)
+
+	req := <-t.received
+	<-t.receivedBodies
+	t.Equal("req-1", req.Header.Get(reqctx.RequestIDHeader))
+}
+
+func (t *WebhookUseCaseSuite) TestDispatchPending_SchedulesRetryWithBackoff() {
+	t.receiverStatus.Store(http.StatusInternalServerError)
+
+	delivery := t.someDelivery()
+
+	t.mockWebhookRepo.EXPECT().ClaimDueDeliveries(t.ctx, gomock.Any(), gomock.Any()).
+		Return([]*entity.WebhookDelivery{delivery}, nil)
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+	t.mockWebhookRepo.EXPECT().RecordWebhookResult(t.ctx, t.someWebhook.ID, false, 2).Return(false, nil)
+	t.mockWebhookRepo.EXPECT().UpdateDelivery(t.ctx, delivery).Return(nil)
+
+	_, err := t.webhookUseCase.DispatchPending(t.ctx)
+
+	t.NoError(err)
+	t.Equal(entity.DeliveryPending, delivery.Status)
+	t.Equal(1, delivery.Attempts)
+	t.Equal(http.StatusInternalServerError, delivery.ResponseStatus)
+	t.True(delivery.NextAttemptAt.After(time.Now()))
+}
+
+func (t *WebhookUseCaseSuite) TestDispatchPending_FailsAfterMaxAttempts() {
+	t.receiverStatus.Store(http.StatusNotFound)
+
+	delivery := t.someDelivery()
+	delivery.Attempts = 2
+
+	t.mockWebhookRepo.EXPECT().ClaimDueDeliveries(t.ctx, gomock.Any(), gomock.Any()).
+		Return([]*entity.WebhookDelivery{delivery}, nil)
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+	t.mockWebhookRepo.EXPECT().RecordWebhookResult(t.ctx, t.someWebhook.ID, false, 2).Return(false, nil)
+	t.mockWebhookRepo.EXPECT().UpdateDelivery(t.ctx, delivery).Return(nil)
+
+	_, err := t.webhookUseCase.DispatchPending(t.ctx)
+
+	t.NoError(err)
+	t.Equal(entity.DeliveryFailed, delivery.Status)
+	t.Equal(3, delivery.Attempts)
+}
+
+func (t *WebhookUseCaseSuite) TestDispatchPending_SkipsDeliveriesOfDisabledWebhook() {
+	t.receiverStatus.Store(http.StatusInternalServerError)
+
+	first, second := t.someDelivery(), t.someDelivery()
+	second.ID = 12
+
+	t.mockWebhookRepo.EXPECT().ClaimDueDeliveries(t.ctx, gomock.Any(), gomock.Any()).
+		Return([]*entity.WebhookDelivery{first, second}, nil)
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+	t.mockWebhookRepo.EXPECT().RecordWebhookResult(t.ctx, t.someWebhook.ID, false, 2).Return(true, nil)
+	t.mockWebhookRepo.EXPECT().UpdateDelivery(t.ctx, gomock.Any()).Times(2).Return(nil)
+
+	_, err := t.webhookUseCase.DispatchPending(t.ctx)
+
+	t.NoError(err)
+	t.Len(t.received, 1)
+	t.Equal(entity.DeliveryFailed, second.Status)
+	t.Equal(0, second.Attempts)
+}
+
+func (t *WebhookUseCaseSuite) TestSendTestEvent_DeliversPingOnce() {
+	t.receiverStatus.Store(http.StatusServiceUnavailable)
+
+	t.mockWebhookRepo.EXPECT().GetWebhook(t.ctx, t.someWebhook.ID).Return(t.someWebhook, nil)
+	t.mockWebhookRepo.EXPECT().CreateDelivery(t.ctx, gomock.Any()).Return(nil)
+	t.mockWebhookRepo.EXPECT().UpdateDelivery(t.ctx, gomock.Any()).Return(nil)
+
+	delivery, err := t.webhookUseCase.SendTestEvent(t.ctx, t.someWebhook.ID, t.someWebhook.UserID)
+
+	t.NoError(err)
+	t.Equal(entity.WebhookEventPing, delivery.Event)
+	t.Equal(entity.DeliveryFailed, delivery.Status)
+	t.Equal(http.StatusServiceUnavailable, delivery.ResponseStatus)
+}
+
+func (t *WebhookUseCaseSuite) TestDispatchPending_ReturnsError_WhenRepoReturnsError() {
+	t.mockWebhookRepo.EXPECT().ClaimDueDeliveries(t.ctx, gomock.Any(), gomock.Any()).Return(nil, assert.AnError)
+
+	_, err := t.webhookUseCase.DispatchPending(t.ctx)
+
+	t.ErrorIs(err, assert.AnError)
+}
@@ -0,0 +1,2 @@
+-- Tokens issued before this moment are rejected
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sessions_revoked_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS request_id;
+ALTER TABLE outbox_dead_letter DROP COLUMN IF EXISTS request_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS request_id;
@@ -0,0 +1,4 @@
+-- Request that caused an event or webhook delivery, for correlating logs
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS request_id VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE outbox_dead_letter ADD COLUMN IF NOT EXISTS request_id VARCHAR(128) NOT NULL DEFAULT '';
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS request_id VARCHAR(128) NOT NULL DEFAULT '';
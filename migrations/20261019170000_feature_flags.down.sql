@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS feature_flags;
+DROP FUNCTION IF EXISTS notify_feature_flags();
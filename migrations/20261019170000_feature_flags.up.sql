@@ -0,0 +1,23 @@
+-- Feature flags, evaluated per user from an in-memory cache of this table
+CREATE TABLE IF NOT EXISTS feature_flags (
+    key VARCHAR(64) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    rollout SMALLINT NOT NULL DEFAULT 0 CHECK (rollout BETWEEN 0 AND 100),
+    user_ids BIGINT[] NOT NULL DEFAULT '{}',
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Tell every instance to reload its cache on commit, also of changes made by hand
+CREATE OR REPLACE FUNCTION notify_feature_flags() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('hive_feature_flags', COALESCE(NEW.key, OLD.key));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS feature_flags_notify ON feature_flags;
+CREATE TRIGGER feature_flags_notify
+    AFTER INSERT OR UPDATE OR DELETE ON feature_flags
+    FOR EACH ROW EXECUTE FUNCTION notify_feature_flags();
@@ -0,0 +1,14 @@
+-- The hash of the latest audit entry. Appends lock this row, so under every
+-- isolation level an append either sees the committed head or fails with a
+-- serialization error and is retried.
+CREATE TABLE IF NOT EXISTS audit_chain_head (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    hash CHAR(64) NOT NULL
+);
+
+INSERT INTO audit_chain_head (id, hash)
+SELECT TRUE, COALESCE(
+    (SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1),
+    '0000000000000000000000000000000000000000000000000000000000000000'
+)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Outbox event that created a notification, for publishing it once committed
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS event_id BIGINT;
+CREATE INDEX IF NOT EXISTS notifications_event_id_idx ON notifications (event_id) WHERE event_id IS NOT NULL;
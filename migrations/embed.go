@@ -0,0 +1,27 @@
+// Package migrations embeds the SQL migrations, so the binary can apply
+// them without the migrations directory.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the *.up.sql and *.down.sql files.
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// SQLiteFS holds the migrations of the SQLite storage, from the sqlite directory.
+var SQLiteFS = mustSub(sqliteFiles, "sqlite")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,3 @@
+ALTER TABLE webhook_deliveries DROP COLUMN request_id;
+ALTER TABLE outbox_dead_letter DROP COLUMN request_id;
+ALTER TABLE outbox DROP COLUMN request_id;
@@ -0,0 +1,4 @@
+-- Request that caused an event or webhook delivery, for correlating logs
+ALTER TABLE outbox ADD COLUMN request_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE outbox_dead_letter ADD COLUMN request_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE webhook_deliveries ADD COLUMN request_id TEXT NOT NULL DEFAULT '';
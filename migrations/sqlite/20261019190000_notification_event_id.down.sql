@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS notifications_event_id_idx;
+ALTER TABLE notifications DROP COLUMN event_id;
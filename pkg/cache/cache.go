@@ -0,0 +1,24 @@
+// Package cache implements key-value caches with expiring entries.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores values for a limited time.
+type Cache interface {
+	// Get returns the values of the keys that are present; missing and
+	// expired keys are left out.
+	Get(ctx context.Context, keys ...string) (map[string][]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Invalidator broadcasts deleted keys to the caches of other instances.
+type Invalidator interface {
+	Publish(ctx context.Context, keys ...string) error
+	// Subscribe calls fn with the keys published by any instance until ctx
+	// is done. It returns once the subscription is established.
+	Subscribe(ctx context.Context, fn func(keys []string)) error
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const _defaultMaxEntries = 10000
+
+// LRU is an in-process cache that evicts the least recently used entry
+// when it is full.
+type LRU struct {
+	maxEntries int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+var _ Cache = (*LRU)(nil)
+
+// NewLRU -.
+func NewLRU(opts ...LRUOption) *LRU {
+	c := &LRU{
+		maxEntries: _defaultMaxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Get -.
+func (c *LRU) Get(_ context.Context, keys ...string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		el, ok := c.items[key]
+		if !ok {
+			continue
+		}
+
+		e := el.Value.(*lruEntry)
+		if !now.Before(e.expiresAt) {
+			c.remove(el)
+			continue
+		}
+
+		c.ll.MoveToFront(el)
+		values[key] = e.value
+	}
+
+	return values, nil
+}
+
+// Set -.
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	expiresAt := time.Now().Add(ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry)
+		e.value, e.expiresAt = value, expiresAt
+		c.ll.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for c.ll.Len() > c.maxEntries {
+		c.remove(c.ll.Back())
+	}
+
+	return nil
+}
+
+// Delete -.
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
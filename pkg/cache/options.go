@@ -0,0 +1,37 @@
+package cache
+
+import "time"
+
+// LRUOption -.
+type LRUOption func(*LRU)
+
+// MaxEntries -.
+func MaxEntries(n int) LRUOption {
+	return func(c *LRU) {
+		c.maxEntries = n
+	}
+}
+
+// RedisOption -.
+type RedisOption func(*Redis)
+
+// Prefix is prepended to every key.
+func Prefix(prefix string) RedisOption {
+	return func(r *Redis) {
+		r.prefix = prefix
+	}
+}
+
+// Channel is the pub/sub channel of invalidations.
+func Channel(channel string) RedisOption {
+	return func(r *Redis) {
+		r.channel = channel
+	}
+}
+
+// ConnTimeout bounds the initial ping.
+func ConnTimeout(timeout time.Duration) RedisOption {
+	return func(r *Redis) {
+		r.timeout = timeout
+	}
+}
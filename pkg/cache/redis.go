@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	_defaultPrefix  = "hive:cache:"
+	_defaultChannel = "hive:cache:invalidate"
+	_defaultTimeout = 5 * time.Second
+)
+
+// Redis is a cache on a server speaking the Redis protocol, shared by all
+// instances. It also broadcasts invalidations over the server's pub/sub.
+type Redis struct {
+	prefix  string
+	channel string
+	timeout time.Duration
+
+	Client *redis.Client
+}
+
+var (
+	_ Cache       = (*Redis)(nil)
+	_ Invalidator = (*Redis)(nil)
+)
+
+// NewRedis connects to the server of a redis:// URL.
+func NewRedis(url string, opts ...RedisOption) (*Redis, error) {
+	r := &Redis{
+		prefix:  _defaultPrefix,
+		channel: _defaultChannel,
+		timeout: _defaultTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	redisOpts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("cache - NewRedis - redis.ParseURL: %w", err)
+	}
+
+	r.Client = redis.NewClient(redisOpts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err = r.Client.Ping(ctx).Err(); err != nil {
+		r.Client.Close()
+		return nil, fmt.Errorf("cache - NewRedis - Ping: %w", err)
+	}
+
+	return r, nil
+}
+
+// Get -.
+func (r *Redis) Get(ctx context.Context, keys ...string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	res, err := r.Client.MGet(ctx, r.prefixed(keys)...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range res {
+		if s, ok := v.(string); ok {
+			values[keys[i]] = []byte(s)
+		}
+	}
+
+	return values, nil
+}
+
+// Set -.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.Client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete -.
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return r.Client.Del(ctx, r.prefixed(keys)...).Err()
+}
+
+// Publish -.
+func (r *Redis) Publish(ctx context.Context, keys ...string) error {
+	msg, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	return r.Client.Publish(ctx, r.channel, msg).Err()
+}
+
+// Subscribe -.
+func (r *Redis) Subscribe(ctx context.Context, fn func(keys []string)) error {
+	sub := r.Client.Subscribe(ctx, r.channel)
+
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return fmt.Errorf("cache - Subscribe - Receive: %w", err)
+	}
+
+	go func() {
+		defer sub.Close()
+
+		ch := sub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				var keys []string
+				if json.Unmarshal([]byte(msg.Payload), &keys) == nil {
+					fn(keys)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close -.
+func (r *Redis) Close() error {
+	return r.Client.Close()
+}
+
+func (r *Redis) prefixed(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	return prefixed
+}
@@ -0,0 +1,82 @@
+// Package grpcserver implements gRPC server.
+package grpcserver
+
+import (
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	_defaultAddr            = ":81"
+	_defaultShutdownTimeout = 3 * time.Second
+)
+
+// Server -.
+type Server struct {
+	App             *grpc.Server
+	notify          chan error
+	address         string
+	serverOptions   []grpc.ServerOption
+	shutdownTimeout time.Duration
+}
+
+// New -.
+// Services are registered on App before Start is called.
+func New(opts ...Option) *Server {
+	s := &Server{
+		notify:          make(chan error, 1),
+		address:         _defaultAddr,
+		shutdownTimeout: _defaultShutdownTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.App = grpc.NewServer(s.serverOptions...)
+
+	return s
+}
+
+// Start -.
+func (s *Server) Start() {
+	go func() {
+		ln, err := net.Listen("tcp", s.address)
+		if err != nil {
+			s.notify <- err
+			close(s.notify)
+
+			return
+		}
+
+		s.notify <- s.App.Serve(ln)
+		close(s.notify)
+	}()
+}
+
+// Notify -.
+func (s *Server) Notify() <-chan error {
+	return s.notify
+}
+
+// Shutdown -.
+// It waits for running calls up to the shutdown timeout, then cancels them.
+func (s *Server) Shutdown() error {
+	stopped := make(chan struct{})
+
+	go func() {
+		s.App.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		s.App.Stop()
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+// Package health implements liveness and readiness checks of the service
+// and their HTTP handlers.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	_defaultTimeout  = 2 * time.Second
+	_defaultCacheTTL = time.Second
+)
+
+// Statuses of a check and of a report.
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check returns an error while the checked subsystem is unhealthy.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a check.
+type Result struct {
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Optional   bool      `json:"optional,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report is the outcome of the liveness or readiness checks.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Healthy reports whether the service passed the checks.
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Registry holds the checks that subsystems register. Readiness runs all of
+// them, liveness only those registered with Liveness. Results are cached for
+// the cache TTL, so frequent probes do not load the dependencies.
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu     sync.RWMutex
+	checks []*check
+
+	draining atomic.Bool
+}
+
+type check struct {
+	name     string
+	fn       Check
+	liveness bool
+	optional bool
+
+	// mu is held while the check runs, so concurrent probes share a run.
+	mu     sync.Mutex
+	result Result
+}
+
+// New -.
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		timeout:  _defaultTimeout,
+		cacheTTL: _defaultCacheTTL,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Register adds the check called name.
+func (r *Registry) Register(name string, fn Check, opts ...CheckOption) {
+	c := &check{name: name, fn: fn}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, c)
+}
+
+// Drain makes readiness fail from now on, so that load balancers stop
+// sending requests before the servers shut down. Liveness is unaffected.
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Live runs the liveness checks.
+func (r *Registry) Live(ctx context.Context) Report {
+	return r.run(ctx, func(c *check) bool { return c.liveness })
+}
+
+// Ready runs all checks, or none while draining.
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.draining.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	return r.run(ctx, func(*check) bool { return true })
+}
+
+// run runs the selected checks concurrently. A failed optional check is
+// reported but leaves the service healthy.
+func (r *Registry) run(ctx context.Context, selected func(c *check) bool) Report {
+	r.mu.RLock()
+
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if selected(c) {
+			checks = append(checks, c)
+		}
+	}
+
+	r.mu.RUnlock()
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, c := range checks {
+		wg.Add(1)
+
+		go func(i int, c *check) {
+			defer wg.Done()
+
+			results[i] = r.result(ctx, c)
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	report := Report{Status: StatusOK}
+	if len(checks) > 0 {
+		report.Checks = make(map[string]Result, len(checks))
+	}
+
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+
+		if results[i].Status != StatusOK && !c.optional {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+// result returns the cached result of c, running it when the result is
+// older than the cache TTL.
+func (r *Registry) result(ctx context.Context, c *check) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.result.CheckedAt.IsZero() && time.Since(c.result.CheckedAt) < r.cacheTTL {
+		return c.result
+	}
+
+	// The result is shared, so it must not depend on the caller giving up.
+	checkCtx, cancel := context.WithTimeout(detach(ctx), r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runCheck(checkCtx, c.fn)
+
+	c.result = Result{
+		Status:     StatusOK,
+		Optional:   c.optional,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt:  start,
+	}
+
+	if err != nil {
+		c.result.Status = StatusFail
+		c.result.Error = err.Error()
+	}
+
+	return c.result
+}
+
+// runCheck runs fn, returning when ctx is done even if fn ignores it.
+func runCheck(ctx context.Context, fn Check) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// detachedContext keeps the values of a context but not its deadline or
+// cancellation.
+type detachedContext struct {
+	context.Context
+	values context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{Context: context.Background(), values: ctx}
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
@@ -0,0 +1,240 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/pkg/health"
+)
+
+var errDown = errors.New("down")
+
+func pass(context.Context) error { return nil }
+
+func fail(context.Context) error { return errDown }
+
+// hang ignores its context and never returns.
+func hang(context.Context) error { select {} }
+
+// counted returns a check that counts its runs.
+func counted(runs *atomic.Int32) health.Check {
+	return func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}
+}
+
+func TestRegistry_Ready(t *testing.T) {
+	type check struct {
+		name string
+		fn   health.Check
+		opts []health.CheckOption
+	}
+
+	tests := []struct {
+		name       string
+		checks     []check
+		wantStatus string
+		wantChecks map[string]string
+		wantErrors map[string]string
+	}{
+		{
+			name:       "no checks",
+			wantStatus: health.StatusOK,
+		},
+		{
+			name:       "all pass",
+			checks:     []check{{name: "db", fn: pass}, {name: "cache", fn: pass}},
+			wantStatus: health.StatusOK,
+			wantChecks: map[string]string{"db": health.StatusOK, "cache": health.StatusOK},
+		},
+		{
+			name:       "required check fails",
+			checks:     []check{{name: "db", fn: fail}, {name: "cache", fn: pass}},
+			wantStatus: health.StatusFail,
+			wantChecks: map[string]string{"db": health.StatusFail, "cache": health.StatusOK},
+			wantErrors: map[string]string{"db": errDown.Error()},
+		},
+		{
+			name:       "optional check fails",
+			checks:     []check{{name: "db", fn: pass}, {name: "cache", fn: fail, opts: []health.CheckOption{health.Optional()}}},
+			wantStatus: health.StatusOK,
+			wantChecks: map[string]string{"db": health.StatusOK, "cache": health.StatusFail},
+			wantErrors: map[string]string{"cache": errDown.Error()},
+		},
+		{
+			name:       "check times out",
+			checks:     []check{{name: "db", fn: hang}},
+			wantStatus: health.StatusFail,
+			wantChecks: map[string]string{"db": health.StatusFail},
+			wantErrors: map[string]string{"db": context.DeadlineExceeded.Error()},
+		},
+		{
+			name:       "optional check times out",
+			checks:     []check{{name: "db", fn: pass}, {name: "cache", fn: hang, opts: []health.CheckOption{health.Optional()}}},
+			wantStatus: health.StatusOK,
+			wantChecks: map[string]string{"db": health.StatusOK, "cache": health.StatusFail},
+			wantErrors: map[string]string{"cache": context.DeadlineExceeded.Error()},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := health.New(health.Timeout(20 * time.Millisecond))
+			for _, c := range tc.checks {
+				r.Register(c.name, c.fn, c.opts...)
+			}
+
+			start := time.Now()
+			report := r.Ready(context.Background())
+
+			require.Less(t, time.Since(start), time.Second, "a hanging check is bounded by the timeout")
+			require.Equal(t, tc.wantStatus, report.Status)
+			require.Len(t, report.Checks, len(tc.wantChecks))
+
+			for name, status := range tc.wantChecks {
+				require.Equal(t, status, report.Checks[name].Status, name)
+				require.Equal(t, tc.wantErrors[name], report.Checks[name].Error, name)
+			}
+		})
+	}
+}
+
+func TestRegistry_Live(t *testing.T) {
+	r := health.New()
+	r.Register("process", pass, health.Liveness())
+	r.Register("db", fail)
+
+	live := r.Live(context.Background())
+	require.True(t, live.Healthy())
+	require.Len(t, live.Checks, 1)
+	require.Contains(t, live.Checks, "process")
+
+	ready := r.Ready(context.Background())
+	require.False(t, ready.Healthy())
+	require.Len(t, ready.Checks, 2)
+}
+
+func TestRegistry_CacheTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		wait     time.Duration
+		wantRuns int32
+	}{
+		{name: "reused within the TTL", ttl: time.Hour, wantRuns: 1},
+		{name: "rerun after the TTL", ttl: 10 * time.Millisecond, wait: 30 * time.Millisecond, wantRuns: 2},
+		{name: "no cache", ttl: 0, wantRuns: 2},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var runs atomic.Int32
+
+			r := health.New(health.CacheTTL(tc.ttl))
+			r.Register("db", counted(&runs), health.Liveness())
+
+			first := r.Ready(context.Background())
+			time.Sleep(tc.wait)
+			// Liveness shares the result of the check with readiness.
+			second := r.Live(context.Background())
+
+			require.Equal(t, tc.wantRuns, runs.Load())
+
+			if tc.wantRuns == 1 {
+				require.Equal(t, first.Checks["db"].CheckedAt, second.Checks["db"].CheckedAt)
+			}
+		})
+	}
+}
+
+func TestRegistry_SharesRunBetweenConcurrentProbes(t *testing.T) {
+	var runs atomic.Int32
+
+	release := make(chan struct{})
+
+	r := health.New(health.CacheTTL(time.Hour))
+	r.Register("db", func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+
+	done := make(chan health.Report, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- r.Ready(context.Background()) }()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		require.True(t, (<-done).Healthy())
+	}
+
+	require.EqualValues(t, 1, runs.Load())
+}
+
+func TestRegistry_CallerCancellationDoesNotFailCheck(t *testing.T) {
+	r := health.New(health.Timeout(time.Second))
+	r.Register("db", func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.True(t, r.Ready(ctx).Healthy())
+}
+
+func TestRegistry_Drain(t *testing.T) {
+	r := health.New()
+	r.Register("process", pass, health.Liveness())
+	r.Register("db", pass)
+
+	get := func(h http.Handler) (int, health.Report) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		var report health.Report
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+
+		return rec.Code, report
+	}
+
+	tests := []struct {
+		name       string
+		drain      bool
+		wantReady  int
+		wantStatus string
+	}{
+		{name: "ready", wantReady: http.StatusOK, wantStatus: health.StatusOK},
+		{name: "draining", drain: true, wantReady: http.StatusServiceUnavailable, wantStatus: health.StatusShuttingDown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.drain {
+				r.Drain()
+			}
+
+			code, report := get(r.ReadyHandler())
+			require.Equal(t, tc.wantReady, code)
+			require.Equal(t, tc.wantStatus, report.Status)
+
+			// Draining does not make the process restart.
+			code, report = get(r.LiveHandler())
+			require.Equal(t, http.StatusOK, code)
+			require.Equal(t, health.StatusOK, report.Status)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package health
+
+import "time"
+
+// Option -.
+type Option func(*Registry)
+
+// Timeout bounds a single check.
+func Timeout(timeout time.Duration) Option {
+	return func(r *Registry) {
+		r.timeout = timeout
+	}
+}
+
+// CacheTTL is how long the result of a check is reused.
+func CacheTTL(ttl time.Duration) Option {
+	return func(r *Registry) {
+		r.cacheTTL = ttl
+	}
+}
+
+// CheckOption -.
+type CheckOption func(*check)
+
+// Liveness also runs the check for liveness. Only checks that a restart
+// can fix belong there; a dependency that is down does not.
+func Liveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
+
+// Optional reports the check without failing when it does, for
+// dependencies the service degrades gracefully without.
+func Optional() CheckOption {
+	return func(c *check) {
+		c.optional = true
+	}
+}
@@ -0,0 +1,112 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// _systemdFirstFD is the first descriptor passed by systemd socket activation.
+const _systemdFirstFD = 3
+
+// ErrNoSystemdSockets is returned when the process was not passed sockets by
+// systemd socket activation.
+var ErrNoSystemdSockets = errors.New("no sockets passed by systemd")
+
+func tcpListener(addr string) func() ([]net.Listener, error) {
+	return func() ([]net.Listener, error) {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+
+		return []net.Listener{ln}, nil
+	}
+}
+
+// unixListener listens on the socket file at path, replacing a socket left
+// behind by a process that did not stop cleanly.
+func unixListener(path string) func() ([]net.Listener, error) {
+	return func() ([]net.Listener, error) {
+		if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+			if err = os.Remove(path); err != nil {
+				return nil, fmt.Errorf("remove stale socket: %w", err)
+			}
+		}
+
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+
+		return []net.Listener{ln}, nil
+	}
+}
+
+func fdListener(fd uintptr) func() ([]net.Listener, error) {
+	return func() ([]net.Listener, error) {
+		ln, err := fileListener(fd)
+		if err != nil {
+			return nil, err
+		}
+
+		return []net.Listener{ln}, nil
+	}
+}
+
+// systemdListeners returns the sockets passed by systemd, as described in
+// sd_listen_fds(3), and unsets its variables so that children do not take
+// them for their own.
+func systemdListeners() ([]net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, ErrNoSystemdSockets
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, ErrNoSystemdSockets
+	}
+
+	listeners := make([]net.Listener, 0, n)
+
+	for fd := _systemdFirstFD; fd < _systemdFirstFD+n; fd++ {
+		ln, err := fileListener(uintptr(fd))
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+
+			return nil, err
+		}
+
+		listeners = append(listeners, ln)
+	}
+
+	return listeners, nil
+}
+
+// fileListener listens on the inherited socket fd. The listener uses a
+// duplicate, so fd itself is closed.
+func fileListener(fd uintptr) (net.Listener, error) {
+	f := os.NewFile(fd, "fd"+strconv.FormatUint(uint64(fd), 10))
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	defer f.Close()
+
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("file descriptor %d: %w", fd, err)
+	}
+
+	return ln, nil
+}
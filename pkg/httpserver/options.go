@@ -8,10 +8,77 @@ import (
 // Option -.
 type Option func(*Server)
 
-// Port -.
+// Port adds a TCP listener on the port. It can be given several times, and
+// combined with the other listeners, to serve on all of them.
 func Port(port string) Option {
 	return func(s *Server) {
-		s.server.Addr = net.JoinHostPort("", port)
+		s.listeners = append(s.listeners, tcpListener(net.JoinHostPort("", port)))
+	}
+}
+
+// UnixSocket adds a listener on the unix socket file at path.
+func UnixSocket(path string) Option {
+	return func(s *Server) {
+		s.listeners = append(s.listeners, unixListener(path))
+	}
+}
+
+// FileDescriptor adds a listener on an inherited socket, such as one passed
+// by a supervisor restarting the process.
+func FileDescriptor(fd uintptr) Option {
+	return func(s *Server) {
+		s.listeners = append(s.listeners, fdListener(fd))
+	}
+}
+
+// Systemd adds listeners on the sockets passed by systemd socket activation.
+// Start fails with ErrNoSystemdSockets when there are none.
+func Systemd() Option {
+	return func(s *Server) {
+		s.listeners = append(s.listeners, systemdListeners)
+	}
+}
+
+// TLS serves HTTPS, and HTTP/2 over it, on every listener with the
+// certificate and key in PEM files. They are reloaded when the files change.
+func TLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		if s.certs == nil {
+			s.certs = &certReloader{interval: _defaultCertReloadInterval}
+		}
+
+		s.certs.certFile, s.certs.keyFile = certFile, keyFile
+	}
+}
+
+// ClientCA requires clients to present a certificate signed by a CA in the
+// PEM file (mutual TLS). It only applies together with TLS.
+func ClientCA(caFile string) Option {
+	return func(s *Server) {
+		if s.certs == nil {
+			s.certs = &certReloader{interval: _defaultCertReloadInterval}
+		}
+
+		s.certs.clientCAFile = caFile
+	}
+}
+
+// CertReloadInterval is how often the TLS files are checked for changes.
+func CertReloadInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		if s.certs == nil {
+			s.certs = &certReloader{}
+		}
+
+		s.certs.interval = interval
+	}
+}
+
+// H2C serves HTTP/2 without TLS to clients that ask for it, such as
+// services behind a proxy that terminates TLS.
+func H2C() Option {
+	return func(s *Server) {
+		s.h2c = true
 	}
 }
 
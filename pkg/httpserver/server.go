@@ -3,8 +3,16 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"sync"
 	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 const (
@@ -19,21 +27,48 @@ type Server struct {
 	server          *http.Server
 	notify          chan error
 	shutdownTimeout time.Duration
+
+	// listeners open the sockets to serve on; the default address when empty.
+	listeners []func() ([]net.Listener, error)
+
+	certs *certReloader
+	h2c   bool
+
+	// stopping is closed when Shutdown starts, see Stopping.
+	stopping     chan struct{}
+	stoppingOnce sync.Once
+}
+
+type stoppingKey struct{}
+
+// Stopping returns a channel that is closed when the server serving the
+// request of ctx starts shutting down, or nil outside such a request.
+// Shutdown waits for running handlers and does not track hijacked
+// connections, so long-lived handlers such as streams return on it.
+func Stopping(ctx context.Context) <-chan struct{} {
+	stopping, _ := ctx.Value(stoppingKey{}).(chan struct{})
+
+	return stopping
 }
 
 // New -.
+// The server listens once Start is called.
 func New(handler http.Handler, opts ...Option) *Server {
 	httpServer := &http.Server{
 		Handler:      handler,
 		ReadTimeout:  _defaultReadTimeout,
 		WriteTimeout: _defaultWriteTimeout,
-		Addr:         _defaultAddr,
 	}
 
 	s := &Server{
 		server:          httpServer,
 		notify:          make(chan error, 1),
 		shutdownTimeout: _defaultShutdownTimeout,
+		stopping:        make(chan struct{}),
+	}
+
+	httpServer.BaseContext = func(net.Listener) context.Context {
+		return context.WithValue(context.Background(), stoppingKey{}, s.stopping)
 	}
 
 	// Custom options
@@ -41,27 +76,106 @@ func New(handler http.Handler, opts ...Option) *Server {
 		opt(s)
 	}
 
-	s.start()
+	// The client CA and reload interval mean nothing without a certificate.
+	if s.certs != nil && s.certs.certFile == "" {
+		s.certs = nil
+	}
+
+	if len(s.listeners) == 0 {
+		s.listeners = append(s.listeners, tcpListener(_defaultAddr))
+	}
+
+	if s.h2c {
+		s.server.Handler = h2c.NewHandler(s.server.Handler, &http2.Server{})
+	}
 
 	return s
 }
 
-func (s *Server) start() {
+// Start -.
+// It serves on every listener; the first of them to fail is reported by
+// Notify.
+func (s *Server) Start() {
 	go func() {
-		s.notify <- s.server.ListenAndServe()
+		listeners, err := s.listen()
+		if err != nil {
+			s.notify <- err
+			close(s.notify)
+
+			return
+		}
+
+		errs := make(chan error, len(listeners))
+
+		for _, ln := range listeners {
+			go func(ln net.Listener) {
+				errs <- s.server.Serve(ln)
+			}(ln)
+		}
+
+		s.notify <- <-errs
 		close(s.notify)
 	}()
 }
 
+// listen opens the listeners, wrapped in TLS when configured. On failure it
+// closes the ones already open.
+func (s *Server) listen() ([]net.Listener, error) {
+	var tlsConfig *tls.Config
+
+	if s.certs != nil {
+		if err := s.certs.load(); err != nil {
+			return nil, fmt.Errorf("httpserver - listen - %w", err)
+		}
+
+		tlsConfig = s.certs.tlsConfig()
+		// Serve sets up HTTP/2 when the config offers it.
+		s.server.TLSConfig = tlsConfig
+	}
+
+	var listeners []net.Listener
+
+	for _, open := range s.listeners {
+		lns, err := open()
+		if err != nil {
+			for _, ln := range listeners {
+				ln.Close()
+			}
+
+			return nil, fmt.Errorf("httpserver - listen - %w", err)
+		}
+
+		listeners = append(listeners, lns...)
+	}
+
+	if tlsConfig != nil {
+		for i, ln := range listeners {
+			listeners[i] = tls.NewListener(ln, tlsConfig)
+		}
+	}
+
+	return listeners, nil
+}
+
 // Notify -.
 func (s *Server) Notify() <-chan error {
 	return s.notify
 }
 
 // Shutdown -.
+// It closes the listeners, tells long-lived handlers to return (see
+// Stopping) and waits for running requests up to the shutdown timeout, then
+// closes their connections, which cancels their contexts.
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 
-	return s.server.Shutdown(ctx)
+	s.stoppingOnce.Do(func() { close(s.stopping) })
+
+	err := s.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errors.Join(err, s.server.Close())
+	}
+
+	return err
 }
@@ -0,0 +1,189 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unixClient returns a client that connects to the socket at path.
+func unixClient(path string, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+			TLSClientConfig:   tlsConfig,
+			ForceAttemptHTTP2: true,
+			DisableKeepAlives: true,
+		},
+	}
+}
+
+// waitListening waits until Start listens on the socket at path.
+func waitListening(t *testing.T, path string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			return false
+		}
+
+		conn.Close()
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func noContent(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestServer_ServesOnEveryListener(t *testing.T) {
+	dir := t.TempDir()
+	sockets := []string{filepath.Join(dir, "a.sock"), filepath.Join(dir, "b.sock")}
+
+	s := New(http.HandlerFunc(noContent), UnixSocket(sockets[0]), UnixSocket(sockets[1]))
+	s.Start()
+
+	for _, path := range sockets {
+		waitListening(t, path)
+
+		resp, err := unixClient(path, nil).Get("http://hive/")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	require.NoError(t, s.Shutdown())
+
+	select {
+	case err := <-s.Notify():
+		require.ErrorIs(t, err, http.ErrServerClosed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Notify did not report the shutdown")
+	}
+
+	_, ok := <-s.Notify()
+	require.False(t, ok, "Notify is closed after the first error")
+
+	for _, path := range sockets {
+		_, err := net.Dial("unix", path)
+		require.Error(t, err, "%s is closed", path)
+	}
+}
+
+func TestServer_ServesTLSOnEveryListener(t *testing.T) {
+	ca := newTestCA(t)
+	files := newCertFiles(t)
+	files.write(t, ca.issue(t, "server", x509.ExtKeyUsageServerAuth))
+
+	dir := t.TempDir()
+	sockets := []string{filepath.Join(dir, "a.sock"), filepath.Join(dir, "b.sock")}
+
+	s := New(http.HandlerFunc(noContent), UnixSocket(sockets[0]), UnixSocket(sockets[1]), TLS(files.cert, files.key))
+	// waitListening closes its connections without a handshake.
+	s.server.ErrorLog = log.New(io.Discard, "", 0)
+	s.Start()
+	t.Cleanup(func() { _ = s.Shutdown() })
+
+	for _, path := range sockets {
+		waitListening(t, path)
+
+		client := unixClient(path, &tls.Config{RootCAs: ca.pool, ServerName: "localhost", MinVersion: tls.VersionTLS12})
+
+		resp, err := client.Get("https://localhost/")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Equal(t, "server", resp.TLS.PeerCertificates[0].Subject.CommonName)
+		require.Equal(t, "h2", resp.TLS.NegotiatedProtocol)
+	}
+}
+
+func TestServer_NotifiesListenError(t *testing.T) {
+	dir := t.TempDir()
+	opened := filepath.Join(dir, "opened.sock")
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{
+			name: "second listener fails",
+			opts: []Option{UnixSocket(opened), UnixSocket(filepath.Join(dir, "missing", "b.sock"))},
+		},
+		{
+			name: "certificate fails to load",
+			opts: []Option{UnixSocket(opened), TLS(filepath.Join(dir, "missing.pem"), filepath.Join(dir, "missing.key"))},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(http.HandlerFunc(noContent), tc.opts...)
+			s.Start()
+
+			select {
+			case err := <-s.Notify():
+				require.Error(t, err)
+				require.NotErrorIs(t, err, http.ErrServerClosed)
+			case <-time.After(5 * time.Second):
+				t.Fatal("Notify did not report the listen error")
+			}
+
+			// The listeners opened before the failure are closed again.
+			_, err := net.Dial("unix", opened)
+			require.Error(t, err)
+
+			_ = os.Remove(opened)
+		})
+	}
+}
+
+func TestServer_ShutdownEndsOpenStreams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.sock")
+
+	// stream writes until the server stops, like a notification stream.
+	stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-Stopping(r.Context()):
+		case <-r.Context().Done():
+		}
+	})
+
+	s := New(stream, UnixSocket(path), ShutdownTimeout(10*time.Second))
+	s.Start()
+	waitListening(t, path)
+
+	resp, err := unixClient(path, nil).Get("http://hive/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	start := time.Now()
+	require.NoError(t, s.Shutdown())
+	require.Less(t, time.Since(start), time.Second, "an open stream does not hold the shutdown")
+
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err, "the stream ends cleanly")
+}
+
+func TestStopping_OutsideRequest(t *testing.T) {
+	require.Nil(t, Stopping(context.Background()))
+}
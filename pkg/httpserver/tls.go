@@ -0,0 +1,125 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const _defaultCertReloadInterval = 10 * time.Second
+
+// ErrNoCACertificates is returned for a client CA file without certificates.
+var ErrNoCACertificates = errors.New("no CA certificates found")
+
+// certReloader holds the certificate and client CAs of the server and
+// reloads them when their files change, so that renewed certificates are
+// served without a restart.
+type certReloader struct {
+	certFile, keyFile string
+	clientCAFile      string
+	interval          time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	modTimes  [3]time.Time
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// tlsConfig returns the config of the listeners. Every handshake gets the
+// current certificate and client CAs.
+func (r *certReloader) tlsConfig() *tls.Config {
+	config := r.config()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		r.reload()
+
+		return r.config(), nil
+	}
+
+	return config
+}
+
+func (r *certReloader) config() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if r.clientCAs != nil {
+		config.ClientCAs = r.clientCAs
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config
+}
+
+// reload loads the files again when one of them changed since the last
+// check, at most once per interval. A file that fails to load, as while it
+// is being replaced, leaves the previous one in use until the next check.
+func (r *certReloader) reload() {
+	r.mu.Lock()
+	due := time.Since(r.checkedAt) >= r.interval
+	r.mu.Unlock()
+
+	if due {
+		_ = r.load()
+	}
+}
+
+// load reads the files unless none of them changed since they were read.
+func (r *certReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkedAt = time.Now()
+
+	var modTimes [3]time.Time
+
+	for i, file := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if file == "" {
+			continue
+		}
+
+		fi, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+
+		modTimes[i] = fi.ModTime()
+	}
+
+	if r.cert != nil && modTimes == r.modTimes {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls.LoadX509KeyPair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("tls: client CA: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tls: client CA %s: %w", r.clientCAFile, ErrNoCACertificates)
+		}
+	}
+
+	r.cert, r.clientCAs, r.modTimes = &cert, clientCAs, modTimes
+
+	return nil
+}
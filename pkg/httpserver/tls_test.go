@@ -0,0 +1,305 @@
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues the certificates of a test.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pool: pool,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// keyPair is a certificate and its key, in PEM.
+type keyPair struct {
+	cert, key []byte
+}
+
+// issue returns a certificate for localhost.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) keyPair {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return keyPair{
+		cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// clientCert returns a client certificate issued by ca.
+func (ca *testCA) clientCert(t *testing.T, name string) tls.Certificate {
+	t.Helper()
+
+	pair := ca.issue(t, name, x509.ExtKeyUsageClientAuth)
+
+	cert, err := tls.X509KeyPair(pair.cert, pair.key)
+	require.NoError(t, err)
+
+	return cert
+}
+
+// certFiles are the PEM files of a server certificate.
+type certFiles struct {
+	cert, key string
+	modTime   time.Time
+}
+
+func newCertFiles(t *testing.T) *certFiles {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return &certFiles{
+		cert:    filepath.Join(dir, "cert.pem"),
+		key:     filepath.Join(dir, "key.pem"),
+		modTime: time.Now(),
+	}
+}
+
+// write replaces the files. Their modification time moves forward on every
+// write, so that the change is seen however fast the files are rewritten.
+func (f *certFiles) write(t *testing.T, pair keyPair) {
+	t.Helper()
+
+	f.modTime = f.modTime.Add(time.Second)
+
+	for file, data := range map[string][]byte{f.cert: pair.cert, f.key: pair.key} {
+		require.NoError(t, os.WriteFile(file, data, 0o600))
+		require.NoError(t, os.Chtimes(file, f.modTime, f.modTime))
+	}
+}
+
+// startTLS serves with the TLS config of the server options, checking the
+// files on every handshake.
+func startTLS(t *testing.T, opts ...Option) *httptest.Server {
+	t.Helper()
+
+	s := New(http.NotFoundHandler(), append(opts, CertReloadInterval(0))...)
+	require.NoError(t, s.certs.load())
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts.TLS = s.certs.tlsConfig()
+	// Rejected handshakes are expected.
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+// get makes a request on a new connection and returns the name of the
+// server certificate.
+func get(url string, roots *x509.CertPool, clientCerts ...tls.Certificate) (string, error) {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:      roots,
+				Certificates: clientCerts,
+				MinVersion:   tls.VersionTLS12,
+			},
+			DisableKeepAlives: true,
+		},
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	return resp.TLS.PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestTLS_ServesRotatedCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	files := newCertFiles(t)
+	files.write(t, ca.issue(t, "first", x509.ExtKeyUsageServerAuth))
+
+	ts := startTLS(t, TLS(files.cert, files.key))
+
+	name, err := get(ts.URL, ca.pool)
+	require.NoError(t, err)
+	require.Equal(t, "first", name)
+
+	files.write(t, ca.issue(t, "second", x509.ExtKeyUsageServerAuth))
+
+	name, err = get(ts.URL, ca.pool)
+	require.NoError(t, err)
+	require.Equal(t, "second", name)
+}
+
+func TestTLS_BrokenFileKeepsPreviousCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	files := newCertFiles(t)
+	files.write(t, ca.issue(t, "first", x509.ExtKeyUsageServerAuth))
+
+	ts := startTLS(t, TLS(files.cert, files.key))
+
+	tests := []struct {
+		name  string
+		files keyPair
+	}{
+		{name: "garbage", files: keyPair{cert: []byte("not a certificate"), key: []byte("not a key")}},
+		{name: "empty"},
+		{name: "half written", files: keyPair{cert: []byte("-----BEGIN CERTIFICATE-----\nMIIB")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files.write(t, tc.files)
+
+			name, err := get(ts.URL, ca.pool)
+			require.NoError(t, err)
+			require.Equal(t, "first", name)
+		})
+	}
+
+	// The next valid files are picked up again.
+	files.write(t, ca.issue(t, "second", x509.ExtKeyUsageServerAuth))
+
+	name, err := get(ts.URL, ca.pool)
+	require.NoError(t, err)
+	require.Equal(t, "second", name)
+}
+
+func TestTLS_ClientCA(t *testing.T) {
+	ca := newTestCA(t)
+	files := newCertFiles(t)
+	files.write(t, ca.issue(t, "server", x509.ExtKeyUsageServerAuth))
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+	ts := startTLS(t, TLS(files.cert, files.key), ClientCA(caFile))
+
+	tests := []struct {
+		name        string
+		clientCerts []tls.Certificate
+		wantErr     bool
+	}{
+		{name: "no certificate", wantErr: true},
+		{name: "certificate of another CA", clientCerts: []tls.Certificate{newTestCA(t).clientCert(t, "stranger")}, wantErr: true},
+		{name: "certificate of the CA", clientCerts: []tls.Certificate{ca.clientCert(t, "client")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := get(ts.URL, ca.pool, tc.clientCerts...)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTLS_LoadFails(t *testing.T) {
+	ca := newTestCA(t)
+	files := newCertFiles(t)
+	files.write(t, ca.issue(t, "server", x509.ExtKeyUsageServerAuth))
+
+	noCA := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(noCA, []byte("no certificates here"), 0o600))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "missing certificate", opts: []Option{TLS(filepath.Join(t.TempDir(), "missing.pem"), files.key)}},
+		{name: "client CA without certificates", opts: []Option{TLS(files.cert, files.key), ClientCA(noCA)}, wantErr: ErrNoCACertificates},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(http.NotFoundHandler(), tc.opts...)
+
+			err := s.certs.load()
+			require.Error(t, err)
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package lifecycle starts the components of a service in dependency order
+// and stops them in reverse order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const _defaultTimeout = 10 * time.Second
+
+// ErrStopTimeout is returned for a component that did not stop in time.
+var ErrStopTimeout = errors.New("timed out stopping")
+
+// Hook starts and stops a component.
+type Hook struct {
+	Name string
+	// OnStart starts the component; nil when constructing it did.
+	OnStart func(ctx context.Context) error
+	// OnStop stops the component and should give up once ctx is done.
+	OnStop func(ctx context.Context) error
+	// Timeout bounds OnStop; zero means the default of the manager.
+	Timeout time.Duration
+}
+
+// Manager runs hooks in the order they were appended, which must be the
+// order the components depend on each other: a component is started after
+// and stopped before everything appended earlier.
+type Manager struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+// New -.
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		timeout: _defaultTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Append adds a component depending on the ones appended before.
+func (m *Manager) Append(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, h)
+}
+
+// Start runs the OnStart hooks in order. When one fails, the components
+// started before it are stopped and its error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for m.started < len(m.hooks) {
+		h := m.hooks[m.started]
+
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				return errors.Join(fmt.Errorf("lifecycle - Start - %s: %w", h.Name, err), m.stop())
+			}
+		}
+
+		m.started++
+	}
+
+	return nil
+}
+
+// Stop runs the OnStop hooks of the started components in reverse order,
+// each bounded by its timeout. A component that does not stop in time is
+// left behind and the next one is stopped. The errors are joined.
+func (m *Manager) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stop()
+}
+
+func (m *Manager) stop() error {
+	var errs []error
+
+	for ; m.started > 0; m.started-- {
+		h := m.hooks[m.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+
+		if err := m.stopHook(h); err != nil {
+			errs = append(errs, fmt.Errorf("lifecycle - Stop - %s: %w", h.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stopHook(h Hook) error {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = m.timeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- h.OnStop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w after %s", ErrStopTimeout, timeout)
+	}
+}
+
+// Background returns a hook that runs run in a goroutine from start until
+// stop, which cancels the context of run and waits for it to return.
+func Background(name string, timeout time.Duration, run func(ctx context.Context)) Hook {
+	var (
+		cancel context.CancelFunc
+		done   = make(chan struct{})
+	)
+
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+
+			go func() {
+				defer close(done)
+
+				run(ctx)
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		Timeout: timeout,
+	}
+}
@@ -0,0 +1,205 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/pkg/lifecycle"
+)
+
+var errFailed = errors.New("failed")
+
+// recorder records the hook calls in the order they are made.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.calls...)
+}
+
+// hook returns a hook recording "start name" and "stop name", failing
+// with startErr or stopErr.
+func (r *recorder) hook(name string, startErr, stopErr error) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			r.record("start " + name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			r.record("stop " + name)
+			return stopErr
+		},
+	}
+}
+
+func TestManager_StartsInOrderAndStopsInReverse(t *testing.T) {
+	var r recorder
+
+	m := lifecycle.New()
+	m.Append(r.hook("db", nil, nil))
+	m.Append(lifecycle.Hook{Name: "repo"})
+	m.Append(r.hook("http", nil, nil))
+
+	require.NoError(t, m.Start(context.Background()))
+	require.Equal(t, []string{"start db", "start http"}, r.recorded())
+
+	require.NoError(t, m.Stop())
+	require.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, r.recorded())
+
+	// The components are stopped once.
+	require.NoError(t, m.Stop())
+	require.Len(t, r.recorded(), 4)
+}
+
+func TestManager_StartsComponentsAppendedLater(t *testing.T) {
+	var r recorder
+
+	m := lifecycle.New()
+	m.Append(r.hook("db", nil, nil))
+	require.NoError(t, m.Start(context.Background()))
+
+	m.Append(r.hook("http", nil, nil))
+	require.NoError(t, m.Start(context.Background()))
+	require.NoError(t, m.Stop())
+
+	require.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, r.recorded())
+}
+
+func TestManager_StartFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		hooks     func(r *recorder) []lifecycle.Hook
+		wantCalls []string
+		wantErrs  []error
+	}{
+		{
+			name: "first hook fails",
+			hooks: func(r *recorder) []lifecycle.Hook {
+				return []lifecycle.Hook{r.hook("db", errFailed, nil), r.hook("http", nil, nil)}
+			},
+			wantCalls: []string{"start db"},
+			wantErrs:  []error{errFailed},
+		},
+		{
+			name: "started hooks are rolled back in reverse",
+			hooks: func(r *recorder) []lifecycle.Hook {
+				return []lifecycle.Hook{r.hook("db", nil, nil), r.hook("cache", nil, nil), r.hook("http", errFailed, nil), r.hook("grpc", nil, nil)}
+			},
+			wantCalls: []string{"start db", "start cache", "start http", "stop cache", "stop db"},
+			wantErrs:  []error{errFailed},
+		},
+		{
+			name: "rollback errors are joined",
+			hooks: func(r *recorder) []lifecycle.Hook {
+				return []lifecycle.Hook{r.hook("db", nil, context.Canceled), r.hook("http", errFailed, nil)}
+			},
+			wantCalls: []string{"start db", "start http", "stop db"},
+			wantErrs:  []error{errFailed, context.Canceled},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var r recorder
+
+			m := lifecycle.New()
+			for _, h := range tc.hooks(&r) {
+				m.Append(h)
+			}
+
+			err := m.Start(context.Background())
+			for _, want := range tc.wantErrs {
+				require.ErrorIs(t, err, want)
+			}
+
+			require.Equal(t, tc.wantCalls, r.recorded())
+
+			// Nothing is left to stop after the rollback.
+			require.NoError(t, m.Stop())
+			require.Equal(t, tc.wantCalls, r.recorded())
+		})
+	}
+}
+
+func TestManager_StopTimeout(t *testing.T) {
+	var r recorder
+
+	// hang never stops, whatever its context.
+	hang := func(name string, timeout time.Duration) lifecycle.Hook {
+		return lifecycle.Hook{
+			Name: name,
+			OnStop: func(context.Context) error {
+				r.record("stop " + name)
+				select {}
+			},
+			Timeout: timeout,
+		}
+	}
+
+	m := lifecycle.New(lifecycle.Timeout(20 * time.Millisecond))
+	m.Append(r.hook("db", nil, nil))
+	m.Append(hang("stuck", 0))
+	m.Append(hang("slow", 30*time.Millisecond))
+
+	require.NoError(t, m.Start(context.Background()))
+
+	start := time.Now()
+	err := m.Stop()
+	elapsed := time.Since(start)
+
+	require.ErrorIs(t, err, lifecycle.ErrStopTimeout)
+	require.ErrorContains(t, err, "slow: timed out stopping after 30ms")
+	require.ErrorContains(t, err, "stuck: timed out stopping after 20ms")
+
+	// A stuck component does not keep the ones before it from stopping.
+	require.Equal(t, []string{"start db", "stop slow", "stop stuck", "stop db"}, r.recorded())
+	require.Less(t, elapsed, time.Second)
+}
+
+func TestBackground(t *testing.T) {
+	var r recorder
+
+	m := lifecycle.New()
+	m.Append(lifecycle.Background("worker", time.Second, func(ctx context.Context) {
+		r.record("run")
+		<-ctx.Done()
+		r.record("done")
+	}))
+
+	require.NoError(t, m.Start(context.Background()))
+	require.Eventually(t, func() bool { return len(r.recorded()) == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, m.Stop())
+	require.Equal(t, []string{"run", "done"}, r.recorded())
+}
+
+func TestBackground_StopTimeout(t *testing.T) {
+	m := lifecycle.New()
+	m.Append(lifecycle.Background("worker", 20*time.Millisecond, func(ctx context.Context) {
+		select {}
+	}))
+
+	require.NoError(t, m.Start(context.Background()))
+
+	// Either the hook or the manager gives up first.
+	err := m.Stop()
+	require.True(t, errors.Is(err, lifecycle.ErrStopTimeout) || errors.Is(err, context.DeadlineExceeded), err)
+}
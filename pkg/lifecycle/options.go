@@ -0,0 +1,13 @@
+package lifecycle
+
+import "time"
+
+// Option -.
+type Option func(*Manager)
+
+// Timeout bounds stopping the components without a timeout of their own.
+func Timeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		m.timeout = timeout
+	}
+}
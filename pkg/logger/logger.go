@@ -1,102 +1,264 @@
 package logger
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/appxpy/hive-test/pkg/reqctx"
 )
 
 // Interface -.
+//
+// Args are alternating keys and values logged as fields. Debug, Error and
+// Fatal also take an error as the message; a string before the fields is
+// then the message, like l.Error(err, "http - v1 - login", "asset_id", id).
 type Interface interface {
 	Debug(message interface{}, args ...interface{})
 	Info(message string, args ...interface{})
 	Warn(message string, args ...interface{})
 	Error(message interface{}, args ...interface{})
 	Fatal(message interface{}, args ...interface{})
+
+	// With returns a logger adding the fields of args to every entry.
+	With(args ...interface{}) Interface
+	// WithContext returns a logger adding the request ID, user ID and
+	// trace ID of ctx to every entry.
+	WithContext(ctx context.Context) Interface
 }
 
 // Logger -.
 type Logger struct {
-	logger *zerolog.Logger
+	logger zerolog.Logger
+	// level is shared with the derived loggers, so SetLevel changes them all.
+	level *atomic.Int32
+
+	output       io.Writer
+	format       string
+	sampleBurst  uint32
+	samplePeriod time.Duration
+	redactKeys   []string
 }
 
 var _ Interface = (*Logger)(nil)
 
 // New -.
-func New(level string) *Logger {
-	var l zerolog.Level
+func New(level string, opts ...Option) *Logger {
+	l := &Logger{
+		level:        &atomic.Int32{},
+		output:       os.Stdout,
+		format:       FormatJSON,
+		samplePeriod: time.Second,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	w := l.output
+	if l.format == FormatConsole {
+		w = zerolog.ConsoleWriter{Out: l.output, TimeFormat: time.RFC3339}
+	}
 
+	l.SetLevel(level)
+
+	skipFrameCount := 2
+	l.logger = zerolog.New(w).
+		With().Timestamp().CallerWithSkipFrameCount(zerolog.CallerSkipFrameCount + skipFrameCount).
+		Logger()
+
+	if l.sampleBurst > 0 {
+		l.logger = l.logger.Sample(zerolog.LevelSampler{
+			DebugSampler: &zerolog.BurstSampler{Burst: l.sampleBurst, Period: l.samplePeriod},
+			InfoSampler:  &zerolog.BurstSampler{Burst: l.sampleBurst, Period: l.samplePeriod},
+		})
+	}
+
+	return l
+}
+
+func parseLevel(level string) zerolog.Level {
 	switch strings.ToLower(level) {
 	case "error":
-		l = zerolog.ErrorLevel
+		return zerolog.ErrorLevel
 	case "warn":
-		l = zerolog.WarnLevel
+		return zerolog.WarnLevel
 	case "info":
-		l = zerolog.InfoLevel
+		return zerolog.InfoLevel
 	case "debug":
-		l = zerolog.DebugLevel
+		return zerolog.DebugLevel
 	default:
-		l = zerolog.InfoLevel
+		return zerolog.InfoLevel
 	}
+}
 
-	zerolog.SetGlobalLevel(l)
-
-	skipFrameCount := 3
-	logger := zerolog.New(os.Stdout).With().Timestamp().CallerWithSkipFrameCount(zerolog.CallerSkipFrameCount + skipFrameCount).Logger()
+// SetLevel changes the level of l and of the loggers derived from it, as
+// when the config is reloaded.
+func (l *Logger) SetLevel(level string) {
+	l.level.Store(int32(parseLevel(level)))
+}
 
-	return &Logger{
-		logger: &logger,
-	}
+func (l *Logger) enabled(level zerolog.Level) bool {
+	return level >= zerolog.Level(l.level.Load())
 }
 
 // Debug -.
 func (l *Logger) Debug(message interface{}, args ...interface{}) {
-	l.msg("debug", message, args...)
+	if l.enabled(zerolog.DebugLevel) {
+		l.log(l.logger.Debug(), message, args)
+	}
 }
 
 // Info -.
 func (l *Logger) Info(message string, args ...interface{}) {
-	l.log(message, args...)
+	if l.enabled(zerolog.InfoLevel) {
+		l.log(l.logger.Info(), message, args)
+	}
 }
 
 // Warn -.
 func (l *Logger) Warn(message string, args ...interface{}) {
-	l.log(message, args...)
+	if l.enabled(zerolog.WarnLevel) {
+		l.log(l.logger.Warn(), message, args)
+	}
 }
 
 // Error -.
 func (l *Logger) Error(message interface{}, args ...interface{}) {
-	if l.logger.GetLevel() == zerolog.DebugLevel {
-		l.Debug(message, args...)
+	if l.enabled(zerolog.ErrorLevel) {
+		l.log(l.logger.Error(), message, args)
 	}
-
-	l.msg("error", message, args...)
 }
 
 // Fatal -.
 func (l *Logger) Fatal(message interface{}, args ...interface{}) {
-	l.msg("fatal", message, args...)
+	l.log(l.logger.WithLevel(zerolog.FatalLevel), message, args)
 
 	os.Exit(1)
 }
 
-func (l *Logger) log(message string, args ...interface{}) {
-	if len(args) == 0 {
-		l.logger.Info().Msg(message)
-	} else {
-		l.logger.Info().Msgf(message, args...)
+// With -.
+func (l *Logger) With(args ...interface{}) Interface {
+	return l.derive(l.logger.With().Fields(l.fields(args)).Logger())
+}
+
+// WithContext -.
+func (l *Logger) WithContext(ctx context.Context) Interface {
+	c := l.logger.With()
+
+	meta := reqctx.FromContext(ctx)
+	if meta.RequestID != "" {
+		c = c.Str("request_id", meta.RequestID)
+	}
+
+	if meta.UserID != 0 {
+		c = c.Int64("user_id", meta.UserID)
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		c = c.Str("trace_id", sc.TraceID().String())
 	}
+
+	return l.derive(c.Logger())
+}
+
+// derive returns a copy of l writing with zl.
+func (l *Logger) derive(zl zerolog.Logger) *Logger {
+	d := *l
+	d.logger = zl
+
+	return &d
 }
 
-func (l *Logger) msg(level string, message interface{}, args ...interface{}) {
-	switch msg := message.(type) {
+// log writes e, or nothing when its level is disabled or sampled out.
+func (l *Logger) log(e *zerolog.Event, message interface{}, args []interface{}) {
+	if e == nil {
+		return
+	}
+
+	var msg string
+
+	switch m := message.(type) {
 	case error:
-		l.log(msg.Error(), args...)
+		e = e.Str(zerolog.ErrorFieldName, scrub(m.Error()))
+
+		if s, ok := firstString(args); ok && len(args)%2 == 1 {
+			msg, args = s, args[1:]
+		}
 	case string:
-		l.log(msg, args...)
+		msg = m
 	default:
-		l.log(fmt.Sprintf("%s message %v has unknown type %v", level, message, msg), args...)
+		msg = fmt.Sprint(m)
+	}
+
+	e.Fields(l.fields(args)).Msg(scrub(msg))
+}
+
+func firstString(args []interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+
+	s, ok := args[0].(string)
+
+	return s, ok
+}
+
+// fields turns the key/value pairs of args into fields, masking secrets at
+// any depth of maps, slices and structs.
+// A key without a value is kept under "!BADKEY".
+func (l *Logger) fields(args []interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, (len(args)+1)/2)
+
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			fields["!BADKEY"] = l.value(args[i])
+			break
+		}
+
+		key := fmt.Sprint(args[i])
+		if l.sensitive(key) {
+			fields[key] = _redacted
+			continue
+		}
+
+		fields[key] = l.value(args[i+1])
+	}
+
+	return fields
+}
+
+func (l *Logger) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return scrub(v)
+	case error:
+		return scrub(v.Error())
+	case url.Values:
+		return l.redactValues(v)
+	case json.Number:
+		return v
+	case fmt.Stringer:
+		return scrub(v.String())
+	case map[string]interface{}:
+		return l.redactMap(v)
+	case []interface{}:
+		return l.redactSlice(v)
+	default:
+		if nested(v) {
+			return l.redactJSON(v)
+		}
+
+		return v
 	}
 }
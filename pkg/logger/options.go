@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// Output formats.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Option -.
+type Option func(*Logger)
+
+// Format -.
+func Format(format string) Option {
+	return func(l *Logger) {
+		l.format = strings.ToLower(format)
+	}
+}
+
+// Output -.
+func Output(w io.Writer) Option {
+	return func(l *Logger) {
+		l.output = w
+	}
+}
+
+// Sampling keeps the first burst debug and info entries of every period and
+// drops the rest. Warnings and errors are never dropped. Zero burst logs all.
+func Sampling(burst uint32, period time.Duration) Option {
+	return func(l *Logger) {
+		l.sampleBurst = burst
+		l.samplePeriod = period
+	}
+}
+
+// RedactKeys adds field keys whose values are never logged, besides
+// passwords, tokens, secrets and credentials.
+func RedactKeys(keys ...string) Option {
+	return func(l *Logger) {
+		for _, k := range keys {
+			l.redactKeys = append(l.redactKeys, normalizeKey(k))
+		}
+	}
+}
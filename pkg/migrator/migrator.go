@@ -0,0 +1,297 @@
+// Package migrator applies golang-migrate migrations from an fs.FS, such as
+// an embed.FS, to PostgreSQL or SQLite.
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	// postgres driver
+	_ "github.com/lib/pq"
+)
+
+const (
+	_defaultConnAttempts = 20
+	_defaultConnTimeout  = time.Second
+	_defaultLockTimeout  = 5 * time.Minute
+
+	// _lockID is the advisory lock held while migrating, so that replicas
+	// starting together run the migrations once.
+	_lockID = 7_310_522_101
+)
+
+// ErrLockTimeout is returned when another instance holds the migration lock
+// for longer than the lock timeout.
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+// ErrNoURL is returned by New for an empty database URL.
+var ErrNoURL = errors.New("database url is empty")
+
+// Migrator -.
+type Migrator struct {
+	connAttempts int
+	connTimeout  time.Duration
+	lockTimeout  time.Duration
+
+	// advisoryLock serializes migrations of concurrently starting replicas;
+	// a SQLite database belongs to a single node.
+	advisoryLock bool
+
+	db  *sql.DB
+	src source.Driver
+	m   *migrate.Migrate
+}
+
+// New connects to the database and reads the migrations from the root of
+// migrations.
+func New(url string, migrations fs.FS, opts ...Option) (*Migrator, error) {
+	if url == "" {
+		return nil, fmt.Errorf("migrator - New: %w", ErrNoURL)
+	}
+
+	mg := newMigrator(opts)
+	mg.advisoryLock = true
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("migrator - New - sql.Open: %w", err)
+	}
+
+	for mg.connAttempts > 0 {
+		err = db.Ping()
+		if err == nil {
+			break
+		}
+
+		log.Printf("Migrate: postgres is trying to connect, attempts left: %d", mg.connAttempts)
+
+		time.Sleep(mg.connTimeout)
+
+		mg.connAttempts--
+	}
+
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrator - New - connAttempts == 0: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrator - New - postgres.WithInstance: %w", err)
+	}
+
+	if err = mg.open(db, "postgres", driver, migrations); err != nil {
+		return nil, fmt.Errorf("migrator - New - %w", err)
+	}
+
+	return mg, nil
+}
+
+// NewSQLite opens the database file at path, a modernc.org/sqlite DSN, and reads
+// the migrations from the root of migrations.
+func NewSQLite(path string, migrations fs.FS, opts ...Option) (*Migrator, error) {
+	if path == "" {
+		return nil, fmt.Errorf("migrator - NewSQLite: %w", ErrNoURL)
+	}
+
+	mg := newMigrator(opts)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("migrator - NewSQLite - sql.Open: %w", err)
+	}
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrator - NewSQLite - sqlite.WithInstance: %w", err)
+	}
+
+	if err = mg.open(db, "sqlite", driver, migrations); err != nil {
+		return nil, fmt.Errorf("migrator - NewSQLite - %w", err)
+	}
+
+	return mg, nil
+}
+
+func newMigrator(opts []Option) *Migrator {
+	mg := &Migrator{
+		connAttempts: _defaultConnAttempts,
+		connTimeout:  _defaultConnTimeout,
+		lockTimeout:  _defaultLockTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(mg)
+	}
+
+	return mg
+}
+
+// open reads the migrations and binds them to the database driver, which
+// it closes on failure.
+func (mg *Migrator) open(db *sql.DB, name string, driver database.Driver, migrations fs.FS) error {
+	src, err := iofs.New(migrations, ".")
+	if err != nil {
+		_ = driver.Close()
+		return fmt.Errorf("iofs.New: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, name, driver)
+	if err != nil {
+		_ = driver.Close()
+		return fmt.Errorf("migrate.NewWithInstance: %w", err)
+	}
+
+	// The driver also locks around every command; allow it as long as our lock.
+	m.LockTimeout = mg.lockTimeout
+
+	mg.db, mg.src, mg.m = db, src, m
+
+	return nil
+}
+
+// Up applies all pending migrations.
+func (mg *Migrator) Up(ctx context.Context) error {
+	return mg.locked(ctx, mg.m.Up)
+}
+
+// Down reverts the last steps migrations.
+func (mg *Migrator) Down(ctx context.Context, steps int) error {
+	return mg.locked(ctx, func() error { return mg.m.Steps(-steps) })
+}
+
+// Goto migrates up or down to the version.
+func (mg *Migrator) Goto(ctx context.Context, version uint) error {
+	return mg.locked(ctx, func() error { return mg.m.Migrate(version) })
+}
+
+// Force sets the version without running migrations and clears the dirty
+// flag, after a failed migration was fixed by hand. -1 means no version.
+func (mg *Migrator) Force(ctx context.Context, version int) error {
+	return mg.locked(ctx, func() error { return mg.m.Force(version) })
+}
+
+// Version returns the current version, 0 when no migration was applied.
+func (mg *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// Versions returns the versions of the available migrations in ascending order.
+func (mg *Migrator) Versions() ([]uint, error) {
+	return versions(mg.src)
+}
+
+// Latest returns the version of the last migration in the root of
+// migrations, 0 when there is none, without connecting to a database.
+func Latest(migrations fs.FS) (uint, error) {
+	src, err := iofs.New(migrations, ".")
+	if err != nil {
+		return 0, fmt.Errorf("migrator - Latest - iofs.New: %w", err)
+	}
+	defer src.Close()
+
+	v, err := versions(src)
+	if err != nil {
+		return 0, fmt.Errorf("migrator - Latest - versions: %w", err)
+	}
+
+	if len(v) == 0 {
+		return 0, nil
+	}
+
+	return v[len(v)-1], nil
+}
+
+func versions(src source.Driver) ([]uint, error) {
+	v, err := src.First()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	all := []uint{v}
+
+	for {
+		v, err = src.Next(v)
+		if errors.Is(err, fs.ErrNotExist) {
+			return all, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, v)
+	}
+}
+
+// Close -.
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+
+	return errors.Join(srcErr, dbErr)
+}
+
+// locked runs fn while holding the migration lock. Running no migration is
+// not an error.
+func (mg *Migrator) locked(ctx context.Context, fn func() error) error {
+	if !mg.advisoryLock {
+		return ignoreNoChange(fn())
+	}
+
+	// Session-level advisory locks belong to a connection, not to the pool.
+	conn, err := mg.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	lockCtx, cancel := context.WithTimeout(ctx, mg.lockTimeout)
+	defer cancel()
+
+	_, err = conn.ExecContext(lockCtx, `SELECT pg_advisory_lock($1)`, _lockID)
+	if err != nil {
+		if lockCtx.Err() != nil && ctx.Err() == nil {
+			return ErrLockTimeout
+		}
+
+		return fmt.Errorf("migrator - pg_advisory_lock: %w", err)
+	}
+
+	defer func() {
+		// A failed unlock is released when the connection closes.
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, _lockID)
+	}()
+
+	return ignoreNoChange(fn())
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+package migrator
+
+import "time"
+
+// Option -.
+type Option func(*Migrator)
+
+// ConnAttempts -.
+func ConnAttempts(attempts int) Option {
+	return func(m *Migrator) {
+		m.connAttempts = attempts
+	}
+}
+
+// ConnTimeout -.
+func ConnTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.connTimeout = timeout
+	}
+}
+
+// LockTimeout is how long to wait for another instance to finish migrating.
+func LockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const _defaultReconnectTimeout = time.Second
+
+// Listener receives the notifications of a channel on a connection of its
+// own, outside the pool, since LISTEN holds the connection for good.
+type Listener struct {
+	url              string
+	channel          string
+	reconnectTimeout time.Duration
+	onConnect        func()
+	onError          func(error)
+}
+
+// NewListener -.
+func NewListener(url, channel string, opts ...ListenerOption) *Listener {
+	l := &Listener{
+		url:              url,
+		channel:          channel,
+		reconnectTimeout: _defaultReconnectTimeout,
+		onConnect:        func() {},
+		onError:          func(error) {},
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Run calls handle with the payload of every notification until ctx is done.
+// After an error it reconnects once the reconnect timeout has passed;
+// notifications sent while disconnected are lost.
+func (l *Listener) Run(ctx context.Context, handle func(payload string)) {
+	for ctx.Err() == nil {
+		err := l.listen(ctx, handle)
+		if err != nil && ctx.Err() == nil {
+			l.onError(err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(l.reconnectTimeout):
+			}
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context, handle func(payload string)) error {
+	conn, err := pgx.Connect(ctx, l.url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize())
+	if err != nil {
+		return err
+	}
+
+	l.onConnect()
+
+	for {
+		msg, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		handle(msg.Payload)
+	}
+}
@@ -0,0 +1,60 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/appxpy/hive-test/pkg/postgres"
+)
+
+// TestListener needs the database of PG_TEST_URL.
+func TestListener(t *testing.T) {
+	url := os.Getenv("PG_TEST_URL")
+	if url == "" {
+		t.Skip("PG_TEST_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pg, err := postgres.New(url, postgres.MaxPoolSize(1), postgres.ConnAttempts(1))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+
+	connected := make(chan struct{}, 1)
+	payloads := make(chan string, 1)
+
+	listener := postgres.NewListener(url, "hive_listener_test",
+		postgres.OnConnect(func() { connected <- struct{}{} }),
+		postgres.OnError(func(err error) { t.Log(err) }),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		listener.Run(ctx, func(payload string) { payloads <- payload })
+	}()
+
+	select {
+	case <-connected:
+	case <-ctx.Done():
+		t.Fatal("listener did not connect")
+	}
+
+	_, err = pg.Pool.Exec(ctx, `SELECT pg_notify('hive_listener_test', 'hello')`)
+	require.NoError(t, err)
+
+	select {
+	case payload := <-payloads:
+		require.Equal(t, "hello", payload)
+	case <-ctx.Done():
+		t.Fatal("notification was not received")
+	}
+
+	cancel()
+	<-done
+}
@@ -25,3 +25,74 @@ func ConnTimeout(timeout time.Duration) Option {
 		c.connTimeout = timeout
 	}
 }
+
+// DialTimeout -.
+func DialTimeout(timeout time.Duration) Option {
+	return func(c *Postgres) {
+		c.dialTimeout = timeout
+	}
+}
+
+// StatementTimeout aborts statements running longer than timeout; zero keeps the server default.
+func StatementTimeout(timeout time.Duration) Option {
+	return func(c *Postgres) {
+		c.statementTimeout = timeout
+	}
+}
+
+// LazyConnect returns the pool without dialing; connections are established on first use.
+func LazyConnect() Option {
+	return func(c *Postgres) {
+		c.lazyConnect = true
+	}
+}
+
+// Tracing records a span with the SQL and row count of every statement, as
+// a child of the span in the statement's context.
+func Tracing() Option {
+	return func(c *Postgres) {
+		c.tracing = true
+	}
+}
+
+// ReplicasOption -.
+type ReplicasOption func(*Replicas)
+
+// MaxReplicaLag is the replay lag above which a replica receives no reads.
+func MaxReplicaLag(lag time.Duration) ReplicasOption {
+	return func(r *Replicas) {
+		r.maxLag = lag
+	}
+}
+
+// ReplicaCheckInterval -.
+func ReplicaCheckInterval(interval time.Duration) ReplicasOption {
+	return func(r *Replicas) {
+		r.checkInterval = interval
+	}
+}
+
+// ListenerOption -.
+type ListenerOption func(*Listener)
+
+// ReconnectTimeout is how long the listener waits to reconnect after an error.
+func ReconnectTimeout(timeout time.Duration) ListenerOption {
+	return func(l *Listener) {
+		l.reconnectTimeout = timeout
+	}
+}
+
+// OnConnect is called every time the listener starts listening, such as to
+// catch up on the changes made while it was disconnected.
+func OnConnect(fn func()) ListenerOption {
+	return func(l *Listener) {
+		l.onConnect = fn
+	}
+}
+
+// OnError is called with every error the listener reconnects after.
+func OnError(fn func(error)) ListenerOption {
+	return func(l *Listener) {
+		l.onError = fn
+	}
+}
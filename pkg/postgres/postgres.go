@@ -3,8 +3,10 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -15,13 +17,22 @@ const (
 	_defaultMaxPoolSize  = 1
 	_defaultConnAttempts = 10
 	_defaultConnTimeout  = time.Second
+	_defaultDialTimeout  = 5 * time.Second
 )
 
+// ErrNoURL is returned by New for an empty connection URL, which pgx would
+// otherwise complete from the PG* environment variables.
+var ErrNoURL = errors.New("connection url is empty")
+
 // Postgres -.
 type Postgres struct {
-	maxPoolSize  int
-	connAttempts int
-	connTimeout  time.Duration
+	maxPoolSize      int
+	connAttempts     int
+	connTimeout      time.Duration
+	dialTimeout      time.Duration
+	statementTimeout time.Duration
+	lazyConnect      bool
+	tracing          bool
 
 	Builder squirrel.StatementBuilderType
 	Pool    *pgxpool.Pool
@@ -29,10 +40,15 @@ type Postgres struct {
 
 // New -.
 func New(url string, opts ...Option) (*Postgres, error) {
+	if url == "" {
+		return nil, fmt.Errorf("postgres - NewPostgres: %w", ErrNoURL)
+	}
+
 	pg := &Postgres{
 		maxPoolSize:  _defaultMaxPoolSize,
 		connAttempts: _defaultConnAttempts,
 		connTimeout:  _defaultConnTimeout,
+		dialTimeout:  _defaultDialTimeout,
 	}
 
 	// Custom options
@@ -48,6 +64,16 @@ func New(url string, opts ...Option) (*Postgres, error) {
 	}
 
 	poolConfig.MaxConns = int32(pg.maxPoolSize)
+	poolConfig.ConnConfig.ConnectTimeout = pg.dialTimeout
+	poolConfig.LazyConnect = pg.lazyConnect
+
+	if pg.tracing {
+		poolConfig.ConnConfig.Logger = queryTracer{}
+	}
+
+	if pg.statementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(pg.statementTimeout.Milliseconds(), 10)
+	}
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.ConnectConfig(context.Background(), poolConfig)
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_defaultMaxReplicaLag        = 10 * time.Second
+	_defaultReplicaCheckInterval = 5 * time.Second
+)
+
+// _replicaLagQuery returns the replay lag in seconds. A replica that is
+// streaming from the primary and has replayed all WAL it received is not
+// lagging, however old its last transaction. Otherwise, including while its
+// WAL receiver is disconnected, the lag is the age of the last replayed
+// transaction, or of the server itself if it has replayed none. A server
+// that is not in recovery has no lag.
+const _replicaLagQuery = `
+    SELECT CASE
+        WHEN NOT pg_is_in_recovery() THEN 0
+        WHEN EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming')
+            AND pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+        ELSE EXTRACT(EPOCH FROM now() - COALESCE(pg_last_xact_replay_timestamp(), pg_postmaster_start_time()))
+    END::float8`
+
+// Replicas is a set of read replicas. Run checks them periodically; a
+// replica is usable while it answers and lags less than the maximum.
+type Replicas struct {
+	maxLag        time.Duration
+	checkInterval time.Duration
+
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	pg     *Postgres
+	usable atomic.Bool
+	lag    atomic.Int64
+}
+
+// NewReplicas -.
+func NewReplicas(pools []*Postgres, opts ...ReplicasOption) *Replicas {
+	r := &Replicas{
+		maxLag:        _defaultMaxReplicaLag,
+		checkInterval: _defaultReplicaCheckInterval,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	for _, pg := range pools {
+		r.replicas = append(r.replicas, &replica{pg: pg})
+	}
+
+	return r
+}
+
+// Pick returns a usable replica in round-robin order, or nil when none is.
+// Replicas are unusable until Run checked them.
+func (r *Replicas) Pick() *Postgres {
+	n := uint64(len(r.replicas))
+
+	for i := uint64(0); i < n; i++ {
+		rep := r.replicas[(r.next.Add(1)-1)%n]
+		if rep.usable.Load() {
+			return rep.pg
+		}
+	}
+
+	return nil
+}
+
+// Run checks the replicas every check interval until ctx is done.
+func (r *Replicas) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		r.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Replicas) check(ctx context.Context) {
+	for _, rep := range r.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, r.checkInterval)
+
+		var lag float64
+		err := rep.pg.Pool.QueryRow(checkCtx, _replicaLagQuery).Scan(&lag)
+
+		cancel()
+
+		lagDuration := time.Duration(lag * float64(time.Second))
+		rep.lag.Store(int64(lagDuration))
+		rep.usable.Store(err == nil && lagDuration <= r.maxLag)
+	}
+}
+
+// Close closes the replica pools.
+func (r *Replicas) Close() {
+	for _, rep := range r.replicas {
+		rep.pg.Close()
+	}
+}
+
+// replicasCollector exports the state of every replica as of its last check.
+type replicasCollector struct {
+	r *Replicas
+
+	usable *prometheus.Desc
+	lag    *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*replicasCollector)(nil)
+
+// NewReplicasCollector returns a Prometheus collector of the replica health.
+func NewReplicasCollector(r *Replicas) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("postgres_replica", "", name), help, []string{"replica"}, nil)
+	}
+
+	return &replicasCollector{
+		r:      r,
+		usable: desc("usable", "Whether the replica receives reads."),
+		lag:    desc("lag_seconds", "Replay lag of the replica."),
+	}
+}
+
+// Describe -.
+func (c *replicasCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.usable
+	ch <- c.lag
+}
+
+// Collect -.
+func (c *replicasCollector) Collect(ch chan<- prometheus.Metric) {
+	for i, rep := range c.r.replicas {
+		label := strconv.Itoa(i)
+
+		usable := 0.0
+		if rep.usable.Load() {
+			usable = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.usable, prometheus.GaugeValue, usable, label)
+		ch <- prometheus.MustNewConstMetric(c.lag, prometheus.GaugeValue,
+			time.Duration(rep.lag.Load()).Seconds(), label)
+	}
+}
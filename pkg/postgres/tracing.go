@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _tracerName = "github.com/appxpy/hive-test/pkg/postgres"
+
+// queryTracer turns the query log of pgx into spans. pgx logs every
+// statement when it completes, with its duration, so the span is started
+// in the past. Arguments are never recorded, they may hold secrets.
+type queryTracer struct{}
+
+var _ pgx.Logger = queryTracer{}
+
+// Log -.
+func (queryTracer) Log(ctx context.Context, _ pgx.LogLevel, msg string, data map[string]interface{}) {
+	// Connection events carry no duration.
+	took, ok := data["time"].(time.Duration)
+	if !ok {
+		return
+	}
+
+	sql, _ := data["sql"].(string)
+	operation := msg
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+
+	end := time.Now()
+
+	_, span := otel.Tracer(_tracerName).Start(ctx, "postgres "+operation,
+		trace.WithTimestamp(end.Add(-took)),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(operation),
+			semconv.DBStatement(sql),
+		),
+	)
+
+	if n, ok := data["rowCount"].(int); ok {
+		span.SetAttributes(attribute.Int("db.rows", n))
+	}
+	if tag, ok := data["commandTag"].(pgconn.CommandTag); ok {
+		span.SetAttributes(attribute.Int64("db.rows_affected", tag.RowsAffected()))
+	}
+
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ErrNestedTx is returned by ExecuteTx when called with a transaction.
+var ErrNestedTx = errors.New("cannot start a transaction within an existing transaction")
+
+// Querier runs statements; *pgxpool.Pool and pgx.Tx implement it, so a
+// repository works the same inside and outside of a transaction.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// beginner starts transactions; it is the part of *pgxpool.Pool ExecuteTx needs.
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// ExecuteTx runs fn in a transaction started on db, committing it when fn
+// returns nil and rolling it back otherwise.
+func ExecuteTx(ctx context.Context, db Querier, fn func(tx pgx.Tx) error) error {
+	if _, ok := db.(pgx.Tx); ok {
+		return ErrNestedTx
+	}
+
+	b, ok := db.(beginner)
+	if !ok {
+		return errors.New("ExecuteTx: querier cannot start transactions")
+	}
+
+	tx, err := b.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ExecuteTx runs fn in a transaction on the pool.
+func (p *Postgres) ExecuteTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	return ExecuteTx(ctx, p.Pool, fn)
+}
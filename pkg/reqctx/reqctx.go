@@ -0,0 +1,52 @@
+// Package reqctx carries per-request metadata through context.Context.
+package reqctx
+
+import "context"
+
+type ctxKey struct{}
+
+// Meta -.
+type Meta struct {
+	RequestID string
+	UserID    int64
+	Role      string
+	IP        string
+	UserAgent string
+}
+
+// WithMeta returns a copy of ctx carrying m.
+func WithMeta(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, ctxKey{}, m)
+}
+
+// WithUserID returns a copy of ctx with the authenticated user ID set.
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	m := FromContext(ctx)
+	m.UserID = userID
+
+	return WithMeta(ctx, m)
+}
+
+// WithUser returns a copy of ctx with the authenticated user ID and role set.
+func WithUser(ctx context.Context, userID int64, role string) context.Context {
+	m := FromContext(ctx)
+	m.UserID, m.Role = userID, role
+
+	return WithMeta(ctx, m)
+}
+
+// WithRequestID returns a copy of ctx with the request ID set, for work
+// done later on behalf of the request, such as webhook deliveries.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	m := FromContext(ctx)
+	m.RequestID = requestID
+
+	return WithMeta(ctx, m)
+}
+
+// FromContext returns the metadata stored in ctx, or an empty Meta.
+func FromContext(ctx context.Context) Meta {
+	m, _ := ctx.Value(ctxKey{}).(Meta)
+
+	return m
+}
@@ -0,0 +1,44 @@
+package reqctx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	_requestIDBytes  = 16
+	_maxRequestIDLen = 128
+)
+
+// NewRequestID returns a random request ID of 32 hex digits.
+func NewRequestID() string {
+	b := make([]byte, _requestIDBytes)
+	_, _ = rand.Read(b) // never fails on supported platforms
+
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID reports whether a request ID sent by a client can be kept:
+// at most 128 letters, digits and "-_.:/+=" characters. Anything else could
+// forge log lines or headers and is replaced.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > _maxRequestIDLen {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/', c == '+', c == '=':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
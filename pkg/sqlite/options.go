@@ -0,0 +1,20 @@
+package sqlite
+
+import "time"
+
+// Option -.
+type Option func(*SQLite)
+
+// MaxOpenConns -.
+func MaxOpenConns(n int) Option {
+	return func(s *SQLite) {
+		s.maxOpenConns = n
+	}
+}
+
+// BusyTimeout is how long a statement waits for the lock held by another writer.
+func BusyTimeout(timeout time.Duration) Option {
+	return func(s *SQLite) {
+		s.busyTimeout = timeout
+	}
+}
@@ -0,0 +1,124 @@
+// Package sqlite implements SQLite connection.
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	// sqlite driver, pure Go, so it works in CGO_ENABLED=0 builds
+	_ "modernc.org/sqlite"
+)
+
+const (
+	_defaultMaxOpenConns = 4
+	_defaultBusyTimeout  = 5 * time.Second
+)
+
+// ErrNoPath is returned by New for an empty database path.
+var ErrNoPath = errors.New("database path is empty")
+
+// SQLite -.
+type SQLite struct {
+	maxOpenConns int
+	busyTimeout  time.Duration
+
+	Builder squirrel.StatementBuilderType
+	DB      *sql.DB
+}
+
+// New opens the database file at path, which may carry modernc.org/sqlite
+// DSN parameters. Write-ahead logging lets readers run next to the single
+// writer; every transaction begins IMMEDIATE, so it holds the write lock
+// from its first statement and waits up to the busy timeout for it.
+func New(path string, opts ...Option) (*SQLite, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite - New: %w", ErrNoPath)
+	}
+
+	s := &SQLite{
+		maxOpenConns: _defaultMaxOpenConns,
+		busyTimeout:  _defaultBusyTimeout,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Builder = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question)
+
+	db, err := sql.Open("sqlite", s.dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite - New - sql.Open: %w", err)
+	}
+
+	db.SetMaxOpenConns(s.maxOpenConns)
+
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("sqlite - New - db.Ping: %w", err)
+	}
+
+	s.DB = db
+
+	return s, nil
+}
+
+// dsn adds the connection parameters to path unless it sets them itself.
+// The busy timeout comes first, so that switching to WAL waits for the lock.
+func (s *SQLite) dsn(path string) string {
+	params := url.Values{}
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		params, _ = url.ParseQuery(path[i+1:])
+		path = path[:i]
+	}
+
+	pragmas := make([]string, 0, len(params["_pragma"])+3)
+	for _, p := range []string{
+		fmt.Sprintf("busy_timeout(%d)", s.busyTimeout.Milliseconds()),
+		"foreign_keys(1)",
+		"journal_mode(WAL)",
+	} {
+		if !hasPragma(params["_pragma"], p[:strings.IndexByte(p, '(')]) {
+			pragmas = append(pragmas, p)
+		}
+	}
+	params["_pragma"] = append(pragmas, params["_pragma"]...)
+
+	defaults := map[string]string{
+		"_txlock": "immediate",
+		// The format go-sqlite3 wrote, so existing databases keep sorting.
+		"_time_format": "sqlite",
+	}
+	for k, v := range defaults {
+		if !params.Has(k) {
+			params.Set(k, v)
+		}
+	}
+
+	return path + "?" + params.Encode()
+}
+
+// hasPragma reports whether pragmas set the named pragma.
+func hasPragma(pragmas []string, name string) bool {
+	for _, p := range pragmas {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == name || strings.HasPrefix(p, name+"(") || strings.HasPrefix(p, name+"=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Close -.
+func (s *SQLite) Close() {
+	if s.DB != nil {
+		_ = s.DB.Close()
+	}
+}
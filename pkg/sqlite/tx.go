@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNestedTx is returned by ExecuteTx when called with a transaction.
+var ErrNestedTx = errors.New("cannot start a transaction within an existing transaction")
+
+// Querier runs statements; *sql.DB and *sql.Tx implement it, so a
+// repository works the same inside and outside of a transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ExecuteTx runs fn in a transaction started on db, committing it when fn
+// returns nil and rolling it back otherwise.
+func ExecuteTx(ctx context.Context, db Querier, fn func(tx *sql.Tx) error) error {
+	if _, ok := db.(*sql.Tx); ok {
+		return ErrNestedTx
+	}
+
+	d, ok := db.(*sql.DB)
+	if !ok {
+		return errors.New("ExecuteTx: querier cannot start transactions")
+	}
+
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return rbErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ExecuteTx runs fn in a transaction on the database.
+func (s *SQLite) ExecuteTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return ExecuteTx(ctx, s.DB, fn)
+}
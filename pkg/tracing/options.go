@@ -0,0 +1,40 @@
+package tracing
+
+// Option -.
+type Option func(*Tracing)
+
+// ServiceName -.
+func ServiceName(name string) Option {
+	return func(t *Tracing) {
+		t.serviceName = name
+	}
+}
+
+// ServiceVersion -.
+func ServiceVersion(version string) Option {
+	return func(t *Tracing) {
+		t.serviceVersion = version
+	}
+}
+
+// Endpoint is the host:port of the OTLP gRPC collector.
+func Endpoint(endpoint string) Option {
+	return func(t *Tracing) {
+		t.endpoint = endpoint
+	}
+}
+
+// Insecure disables TLS to the OTLP collector.
+func Insecure(insecure bool) Option {
+	return func(t *Tracing) {
+		t.insecure = insecure
+	}
+}
+
+// SampleRatio is the share of new traces that are recorded. Traces started
+// by a caller follow the caller's decision.
+func SampleRatio(ratio float64) Option {
+	return func(t *Tracing) {
+		t.sampleRatio = ratio
+	}
+}
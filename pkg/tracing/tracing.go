@@ -0,0 +1,119 @@
+// Package tracing sets up OpenTelemetry tracing.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Exporters.
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const _defaultSampleRatio = 1
+
+// ErrUnknownExporter is returned by New for an exporter it does not know.
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// Tracing owns the tracer provider installed as the global one.
+type Tracing struct {
+	serviceName    string
+	serviceVersion string
+	endpoint       string
+	insecure       bool
+	sampleRatio    float64
+
+	provider *sdktrace.TracerProvider
+}
+
+// New installs a global tracer provider exporting spans to exporter and
+// the W3C trace context propagator. With ExporterNone spans are not
+// recorded, but incoming trace IDs still propagate.
+func New(exporter string, opts ...Option) (*Tracing, error) {
+	t := &Tracing{
+		sampleRatio: _defaultSampleRatio,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exp sdktrace.SpanExporter
+		err error
+	)
+
+	switch exporter {
+	case ExporterNone:
+		return t, nil
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		exp, err = otlptracegrpc.New(context.Background(), t.otlpOptions()...)
+	default:
+		return nil, fmt.Errorf("tracing - New: %w: %q", ErrUnknownExporter, exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("tracing - New - %s exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(t.serviceName),
+		semconv.ServiceVersion(t.serviceVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing - New - resource.Merge: %w", err)
+	}
+
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(t.sampleRatio))),
+	)
+	otel.SetTracerProvider(t.provider)
+
+	return t, nil
+}
+
+func (t *Tracing) otlpOptions() []otlptracegrpc.Option {
+	var opts []otlptracegrpc.Option
+
+	// Without an endpoint the exporter reads OTEL_EXPORTER_OTLP_* variables.
+	if t.endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(t.endpoint))
+	}
+	if t.insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	return opts
+}
+
+// Shutdown flushes the spans not exported yet.
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+
+	return t.provider.Shutdown(ctx)
+}